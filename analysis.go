@@ -0,0 +1,266 @@
+package buna
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/jedib0t/go-pretty/table"
+	"golang.org/x/crypto/ssh/terminal"
+)
+
+// A brewing parameter that is analysed against the rating.
+// value returns false if the brewing has no value for the parameter.
+type brewingParameter struct {
+	name  string
+	value func(brewing brewing) (float64, bool)
+}
+
+var analysedBrewingParameters = []brewingParameter{
+	{"Grind setting", func(brewing brewing) (float64, bool) {
+		return float64(brewing.grindSetting), true
+	}},
+	{"Brew ratio (1:x)", brewRatio},
+	{"Total time (s)", func(brewing brewing) (float64, bool) {
+		return float64(brewing.totalBrewingTimeSec), true
+	}},
+	{"Rest days", restDays},
+}
+
+// Result of analysing a brewing parameter against the rating.
+type parameterAnalysis struct {
+	parameter       brewingParameter
+	xs              []float64
+	ratings         []float64
+	hasFit          bool
+	r               float64
+	slope           float64
+	intercept       float64
+	topMin          float64
+	topMax          float64
+	topBrewingCount int
+}
+
+// Returns the number of days between roasting and brewing.
+func restDays(brewing brewing) (float64, bool) {
+	const layout = "2006-01-02"
+
+	brewed, err := time.Parse(layout, timestampDate(brewing.date))
+	if err != nil {
+		return 0, false
+	}
+
+	roasted, err := time.Parse(layout, brewing.roastDate)
+	if err != nil {
+		return 0, false
+	}
+
+	return math.Round(brewed.Sub(roasted).Hours() / 24), true
+}
+
+// Returns the Pearson correlation coefficient of xs and ys.
+// Returns false if there are less than two points or either variable is constant.
+func pearsonCorrelation(xs []float64, ys []float64) (float64, bool) {
+	n := float64(len(xs))
+	if n < 2 {
+		return 0, false
+	}
+
+	meanX, meanY := mean(xs), mean(ys)
+	var covariance, varianceX, varianceY float64
+	for i := range xs {
+		dx, dy := xs[i]-meanX, ys[i]-meanY
+		covariance += dx * dy
+		varianceX += dx * dx
+		varianceY += dy * dy
+	}
+	if varianceX == 0 || varianceY == 0 {
+		return 0, false
+	}
+
+	return covariance / math.Sqrt(varianceX*varianceY), true
+}
+
+// Returns the slope and intercept of the least squares line through the points.
+// Returns false if there are less than two points or all xs are equal.
+func linearRegression(xs []float64, ys []float64) (float64, float64, bool) {
+	if len(xs) < 2 {
+		return 0, 0, false
+	}
+
+	meanX, meanY := mean(xs), mean(ys)
+	var covariance, varianceX float64
+	for i := range xs {
+		dx := xs[i] - meanX
+		covariance += dx * (ys[i] - meanY)
+		varianceX += dx * dx
+	}
+	if varianceX == 0 {
+		return 0, 0, false
+	}
+
+	slope := covariance / varianceX
+	return slope, meanY - slope*meanX, true
+}
+
+func mean(vals []float64) float64 {
+	var sum float64
+	for _, val := range vals {
+		sum += val
+	}
+	return sum / float64(len(vals))
+}
+
+// Describes the strength and direction of a correlation coefficient.
+func describeCorrelation(r float64) string {
+	direction := "positive"
+	if r < 0 {
+		direction = "negative"
+	}
+
+	switch abs := math.Abs(r); {
+	case abs < 0.1:
+		return "none"
+	case abs < 0.3:
+		return "weak " + direction
+	case abs < 0.5:
+		return "moderate " + direction
+	default:
+		return "strong " + direction
+	}
+}
+
+// Returns the lowest rating that is still among the top fifth of the ratings.
+// ratings must not be empty.
+func topRatingThreshold(ratings []float64) float64 {
+	sorted := append([]float64(nil), ratings...)
+	sort.Sort(sort.Reverse(sort.Float64Slice(sorted)))
+
+	return sorted[len(sorted)/5]
+}
+
+func analyseBrewingParameter(parameter brewingParameter, brewings []brewing, topThreshold float64) parameterAnalysis {
+	analysis := parameterAnalysis{
+		parameter: parameter,
+		topMin:    math.Inf(1),
+		topMax:    math.Inf(-1),
+	}
+
+	for _, brewing := range brewings {
+		x, ok := parameter.value(brewing)
+		if !ok {
+			continue
+		}
+
+		rating := float64(brewing.rating)
+		analysis.xs = append(analysis.xs, x)
+		analysis.ratings = append(analysis.ratings, rating)
+
+		if rating >= topThreshold {
+			analysis.topMin = math.Min(analysis.topMin, x)
+			analysis.topMax = math.Max(analysis.topMax, x)
+			analysis.topBrewingCount++
+		}
+	}
+
+	r, hasR := pearsonCorrelation(analysis.xs, analysis.ratings)
+	slope, intercept, hasRegression := linearRegression(analysis.xs, analysis.ratings)
+	analysis.hasFit = hasR && hasRegression
+	analysis.r, analysis.slope, analysis.intercept = r, slope, intercept
+
+	return analysis
+}
+
+// Prompts user for a brewing filter and analyses how the brewing parameters relate to the rating.
+func displayRatingCorrelations(ctx context.Context, db DB) error {
+	const maxAnalysedBrewings = 1000
+	const minAnalysedBrewings = 3
+	const plotHeight = 12
+	const maxPlotWidth = 60
+
+	session := sessionFromContext(ctx)
+	fmt.Println("Getting rating correlations (Enter " + session.quit + " to quit):")
+
+	brewingFilter, band, quit, err := getBrewingFilterInput(ctx, db, true)
+	if err != nil {
+		return fmt.Errorf("buna: analysis: failed to get brewing filter: %w", err)
+	}
+	if quit != answered {
+		fmt.Println(quitMsg)
+		return nil
+	}
+
+	brewings, err := db.getRatedBrewings(ctx, brewingFilter, band, maxAnalysedBrewings)
+	if err != nil {
+		return fmt.Errorf("buna: analysis: failed to get rated brewings: %w", err)
+	}
+
+	if len(brewings) < minAnalysedBrewings {
+		fmt.Printf("At least %d rated brewings are needed, found %d\n", minAnalysedBrewings, len(brewings))
+		return nil
+	}
+
+	ratings := make([]float64, len(brewings))
+	for i, brewing := range brewings {
+		ratings[i] = float64(brewing.rating)
+	}
+	topThreshold := topRatingThreshold(ratings)
+
+	t := table.NewWriter()
+
+	t.AppendHeader(table.Row{
+		"Parameter",
+		"Brewings",
+		"Correlation",
+		"Regression",
+		fmt.Sprintf("Top-rated range\n(rating >= %.0f)", topThreshold),
+	})
+
+	analyses := make([]parameterAnalysis, 0, len(analysedBrewingParameters))
+	for _, parameter := range analysedBrewingParameters {
+		analysis := analyseBrewingParameter(parameter, brewings, topThreshold)
+		analyses = append(analyses, analysis)
+
+		correlation, regression := "-", "-"
+		if analysis.hasFit {
+			correlation = fmt.Sprintf("r = %.2f\n(%v)", analysis.r, describeCorrelation(analysis.r))
+			regression = fmt.Sprintf("rating = %.2f + %.3f * x", analysis.intercept, analysis.slope)
+		}
+
+		topRange := "-"
+		if analysis.topBrewingCount > 0 {
+			topRange = formatAxisValue(analysis.topMin) + " - " + formatAxisValue(analysis.topMax)
+		}
+
+		t.AppendRow(table.Row{parameter.name, len(analysis.xs), correlation, regression, topRange})
+		t.AppendSeparator()
+	}
+
+	terminalWidth, _, err := terminal.GetSize(int(os.Stdin.Fd()))
+	if err != nil {
+		return fmt.Errorf("buna: analysis: failed to get terminal width: %w", err)
+	}
+	t.SetAllowedRowLength(terminalWidth)
+
+	t.SetOutputMirror(os.Stdout)
+	renderTable(ctx, t)
+
+	plotWidth := terminalWidth - 10
+	if plotWidth > maxPlotWidth {
+		plotWidth = maxPlotWidth
+	}
+
+	for _, analysis := range analyses {
+		if len(analysis.xs) == 0 {
+			continue
+		}
+
+		fmt.Println()
+		fmt.Print(renderScatterPlot(analysis.xs, analysis.ratings, plotWidth, plotHeight, analysis.parameter.name, "Rating"))
+	}
+
+	return nil
+}
@@ -0,0 +1,218 @@
+package buna
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Ways of entering the coffee and water weights of a brewing.
+// The weight that is not entered is derived from the brew ratio.
+const (
+	coffeeAndWaterInput = "coffee and water"
+	coffeeAndRatioInput = "coffee and ratio"
+	waterAndRatioInput  = "water and ratio"
+)
+
+// Bounds of the x in a brew ratio of 1:x
+const (
+	minBrewRatio = 1
+	maxBrewRatio = 30
+)
+
+// A range of brew ratios from 1:min up to but excluding 1:max.
+// min is 0 for the first band and max is 0 for the last band.
+type ratioBand struct {
+	name string
+	min  float64
+	max  float64
+}
+
+// Ratio bands brewings are filtered and grouped by, ordered from the strongest to the weakest brews.
+// The zero ratioBand matches every brewing.
+var ratioBands = []ratioBand{
+	{"below 1:1.5", 0, 1.5},
+	{"1:1.5 to 1:2.5", 1.5, 2.5},
+	{"1:2.5 to 1:4", 2.5, 4},
+	{"1:4 to 1:12", 4, 12},
+	{"1:12 to 1:14", 12, 14},
+	{"1:14 to 1:15", 14, 15},
+	{"1:15 to 1:16", 15, 16},
+	{"1:16 to 1:17", 16, 17},
+	{"1:17 to 1:18", 17, 18},
+	{"1:18 and above", 18, 0},
+}
+
+// Brewings of a ratio band
+type ratioBandStatistics struct {
+	band          ratioBand
+	brewingCount  int
+	ratedCount    int
+	averageRating float64
+	averageRatio  float64
+
+	// Number of brewings with each extraction verdict
+	extractionCounts map[string]int
+}
+
+// Returns the water weight per gram of coffee, the x in a brew ratio of 1:x.
+// Returns false if the coffee weight is unknown.
+func brewRatio(brewing brewing) (float64, bool) {
+	return brewing.waterGrams / brewing.coffeeGrams, brewing.coffeeGrams > 0
+}
+
+// Returns the brew ratio for display, e.g. "1:16.7".
+func formatBrewRatio(brewing brewing) string {
+	ratio, ok := brewRatio(brewing)
+	if !ok {
+		return "Unknown"
+	}
+	return fmt.Sprintf("1:%.1f", ratio)
+}
+
+// Parses a brew ratio given as "1:x" or as x.
+// Returns false if the input is not a ratio between minBrewRatio and maxBrewRatio.
+func parseBrewRatio(input string) (float64, bool) {
+	input = strings.TrimSpace(input)
+	if i := strings.Index(input, ":"); i != -1 {
+		if strings.TrimSpace(input[:i]) != "1" {
+			return 0, false
+		}
+		input = strings.TrimSpace(input[i+1:])
+	}
+
+	ratio, err := strconv.ParseFloat(input, 64)
+	if err != nil || math.IsNaN(ratio) || ratio < minBrewRatio || ratio > maxBrewRatio {
+		return 0, false
+	}
+
+	return ratio, true
+}
+
+// Returns the index of the ratio band the brew ratio is in.
+func ratioBandIndex(ratio float64) int {
+	for i, band := range ratioBands {
+		if band.max == 0 || ratio < band.max {
+			return i
+		}
+	}
+	return len(ratioBands) - 1
+}
+
+// Returns an SQL expression for the index in ratioBands of the brew ratio expression ratioExpr,
+// which is NULL if ratioExpr is NULL.
+func ratioBandIndexSQL(ratioExpr string) string {
+	var sb strings.Builder
+	sb.WriteString("CASE WHEN " + ratioExpr + " IS NULL THEN NULL")
+	for i, band := range ratioBands {
+		if band.max == 0 {
+			break
+		}
+		sb.WriteString(fmt.Sprintf(" WHEN %v < %v THEN %d", ratioExpr, band.max, i))
+	}
+	sb.WriteString(fmt.Sprintf(" ELSE %d END", len(ratioBands)-1))
+
+	return sb.String()
+}
+
+// Rounds a weight in grams to the 0.1 g a scale shows.
+func roundGrams(grams float64) float64 {
+	return math.Round(grams*10) / 10
+}
+
+// Prompts user for the coffee and water weights, either directly or as one of them and the brew ratio.
+// The weights are asked for with the coffee_grams and water_grams fields of fields, whose suggestions depend on values.
+// Returns coffeeGrams, waterGrams, promptResult, error
+func getBrewingWeightsInput(ctx context.Context, db DB, exits promptExits, fields fieldSet, values fieldValues) (float64, float64, promptResult, error) {
+	units := sessionFromContext(ctx).unitSystem
+	coffeeField, ok := fields.field("coffee_grams")
+	if !ok {
+		return 0, 0, answered, unknownFieldError("coffee_grams")
+	}
+	waterField, ok := fields.field("water_grams")
+	if !ok {
+		return 0, 0, answered, unknownFieldError("water_grams")
+	}
+
+	fmt.Print("Enter the weights as (defaults to coffee and water): ")
+	inputMode, quit := validateStrInput(exits, true, []string{coffeeAndWaterInput, coffeeAndRatioInput, waterAndRatioInput}, nil)
+	if quit != answered {
+		return 0, 0, quit, nil
+	}
+
+	var coffeeGrams, waterGrams float64
+	var err error
+	switch inputMode {
+	case coffeeAndRatioInput:
+		coffeeGrams, quit, err = coffeeField.askQuantity(ctx, db, exits, values)
+		if err != nil || quit != answered {
+			return 0, 0, quit, err
+		}
+
+		ratio, quit := getBrewRatioInput(exits, false)
+		if quit != answered {
+			return 0, 0, quit, nil
+		}
+		waterGrams = roundGrams(coffeeGrams * ratio)
+	case waterAndRatioInput:
+		waterGrams, quit, err = waterField.askQuantity(ctx, db, exits, values)
+		if err != nil || quit != answered {
+			return 0, 0, quit, err
+		}
+
+		ratio, quit := getBrewRatioInput(exits, false)
+		if quit != answered {
+			return 0, 0, quit, nil
+		}
+		coffeeGrams = roundGrams(waterGrams / ratio)
+	default:
+		coffeeGrams, quit, err = coffeeField.askQuantity(ctx, db, exits, values)
+		if err != nil || quit != answered {
+			return 0, 0, quit, err
+		}
+
+		waterGrams, quit, err = waterField.askQuantity(ctx, db, exits, values)
+		if err != nil || quit != answered {
+			return 0, 0, quit, err
+		}
+	}
+
+	fmt.Printf("%v of coffee and %v of water (%v)\n", units.formatQuantityWithUnit(coffeeGrams, coffeeWeight), units.formatQuantityWithUnit(waterGrams, waterWeight), formatBrewRatio(brewing{coffeeGrams: coffeeGrams, waterGrams: waterGrams}))
+
+	return coffeeGrams, waterGrams, answered, nil
+}
+
+// Prompts user until a valid brew ratio is entered.
+// Returns ratio, promptResult
+// Optional ratios default to 0.
+func getBrewRatioInput(exits promptExits, isOptional bool) (float64, promptResult) {
+	fmt.Printf("Enter the brew ratio (e.g. 1:16.5, 1:%v <= x <= 1:%v): ", minBrewRatio, maxBrewRatio)
+
+	return validateFloatInput(exits, isOptional, parseBrewRatio, "Input invalid. Please try again: ", nil, nil)
+}
+
+// Prompts user for an optional ratio band.
+// Returns ratioBand, promptResult
+// The zero ratioBand is returned if no band is selected.
+func getRatioBandInput(exits promptExits) (ratioBand, promptResult) {
+	names := make([]string, len(ratioBands))
+	for i, band := range ratioBands {
+		names[i] = band.name
+	}
+
+	fmt.Print("Enter the brew ratio band: ")
+	name, quit := validateStrInput(exits, true, names, nil)
+	if quit != answered || name == "" {
+		return ratioBand{}, quit
+	}
+
+	for _, band := range ratioBands {
+		if band.name == name {
+			return band, answered
+		}
+	}
+
+	return ratioBand{}, answered
+}
@@ -0,0 +1,37 @@
+package buna
+
+import "testing"
+
+func TestParseBrewRatio(t *testing.T) {
+	tests := []struct {
+		input  string
+		want   float64
+		wantOK bool
+	}{
+		{"16", 16, true},
+		{"16.5", 16.5, true},
+		{"1:16", 16, true},
+		{" 1 : 16.5 ", 16.5, true},
+		{"1:1", 1, true},
+		{"1:30", 30, true},
+		{"1:0.5", 0, false},
+		{"31", 0, false},
+		{"2:16", 0, false},
+		{":16", 0, false},
+		{"1:", 0, false},
+		{"1:16:1", 0, false},
+		{"16g", 0, false},
+		{"NaN", 0, false},
+		{"1:inf", 0, false},
+		{"", 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, ok := parseBrewRatio(tt.input)
+			if ok != tt.wantOK || got != tt.want {
+				t.Errorf("parseBrewRatio(%q) = %v, %v, want %v, %v", tt.input, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
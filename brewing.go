@@ -2,18 +2,21 @@ package buna
 
 import (
 	"context"
+	"database/sql"
 	"errors"
 	"fmt"
 	"os"
+	"sort"
 	"strings"
-	"time"
 
 	"github.com/jedib0t/go-pretty/table"
 	"golang.org/x/crypto/ssh/terminal"
 )
 
 type brewing struct {
-	date                                   string
+	id                                     int
+	date                                   string // RFC 3339 timestamp
+	timeKnown                              bool   // false for brewings added before brewing times were recorded
 	coffeeName                             string
 	coffeeRoaster                          string
 	brewingMethodName                      string
@@ -25,147 +28,236 @@ type brewing struct {
 	waterGrams                             float64
 	v60FilterType                          string
 	rating                                 int
+	scores                                 brewingScores
+	extraction                             string
 	recommendedGrindSettingAdjustment      string
 	recommendedCoffeeWeightAdjustmentGrams float64
 	notes                                  string
+	flavors                                []string
+	userName                               string // empty for brewings not attributed to a user
 }
 
-func addBrewing(ctx context.Context, db DB) error {
-	fmt.Println("Adding new coffee brewing (Enter # to quit):")
-	brewingDate, quit := getDateInput(quitStr, false, "Enter brewing ?: ", []date{
-		{year: time.Now().Year(), month: int(time.Now().Month()), day: time.Now().Day()},
-		{year: time.Now().Year(), month: int(time.Now().Month()), day: time.Now().Day() - 1},
-	})
-	if quit {
-		fmt.Println(quitMsg)
-		return nil
-	}
-
-	coffeeName, quit, err := getCoffeeNameWithSuggestions(ctx, db, quitStr, false)
-	if err != nil {
-		return fmt.Errorf("buna: brewing: failed to get coffee name: %w", err)
-	}
-	if quit {
-		fmt.Println(quitMsg)
-		return nil
-	}
-
-	coffeeRoaster, quit, err := getCoffeeRoasterWithSuggestions(ctx, db, quitStr, coffeeName)
-	if err != nil {
-		return fmt.Errorf("buna: brewing: failed to get coffee roaster: %w", err)
-	}
-	if quit {
-		fmt.Println(quitMsg)
-		return nil
-	}
+// Fields of a brewing that are entered directly.
+// Detailed scores and flavors are only entered interactively.
+var brewingFields = fieldSet{
+	{name: "date", key: "Brewing date", prompt: "Enter brewing date", help: "Brewing date with an optional time of day, defaults to now", kind: timestampField, optional: true},
+	{name: "coffee", key: "Coffee", prompt: "Enter coffee name", help: "Name of an existing coffee", kind: textField,
+		suggest: func(ctx context.Context, db DB, values fieldValues) (interface{}, error) {
+			return db.getCoffeeNameSuggestions(ctx, sessionFromContext(ctx).config.intValue("display.coffee_suggestions"))
+		}},
+	{name: "roaster", key: "Roaster", prompt: "Enter roaster/producer name", help: "Roaster of the coffee", kind: textField,
+		suggest: func(ctx context.Context, db DB, values fieldValues) (interface{}, error) {
+			return db.getRoastersByCoffeeName(ctx, values.textValue("coffee"), sessionFromContext(ctx).config.intValue("display.roaster_suggestions"))
+		}},
+	{name: "method", key: "Brewing method", prompt: "Enter brewing method name", help: "Name of an existing brewing method", kind: textField, defaultSetting: "defaults.method",
+		suggest: func(ctx context.Context, db DB, values fieldValues) (interface{}, error) {
+			return db.getMostRecentlyUsedBrewingMethodNames(ctx, sessionFromContext(ctx).config.intValue("display.method_suggestions"))
+		}},
+	{name: "roast_date", key: "Roast date", prompt: "Enter roast date", help: "Roast date of the coffee", kind: dateField, optional: true,
+		suggest: func(ctx context.Context, db DB, values fieldValues) (interface{}, error) {
+			roastDate, err := db.getLastCoffeeRoastDate(ctx, values.textValue("coffee"))
+			if err != nil || roastDate.year == 0 {
+				return nil, err
+			}
+			return []date{roastDate}, nil
+		}},
+	{name: "grinder", key: "Grinder", prompt: "Enter coffee grinder name", help: "Name of an existing coffee grinder", kind: textField, defaultSetting: "defaults.grinder",
+		suggest: func(ctx context.Context, db DB, values fieldValues) (interface{}, error) {
+			return db.getMostRecentlyUsedCoffeeGrinderNames(ctx, sessionFromContext(ctx).config.intValue("display.grinder_suggestions"))
+		}},
+	// This assumes that every grinder has settings in the range 0 to 50
+	// An improvement would be to look up the possible grind settings using the grinder name
+	{name: "grind_setting", key: "Grind setting", prompt: "Enter grind setting", help: "Grind setting", kind: intField, min: 0, max: 50},
+	{name: "total_brewing_time_sec", key: "Total brewing time (s)", prompt: "Enter the total brewing time in seconds", help: "Total brewing time in seconds", kind: intField, min: 10, max: 1800},
+	{name: "coffee_grams", key: "Coffee weight (g)", prompt: "Enter the coffee weight used", help: "Coffee weight with an optional unit", kind: quantityField, quantity: coffeeWeight, min: 5, max: 100,
+		suggest: func(ctx context.Context, db DB, values fieldValues) (interface{}, error) {
+			return db.getMostRecentlyUsedCoffeeWeights(ctx, values.textValue("method"), values.textValue("grinder"), sessionFromContext(ctx).config.intValue("display.weight_suggestions"))
+		}},
+	{name: "water_grams", key: "Water weight (g)", prompt: "Enter the water weight used", help: "Water weight with an optional unit", kind: quantityField, quantity: waterWeight, min: 20, max: 2000,
+		suggest: func(ctx context.Context, db DB, values fieldValues) (interface{}, error) {
+			return db.getMostRecentlyUsedWaterWeights(ctx, values.textValue("method"), values.textValue("grinder"), sessionFromContext(ctx).config.intValue("display.weight_suggestions"))
+		}},
+	{name: "v60_filter_type", key: "V60 filter type", prompt: "Enter v60 filter type", help: "V60 filter type", kind: textField, optional: true, options: []string{"eu", "jp"}},
+	{name: "rating", key: "Rating", prompt: "Enter your rating for this brew", help: "Rating", kind: intField, optional: true, min: 1, max: 10},
+	{name: "extraction", key: "Extraction", prompt: "Enter extraction verdict (under/sour, balanced or over/bitter)", help: "Extraction verdict, one of under, balanced or over or an alias like sour or bitter", kind: textField, optional: true,
+		normalize: parseExtraction,
+		suggest: func(ctx context.Context, db DB, values fieldValues) (interface{}, error) {
+			return []string{underExtracted, balancedExtracted, overExtracted}, nil
+		}},
+	{name: "recommended_grind_setting_adjustment", key: "Recommended grind setting adjustment", prompt: "Enter recommended grind setting adjustment", help: "Recommended grind setting adjustment", kind: textField, optional: true, options: []string{"lower", "higher"}},
+	{name: "recommended_coffee_weight_adjustment_grams", key: "Recommended coffee weight adjustment (g)", prompt: "Enter recommended coffee weight adjustment", help: "Recommended coffee weight adjustment with an optional unit", kind: quantityField, optional: true, quantity: coffeeWeight, min: -20, max: 20},
+	{name: "notes", key: "Notes", prompt: "Enter some brewing notes", help: "Brewing notes", kind: textField, optional: true},
+}
 
-	brewingMethodName, quit, err := getBrewingMethodNameWithSuggestions(ctx, db, quitStr, false)
-	if err != nil {
-		return fmt.Errorf("buna: brewing: failed to get brewing method name: %w", err)
-	}
-	if quit {
-		fmt.Println(quitMsg)
-		return nil
+// Returns the brewing of the values of brewingFields.
+func brewingFromFieldValues(values fieldValues) brewing {
+	return brewing{
+		date:                                   values.textValue("date"),
+		coffeeName:                             values.textValue("coffee"),
+		coffeeRoaster:                          values.textValue("roaster"),
+		brewingMethodName:                      values.textValue("method"),
+		roastDate:                              values.textValue("roast_date"),
+		grinderName:                            values.textValue("grinder"),
+		grindSetting:                           values.intValue("grind_setting"),
+		totalBrewingTimeSec:                    values.intValue("total_brewing_time_sec"),
+		coffeeGrams:                            values.quantityValue("coffee_grams"),
+		waterGrams:                             values.quantityValue("water_grams"),
+		v60FilterType:                          values.textValue("v60_filter_type"),
+		rating:                                 values.intValue("rating"),
+		extraction:                             values.textValue("extraction"),
+		recommendedGrindSettingAdjustment:      values.textValue("recommended_grind_setting_adjustment"),
+		recommendedCoffeeWeightAdjustmentGrams: values.quantityValue("recommended_coffee_weight_adjustment_grams"),
+		notes:                                  values.textValue("notes"),
 	}
+}
 
-	roastDate, quit, err := getCoffeeRoastDateWithSuggestions(ctx, db, quitStr, coffeeName)
+func addBrewing(ctx context.Context, db DB) error {
+	r, quit, err := startDraft(ctx, db, brewingDraftFlow)
 	if err != nil {
-		return fmt.Errorf("buna: brewing: failed to get coffee roast date: %w", err)
+		return fmt.Errorf("buna: brewing: failed to start brewing draft: %w", err)
 	}
-	if quit {
+	if quit != answered {
 		fmt.Println(quitMsg)
 		return nil
 	}
 
-	grinderName, quit, err := getCoffeeGrinderNameWithSuggestions(ctx, db, quitStr, false)
-	if err != nil {
-		return fmt.Errorf("buna: brewing: failed to get coffee grinder name: %w", err)
-	}
-	if quit {
-		fmt.Println(quitMsg)
-		return nil
-	}
+	return addBrewingFromDraft(ctx, db, r)
+}
 
-	grindSetting, quit := getCoffeeGrindSettingWithSuggestions(quitStr)
-	if quit {
-		fmt.Println(quitMsg)
-		return nil
-	}
+// Prompts for every answer of the brewing that is not in the draft yet.
+// The draft is kept until the brewing was inserted, so that quitting or a failed insert does not lose any answers.
+func addBrewingFromDraft(ctx context.Context, db DB, r *draftRecorder) error {
+	session := sessionFromContext(ctx)
+	fmt.Println("Adding new coffee brewing (Enter " + session.quit + " to quit, " + session.back + " to go back):")
 
-	totalBrewingTimeSec, quit := getTotalCoffeeBrewingTimeSecWithSuggestions(quitStr)
-	if quit {
-		fmt.Println(quitMsg)
-		return nil
-	}
+	resumeMsg := "\nContinuing with the new coffee brewing (Enter " + session.quit + " to quit, " + session.back + " to go back):"
 
-	coffeeGrams, quit, err := getCoffeeWeightWithSuggestions(ctx, db, quitStr, brewingMethodName, grinderName, false)
-	if err != nil {
-		return fmt.Errorf("buna: brewing: failed to get coffee weight: %w", err)
-	}
-	if quit {
-		fmt.Println(quitMsg)
-		return nil
-	}
+	var (
+		brewingDate                            string
+		brewedCoffee                           draftCoffee
+		brewingMethodName                      string
+		roastDate                              string
+		grinderName                            string
+		grindSetting                           int
+		totalBrewingTimeSec                    int
+		weights                                [2]float64 // Coffee and water weight in grams
+		v60FilterType                          string
+		rating                                 int
+		scores                                 brewingScores
+		extraction                             string
+		recommendedGrindSettingAdjustment      string
+		recommendedCoffeeWeightAdjustmentGrams float64
+		notes                                  string
+		flavors                                []string
+	)
 
-	waterGrams, quit, err := getWaterWeightWithSuggestions(ctx, db, quitStr, brewingMethodName, grinderName, false)
+	// Answers the suggestions of later steps depend on
+	answers := func() fieldValues {
+		return fieldValues{"coffee": brewedCoffee.Name, "method": brewingMethodName, "grinder": grinderName}
+	}
+
+	// The coffee, brewing method and grinder are looked up by name and can be created on the way, the weights, detailed scores
+	// and flavors consist of several values and the grind setting adjustment defaults to the extraction verdict,
+	// so they are asked for with hand-written steps instead of fields
+	steps := []formStep{
+		brewingFields.step(ctx, db, "date", &brewingDate, nil),
+		{key: "Coffee", value: &brewedCoffee, prompt: func(exits promptExits) (promptResult, error) {
+			c, quit, err := getExistingCoffeeWithSuggestions(ctx, db, exits, resumeMsg)
+			brewedCoffee = draftCoffee{Name: c.name, Roaster: c.roaster}
+			return quit, err
+		}, check: func() (bool, error) {
+			return referenceExists(db.getCoffeeIDByNameRoaster(ctx, brewedCoffee.Name, brewedCoffee.Roaster))
+		}},
+		{key: "Brewing method", value: &brewingMethodName, prompt: func(exits promptExits) (promptResult, error) {
+			var quit promptResult
+			var err error
+			brewingMethodName, quit, err = getExistingBrewingMethodNameWithSuggestions(ctx, db, exits, resumeMsg)
+			return quit, err
+		}, check: func() (bool, error) {
+			return referenceExists(db.getMethodIDByName(ctx, brewingMethodName))
+		}},
+		brewingFields.step(ctx, db, "roast_date", &roastDate, answers),
+		{key: "Grinder", value: &grinderName, prompt: func(exits promptExits) (promptResult, error) {
+			var quit promptResult
+			var err error
+			grinderName, quit, err = getExistingGrinderNameWithSuggestions(ctx, db, exits, resumeMsg)
+			return quit, err
+		}, check: func() (bool, error) {
+			return referenceExists(db.getGrinderIDByName(ctx, grinderName))
+		}},
+		brewingFields.step(ctx, db, "grind_setting", &grindSetting, nil),
+		brewingFields.step(ctx, db, "total_brewing_time_sec", &totalBrewingTimeSec, nil),
+		{key: "Coffee and water weights (g)", value: &weights, prompt: func(exits promptExits) (promptResult, error) {
+			var quit promptResult
+			var err error
+			weights[0], weights[1], quit, err = getBrewingWeightsInput(ctx, db, exits, brewingFields, answers())
+			return quit, err
+		}},
+		brewingFields.step(ctx, db, "v60_filter_type", &v60FilterType, nil),
+		brewingFields.step(ctx, db, "rating", &rating, nil),
+		{key: "Detailed scores", value: &scores, prompt: func(exits promptExits) (promptResult, error) {
+			var quit promptResult
+			scores, quit = getBrewingScoresInput(exits)
+			return quit, nil
+		}},
+		brewingFields.step(ctx, db, "extraction", &extraction, nil),
+		{key: "Recommended grind setting adjustment", value: &recommendedGrindSettingAdjustment, prompt: func(exits promptExits) (promptResult, error) {
+			var quit promptResult
+			recommendedGrindSettingAdjustment, quit = getRecommendedGrindSettingAdjustmentWithSuggestions(exits, extraction)
+			return quit, nil
+		}},
+		brewingFields.step(ctx, db, "recommended_coffee_weight_adjustment_grams", &recommendedCoffeeWeightAdjustmentGrams, nil),
+		brewingFields.step(ctx, db, "notes", &notes, nil),
+		{key: "Flavors", value: &flavors, prompt: func(exits promptExits) (promptResult, error) {
+			var quit promptResult
+			flavors, quit = getFlavorsInput(exits, "brewing")
+			return quit, nil
+		}},
+	}
+
+	quit, err := runForm(ctx, r, steps)
 	if err != nil {
-		return fmt.Errorf("buna: brewing: failed to get water weight: %w", err)
-	}
-	if quit {
-		fmt.Println(quitMsg)
-		return nil
-	}
-
-	v60FilterType, quit := getV60FilterTypeWithSuggestions(quitStr)
-	if quit {
-		fmt.Println(quitMsg)
-		return nil
-	}
-
-	rating, quit := getCoffeeRatingWithSuggestions(quitStr)
-	if quit {
-		fmt.Println(quitMsg)
-		return nil
-	}
-
-	recommendedGrindSettingAdjustment, quit := getRecommendedGrindSettingAdjustmentWithSuggestions(quitStr)
-	if quit {
-		fmt.Println(quitMsg)
-		return nil
+		return fmt.Errorf("buna: brewing: failed to run brewing form: %w", err)
 	}
-
-	recommendedCoffeeWeightAdjustmentGrams, quit := getRecommendedCoffeeWeightAdjustmentGramsWithSuggestions(quitStr)
-	if quit {
-		fmt.Println(quitMsg)
-		return nil
-	}
-
-	notes, quit := getNotes(quitStr, true, "brewing")
-	if quit {
-		fmt.Println(quitMsg)
+	if quit != answered {
+		r.printQuitMsg()
 		return nil
 	}
 
 	brewing := brewing{
-		date:                                   createDateString(brewingDate),
-		coffeeName:                             coffeeName,
-		coffeeRoaster:                          coffeeRoaster,
+		date:                                   brewingDate,
+		coffeeName:                             brewedCoffee.Name,
+		coffeeRoaster:                          brewedCoffee.Roaster,
 		brewingMethodName:                      brewingMethodName,
-		roastDate:                              createDateString(roastDate),
+		roastDate:                              roastDate,
 		grinderName:                            grinderName,
 		grindSetting:                           grindSetting,
 		totalBrewingTimeSec:                    totalBrewingTimeSec,
-		coffeeGrams:                            coffeeGrams,
-		waterGrams:                             waterGrams,
+		coffeeGrams:                            weights[0],
+		waterGrams:                             weights[1],
 		v60FilterType:                          v60FilterType,
 		rating:                                 rating,
+		scores:                                 scores,
+		extraction:                             extraction,
 		recommendedGrindSettingAdjustment:      recommendedGrindSettingAdjustment,
 		recommendedCoffeeWeightAdjustmentGrams: recommendedCoffeeWeightAdjustmentGrams,
 		notes:                                  notes,
+		flavors:                                flavors,
+	}
+
+	// The coffee, brewing method or grinder might have been removed or renamed since they were entered
+	err = db.insertBrewing(ctx, brewing)
+	if errors.Is(err, sql.ErrNoRows) {
+		fmt.Println("Unable to add the brewing, as its coffee, brewing method or grinder no longer exists")
+		fmt.Println("Your answers are kept as a draft. Resume it to enter them again.")
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("buna: brewing: failed to insert coffee brewing, the answers are kept as a draft: %w", err)
 	}
 
-	if err := db.insertBrewing(ctx, brewing); err != nil {
-		return fmt.Errorf("buna: brewing: failed to insert coffee brewing: %w", err)
+	if err := r.discard(ctx); err != nil {
+		return fmt.Errorf("buna: brewing: failed to discard brewing draft: %w", err)
 	}
 
 	fmt.Println("Added coffee brewing successfully")
@@ -177,18 +269,21 @@ func retrieveBrewing(ctx context.Context, db DB) error {
 		0: "Retrieve brewing suggestions",
 		1: "Retrieve brewing ordered by last added",
 		2: "Retrieve brewing ordered by rating",
+		3: "Retrieve brewings by query",
+		4: "Retrieve brewing details",
 	}
 
-	fmt.Println("Retrieving brewing (Enter # to quit):")
-	if err := displayIntOptions(options); err != nil {
+	session := sessionFromContext(ctx)
+	fmt.Println("Retrieving brewing (Enter " + session.quit + " to quit):")
+	if err := displayIntOptions(ctx, options); err != nil {
 		return fmt.Errorf("buna: brewing: failed to display int options: %w", err)
 	}
 
-	selection, quit, err := getIntSelection(options, quitStr)
+	selection, quit, err := getIntSelection(ctx, options, quitExits(ctx))
 	if err != nil {
 		return fmt.Errorf("buna: brewing: failed to get int selection: %w", err)
 	}
-	if quit {
+	if quit != answered {
 		fmt.Println(quitMsg)
 		return nil
 	}
@@ -214,45 +309,116 @@ func runRetrieveBrewingSelection(ctx context.Context, selection int, db DB) erro
 		if err := displayBrewingsByRating(ctx, db); err != nil {
 			return fmt.Errorf("buna: brewing: failed to display brewings by rating: %w", err)
 		}
+	case 3:
+		if err := displayBrewingsByQuery(ctx, db); err != nil {
+			return fmt.Errorf("buna: brewing: failed to display brewings by query: %w", err)
+		}
+	case 4:
+		if err := displayBrewingDetailsByID(ctx, db); err != nil {
+			return fmt.Errorf("buna: brewing: failed to display brewing details: %w", err)
+		}
 	default:
 		return errors.New("buna: brewing: invalid retrieve selection")
 	}
 	return nil
 }
 
+// orderByName must be "id" or "rating".
 func displayBrewingsBy(ctx context.Context, db DB, orderByName string) error {
-	const defaultDisplayAmount = 5
-	const maxDisplayAmount = 30
-	const maxNoteFieldWidth = 50
-
-	fmt.Print("Enter a limit for the number of brewings to display: ")
-	limit, quit := validateIntInput(quitStr, true, 1, maxDisplayAmount, []int{})
-	if quit {
+	pageSize, quit := getBrewingPageSize(ctx)
+	if quit != answered {
 		fmt.Println(quitMsg)
 		return nil
 	}
-	if limit == 0 {
-		limit = defaultDisplayAmount
+
+	var keyOf func(brewing brewing) interface{}
+	if orderByName == "rating" {
+		keyOf = func(brewing brewing) interface{} { return brewing.rating }
 	}
 
-	brewings, err := db.getBrewingsOrderByDesc(ctx, limit, orderByName)
-	if err != nil {
-		return fmt.Errorf("buna: brewing: failed to get brewings order by desc: %w", err)
+	if err := browseBrewings(ctx, pageSize, func(after *pageCursor, limit int) ([]brewing, error) {
+		return db.getBrewingsOrderByDesc(ctx, after, limit, orderByName)
+	}, keyOf, nil); err != nil {
+		return fmt.Errorf("buna: brewing: failed to browse brewings order by desc: %w", err)
+	}
+
+	return nil
+}
+
+// Returns pageSize, promptResult
+func getBrewingPageSize(ctx context.Context) (int, promptResult) {
+	defaultPageSize := sessionFromContext(ctx).config.intValue("display.brewings_page_size")
+	const maxPageSize = 30
+
+	fmt.Print("Enter the number of brewings to display per page: ")
+	pageSize, quit := validateIntInput(quitExits(ctx), true, 1, maxPageSize, []int{})
+	if quit != answered {
+		return 0, quit
+	}
+
+	if pageSize == 0 {
+		pageSize = defaultPageSize
 	}
 
+	return pageSize, answered
+}
+
+// Displays the brewings retrieved by getBrewings page by page.
+// keyOf returns the page cursor key of a brewing and is nil if the brewings are only ordered by id.
+// dateCursor is nil if the brewings are not ordered by date.
+func browseBrewings(ctx context.Context, pageSize int, getBrewings func(after *pageCursor, limit int) ([]brewing, error), keyOf func(brewing brewing) interface{}, dateCursor func(date string) (pageCursor, error)) error {
+	var brewings []brewing
+	listing := pagedListing{
+		fetch: func(after *pageCursor, limit int) ([]pageCursor, error) {
+			var err error
+			brewings, err = getBrewings(after, limit)
+			if err != nil {
+				return nil, fmt.Errorf("buna: brewing: failed to get brewings: %w", err)
+			}
+
+			cursors := make([]pageCursor, len(brewings))
+			for i, brewing := range brewings {
+				cursors[i].id = brewing.id
+				if keyOf != nil {
+					cursors[i].key = keyOf(brewing)
+				}
+			}
+			return cursors, nil
+		},
+		display: func(n int) error {
+			return displayBrewings(ctx, brewings[:n])
+		},
+		dateCursor: dateCursor,
+	}
+
+	if err := browsePages(quitExits(ctx), listing, pageSize); err != nil {
+		return fmt.Errorf("buna: brewing: failed to browse pages: %w", err)
+	}
+
+	return nil
+}
+
+func displayBrewings(ctx context.Context, brewings []brewing) error {
+	const maxNoteFieldWidth = 50
+
+	units := sessionFromContext(ctx).unitSystem
 	t := table.NewWriter()
 
 	t.AppendHeader(table.Row{
+		"ID",
 		"Date",
 		"Coffee\nName",
 		"Method",
 		"Grind\nSetting",
 		"Time\n(s)",
-		"Coffee\nWeight\n(g)",
-		"Water\nWeight\n(g)",
+		units.quantityHeader("Coffee\nWeight", coffeeWeight),
+		units.quantityHeader("Water\nWeight", waterWeight),
+		"Ratio",
 		"Rating",
+		"Scores",
+		"Extraction",
 		"Recommended\nGrind\nAdjustment",
-		"Recommended\nCoffee\nAdjustment\n(g)",
+		units.quantityHeader("Recommended\nCoffee\nAdjustment", coffeeWeight),
 		"V60\nFilter\nType",
 		"Notes",
 		"Grinder",
@@ -269,16 +435,20 @@ func displayBrewingsBy(ctx context.Context, db DB, orderByName string) error {
 		coffeeRoaster := strings.ReplaceAll(brewing.coffeeRoaster, " ", "\n")
 
 		row := table.Row{
-			brewing.date,
+			brewing.id,
+			formatTimestamp(brewing.date, brewing.timeKnown),
 			coffeeName,
 			brewingMethodName,
 			brewing.grindSetting,
 			brewing.totalBrewingTimeSec,
-			brewing.coffeeGrams,
-			brewing.waterGrams,
+			units.formatQuantity(brewing.coffeeGrams, coffeeWeight),
+			units.formatQuantity(brewing.waterGrams, waterWeight),
+			formatBrewRatio(brewing),
 			brewing.rating,
+			formatBrewingScores(brewing.scores),
+			brewing.extraction,
 			brewing.recommendedGrindSettingAdjustment,
-			brewing.recommendedCoffeeWeightAdjustmentGrams,
+			units.formatQuantity(brewing.recommendedCoffeeWeightAdjustmentGrams, coffeeWeight),
 			brewing.v60FilterType,
 			notes,
 			grinderName,
@@ -297,13 +467,14 @@ func displayBrewingsBy(ctx context.Context, db DB, orderByName string) error {
 	t.SetAllowedRowLength(terminalWidth)
 
 	t.SetOutputMirror(os.Stdout)
-	t.Render()
+	renderTable(ctx, t)
 
 	return nil
 }
 
 func displayBrewingsByLastAdded(ctx context.Context, db DB) error {
-	fmt.Println("Displaying brewings by last added (Enter # to quit):")
+	session := sessionFromContext(ctx)
+	fmt.Println("Displaying brewings by last added (Enter " + session.quit + " to quit):")
 
 	if err := displayBrewingsBy(ctx, db, "id"); err != nil {
 		return fmt.Errorf("buna: brewing: failed to get brewings by last added: %w", err)
@@ -313,7 +484,8 @@ func displayBrewingsByLastAdded(ctx context.Context, db DB) error {
 }
 
 func displayBrewingsByRating(ctx context.Context, db DB) error {
-	fmt.Println("Displaying brewings by rating (Enter # to quit):")
+	session := sessionFromContext(ctx)
+	fmt.Println("Displaying brewings by rating (Enter " + session.quit + " to quit):")
 
 	if err := displayBrewingsBy(ctx, db, "rating"); err != nil {
 		return fmt.Errorf("buna: brewing: failed to get brewings by rating: %w", err)
@@ -323,15 +495,17 @@ func displayBrewingsByRating(ctx context.Context, db DB) error {
 }
 
 func displayBrewingSuggestions(ctx context.Context, db DB) error {
-	const defaultDisplayAmount = 6
+	session := sessionFromContext(ctx)
+	units := session.unitSystem
+	defaultDisplayAmount := session.config.intValue("display.brewing_suggestions")
 	const maxDisplayAmount = 20
 	const maxNoteFieldWidth = 50
 
-	fmt.Println("Displaying brewing suggestions (Enter # to quit):")
+	fmt.Println("Displaying brewing suggestions (Enter " + session.quit + " to quit):")
 
 	fmt.Print("Enter a limit for the number of suggestions to display: ")
-	limit, quit := validateIntInput(quitStr, true, 1, maxDisplayAmount, []int{})
-	if quit {
+	limit, quit := validateIntInput(quitExits(ctx), true, 1, maxDisplayAmount, []int{})
+	if quit != answered {
 		fmt.Println(quitMsg)
 		return nil
 	}
@@ -339,27 +513,30 @@ func displayBrewingSuggestions(ctx context.Context, db DB) error {
 		limit = defaultDisplayAmount
 	}
 
-	brewingMethodName, quit, err := getBrewingMethodNameWithSuggestions(ctx, db, quitStr, false)
+	brewingMethodName, quit, err := getBrewingMethodNameWithSuggestions(ctx, db, quitExits(ctx), false)
 	if err != nil {
 		return fmt.Errorf("buna: brewing: failed to get brewing method name: %w", err)
 	}
-	if quit {
+	if quit != answered {
 		fmt.Println(quitMsg)
 		return nil
 	}
 
 	var v60FilterType string
 	if brewingMethodName == "v60" || brewingMethodName == "V60" {
-		v60FilterType, quit = getV60FilterTypeWithSuggestions(quitStr)
-		if quit {
+		v60FilterType, quit, err = getV60FilterTypeWithSuggestions(quitExits(ctx))
+		if err != nil {
+			return fmt.Errorf("buna: brewing: failed to get v60 filter type: %w", err)
+		}
+		if quit != answered {
 			fmt.Println(quitMsg)
 			return nil
 		}
 	}
 
 	fmt.Print("Show optional options (true or false): ")
-	showOptionalOptions, quit := validateBoolInput(quitStr, true)
-	if quit {
+	showOptionalOptions, quit := validateBoolInput(quitExits(ctx), true)
+	if quit != answered {
 		fmt.Println(quitMsg)
 		return nil
 	}
@@ -367,51 +544,58 @@ func displayBrewingSuggestions(ctx context.Context, db DB) error {
 	var (
 		coffeeName, coffeeRoaster, grinderName string
 		coffeeGrams, waterGrams                float64
+		band                                   ratioBand
 	)
 	if showOptionalOptions {
-		coffeeName, quit, err = getCoffeeNameWithSuggestions(ctx, db, quitStr, true)
+		coffeeName, quit, err = getCoffeeNameWithSuggestions(ctx, db, quitExits(ctx), true)
 		if err != nil {
 			return fmt.Errorf("buna: brewing: failed to get coffee name: %w", err)
 		}
-		if quit {
+		if quit != answered {
 			fmt.Println(quitMsg)
 			return nil
 		}
 
 		if coffeeName != "" {
-			coffeeRoaster, quit, err = getCoffeeRoasterWithSuggestions(ctx, db, quitStr, coffeeName)
+			coffeeRoaster, quit, err = getCoffeeRoasterWithSuggestions(ctx, db, quitExits(ctx), coffeeName)
 			if err != nil {
 				return fmt.Errorf("buna: brewing: failed to get coffee roaster: %w", err)
 			}
-			if quit {
+			if quit != answered {
 				fmt.Println(quitMsg)
 				return nil
 			}
 		}
 
-		grinderName, quit, err = getCoffeeGrinderNameWithSuggestions(ctx, db, quitStr, true)
+		grinderName, quit, err = getCoffeeGrinderNameWithSuggestions(ctx, db, quitExits(ctx), true)
 		if err != nil {
 			return fmt.Errorf("buna: brewing: failed to get coffee grinder name: %w", err)
 		}
-		if quit {
+		if quit != answered {
 			fmt.Println(quitMsg)
 			return nil
 		}
 
-		coffeeGrams, quit, err = getCoffeeWeightWithSuggestions(ctx, db, quitStr, brewingMethodName, grinderName, true)
+		coffeeGrams, quit, err = getCoffeeWeightWithSuggestions(ctx, db, quitExits(ctx), brewingMethodName, grinderName, true)
 		if err != nil {
 			return fmt.Errorf("buna: brewing: failed to get coffee weight: %w", err)
 		}
-		if quit {
+		if quit != answered {
 			fmt.Println(quitMsg)
 			return nil
 		}
 
-		waterGrams, quit, err = getWaterWeightWithSuggestions(ctx, db, quitStr, brewingMethodName, grinderName, true)
+		waterGrams, quit, err = getWaterWeightWithSuggestions(ctx, db, quitExits(ctx), brewingMethodName, grinderName, true)
 		if err != nil {
 			return fmt.Errorf("buna: brewing: failed to get water weight: %w", err)
 		}
-		if quit {
+		if quit != answered {
+			fmt.Println(quitMsg)
+			return nil
+		}
+
+		band, quit = getRatioBandInput(quitExits(ctx))
+		if quit != answered {
 			fmt.Println(quitMsg)
 			return nil
 		}
@@ -435,20 +619,27 @@ func displayBrewingSuggestions(ctx context.Context, db DB) error {
 		notes:                                  "",
 	}
 
-	suggestions, err := db.getBrewingSuggestions(ctx, limit, brewingFilter)
+	suggestions, err := db.getBrewingSuggestions(ctx, limit, brewingFilter, band)
 	if err != nil {
 		return fmt.Errorf("buna: brewing: failed to get brewing suggestions: %w", err)
 	}
 
+	// Group the suggestions by ratio band, keeping the most recent suggestions first within a band
+	sort.SliceStable(suggestions, func(i, j int) bool {
+		return suggestionRatioBandIndex(suggestions[i]) < suggestionRatioBandIndex(suggestions[j])
+	})
+
 	t := table.NewWriter()
 
 	t.AppendHeader(table.Row{
+		"Ratio\nBand",
 		"Grind\nSetting",
 		"Time\n(s)",
-		"Coffee\nWeight\n(g)",
-		"Water\nWeight\n(g)",
+		units.quantityHeader("Coffee\nWeight", coffeeWeight),
+		units.quantityHeader("Water\nWeight", waterWeight),
+		"Ratio",
 		"Recommended\nGrind\nAdjustment",
-		"Recommended\nCoffee\nAdjustment\n(g)",
+		units.quantityHeader("Recommended\nCoffee\nAdjustment", coffeeWeight),
 		"Notes",
 		"Rating",
 		"V60\nFilter\nType",
@@ -457,22 +648,33 @@ func displayBrewingSuggestions(ctx context.Context, db DB) error {
 		"Grinder",
 	})
 
-	for _, suggestion := range suggestions {
+	for i, suggestion := range suggestions {
 		notes := splitTextIntoField(suggestion.notes, maxNoteFieldWidth)
 		grinder := strings.ReplaceAll(suggestion.grinderName, "(", "\n(")
 
+		// Only name the band on the first suggestion of the band
+		bandName := ""
+		if bandIndex := suggestionRatioBandIndex(suggestion); i == 0 || bandIndex != suggestionRatioBandIndex(suggestions[i-1]) {
+			bandName = "Unknown"
+			if bandIndex < len(ratioBands) {
+				bandName = strings.ReplaceAll(ratioBands[bandIndex].name, " ", "\n")
+			}
+		}
+
 		row := table.Row{
+			bandName,
 			suggestion.grindSetting,
 			suggestion.totalBrewingTimeSec,
-			suggestion.coffeeGrams,
-			suggestion.waterGrams,
+			units.formatQuantity(suggestion.coffeeGrams, coffeeWeight),
+			units.formatQuantity(suggestion.waterGrams, waterWeight),
+			formatBrewRatio(suggestion),
 			suggestion.recommendedGrindSettingAdjustment,
-			suggestion.recommendedCoffeeWeightAdjustmentGrams,
+			units.formatQuantity(suggestion.recommendedCoffeeWeightAdjustmentGrams, coffeeWeight),
 			notes,
 			suggestion.rating,
 			suggestion.v60FilterType,
 			suggestion.coffeeName,
-			suggestion.date,
+			timestampDate(suggestion.date),
 			grinder,
 		}
 
@@ -487,7 +689,16 @@ func displayBrewingSuggestions(ctx context.Context, db DB) error {
 	t.SetAllowedRowLength(terminalWidth)
 
 	t.SetOutputMirror(os.Stdout)
-	t.Render()
+	renderTable(ctx, t)
 
 	return nil
 }
+
+// Returns the index in ratioBands of the ratio band of the suggestion or len(ratioBands) if its brew ratio is unknown.
+func suggestionRatioBandIndex(suggestion brewing) int {
+	ratio, ok := brewRatio(suggestion)
+	if !ok {
+		return len(ratioBands)
+	}
+	return ratioBandIndex(ratio)
+}
@@ -0,0 +1,289 @@
+package buna
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"strings"
+
+	"github.com/jedib0t/go-pretty/table"
+	"golang.org/x/crypto/ssh/terminal"
+)
+
+// Lists the most recent brewings and promts user for the ID of the brewing to display.
+func displayBrewingDetailsByID(ctx context.Context, db DB) error {
+	const overviewAmount = 10
+
+	session := sessionFromContext(ctx)
+	fmt.Println("Displaying brewing details (Enter " + session.quit + " to quit):")
+
+	brewings, err := db.getBrewingsOrderByDesc(ctx, nil, overviewAmount, "id")
+	if err != nil {
+		return fmt.Errorf("buna: brewing_details: failed to get brewings by last added: %w", err)
+	}
+
+	if len(brewings) == 0 {
+		fmt.Println("No brewings to display")
+		return nil
+	}
+
+	t := table.NewWriter()
+
+	t.AppendHeader(table.Row{"ID", "Date", "Coffee", "Method", "Rating"})
+
+	for _, brewing := range brewings {
+		t.AppendRow(table.Row{brewing.id, formatTimestamp(brewing.date, brewing.timeKnown), brewing.coffeeName + " (" + brewing.coffeeRoaster + ")", brewing.brewingMethodName, formatRating(brewing.rating)})
+	}
+
+	terminalWidth, _, err := terminal.GetSize(int(os.Stdin.Fd()))
+	if err != nil {
+		return fmt.Errorf("buna: brewing_details: failed to get terminal width: %w", err)
+	}
+	t.SetAllowedRowLength(terminalWidth)
+
+	t.SetOutputMirror(os.Stdout)
+	renderTable(ctx, t)
+
+	fmt.Print("Enter the ID of the brewing to display: ")
+	id, quit := validateIntInput(quitExits(ctx), false, 1, math.MaxInt64, nil)
+	if quit != answered {
+		fmt.Println(quitMsg)
+		return nil
+	}
+
+	brewing, err := db.getBrewingByID(ctx, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		fmt.Println("No brewing with this ID exists")
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("buna: brewing_details: failed to get brewing by id: %w", err)
+	}
+
+	if err := displayBrewingDetails(ctx, db, brewing); err != nil {
+		return fmt.Errorf("buna: brewing_details: failed to display brewing details: %w", err)
+	}
+
+	return nil
+}
+
+// Displays every field of a single brewing together with its coffee, the purchase it came from,
+// the previous and next brewings of the same coffee and whether the adjustments recommended by the previous brewing were followed.
+func displayBrewingDetails(ctx context.Context, db DB, brewing brewing) error {
+	const maxNoteFieldWidth = 80
+
+	units := sessionFromContext(ctx).unitSystem
+	coffee, err := db.getCoffeeByNameRoaster(ctx, brewing.coffeeName, brewing.coffeeRoaster)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("buna: brewing_details: failed to get coffee: %w", err)
+	}
+	hasCoffee := err == nil
+
+	purchase, err := db.getCoffeePurchaseOfBrewing(ctx, brewing)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("buna: brewing_details: failed to get coffee purchase: %w", err)
+	}
+	hasPurchase := err == nil
+
+	previous, err := db.getPreviousBrewingOfCoffee(ctx, brewing)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("buna: brewing_details: failed to get previous brewing: %w", err)
+	}
+	hasPrevious := err == nil
+
+	next, err := db.getNextBrewingOfCoffee(ctx, brewing)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("buna: brewing_details: failed to get next brewing: %w", err)
+	}
+	hasNext := err == nil
+
+	terminalWidth, _, err := terminal.GetSize(int(os.Stdin.Fd()))
+	if err != nil {
+		return fmt.Errorf("buna: brewing_details: failed to get terminal width: %w", err)
+	}
+
+	restDaysField := "Unknown"
+	if days, ok := restDays(brewing); ok {
+		restDaysField = fmt.Sprintf("%.0f", days)
+	}
+
+	brewingRows := []table.Row{
+		{"ID", brewing.id},
+		{"Date", formatTimestamp(brewing.date, brewing.timeKnown)},
+	}
+	if brewing.userName != "" {
+		brewingRows = append(brewingRows, table.Row{"Brewer", brewing.userName})
+	}
+
+	fmt.Println("Brewing")
+	renderFieldTable(ctx, terminalWidth, append(brewingRows, []table.Row{
+		{"Coffee", brewing.coffeeName},
+		{"Roaster", brewing.coffeeRoaster},
+		{"Brewing method", brewing.brewingMethodName},
+		{"V60 filter type", brewing.v60FilterType},
+		{"Grinder", brewing.grinderName},
+		{"Grind setting", brewing.grindSetting},
+		{"Total brewing time (s)", brewing.totalBrewingTimeSec},
+		{"Coffee weight", units.formatQuantityWithUnit(brewing.coffeeGrams, coffeeWeight)},
+		{"Water weight", units.formatQuantityWithUnit(brewing.waterGrams, waterWeight)},
+		{"Brew ratio", formatBrewRatio(brewing)},
+		{"Roast date", brewing.roastDate},
+		{"Rest days", restDaysField},
+		{"Rating", formatRating(brewing.rating)},
+		{"Scores", formatBrewingScores(brewing.scores)},
+		{"Extraction", brewing.extraction},
+		{"Recommended grind adjustment", brewing.recommendedGrindSettingAdjustment},
+		{"Recommended coffee adjustment", units.formatQuantityChange(brewing.recommendedCoffeeWeightAdjustmentGrams, coffeeWeight)},
+		{"Flavors", strings.Join(brewing.flavors, ", ")},
+		{"Notes", splitTextIntoField(brewing.notes, maxNoteFieldWidth)},
+	}...))
+
+	fmt.Println("\nCoffee")
+	if hasCoffee {
+		renderFieldTable(ctx, terminalWidth, []table.Row{
+			{"Origin", formatOrigin(coffee.region, coffee.countryCode)},
+			{"Farm/Producer", joinNonEmpty(" / ", coffee.farm, coffee.producer)},
+			{"Altitude", formatAltitude(coffee.altitudeMinM, coffee.altitudeMaxM)},
+			{"Varieties", strings.Join(coffee.varieties, ", ")},
+			{"Processing method", formatProcess(coffee.process, coffee.processOther)},
+			{"Decaf", coffee.decaf},
+			{"Brewings", coffee.brewingCount},
+			{"Avg rating", formatAverageRating(coffee.averageRating)},
+		})
+	} else {
+		fmt.Println("The coffee no longer exists")
+	}
+
+	fmt.Println("\nPurchase")
+	if hasPurchase {
+		renderFieldTable(ctx, terminalWidth, []table.Row{
+			{"Bought date", timestampDate(purchase.boughtDate)},
+			{"Roast date", purchase.roastDate},
+		})
+	} else {
+		fmt.Println("No purchase of this coffee was recorded")
+	}
+
+	fmt.Println("\nOther brewings of this coffee")
+	if hasPrevious || hasNext {
+		t := table.NewWriter()
+
+		t.AppendHeader(table.Row{"", "ID", "Date", "Method", "Grind\nSetting", "Time\n(s)", units.quantityHeader("Coffee\nWeight", coffeeWeight), units.quantityHeader("Water\nWeight", waterWeight), "Ratio", "Rating"})
+
+		if hasPrevious {
+			t.AppendRow(adjacentBrewingRow(ctx, "Previous", previous))
+		}
+		if hasNext {
+			t.AppendRow(adjacentBrewingRow(ctx, "Next", next))
+		}
+
+		t.SetAllowedRowLength(terminalWidth)
+		t.SetOutputMirror(os.Stdout)
+		renderTable(ctx, t)
+	} else {
+		fmt.Println("This is the only brewing of this coffee")
+	}
+
+	fmt.Println("\nAdjustments recommended by the previous brewing")
+	if hasPrevious {
+		renderFieldTable(ctx, terminalWidth, []table.Row{
+			{"Grind setting", describeGrindAdjustmentFollowUp(previous, brewing)},
+			{"Coffee weight", describeCoffeeWeightAdjustmentFollowUp(ctx, previous, brewing)},
+		})
+	} else {
+		fmt.Println("There is no previous brewing of this coffee")
+	}
+	fmt.Println()
+
+	return nil
+}
+
+// Renders a table with one field name and value per row.
+func renderFieldTable(ctx context.Context, terminalWidth int, rows []table.Row) {
+	t := table.NewWriter()
+
+	t.AppendRows(rows)
+
+	t.SetAllowedRowLength(terminalWidth)
+	t.SetOutputMirror(os.Stdout)
+	renderTable(ctx, t)
+}
+
+func adjacentBrewingRow(ctx context.Context, name string, brewing brewing) table.Row {
+	units := sessionFromContext(ctx).unitSystem
+	return table.Row{
+		name,
+		brewing.id,
+		formatTimestamp(brewing.date, brewing.timeKnown),
+		brewing.brewingMethodName,
+		brewing.grindSetting,
+		brewing.totalBrewingTimeSec,
+		units.formatQuantity(brewing.coffeeGrams, coffeeWeight),
+		units.formatQuantity(brewing.waterGrams, waterWeight),
+		formatBrewRatio(brewing),
+		formatRating(brewing.rating),
+	}
+}
+
+// Returns "Unrated" for brewings without a rating.
+func formatRating(rating int) string {
+	if rating == 0 {
+		return "Unrated"
+	}
+	return fmt.Sprint(rating)
+}
+
+// Describes the grind setting adjustment recommended by the previous brewing and whether the brewing followed it.
+func describeGrindAdjustmentFollowUp(previous brewing, current brewing) string {
+	adjustment := previous.recommendedGrindSettingAdjustment
+	if adjustment != "lower" && adjustment != "higher" {
+		return "None recommended"
+	}
+
+	recommendation := fmt.Sprintf("%v than %d", adjustment, previous.grindSetting)
+	if previous.grinderName != current.grinderName {
+		return fmt.Sprintf("%v: not comparable, a different grinder was used", recommendation)
+	}
+
+	change := fmt.Sprintf("%d -> %d", previous.grindSetting, current.grindSetting)
+	switch {
+	case current.grindSetting == previous.grindSetting:
+		return fmt.Sprintf("%v: not followed, the grind setting was kept at %d", recommendation, current.grindSetting)
+	case (current.grindSetting < previous.grindSetting) == (adjustment == "lower"):
+		return fmt.Sprintf("%v: followed (%v)", recommendation, change)
+	default:
+		return fmt.Sprintf("%v: not followed, the grind setting was changed the other way (%v)", recommendation, change)
+	}
+}
+
+// Describes the coffee weight adjustment recommended by the previous brewing and whether the brewing followed it.
+func describeCoffeeWeightAdjustmentFollowUp(ctx context.Context, previous brewing, current brewing) string {
+	units := sessionFromContext(ctx).unitSystem
+	// Weights within this many grams of each other are considered equal
+	const tolerance = 0.05
+
+	adjustment := previous.recommendedCoffeeWeightAdjustmentGrams
+	if adjustment == 0 {
+		return "None recommended"
+	}
+
+	recommendation := fmt.Sprintf("%v from %v", units.formatQuantityChange(adjustment, coffeeWeight), units.formatQuantityWithUnit(previous.coffeeGrams, coffeeWeight))
+	if previous.brewingMethodName != current.brewingMethodName {
+		return fmt.Sprintf("%v: not comparable, a different brewing method was used", recommendation)
+	}
+
+	change := current.coffeeGrams - previous.coffeeGrams
+	switch {
+	case math.Abs(change-adjustment) <= tolerance:
+		return fmt.Sprintf("%v: followed (%v -> %v)", recommendation, units.formatQuantityWithUnit(previous.coffeeGrams, coffeeWeight), units.formatQuantityWithUnit(current.coffeeGrams, coffeeWeight))
+	case math.Abs(change) <= tolerance:
+		return fmt.Sprintf("%v: not followed, the coffee weight was kept at %v", recommendation, units.formatQuantityWithUnit(current.coffeeGrams, coffeeWeight))
+	case (change > 0) == (adjustment > 0):
+		return fmt.Sprintf("%v: followed with a different amount (%v)", recommendation, units.formatQuantityChange(change, coffeeWeight))
+	default:
+		return fmt.Sprintf("%v: not followed, the coffee weight was changed the other way (%v)", recommendation, units.formatQuantityChange(change, coffeeWeight))
+	}
+}
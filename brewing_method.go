@@ -2,6 +2,7 @@ package buna
 
 import (
 	"context"
+	"database/sql"
 	"errors"
 	"fmt"
 	"os"
@@ -11,28 +12,82 @@ import (
 )
 
 type brewingMethod struct {
+	id   int
 	name string
 }
 
-func addBrewingMethod(ctx context.Context, db DB) error {
-	fmt.Println("Adding new coffee brewing method (Enter # to quit):")
-	fmt.Print("Enter brewing method name: ")
-	name, quit := validateStrInput(quitStr, false, nil, nil)
-	if quit {
-		fmt.Println(quitMsg)
-		return nil
+// Returns the added brewing method.
+// The user is only prompted for the brewing method name if name is empty.
+func addBrewingMethod(ctx context.Context, db DB, name string) (brewingMethod, error) {
+	session := sessionFromContext(ctx)
+	fmt.Println("Adding new coffee brewing method (Enter " + session.quit + " to quit):")
+
+	if name == "" {
+		var quit promptResult
+		fmt.Print("Enter brewing method name: ")
+		name, quit = validateStrInput(quitExits(ctx), false, nil, nil)
+		if quit != answered {
+			fmt.Println(quitMsg)
+			return brewingMethod{}, nil
+		}
+	} else {
+		fmt.Println("Brewing method name: " + name)
 	}
 
-	brewingMethod := brewingMethod{
+	newBrewingMethod := brewingMethod{
 		name: name,
 	}
 
-	if err := db.insertBrewingMethod(ctx, brewingMethod); err != nil {
-		return fmt.Errorf("buna: brewing_method: failed to insert brewingMethod: %w", err)
+	if err := db.insertBrewingMethod(ctx, newBrewingMethod); err != nil {
+		return brewingMethod{}, fmt.Errorf("buna: brewing_method: failed to insert brewingMethod: %w", err)
 	}
 
 	fmt.Println("Added coffee brewing method successfully")
-	return nil
+	return newBrewingMethod, nil
+}
+
+// Returns the name of the brewing method.
+// If it does not exist, the user can choose a brewing method with a similar name instead, create it and continue after printing resumeMsg
+// or enter a different name, in which case the returned name is empty.
+// Returns brewingMethodName, promptResult, error
+func getExistingBrewingMethodName(ctx context.Context, db DB, exits promptExits, name string, resumeMsg string) (string, promptResult, error) {
+	_, err := db.getMethodIDByName(ctx, name)
+	if err == nil {
+		return name, answered, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return "", answered, fmt.Errorf("buna: brewing_method: failed to get brewing method id by name: %w", err)
+	}
+
+	similarNames, err := db.getSimilarBrewingMethodNames(ctx, name, maxSimilarNames)
+	if err != nil {
+		return "", answered, fmt.Errorf("buna: brewing_method: failed to get similar brewing method names: %w", err)
+	}
+
+	selection, quit := getDidYouMeanSelection(exits, "brewing method", name, similarNames)
+	if quit != answered {
+		return "", quit, nil
+	}
+	if selection == reenterSelection {
+		return "", answered, nil
+	}
+	if selection >= 0 {
+		return similarNames[selection], answered, nil
+	}
+
+	addedBrewingMethod, err := addBrewingMethod(ctx, db, name)
+	if err != nil {
+		return "", answered, fmt.Errorf("buna: brewing_method: failed to add brewing method: %w", err)
+	}
+	if addedBrewingMethod.name == "" {
+		return "", quitPrompt, nil
+	}
+
+	if resumeMsg != "" {
+		fmt.Println(resumeMsg)
+	}
+
+	return addedBrewingMethod.name, answered, nil
 }
 
 func retrieveBrewingMethod(ctx context.Context, db DB) error {
@@ -40,16 +95,17 @@ func retrieveBrewingMethod(ctx context.Context, db DB) error {
 		0: "Retrieve brewing methods ordered by last added",
 	}
 
-	fmt.Println("Retrieving brewing methods (Enter # to quit):")
-	if err := displayIntOptions(options); err != nil {
+	session := sessionFromContext(ctx)
+	fmt.Println("Retrieving brewing methods (Enter " + session.quit + " to quit):")
+	if err := displayIntOptions(ctx, options); err != nil {
 		return fmt.Errorf("buna: brewing_method: failed to display int options: %w", err)
 	}
 
-	selection, quit, err := getIntSelection(options, quitStr)
+	selection, quit, err := getIntSelection(ctx, options, quitExits(ctx))
 	if err != nil {
 		return fmt.Errorf("buna: brewing_method: failed to get int selection: %w", err)
 	}
-	if quit {
+	if quit != answered {
 		fmt.Println(quitMsg)
 		return nil
 	}
@@ -73,29 +129,53 @@ func runRetrieveBrewingMethodSelection(ctx context.Context, selection int, db DB
 	return nil
 }
 
-// Promts user for an optional limit.
+// Promts user for an optional page size.
 func displayBrewingMethodsByLastAdded(ctx context.Context, db DB) error {
-	const defaultDisplayAmount = 20
-	const maxDisplayAmount = 60
+	session := sessionFromContext(ctx)
+	defaultPageSize := session.config.intValue("display.methods_page_size")
+	const maxPageSize = 60
 
-	fmt.Println("Displaying brewing methods by last added (Enter # to quit):")
+	fmt.Println("Displaying brewing methods by last added (Enter " + session.quit + " to quit):")
 
-	fmt.Print("Enter a limit for the number of brewing methods to display: ")
-	limit, quit := validateIntInput(quitStr, true, 1, maxDisplayAmount, []int{})
-	if quit {
+	fmt.Print("Enter the number of brewing methods to display per page: ")
+	pageSize, quit := validateIntInput(quitExits(ctx), true, 1, maxPageSize, []int{})
+	if quit != answered {
 		fmt.Println(quitMsg)
 		return nil
 	}
 
-	if limit == 0 {
-		limit = defaultDisplayAmount
+	if pageSize == 0 {
+		pageSize = defaultPageSize
 	}
 
-	brewingMethods, err := db.getBrewingMethodsByLastAdded(ctx, limit)
-	if err != nil {
-		return fmt.Errorf("buna: brewing_method: failed to get brewing methods by last added: %w", err)
+	var brewingMethods []brewingMethod
+	listing := pagedListing{
+		fetch: func(after *pageCursor, limit int) ([]pageCursor, error) {
+			var err error
+			brewingMethods, err = db.getBrewingMethodsByLastAdded(ctx, after, limit)
+			if err != nil {
+				return nil, fmt.Errorf("buna: brewing_method: failed to get brewing methods by last added: %w", err)
+			}
+
+			cursors := make([]pageCursor, len(brewingMethods))
+			for i := range brewingMethods {
+				cursors[i].id = brewingMethods[i].id
+			}
+			return cursors, nil
+		},
+		display: func(n int) error {
+			return displayBrewingMethods(ctx, brewingMethods[:n])
+		},
+	}
+
+	if err := browsePages(quitExits(ctx), listing, pageSize); err != nil {
+		return fmt.Errorf("buna: brewing_method: failed to browse brewing methods by last added: %w", err)
 	}
 
+	return nil
+}
+
+func displayBrewingMethods(ctx context.Context, brewingMethods []brewingMethod) error {
 	t := table.NewWriter()
 
 	t.AppendHeader(table.Row{"Name"})
@@ -112,7 +192,7 @@ func displayBrewingMethodsByLastAdded(ctx context.Context, db DB) error {
 	t.SetAllowedRowLength(terminalWidth)
 
 	t.SetOutputMirror(os.Stdout)
-	t.Render()
+	renderTable(ctx, t)
 
 	return nil
 }
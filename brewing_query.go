@@ -0,0 +1,344 @@
+package buna
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Criteria of a general brewing query.
+// Zero values mean that the criterion is not used.
+type brewingQuery struct {
+	// Inclusive date range, YYYY-MM-DD
+	fromDate string
+	toDate   string
+	// Matched case-insensitively and partially
+	coffeeName        string
+	coffeeRoaster     string
+	brewingMethodName string
+	grinderName       string
+	notes             string
+
+	v60FilterType   string
+	minRating       int
+	maxRating       int
+	minGrindSetting int
+	maxGrindSetting int
+	// Brew ratio 1:x
+	minRatio float64
+	maxRatio float64
+
+	// One of brewingSortKeys, defaults to "date"
+	sortBy    string
+	ascending bool
+}
+
+// Keys brewings can be sorted by.
+var brewingSortKeys = []string{
+	"date",
+	"rating",
+	"coffee",
+	"roaster",
+	"method",
+	"grinder",
+	"grind",
+	"time",
+	"ratio",
+	"coffee-weight",
+	"water-weight",
+	"id",
+}
+
+const defaultBrewingSortKey = "date"
+
+// Returns an error if the query has an unknown sort key, a malformed date or a range whose minimum exceeds its maximum.
+func validateBrewingQuery(query brewingQuery) error {
+	for _, d := range []string{query.fromDate, query.toDate} {
+		if d == "" {
+			continue
+		}
+		if _, err := time.Parse("2006-01-02", d); err != nil {
+			return fmt.Errorf("buna: brewing_query: invalid date %q, expected YYYY-MM-DD", d)
+		}
+	}
+	if query.fromDate != "" && query.toDate != "" && query.fromDate > query.toDate {
+		return errors.New("buna: brewing_query: from date is after to date")
+	}
+
+	if query.maxRating != 0 && query.minRating > query.maxRating {
+		return errors.New("buna: brewing_query: min rating is greater than max rating")
+	}
+	if query.maxGrindSetting != 0 && query.minGrindSetting > query.maxGrindSetting {
+		return errors.New("buna: brewing_query: min grind setting is greater than max grind setting")
+	}
+	if query.maxRatio != 0 && query.minRatio > query.maxRatio {
+		return errors.New("buna: brewing_query: min ratio is greater than max ratio")
+	}
+
+	if query.sortBy != "" && !containsString(brewingSortKeys, query.sortBy) {
+		return fmt.Errorf("buna: brewing_query: unknown sort key %q", query.sortBy)
+	}
+
+	return nil
+}
+
+// Returns the value brewings are sorted by for the sort key, which is used as the page cursor key.
+// Matches the SQL expressions of the sort keys, e.g. missing ratings are 0.
+func brewingSortKeyValue(sortKey string, brewing brewing) interface{} {
+	switch sortKey {
+	case "rating":
+		return brewing.rating
+	case "coffee":
+		return brewing.coffeeName
+	case "roaster":
+		return brewing.coffeeRoaster
+	case "method":
+		return brewing.brewingMethodName
+	case "grinder":
+		return brewing.grinderName
+	case "grind":
+		return brewing.grindSetting
+	case "time":
+		return brewing.totalBrewingTimeSec
+	case "ratio":
+		if brewing.coffeeGrams == 0 {
+			return 0
+		}
+		return brewing.waterGrams / brewing.coffeeGrams
+	case "coffee-weight":
+		return brewing.coffeeGrams
+	case "water-weight":
+		return brewing.waterGrams
+	case "id":
+		return brewing.id
+	default:
+		return utcTimestamp(brewing.date)
+	}
+}
+
+func containsString(vals []string, val string) bool {
+	for _, v := range vals {
+		if v == val {
+			return true
+		}
+	}
+	return false
+}
+
+// Promts user for optional query criteria, a sort order and an optional page size.
+func displayBrewingsByQuery(ctx context.Context, db DB) error {
+	session := sessionFromContext(ctx)
+	fmt.Println("Displaying brewings by query (Enter " + session.quit + " to quit):")
+
+	query, quit, err := getBrewingQueryInput(ctx, db)
+	if err != nil {
+		return fmt.Errorf("buna: brewing_query: failed to get brewing query: %w", err)
+	}
+	if quit != answered {
+		fmt.Println(quitMsg)
+		return nil
+	}
+
+	pageSize, quit := getBrewingPageSize(ctx)
+	if quit != answered {
+		fmt.Println(quitMsg)
+		return nil
+	}
+
+	if err := validateBrewingQuery(query); err != nil {
+		fmt.Println("Invalid query:", err)
+		return nil
+	}
+
+	sortBy := query.sortBy
+	if sortBy == "" {
+		sortBy = defaultBrewingSortKey
+	}
+
+	var dateCursor func(date string) (pageCursor, error)
+	if sortBy == "date" {
+		dateCursor = descendingDateCursor
+		if query.ascending {
+			dateCursor = ascendingDateCursor
+		}
+	}
+
+	if err := browseBrewings(ctx, pageSize, func(after *pageCursor, limit int) ([]brewing, error) {
+		return db.getBrewingsByQuery(ctx, query, after, limit)
+	}, func(brewing brewing) interface{} {
+		return brewingSortKeyValue(sortBy, brewing)
+	}, dateCursor); err != nil {
+		return fmt.Errorf("buna: brewing_query: failed to browse brewings by query: %w", err)
+	}
+
+	return nil
+}
+
+// Prompts user for the optional criteria and the sort order of a brewing query.
+// Returns query, promptResult, error
+func getBrewingQueryInput(ctx context.Context, db DB) (brewingQuery, promptResult, error) {
+	var query brewingQuery
+
+	fmt.Print("Add filters (true or false): ")
+	addFilters, quit := validateBoolInput(quitExits(ctx), true)
+	if quit != answered {
+		return brewingQuery{}, quit, nil
+	}
+
+	if addFilters {
+		var err error
+		query, quit, err = getBrewingQueryFilterInput(ctx, db)
+		if err != nil {
+			return brewingQuery{}, answered, fmt.Errorf("buna: brewing_query: failed to get brewing query filters: %w", err)
+		}
+		if quit != answered {
+			return brewingQuery{}, quit, nil
+		}
+	}
+
+	return getBrewingQuerySortInput(ctx, query)
+}
+
+// Prompts user for the optional criteria of a brewing query.
+// Returns query, promptResult, error
+func getBrewingQueryFilterInput(ctx context.Context, db DB) (brewingQuery, promptResult, error) {
+	var query brewingQuery
+
+	fromDate, quit := getDateInput(quitExits(ctx), true, "Enter first brewing date (Leave empty for no lower bound): ", nil)
+	if quit != answered {
+		return brewingQuery{}, quit, nil
+	}
+	if fromDate.year != 0 {
+		query.fromDate = createDateString(fromDate)
+	}
+
+	toDate, quit := getDateInput(quitExits(ctx), true, "Enter last brewing date (Leave empty for no upper bound): ", nil)
+	if quit != answered {
+		return brewingQuery{}, quit, nil
+	}
+	if toDate.year != 0 {
+		query.toDate = createDateString(toDate)
+	}
+
+	coffeeName, quit, err := getCoffeeNameWithSuggestions(ctx, db, quitExits(ctx), true)
+	if err != nil {
+		return brewingQuery{}, answered, fmt.Errorf("buna: brewing_query: failed to get coffee name: %w", err)
+	}
+	if quit != answered {
+		return brewingQuery{}, quit, nil
+	}
+	query.coffeeName = coffeeName
+
+	coffeeRoaster, quit, err := getRoasterNameWithSuggestions(ctx, db, quitExits(ctx), true)
+	if err != nil {
+		return brewingQuery{}, answered, fmt.Errorf("buna: brewing_query: failed to get roaster name: %w", err)
+	}
+	if quit != answered {
+		return brewingQuery{}, quit, nil
+	}
+	query.coffeeRoaster = coffeeRoaster
+
+	brewingMethodName, quit, err := getBrewingMethodNameWithSuggestions(ctx, db, quitExits(ctx), true)
+	if err != nil {
+		return brewingQuery{}, answered, fmt.Errorf("buna: brewing_query: failed to get brewing method name: %w", err)
+	}
+	if quit != answered {
+		return brewingQuery{}, quit, nil
+	}
+	query.brewingMethodName = brewingMethodName
+
+	if brewingMethodName == "v60" || brewingMethodName == "V60" {
+		query.v60FilterType, quit, err = getV60FilterTypeWithSuggestions(quitExits(ctx))
+		if err != nil {
+			return brewingQuery{}, answered, fmt.Errorf("buna: brewing_query: failed to get v60 filter type: %w", err)
+		}
+		if quit != answered {
+			return brewingQuery{}, quit, nil
+		}
+	}
+
+	grinderName, quit, err := getCoffeeGrinderNameWithSuggestions(ctx, db, quitExits(ctx), true)
+	if err != nil {
+		return brewingQuery{}, answered, fmt.Errorf("buna: brewing_query: failed to get coffee grinder name: %w", err)
+	}
+	if quit != answered {
+		return brewingQuery{}, quit, nil
+	}
+	query.grinderName = grinderName
+
+	fmt.Print("Enter the minimum rating (1 <= x <= 10): ")
+	query.minRating, quit = validateIntInput(quitExits(ctx), true, 1, 10, nil)
+	if quit != answered {
+		return brewingQuery{}, quit, nil
+	}
+
+	fmt.Print("Enter the maximum rating (1 <= x <= 10): ")
+	query.maxRating, quit = validateIntInput(quitExits(ctx), true, 1, 10, nil)
+	if quit != answered {
+		return brewingQuery{}, quit, nil
+	}
+
+	fmt.Print("Enter the minimum grind setting: ")
+	query.minGrindSetting, quit = validateIntInput(quitExits(ctx), true, 0, 50, nil)
+	if quit != answered {
+		return brewingQuery{}, quit, nil
+	}
+
+	fmt.Print("Enter the maximum grind setting: ")
+	query.maxGrindSetting, quit = validateIntInput(quitExits(ctx), true, 0, 50, nil)
+	if quit != answered {
+		return brewingQuery{}, quit, nil
+	}
+
+	fmt.Print("Enter the minimum brew ratio (1:x): ")
+	query.minRatio, quit = validateFloatInput(quitExits(ctx), true, parseBrewRatio, "Input invalid. Please try again: ", nil, nil)
+	if quit != answered {
+		return brewingQuery{}, quit, nil
+	}
+
+	fmt.Print("Enter the maximum brew ratio (1:x): ")
+	query.maxRatio, quit = validateFloatInput(quitExits(ctx), true, parseBrewRatio, "Input invalid. Please try again: ", nil, nil)
+	if quit != answered {
+		return brewingQuery{}, quit, nil
+	}
+
+	fmt.Print("Enter text the notes must contain: ")
+	query.notes, quit = validateStrInput(quitExits(ctx), true, nil, nil)
+	if quit != answered {
+		return brewingQuery{}, quit, nil
+	}
+
+	return query, answered, nil
+}
+
+// Prompts user for the sort key and direction of the query.
+// Returns query with the sort order set, promptResult, error
+func getBrewingQuerySortInput(ctx context.Context, query brewingQuery) (brewingQuery, promptResult, error) {
+	options := make(map[int]string, len(brewingSortKeys))
+	for i, key := range brewingSortKeys {
+		options[i] = key
+	}
+
+	fmt.Println("Sort by:")
+	if err := displayIntOptions(ctx, options); err != nil {
+		return brewingQuery{}, answered, fmt.Errorf("buna: brewing_query: failed to display int options: %w", err)
+	}
+
+	selection, quit, err := getIntSelection(ctx, options, quitExits(ctx))
+	if err != nil {
+		return brewingQuery{}, answered, fmt.Errorf("buna: brewing_query: failed to get int selection: %w", err)
+	}
+	if quit != answered {
+		return brewingQuery{}, quit, nil
+	}
+	query.sortBy = brewingSortKeys[selection]
+
+	fmt.Print("Sort ascending (true or false): ")
+	query.ascending, quit = validateBoolInput(quitExits(ctx), true)
+	if quit != answered {
+		return brewingQuery{}, quit, nil
+	}
+
+	return query, answered, nil
+}
@@ -0,0 +1,110 @@
+package buna
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Extraction verdicts of a brewing
+const (
+	underExtracted    = "under"
+	balancedExtracted = "balanced"
+	overExtracted     = "over"
+)
+
+// Maps the accepted extraction verdict inputs to the stored verdicts.
+// Sour brews are usually under-extracted and bitter brews over-extracted.
+var extractionAliases = map[string]string{
+	"under":           underExtracted,
+	"under-extracted": underExtracted,
+	"sour":            underExtracted,
+	"balanced":        balancedExtracted,
+	"good":            balancedExtracted,
+	"over":            overExtracted,
+	"over-extracted":  overExtracted,
+	"bitter":          overExtracted,
+}
+
+// Optional sub-scores of a brewing, each 1 <= x <= 10 or 0 if not scored.
+type brewingScores struct {
+	sweetness   int
+	acidity     int
+	bitterness  int
+	body        int
+	clarity     int
+	astringency int
+}
+
+// Scores are kept in drafts as a JSON array in the order of the brewingScores fields.
+func (s brewingScores) MarshalJSON() ([]byte, error) {
+	return json.Marshal([6]int{s.sweetness, s.acidity, s.bitterness, s.body, s.clarity, s.astringency})
+}
+
+func (s *brewingScores) UnmarshalJSON(data []byte) error {
+	var values [6]int
+	if err := json.Unmarshal(data, &values); err != nil {
+		return fmt.Errorf("buna: brewing_score: failed to decode brewing scores: %w", err)
+	}
+
+	*s = brewingScores{values[0], values[1], values[2], values[3], values[4], values[5]}
+	return nil
+}
+
+// Average rating and sub-scores of a set of brewings.
+// Averages are 0 if no brewing has the score.
+type brewingScoreAverages struct {
+	brewingCount int
+	rating       float64
+	sweetness    float64
+	acidity      float64
+	bitterness   float64
+	body         float64
+	clarity      float64
+	astringency  float64
+
+	// Number of brewings with each extraction verdict
+	extractionCounts map[string]int
+}
+
+// Returns the extraction verdict of the input, including aliases like "sour" and "bitter".
+// The second return value is false if the input is not a known verdict.
+func parseExtraction(input string) (string, bool) {
+	extraction, ok := extractionAliases[strings.ToLower(strings.TrimSpace(input))]
+	return extraction, ok
+}
+
+// Returns the grind setting adjustment implied by the extraction verdict, or "" if there is none.
+// Under-extracted brews need a finer (lower) grind setting and over-extracted brews a coarser (higher) one.
+func grindAdjustmentForExtraction(extraction string) string {
+	switch extraction {
+	case underExtracted:
+		return "lower"
+	case overExtracted:
+		return "higher"
+	default:
+		return ""
+	}
+}
+
+// Returns the sub-scores for display, e.g. "Sweetness: 7\nAcidity: 6", leaving out missing scores.
+func formatBrewingScores(scores brewingScores) string {
+	var lines []string
+	for _, score := range []struct {
+		name  string
+		value int
+	}{
+		{"Sweetness", scores.sweetness},
+		{"Acidity", scores.acidity},
+		{"Bitterness", scores.bitterness},
+		{"Body", scores.body},
+		{"Clarity", scores.clarity},
+		{"Astringency", scores.astringency},
+	} {
+		if score.value != 0 {
+			lines = append(lines, fmt.Sprintf("%v: %d", score.name, score.value))
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,132 @@
+package buna
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/jedib0t/go-pretty/table"
+	"golang.org/x/crypto/ssh/terminal"
+)
+
+// A part of the day from startHour up to but excluding endHour.
+type timeOfDay struct {
+	name      string
+	startHour int
+	endHour   int
+}
+
+// Parts of the day brewings are grouped by, in the order of the day.
+var timesOfDay = []timeOfDay{
+	{"Night (0-5)", 0, 5},
+	{"Early morning (5-9)", 5, 9},
+	{"Late morning (9-12)", 9, 12},
+	{"Afternoon (12-17)", 12, 17},
+	{"Evening (17-21)", 17, 21},
+	{"Late evening (21-24)", 21, 24},
+}
+
+// Brewings of a time of day or a weekday
+type brewingTimeStatistics struct {
+	name          string
+	brewingCount  int
+	ratedCount    int
+	averageRating float64
+}
+
+// Returns an SQL expression for the index in timesOfDay of the brewing b,
+// which is NULL if the time of the brewing is unknown.
+func timeOfDayIndexSQL() string {
+	// Timestamps are stored in the time zone they were entered in, so the hour is the local hour of the brewing
+	const hourExpr = "CAST(substr(b.date, 12, 2) AS INTEGER)"
+
+	var sb strings.Builder
+	sb.WriteString("CASE WHEN NOT b.time_known THEN NULL")
+	for i, t := range timesOfDay {
+		sb.WriteString(fmt.Sprintf(" WHEN %v < %d THEN %d", hourExpr, t.endHour, i))
+	}
+	sb.WriteString(" END")
+
+	return sb.String()
+}
+
+// Returns an SQL expression for the weekday of the brewing b, 0 being Monday.
+func weekdayIndexSQL() string {
+	return `(CAST(strftime("%w", substr(b.date, 1, 10)) AS INTEGER) + 6) % 7`
+}
+
+// Returns the name of the weekday with the index returned by weekdayIndexSQL.
+func weekdayIndexName(index int) string {
+	return time.Weekday((index + 1) % 7).String()
+}
+
+func getBrewingTimeStatistics(ctx context.Context, db DB) error {
+	session := sessionFromContext(ctx)
+	fmt.Println("Getting ratings by time of day and weekday (Enter " + session.quit + " to quit):")
+
+	brewingFilter, band, quit, err := getBrewingFilterInput(ctx, db, true)
+	if err != nil {
+		return fmt.Errorf("buna: brewing_time: failed to get brewing filter: %w", err)
+	}
+	if quit != answered {
+		fmt.Println(quitMsg)
+		return nil
+	}
+
+	timeOfDayStatistics, err := db.getTimeOfDayStatistics(ctx, brewingFilter, band)
+	if err != nil {
+		return fmt.Errorf("buna: brewing_time: failed to get time of day statistics: %w", err)
+	}
+
+	weekdayStatistics, err := db.getWeekdayStatistics(ctx, brewingFilter, band)
+	if err != nil {
+		return fmt.Errorf("buna: brewing_time: failed to get weekday statistics: %w", err)
+	}
+
+	if len(weekdayStatistics) == 0 {
+		fmt.Println("No brewings exist")
+		return nil
+	}
+
+	fmt.Println("\nBy time of day")
+	if len(timeOfDayStatistics) > 0 {
+		if err := displayBrewingTimeStatistics(ctx, "Time of day", timeOfDayStatistics); err != nil {
+			return fmt.Errorf("buna: brewing_time: failed to display time of day statistics: %w", err)
+		}
+	}
+	fmt.Println("Brewings added before brewing times were recorded are left out")
+
+	fmt.Println("\nBy weekday")
+	if err := displayBrewingTimeStatistics(ctx, "Weekday", weekdayStatistics); err != nil {
+		return fmt.Errorf("buna: brewing_time: failed to display weekday statistics: %w", err)
+	}
+
+	return nil
+}
+
+func displayBrewingTimeStatistics(ctx context.Context, groupHeader string, statistics []brewingTimeStatistics) error {
+	t := table.NewWriter()
+
+	t.AppendHeader(table.Row{groupHeader, "Brewings", "Avg rating"})
+	for _, stats := range statistics {
+		averageRating := "-"
+		if stats.ratedCount > 0 {
+			averageRating = fmt.Sprintf("%.1f/10 (%d rated)", stats.averageRating, stats.ratedCount)
+		}
+
+		t.AppendRow(table.Row{stats.name, stats.brewingCount, averageRating})
+	}
+
+	terminalWidth, _, err := terminal.GetSize(int(os.Stdin.Fd()))
+	if err != nil {
+		return fmt.Errorf("buna: brewing_time: failed to get terminal width: %w", err)
+	}
+	t.SetAllowedRowLength(terminalWidth)
+
+	t.SetOutputMirror(os.Stdout)
+	renderTable(ctx, t)
+
+	return nil
+}
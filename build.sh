@@ -1,3 +1,3 @@
 #!/bin/bash
-go build cmd/buna/main.go
+go build -tags sqlite_fts5 cmd/buna/main.go
 mv main buna
@@ -0,0 +1,192 @@
+package buna
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// Characters used to mark one, a few and many points in the same cell of a scatter plot
+const (
+	scatterOnePoint   = '.'
+	scatterFewPoints  = 'o'
+	scatterManyPoints = '@'
+)
+
+// Renders the points as an ASCII scatter plot with width x height cells, labelled with the axis ranges.
+// Cells with more points are drawn with denser characters.
+// xs and ys must have the same length.
+func renderScatterPlot(xs []float64, ys []float64, width int, height int, xLabel string, yLabel string) string {
+	if len(xs) == 0 || width < 2 || height < 2 {
+		return ""
+	}
+
+	minX, maxX := floatRange(xs)
+	minY, maxY := floatRange(ys)
+
+	counts := make([][]int, height)
+	for i := range counts {
+		counts[i] = make([]int, width)
+	}
+	for i := range xs {
+		col := scaleToCell(xs[i], minX, maxX, width)
+		row := height - 1 - scaleToCell(ys[i], minY, maxY, height)
+		counts[row][col]++
+	}
+
+	yMaxLabel := formatAxisValue(maxY)
+	yMinLabel := formatAxisValue(minY)
+	labelWidth := len(yMaxLabel)
+	if len(yMinLabel) > labelWidth {
+		labelWidth = len(yMinLabel)
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("%*s\n", labelWidth+len(yLabel)+1, yLabel))
+	for row := range counts {
+		label := ""
+		switch row {
+		case 0:
+			label = yMaxLabel
+		case height - 1:
+			label = yMinLabel
+		}
+		sb.WriteString(fmt.Sprintf("%*s |", labelWidth, label))
+
+		for _, count := range counts[row] {
+			switch {
+			case count == 0:
+				sb.WriteRune(' ')
+			case count == 1:
+				sb.WriteRune(scatterOnePoint)
+			case count <= 3:
+				sb.WriteRune(scatterFewPoints)
+			default:
+				sb.WriteRune(scatterManyPoints)
+			}
+		}
+		sb.WriteRune('\n')
+	}
+
+	xMinLabel := formatAxisValue(minX)
+	xMaxLabel := formatAxisValue(maxX)
+	padding := width - len(xMinLabel) - len(xMaxLabel)
+	if padding < 1 {
+		padding = 1
+	}
+	sb.WriteString(strings.Repeat(" ", labelWidth+1) + "+" + strings.Repeat("-", width) + "\n")
+	sb.WriteString(strings.Repeat(" ", labelWidth+2) + xMinLabel + strings.Repeat(" ", padding) + xMaxLabel + "\n")
+	sb.WriteString(strings.Repeat(" ", labelWidth+2) + xLabel + "\n")
+
+	return sb.String()
+}
+
+// Returns the index of the cell val falls into when [min, max] is divided into cells cells.
+func scaleToCell(val float64, min float64, max float64, cells int) int {
+	if max == min {
+		return cells / 2
+	}
+
+	cell := int((val - min) / (max - min) * float64(cells-1))
+	if cell < 0 {
+		return 0
+	}
+	if cell >= cells {
+		return cells - 1
+	}
+	return cell
+}
+
+func floatRange(vals []float64) (float64, float64) {
+	min, max := math.Inf(1), math.Inf(-1)
+	for _, val := range vals {
+		min = math.Min(min, val)
+		max = math.Max(max, val)
+	}
+	return min, max
+}
+
+// Formats whole numbers without decimals and everything else with one decimal.
+func formatAxisValue(val float64) string {
+	if val == math.Trunc(val) {
+		return fmt.Sprintf("%.0f", val)
+	}
+	return fmt.Sprintf("%.1f", val)
+}
+
+// Characters used to draw sparklines, from lowest to highest
+var sparklineBlocks = []rune("▁▂▃▄▅▆▇█")
+
+// Characters used to draw the fractional end of a bar, from one eighth to seven eighths of a cell
+var partialBarBlocks = []rune("▏▎▍▌▋▊▉")
+
+// Renders the values as a sparkline of at most width characters, one character per value.
+// NaN values are drawn as gaps. If there are more values than fit, only the last width values are drawn.
+func renderSparkline(vals []float64, width int) string {
+	if len(vals) > width {
+		vals = vals[len(vals)-width:]
+	}
+
+	min, max, ok := floatRangeIgnoringNaN(vals)
+	if !ok {
+		return strings.Repeat(" ", len(vals))
+	}
+
+	var sb strings.Builder
+	for _, val := range vals {
+		if math.IsNaN(val) {
+			sb.WriteRune(' ')
+			continue
+		}
+		sb.WriteRune(sparklineBlocks[scaleToCell(val, min, max, len(sparklineBlocks))])
+	}
+
+	return sb.String()
+}
+
+// Renders one horizontal bar per value, scaled so that max fills width cells.
+// Every bar is preceded by its label and followed by its value label. NaN values are drawn without a bar.
+// labels, vals and valueLabels must have the same length.
+func renderBarChart(labels []string, vals []float64, valueLabels []string, max float64, width int) string {
+	labelWidth := 0
+	for _, label := range labels {
+		if len(label) > labelWidth {
+			labelWidth = len(label)
+		}
+	}
+
+	var sb strings.Builder
+	for i, val := range vals {
+		sb.WriteString(fmt.Sprintf("%-*s │", labelWidth, labels[i]))
+
+		if !math.IsNaN(val) && max > 0 {
+			// Bar length in eighths of a cell
+			eighths := int(math.Round(math.Min(val, max) / max * float64(width*8)))
+			sb.WriteString(strings.Repeat("█", eighths/8))
+			if eighths%8 != 0 {
+				sb.WriteRune(partialBarBlocks[eighths%8-1])
+			}
+		}
+
+		sb.WriteString(" " + valueLabels[i] + "\n")
+	}
+
+	return sb.String()
+}
+
+// Returns the smallest and largest value that is not NaN.
+// The third return value is false if all values are NaN.
+func floatRangeIgnoringNaN(vals []float64) (float64, float64, bool) {
+	var nonNaN []float64
+	for _, val := range vals {
+		if !math.IsNaN(val) {
+			nonNaN = append(nonNaN, val)
+		}
+	}
+	if len(nonNaN) == 0 {
+		return 0, 0, false
+	}
+
+	min, max := floatRange(nonNaN)
+	return min, max, true
+}
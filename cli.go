@@ -0,0 +1,274 @@
+package buna
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/ssh/terminal"
+)
+
+// RunCommand runs a single non-interactive command.
+// args are the command line arguments following the global flags, starting with the command name.
+func RunCommand(ctx context.Context, db DB, cfg Config, args []string) error {
+	if len(args) == 0 {
+		return errors.New("buna: cli: no command given")
+	}
+
+	ctx, err := startSession(ctx, db, cfg)
+	if err != nil {
+		return fmt.Errorf("buna: cli: failed to start session: %w", err)
+	}
+
+	ctx, err = withDefaultUser(ctx, db)
+	if err != nil {
+		return fmt.Errorf("buna: cli: failed to select default user: %w", err)
+	}
+
+	switch args[0] {
+	case "search":
+		if err := runSearchCommand(ctx, db, args[1:]); err != nil {
+			return fmt.Errorf("buna: cli: failed to run search command: %w", err)
+		}
+	case "brewings":
+		if err := runBrewingsCommand(ctx, db, args[1:]); err != nil {
+			return fmt.Errorf("buna: cli: failed to run brewings command: %w", err)
+		}
+	case "add-brewing":
+		if err := runAddBrewingCommand(ctx, db, args[1:]); err != nil {
+			return fmt.Errorf("buna: cli: failed to run add-brewing command: %w", err)
+		}
+	default:
+		return fmt.Errorf("buna: cli: unknown command %q", args[0])
+	}
+
+	return nil
+}
+
+// Usage: search [-limit n] <terms>
+func runSearchCommand(ctx context.Context, db DB, args []string) error {
+	flags := flag.NewFlagSet("search", flag.ContinueOnError)
+	limit := flags.Int("limit", sessionFromContext(ctx).config.intValue("display.search_hits"), "Maximum number of hits to display")
+	if err := flags.Parse(args); err != nil {
+		return fmt.Errorf("buna: cli: failed to parse search flags: %w", err)
+	}
+
+	query := strings.Join(flags.Args(), " ")
+	if strings.TrimSpace(query) == "" {
+		return errors.New("buna: cli: no search terms given")
+	}
+	if *limit <= 0 {
+		return errors.New("buna: cli: limit must be positive")
+	}
+
+	available, err := db.getNoteSearchAvailable(ctx)
+	if err != nil {
+		return fmt.Errorf("buna: cli: failed to check whether notes can be searched: %w", err)
+	}
+	if !available {
+		return errors.New("buna: cli: notes can only be searched if buna is built with the sqlite_fts5 tag")
+	}
+
+	// Only highlight matches if the output is displayed in a terminal
+	start, end := "", ""
+	if terminal.IsTerminal(int(os.Stdout.Fd())) {
+		start, end = highlightStart, highlightEnd
+	}
+
+	hits, err := db.getNoteSearchHits(ctx, buildNotesSearchQuery(query), start, end, *limit)
+	if err != nil {
+		return fmt.Errorf("buna: cli: failed to get note search hits: %w", err)
+	}
+
+	if len(hits) == 0 {
+		fmt.Println("No notes match the search terms")
+		return nil
+	}
+
+	if err := displayNoteSearchHits(ctx, hits); err != nil {
+		return fmt.Errorf("buna: cli: failed to display note search hits: %w", err)
+	}
+
+	return nil
+}
+
+// Usage: brewings [-from YYYY-MM-DD] [-to YYYY-MM-DD] [-coffee name] [-roaster name] [-method name] [-grinder name]
+// [-filter type] [-min-rating n] [-max-rating n] [-min-grind n] [-max-grind n] [-min-ratio x] [-max-ratio x]
+// [-notes text] [-sort key] [-asc] [-limit n]
+func runBrewingsCommand(ctx context.Context, db DB, args []string) error {
+	var query brewingQuery
+	flags := flag.NewFlagSet("brewings", flag.ContinueOnError)
+	flags.StringVar(&query.fromDate, "from", "", "First brewing date (YYYY-MM-DD)")
+	flags.StringVar(&query.toDate, "to", "", "Last brewing date (YYYY-MM-DD)")
+	flags.StringVar(&query.coffeeName, "coffee", "", "Part of the coffee name")
+	flags.StringVar(&query.coffeeRoaster, "roaster", "", "Part of the roaster name")
+	flags.StringVar(&query.brewingMethodName, "method", "", "Part of the brewing method name")
+	flags.StringVar(&query.grinderName, "grinder", "", "Part of the grinder name")
+	flags.StringVar(&query.v60FilterType, "filter", "", "V60 filter type (eu or jp)")
+	flags.IntVar(&query.minRating, "min-rating", 0, "Minimum rating")
+	flags.IntVar(&query.maxRating, "max-rating", 0, "Maximum rating")
+	flags.IntVar(&query.minGrindSetting, "min-grind", 0, "Minimum grind setting")
+	flags.IntVar(&query.maxGrindSetting, "max-grind", 0, "Maximum grind setting")
+	flags.Float64Var(&query.minRatio, "min-ratio", 0, "Minimum brew ratio (1:x)")
+	flags.Float64Var(&query.maxRatio, "max-ratio", 0, "Maximum brew ratio (1:x)")
+	flags.StringVar(&query.notes, "notes", "", "Text the notes must contain")
+	flags.StringVar(&query.sortBy, "sort", defaultBrewingSortKey, "Sort key, one of "+strings.Join(brewingSortKeys, ", "))
+	flags.BoolVar(&query.ascending, "asc", false, "Sort ascending instead of descending")
+	limit := flags.Int("limit", 10, "Maximum number of brewings to display")
+	if err := flags.Parse(args); err != nil {
+		// The usage has already been printed
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+		return fmt.Errorf("buna: cli: failed to parse brewings flags: %w", err)
+	}
+
+	if flags.NArg() > 0 {
+		return fmt.Errorf("buna: cli: unexpected arguments %q", flags.Args())
+	}
+	if *limit <= 0 {
+		return errors.New("buna: cli: limit must be positive")
+	}
+	if err := validateBrewingQuery(query); err != nil {
+		return fmt.Errorf("buna: cli: invalid brewing query: %w", err)
+	}
+
+	brewings, err := db.getBrewingsByQuery(ctx, query, nil, *limit)
+	if err != nil {
+		return fmt.Errorf("buna: cli: failed to get brewings by query: %w", err)
+	}
+
+	if len(brewings) == 0 {
+		fmt.Println("No brewings match the query")
+		return nil
+	}
+
+	if err := displayBrewings(ctx, brewings); err != nil {
+		return fmt.Errorf("buna: cli: failed to display brewings: %w", err)
+	}
+
+	return nil
+}
+
+// Usage: add-brewing [-json file] [-date date] [-coffee name] [-roaster name] [-method name] [-grinder name] ...
+// Takes a flag for every field in brewingFields. Field values can also be given as a JSON object in a file, or on
+// stdin if the file is "-", which flags override.
+func runAddBrewingCommand(ctx context.Context, db DB, args []string) error {
+	flags := flag.NewFlagSet("add-brewing", flag.ContinueOnError)
+	jsonPath := flags.String("json", "", "JSON file with the field values, - for stdin")
+	inputs := brewingFields.defineFlags(ctx, flags)
+	if err := flags.Parse(args); err != nil {
+		// The usage has already been printed
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+		return fmt.Errorf("buna: cli: failed to parse add-brewing flags: %w", err)
+	}
+
+	if flags.NArg() > 0 {
+		return fmt.Errorf("buna: cli: unexpected arguments %q", flags.Args())
+	}
+
+	values := fieldValues{}
+	if *jsonPath != "" {
+		var data []byte
+		var err error
+		if *jsonPath == "-" {
+			data, err = ioutil.ReadAll(os.Stdin)
+		} else {
+			data, err = ioutil.ReadFile(*jsonPath)
+		}
+		if err != nil {
+			return fmt.Errorf("buna: cli: failed to read JSON field values: %w", err)
+		}
+
+		// Required fields may still be given as flags, so missing fields are only checked once the flags are parsed
+		values, err = brewingFields.decodeJSON(ctx, data)
+		if err != nil {
+			return fmt.Errorf("buna: cli: invalid JSON field values: %w", err)
+		}
+	}
+
+	values, err := brewingFields.parseFlags(ctx, flags, inputs, values)
+	if err != nil {
+		return fmt.Errorf("buna: cli: invalid field values: %w", err)
+	}
+
+	b := brewingFromFieldValues(values)
+	if b.date == "" {
+		b.date = time.Now().Format(time.RFC3339)
+	}
+
+	if err := checkBrewingReferences(ctx, db, b); err != nil {
+		return fmt.Errorf("buna: cli: invalid brewing: %w", err)
+	}
+
+	if err := db.insertBrewing(ctx, b); err != nil {
+		return fmt.Errorf("buna: cli: failed to insert brewing: %w", err)
+	}
+
+	fmt.Println("Added coffee brewing successfully")
+	return nil
+}
+
+// Checks that the coffee, brewing method and grinder of the brewing exist, naming similar ones if they do not.
+func checkBrewingReferences(ctx context.Context, db DB, b brewing) error {
+	if _, err := db.getCoffeeByNameRoaster(ctx, b.coffeeName, b.coffeeRoaster); err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("buna: cli: failed to get coffee by name and roaster: %w", err)
+		}
+
+		similarCoffees, err := db.getSimilarCoffees(ctx, b.coffeeName, b.coffeeRoaster, maxSimilarNames)
+		if err != nil {
+			return fmt.Errorf("buna: cli: failed to get similar coffees: %w", err)
+		}
+		similarNames := make([]string, 0, len(similarCoffees))
+		for _, c := range similarCoffees {
+			similarNames = append(similarNames, c.name+" ("+c.roaster+")")
+		}
+
+		return unknownReferenceError("coffee", b.coffeeName+" ("+b.coffeeRoaster+")", similarNames)
+	}
+
+	if _, err := db.getMethodIDByName(ctx, b.brewingMethodName); err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("buna: cli: failed to get brewing method: %w", err)
+		}
+
+		similarNames, err := db.getSimilarBrewingMethodNames(ctx, b.brewingMethodName, maxSimilarNames)
+		if err != nil {
+			return fmt.Errorf("buna: cli: failed to get similar brewing method names: %w", err)
+		}
+
+		return unknownReferenceError("brewing method", b.brewingMethodName, similarNames)
+	}
+
+	if _, err := db.getGrinderIDByName(ctx, b.grinderName); err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("buna: cli: failed to get grinder: %w", err)
+		}
+
+		similarNames, err := db.getSimilarGrinderNames(ctx, b.grinderName, maxSimilarNames)
+		if err != nil {
+			return fmt.Errorf("buna: cli: failed to get similar grinder names: %w", err)
+		}
+
+		return unknownReferenceError("grinder", b.grinderName, similarNames)
+	}
+
+	return nil
+}
+
+func unknownReferenceError(entityName string, name string, similarNames []string) error {
+	if len(similarNames) == 0 {
+		return fmt.Errorf("buna: cli: unknown %v %q", entityName, name)
+	}
+
+	return fmt.Errorf("buna: cli: unknown %v %q, did you mean %v?", entityName, name, strings.Join(similarNames, " or "))
+}
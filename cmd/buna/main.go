@@ -10,7 +10,10 @@ import (
 )
 
 func main() {
-	var bunaDBFilePath = flag.String("db", "bunaDB.db", "SQLite BunaDB file path")
+	var bunaDBFilePath = flag.String("db", "", "SQLite BunaDB file path (default database.path of the config, bunaDB.db)")
+	var configFilePath = flag.String("config", buna.DefaultConfigPath(), "Config file path")
+	var fullScreen = flag.Bool("tui", false, "Run the full-screen terminal UI instead of the menus")
+	var userName = flag.String("user", "", "Name of the user profile to use (default defaults.user of the config)")
 	flag.Parse()
 
 	ctx := context.Background()
@@ -21,6 +24,23 @@ func main() {
 	}
 	defer logger.Sync() // nolint:errcheck
 
+	// The config command only edits the config file and does not need the database
+	if flag.NArg() > 0 && flag.Arg(0) == "config" {
+		if err := buna.RunConfigCommand(*configFilePath, flag.Args()[1:]); err != nil {
+			logger.Fatal("buna: failed to run buna config command", zap.Error(err))
+		}
+		return
+	}
+
+	cfg, err := buna.LoadConfig(*configFilePath)
+	if err != nil {
+		logger.Fatal("buna: failed to load config", zap.Error(err))
+	}
+
+	if *bunaDBFilePath == "" {
+		*bunaDBFilePath = cfg.DBPath()
+	}
+
 	bunaDB, err := buna.OpenSQLiteDB(ctx, logger, *bunaDBFilePath)
 	if err != nil {
 		logger.Fatal("buna: failed to open SQLite buna database", zap.Error(err))
@@ -28,7 +48,28 @@ func main() {
 	defer bunaDB.Close()
 	logger.Info("buna: connected to SQLite buna database")
 
-	if err := buna.Run(ctx, bunaDB); err != nil {
+	if *userName != "" {
+		ctx, err = buna.WithUser(ctx, bunaDB, *userName)
+		if err != nil {
+			logger.Fatal("buna: failed to select user", zap.Error(err))
+		}
+	}
+
+	if flag.NArg() > 0 {
+		if err := buna.RunCommand(ctx, bunaDB, cfg, flag.Args()); err != nil {
+			logger.Fatal("buna: failed to run buna command", zap.Error(err))
+		}
+		return
+	}
+
+	if *fullScreen {
+		if err := buna.RunTUI(ctx, bunaDB, cfg); err != nil {
+			logger.Fatal("buna: failed to run buna full-screen terminal UI", zap.Error(err))
+		}
+		return
+	}
+
+	if err := buna.Run(ctx, bunaDB, cfg); err != nil {
 		logger.Fatal("buna: failed to run buna", zap.Error(err))
 	}
 }
@@ -2,107 +2,249 @@ package buna
 
 import (
 	"context"
+	"database/sql"
 	"errors"
 	"fmt"
 	"os"
+	"strings"
 
 	"github.com/jedib0t/go-pretty/table"
 	"golang.org/x/crypto/ssh/terminal"
 )
 
 type coffee struct {
-	name    string
-	roaster string
-	region  string
-	variety string
-	method  string
-	decaf   bool
-}
-
-// Returns the added coffee
-func addCoffee(ctx context.Context, db DB) (coffee, error) {
-	fmt.Println("Adding new coffee (Enter # to quit):")
-	fmt.Print("Enter coffee name: ")
-	name, quit := validateStrInput(quitStr, false, nil, nil)
-	if quit {
-		fmt.Println(quitMsg)
-		return coffee{}, nil
-	}
+	id           int
+	name         string
+	roaster      string
+	countryCode  string
+	region       string
+	farm         string
+	producer     string
+	altitudeMinM int
+	altitudeMaxM int
+	varieties    []string
+	process      string
+	processOther string
+	decaf        bool
 
-	fmt.Print("Enter roaster/producer name: ")
-	roaster, quit := validateStrInput(quitStr, false, nil, nil)
-	if quit {
-		fmt.Println(quitMsg)
-		return coffee{}, nil
-	}
+	// Aggregates that are only set when retrieving coffees
+	brewingCount   int
+	averageRating  float64
+	lastBrewedDate string
+	purchaseCount  int
+}
 
-	fmt.Print("Enter origin/region (Format: Region, Country): ")
-	region, quit := validateStrInput(quitStr, true, nil, nil)
-	if quit {
-		fmt.Println(quitMsg)
-		return coffee{}, nil
-	}
+// Fields of a coffee that are entered directly.
+// The roaster is looked up by name and can be created on the way, the country is picked from the known countries
+// and the processing method and decaf consist of several values or are no text, so they are asked for with hand-written steps.
+var coffeeFields = fieldSet{
+	{name: "name", key: "Coffee name", prompt: "Enter coffee name", help: "Name of the coffee", kind: textField},
+	{name: "region", key: "Region", prompt: "Enter region", help: "Region the coffee was grown in", kind: textField, optional: true},
+	{name: "farm", key: "Farm/washing station", prompt: "Enter farm/washing station", help: "Farm or washing station", kind: textField, optional: true},
+	{name: "producer", key: "Producer", prompt: "Enter producer", help: "Producer", kind: textField, optional: true},
+	{name: "altitude_min_m", key: "Minimum altitude (m)", prompt: "Enter the minimum altitude in metres", help: "Minimum altitude in metres", kind: intField, optional: true, min: 0, max: maxAltitudeM},
+	// The lower bound is the minimum altitude of the coffee
+	{name: "altitude_max_m", key: "Maximum altitude (m)", prompt: "Enter the maximum altitude in metres", help: "Maximum altitude in metres", kind: intField, optional: true, min: 0, max: maxAltitudeM},
+	{name: "varieties", key: "Varieties", prompt: "Enter varieties (Format: Variety 1, Variety 2, ...)", help: "Comma separated varieties", kind: textField, optional: true},
+}
 
-	fmt.Print("Enter variety (Format: Variety 1, Variety 2, ...): ")
-	variety, quit := validateStrInput(quitStr, true, nil, nil)
-	if quit {
-		fmt.Println(quitMsg)
-		return coffee{}, nil
+// The user is only prompted for the coffee name and the roaster name if name and roasterName are empty.
+// Returns the added coffee, how the form was left, error
+func addCoffee(ctx context.Context, db DB, name string, roasterName string) (coffee, promptResult, error) {
+	session := sessionFromContext(ctx)
+	fmt.Println("Adding new coffee (Enter " + session.quit + " to quit, " + session.back + " to go back):")
+
+	var (
+		roaster      string
+		countryCode  string
+		region       string
+		farm         string
+		producer     string
+		altitudeMinM int
+		altitudeMaxM int
+		varieties    string
+		process      string
+		processOther string
+		decaf        bool
+	)
+
+	nameStep := coffeeFields.step(ctx, db, "name", &name, nil)
+	nameStep.prefilled = name != ""
+
+	steps := []formStep{
+		nameStep,
+		{key: "Roaster", value: &roaster, prompt: func(exits promptExits) (promptResult, error) {
+			// A roaster name that does not exist and is not created is entered again
+			for {
+				if roasterName == "" {
+					var quit promptResult
+					var err error
+					roasterName, quit, err = getRoasterNameWithSuggestions(ctx, db, exits, false)
+					if err != nil || quit != answered {
+						return quit, err
+					}
+				}
+
+				var quit promptResult
+				var err error
+				roaster, quit, err = getExistingRoasterName(ctx, db, exits, roasterName, "\nContinuing with the new coffee (Enter "+session.quit+" to quit, "+session.back+" to go back):")
+				roasterName = ""
+				if err != nil || quit != answered {
+					return quit, err
+				}
+				if roaster != "" {
+					return answered, nil
+				}
+			}
+		}},
+		{key: "Country", value: &countryCode, prompt: func(exits promptExits) (promptResult, error) {
+			var quit promptResult
+			var err error
+			countryCode, quit, err = getCountryCodeWithSuggestions(ctx, db, exits, true)
+			return quit, err
+		}},
+		coffeeFields.step(ctx, db, "region", &region, nil),
+		coffeeFields.step(ctx, db, "farm", &farm, nil),
+		coffeeFields.step(ctx, db, "producer", &producer, nil),
+		coffeeFields.step(ctx, db, "altitude_min_m", &altitudeMinM, nil),
+		{key: "Maximum altitude (m)", value: &altitudeMaxM, skip: func() bool { return altitudeMinM == 0 }, prompt: func(exits promptExits) (promptResult, error) {
+			f, ok := coffeeFields.field("altitude_max_m")
+			if !ok {
+				return answered, unknownFieldError("altitude_max_m")
+			}
+			f.min = float64(altitudeMinM)
+
+			var quit promptResult
+			var err error
+			altitudeMaxM, quit, err = f.askInt(ctx, db, exits, nil)
+			return quit, err
+		}},
+		coffeeFields.step(ctx, db, "varieties", &varieties, nil),
+		{key: "Processing method", value: &process, prompt: func(exits promptExits) (promptResult, error) {
+			var quit promptResult
+			process, processOther, quit = getProcessInput(exits, true)
+			return quit, nil
+		}},
+		{key: "Decaf", value: &decaf, prompt: func(exits promptExits) (promptResult, error) {
+			var quit promptResult
+			fmt.Print("Is decaf (true or false): ")
+			decaf, quit = validateBoolInput(exits, true)
+			return quit, nil
+		}},
 	}
 
-	fmt.Print("Enter processing method: ")
-	method, quit := validateStrInput(quitStr, true, nil, nil)
-	if quit {
-		fmt.Println(quitMsg)
-		return coffee{}, nil
+	result, err := runForm(ctx, nil, steps)
+	if err != nil {
+		return coffee{}, answered, fmt.Errorf("buna: coffee: failed to run coffee form: %w", err)
+	}
+	if result != answered {
+		// Going back from the first prompt returns to the flow the coffee is added for
+		if result == quitPrompt {
+			fmt.Println(quitMsg)
+		}
+		return coffee{}, result, nil
 	}
 
-	fmt.Print("Is decaf (true or false): ")
-	decaf, quit := validateBoolInput(quitStr, true)
-	if quit {
-		fmt.Println(quitMsg)
-		return coffee{}, nil
+	// The maximum altitude defaults to the minimum altitude and is not asked for without one
+	if altitudeMaxM == 0 || altitudeMinM == 0 {
+		altitudeMaxM = altitudeMinM
 	}
 
 	newCoffee := coffee{
-		name:    name,
-		roaster: roaster,
-		region:  region,
-		variety: variety,
-		method:  method,
-		decaf:   decaf,
+		name:         name,
+		roaster:      roaster,
+		countryCode:  countryCode,
+		region:       region,
+		farm:         farm,
+		producer:     producer,
+		altitudeMinM: altitudeMinM,
+		altitudeMaxM: altitudeMaxM,
+		varieties:    parseVarieties(varieties),
+		process:      process,
+		processOther: processOther,
+		decaf:        decaf,
 	}
 
 	if err := db.insertCoffee(ctx, newCoffee); err != nil {
-		return coffee{}, fmt.Errorf("buna: coffee: failed to insert coffee: %w", err)
+		return coffee{}, answered, fmt.Errorf("buna: coffee: failed to insert coffee: %w", err)
 	}
 
 	fmt.Println("Added coffee successfully")
-	return newCoffee, nil
+	return newCoffee, answered, nil
+}
+
+// Returns the stored coffee with the name and roaster, which might differ in case from name and roasterName.
+// If it does not exist, the user can choose a coffee with a similar name instead, create it and continue after printing resumeMsg
+// or enter a different name, in which case the returned name is empty.
+// Returns coffee, promptResult, error
+func getExistingCoffee(ctx context.Context, db DB, exits promptExits, name string, roasterName string, resumeMsg string) (coffee, promptResult, error) {
+	existingCoffee, err := db.getCoffeeByNameRoaster(ctx, name, roasterName)
+	if err == nil {
+		return existingCoffee, answered, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return coffee{}, answered, fmt.Errorf("buna: coffee: failed to get coffee by name and roaster: %w", err)
+	}
+
+	similarCoffees, err := db.getSimilarCoffees(ctx, name, roasterName, maxSimilarNames)
+	if err != nil {
+		return coffee{}, answered, fmt.Errorf("buna: coffee: failed to get similar coffees: %w", err)
+	}
+
+	similarNames := make([]string, 0, len(similarCoffees))
+	for _, c := range similarCoffees {
+		similarNames = append(similarNames, c.name+" ("+c.roaster+")")
+	}
+
+	selection, quit := getDidYouMeanSelection(exits, "coffee", name+" ("+roasterName+")", similarNames)
+	if quit != answered {
+		return coffee{}, quit, nil
+	}
+	if selection == reenterSelection {
+		return coffee{}, answered, nil
+	}
+	if selection >= 0 {
+		return similarCoffees[selection], answered, nil
+	}
+
+	addedCoffee, result, err := addCoffee(ctx, db, name, roasterName)
+	if err != nil {
+		return coffee{}, answered, fmt.Errorf("buna: coffee: failed to add coffee: %w", err)
+	}
+	if result != answered {
+		return coffee{}, result, nil
+	}
+
+	if resumeMsg != "" {
+		fmt.Println(resumeMsg)
+	}
+
+	return addedCoffee, answered, nil
 }
 
 func retrieveCoffee(ctx context.Context, db DB) error {
 	options := map[int]string{
 		0: "Retrieve coffees ordered by last added",
-		// 1: "Retrieve coffee by name",
-		// 2: "Retrieve coffees ordered alphabetically",
-		// 3: "Retrieve coffees by origin",
-		// 4: "Retrieve coffees by roaster",
-		// 5: "Retrieve coffees by processing method",
-		// 6: "Retrieve decaf coffees ordered by last added",
-		// 7: "Retrieve decaf coffees ordered alphabetically",
+		1: "Retrieve coffee by name",
+		2: "Retrieve coffees ordered alphabetically",
+		3: "Retrieve coffees by origin",
+		4: "Retrieve coffees by roaster",
+		5: "Retrieve coffees by processing method",
+		6: "Retrieve decaf coffees ordered by last added",
+		7: "Retrieve decaf coffees ordered alphabetically",
 	}
 
-	fmt.Println("Retrieving coffee (Enter # to quit):")
-	if err := displayIntOptions(options); err != nil {
+	session := sessionFromContext(ctx)
+	fmt.Println("Retrieving coffee (Enter " + session.quit + " to quit):")
+	if err := displayIntOptions(ctx, options); err != nil {
 		return fmt.Errorf("buna: coffee: failed to display int options: %w", err)
 	}
 
-	selection, quit, err := getIntSelection(options, quitStr)
+	selection, quit, err := getIntSelection(ctx, options, quitExits(ctx))
 	if err != nil {
 		return fmt.Errorf("buna: coffee: failed to get int selection: %w", err)
 	}
-	if quit {
+	if quit != answered {
 		fmt.Println(quitMsg)
 		return nil
 	}
@@ -120,53 +262,318 @@ func runRetrieveCoffeeSelection(ctx context.Context, selection int, db DB) error
 		if err := displayCoffeesByLastAdded(ctx, db); err != nil {
 			return fmt.Errorf("buna: coffee: failed to display coffees by last added: %w", err)
 		}
+	case 1:
+		if err := displayCoffeesByName(ctx, db); err != nil {
+			return fmt.Errorf("buna: coffee: failed to display coffees by name: %w", err)
+		}
+	case 2:
+		if err := displayCoffeesAlphabetically(ctx, db); err != nil {
+			return fmt.Errorf("buna: coffee: failed to display coffees alphabetically: %w", err)
+		}
+	case 3:
+		if err := displayCoffeesByOrigin(ctx, db); err != nil {
+			return fmt.Errorf("buna: coffee: failed to display coffees by origin: %w", err)
+		}
+	case 4:
+		if err := displayCoffeesByRoaster(ctx, db); err != nil {
+			return fmt.Errorf("buna: coffee: failed to display coffees by roaster: %w", err)
+		}
+	case 5:
+		if err := displayCoffeesByProcess(ctx, db); err != nil {
+			return fmt.Errorf("buna: coffee: failed to display coffees by process: %w", err)
+		}
+	case 6:
+		if err := displayDecafCoffeesByLastAdded(ctx, db); err != nil {
+			return fmt.Errorf("buna: coffee: failed to display decaf coffees by last added: %w", err)
+		}
+	case 7:
+		if err := displayDecafCoffeesAlphabetically(ctx, db); err != nil {
+			return fmt.Errorf("buna: coffee: failed to display decaf coffees alphabetically: %w", err)
+		}
 	default:
 		return errors.New("buna: coffee: invalid retrieve selection")
 	}
 	return nil
 }
 
-// Promts user for an optional limit.
+// Promts user for an optional page size.
 func displayCoffeesByLastAdded(ctx context.Context, db DB) error {
-	const defaultDisplayAmount = 15
-	const maxDisplayAmount = 60
+	session := sessionFromContext(ctx)
+	fmt.Println("Displaying coffees by last added (Enter " + session.quit + " to quit):")
+	pageSize, quit := getCoffeePageSize(ctx)
+	if quit != answered {
+		fmt.Println(quitMsg)
+		return nil
+	}
+
+	if err := browseCoffees(ctx, pageSize, func(after *pageCursor, limit int) ([]coffee, error) {
+		return db.getCoffeesByLastAdded(ctx, after, limit)
+	}, nil); err != nil {
+		return fmt.Errorf("buna: coffee: failed to browse coffees by last added: %w", err)
+	}
+
+	return nil
+}
+
+// Prompts user for a (partial) name and an optional page size.
+func displayCoffeesByName(ctx context.Context, db DB) error {
+	session := sessionFromContext(ctx)
+	fmt.Println("Displaying coffees by name (Enter " + session.quit + " to quit):")
+	fmt.Print("Enter coffee name (partial names match): ")
+	name, quit := validateStrInput(quitExits(ctx), false, nil, nil)
+	if quit != answered {
+		fmt.Println(quitMsg)
+		return nil
+	}
+
+	pageSize, quit := getCoffeePageSize(ctx)
+	if quit != answered {
+		fmt.Println(quitMsg)
+		return nil
+	}
+
+	if err := browseCoffees(ctx, pageSize, func(after *pageCursor, limit int) ([]coffee, error) {
+		return db.getCoffeesByName(ctx, name, after, limit)
+	}, coffeeNameKey); err != nil {
+		return fmt.Errorf("buna: coffee: failed to browse coffees by name: %w", err)
+	}
+
+	return nil
+}
 
-	fmt.Println("Displaying coffees by last added (Enter # to quit):")
-	fmt.Print("Enter a limit for the number of coffees to display: ")
-	limit, quit := validateIntInput(quitStr, true, 1, maxDisplayAmount, []int{})
-	if quit {
+// Promts user for an optional page size.
+func displayCoffeesAlphabetically(ctx context.Context, db DB) error {
+	session := sessionFromContext(ctx)
+	fmt.Println("Displaying coffees alphabetically (Enter " + session.quit + " to quit):")
+	pageSize, quit := getCoffeePageSize(ctx)
+	if quit != answered {
 		fmt.Println(quitMsg)
 		return nil
 	}
 
-	if limit == 0 {
-		limit = defaultDisplayAmount
+	if err := browseCoffees(ctx, pageSize, func(after *pageCursor, limit int) ([]coffee, error) {
+		return db.getCoffeesAlphabetically(ctx, after, limit)
+	}, coffeeNameKey); err != nil {
+		return fmt.Errorf("buna: coffee: failed to browse coffees alphabetically: %w", err)
 	}
 
-	coffees, err := db.getCoffeesByLastAdded(ctx, limit)
+	return nil
+}
+
+// Prompts user for a (partial) roaster name and an optional page size.
+func displayCoffeesByRoaster(ctx context.Context, db DB) error {
+	session := sessionFromContext(ctx)
+	fmt.Println("Displaying coffees by roaster (Enter " + session.quit + " to quit):")
+	roaster, quit, err := getRoasterNameWithSuggestions(ctx, db, quitExits(ctx), false)
 	if err != nil {
-		return fmt.Errorf("buna: coffee: failed to get coffees by last added: %w", err)
+		return fmt.Errorf("buna: coffee: failed to get roaster name: %w", err)
+	}
+	if quit != answered {
+		fmt.Println(quitMsg)
+		return nil
 	}
 
+	pageSize, quit := getCoffeePageSize(ctx)
+	if quit != answered {
+		fmt.Println(quitMsg)
+		return nil
+	}
+
+	if err := browseCoffees(ctx, pageSize, func(after *pageCursor, limit int) ([]coffee, error) {
+		return db.getCoffeesByRoaster(ctx, roaster, after, limit)
+	}, coffeeRoasterKey); err != nil {
+		return fmt.Errorf("buna: coffee: failed to browse coffees by roaster: %w", err)
+	}
+
+	return nil
+}
+
+// Promts user for an optional page size.
+func displayDecafCoffeesByLastAdded(ctx context.Context, db DB) error {
+	session := sessionFromContext(ctx)
+	fmt.Println("Displaying decaf coffees by last added (Enter " + session.quit + " to quit):")
+	pageSize, quit := getCoffeePageSize(ctx)
+	if quit != answered {
+		fmt.Println(quitMsg)
+		return nil
+	}
+
+	if err := browseCoffees(ctx, pageSize, func(after *pageCursor, limit int) ([]coffee, error) {
+		return db.getDecafCoffeesByLastAdded(ctx, after, limit)
+	}, nil); err != nil {
+		return fmt.Errorf("buna: coffee: failed to browse decaf coffees by last added: %w", err)
+	}
+
+	return nil
+}
+
+// Promts user for an optional page size.
+func displayDecafCoffeesAlphabetically(ctx context.Context, db DB) error {
+	session := sessionFromContext(ctx)
+	fmt.Println("Displaying decaf coffees alphabetically (Enter " + session.quit + " to quit):")
+	pageSize, quit := getCoffeePageSize(ctx)
+	if quit != answered {
+		fmt.Println(quitMsg)
+		return nil
+	}
+
+	if err := browseCoffees(ctx, pageSize, func(after *pageCursor, limit int) ([]coffee, error) {
+		return db.getDecafCoffeesAlphabetically(ctx, after, limit)
+	}, coffeeNameKey); err != nil {
+		return fmt.Errorf("buna: coffee: failed to browse decaf coffees alphabetically: %w", err)
+	}
+
+	return nil
+}
+
+// Prompts user for a country, an optional region and an optional page size.
+func displayCoffeesByOrigin(ctx context.Context, db DB) error {
+	session := sessionFromContext(ctx)
+	fmt.Println("Displaying coffees by origin (Enter " + session.quit + " to quit):")
+	countryCode, quit, err := getCountryCodeWithSuggestions(ctx, db, quitExits(ctx), false)
+	if err != nil {
+		return fmt.Errorf("buna: coffee: failed to get country code: %w", err)
+	}
+	if quit != answered {
+		fmt.Println(quitMsg)
+		return nil
+	}
+
+	fmt.Print("Enter region (optional, partial names match): ")
+	region, quit := validateStrInput(quitExits(ctx), true, nil, nil)
+	if quit != answered {
+		fmt.Println(quitMsg)
+		return nil
+	}
+
+	pageSize, quit := getCoffeePageSize(ctx)
+	if quit != answered {
+		fmt.Println(quitMsg)
+		return nil
+	}
+
+	if err := browseCoffees(ctx, pageSize, func(after *pageCursor, limit int) ([]coffee, error) {
+		return db.getCoffeesByOrigin(ctx, countryCode, region, after, limit)
+	}, nil); err != nil {
+		return fmt.Errorf("buna: coffee: failed to browse coffees by origin: %w", err)
+	}
+
+	return nil
+}
+
+// Prompts user for a processing method and an optional page size.
+func displayCoffeesByProcess(ctx context.Context, db DB) error {
+	session := sessionFromContext(ctx)
+	fmt.Println("Displaying coffees by processing method (Enter " + session.quit + " to quit):")
+	fmt.Print("Enter processing method (partial names match): ")
+	process, quit := validateStrInput(quitExits(ctx), false, nil, processes)
+	if quit != answered {
+		fmt.Println(quitMsg)
+		return nil
+	}
+
+	pageSize, quit := getCoffeePageSize(ctx)
+	if quit != answered {
+		fmt.Println(quitMsg)
+		return nil
+	}
+
+	if err := browseCoffees(ctx, pageSize, func(after *pageCursor, limit int) ([]coffee, error) {
+		return db.getCoffeesByProcess(ctx, process, after, limit)
+	}, nil); err != nil {
+		return fmt.Errorf("buna: coffee: failed to browse coffees by process: %w", err)
+	}
+
+	return nil
+}
+
+// Returns pageSize, promptResult
+func getCoffeePageSize(ctx context.Context) (int, promptResult) {
+	defaultPageSize := sessionFromContext(ctx).config.intValue("display.coffees_page_size")
+	const maxPageSize = 60
+
+	fmt.Print("Enter the number of coffees to display per page: ")
+	pageSize, quit := validateIntInput(quitExits(ctx), true, 1, maxPageSize, []int{})
+	if quit != answered {
+		return 0, quit
+	}
+
+	if pageSize == 0 {
+		pageSize = defaultPageSize
+	}
+
+	return pageSize, answered
+}
+
+// Page cursor keys of coffees ordered by name or by roaster
+func coffeeNameKey(c coffee) interface{}    { return c.name }
+func coffeeRoasterKey(c coffee) interface{} { return c.roaster }
+
+// Displays the coffees retrieved by getCoffees page by page.
+// keyOf returns the page cursor key of a coffee and is nil if the coffees are only ordered by id.
+func browseCoffees(ctx context.Context, pageSize int, getCoffees func(after *pageCursor, limit int) ([]coffee, error), keyOf func(c coffee) interface{}) error {
+	var coffees []coffee
+	listing := pagedListing{
+		fetch: func(after *pageCursor, limit int) ([]pageCursor, error) {
+			var err error
+			coffees, err = getCoffees(after, limit)
+			if err != nil {
+				return nil, fmt.Errorf("buna: coffee: failed to get coffees: %w", err)
+			}
+
+			cursors := make([]pageCursor, len(coffees))
+			for i, c := range coffees {
+				cursors[i].id = c.id
+				if keyOf != nil {
+					cursors[i].key = keyOf(c)
+				}
+			}
+			return cursors, nil
+		},
+		display: func(n int) error {
+			return displayCoffees(ctx, coffees[:n])
+		},
+	}
+
+	if err := browsePages(quitExits(ctx), listing, pageSize); err != nil {
+		return fmt.Errorf("buna: coffee: failed to browse pages: %w", err)
+	}
+
+	return nil
+}
+
+func displayCoffees(ctx context.Context, coffees []coffee) error {
 	t := table.NewWriter()
 
 	t.AppendHeader(table.Row{
 		"Name",
 		"Roaster",
-		"Region/Origin",
-		"Variety",
+		"Origin",
+		"Farm/Producer",
+		"Altitude",
+		"Varieties",
 		"Processing method",
 		"Decaf",
+		"Brewings",
+		"Avg rating",
+		"Last brewed",
+		"Purchases",
 	})
 
 	for _, coffee := range coffees {
 		t.AppendRow(table.Row{
 			coffee.name,
 			coffee.roaster,
-			coffee.region,
-			coffee.variety,
-			coffee.method,
+			formatOrigin(coffee.region, coffee.countryCode),
+			joinNonEmpty(" / ", coffee.farm, coffee.producer),
+			formatAltitude(coffee.altitudeMinM, coffee.altitudeMaxM),
+			strings.Join(coffee.varieties, ", "),
+			formatProcess(coffee.process, coffee.processOther),
 			coffee.decaf,
+			coffee.brewingCount,
+			formatAverageRating(coffee.averageRating),
+			coffee.lastBrewedDate,
+			coffee.purchaseCount,
 		})
 		t.AppendSeparator()
 	}
@@ -178,7 +585,15 @@ func displayCoffeesByLastAdded(ctx context.Context, db DB) error {
 	t.SetAllowedRowLength(terminalWidth)
 
 	t.SetOutputMirror(os.Stdout)
-	t.Render()
+	renderTable(ctx, t)
 
 	return nil
 }
+
+// Returns an empty string for coffees that have no rated brewings.
+func formatAverageRating(averageRating float64) string {
+	if averageRating == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%.1f", averageRating)
+}
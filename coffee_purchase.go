@@ -5,76 +5,81 @@ import (
 	"errors"
 	"fmt"
 	"os"
-	"time"
 
 	"github.com/jedib0t/go-pretty/table"
 	"golang.org/x/crypto/ssh/terminal"
 )
 
 type coffeePurchase struct {
+	id            int
 	coffeeName    string
 	coffeeRoaster string
-	boughtDate    string
+	boughtDate    string // RFC 3339 timestamp
 	roastDate     string
 }
 
-func addCoffeePurchase(ctx context.Context, db DB) error {
-	fmt.Println("Adding new coffee purchase (Enter # to quit):")
-
-	fmt.Print("Do you want to create a new coffee first? (true or false): ")
-	createCoffee, quit := validateBoolInput(quitStr, true)
-	if quit {
-		fmt.Println(quitMsg)
-		return nil
-	}
-
-	var name, roaster string
-	if createCoffee {
-		addedCoffee, err := addCoffee(ctx, db)
-		if err != nil {
-			return fmt.Errorf("buna: coffee_purchases: failed to create new coffee_purchases: %w", err)
-		}
-
-		name = addedCoffee.name
-		roaster = addedCoffee.roaster
-
-		fmt.Println("\nAdding new coffee purchase for the just added coffee (Enter # to quit):")
-	} else {
-		fmt.Print("Enter coffee name: ")
-		name, quit = validateStrInput(quitStr, false, nil, nil)
-		if quit {
-			fmt.Println(quitMsg)
-			return nil
-		}
-
-		fmt.Print("Enter roaster/producer name: ")
-		roaster, quit = validateStrInput(quitStr, false, nil, nil)
-		if quit {
-			fmt.Println(quitMsg)
-			return nil
-		}
-	}
+// Fields of a coffee purchase that are entered directly.
+// The coffee is looked up by name or created on the way, so it is asked for with hand-written steps.
+var coffeePurchaseFields = fieldSet{
+	{name: "bought_date", key: "Bought date", prompt: "Enter date of purchase or date of arrival if bought online", help: "Date of purchase or arrival with an optional time of day", kind: timestampField},
+	{name: "roast_date", key: "Roast date", prompt: "Enter roast date", help: "Roast date of the coffee", kind: dateField, optional: true},
+}
 
-	boughtDate, quit := getDateInput(quitStr, false, "Enter ? of purchase or ? of arrival if bought online: ", []date{
-		{year: time.Now().Year(), month: int(time.Now().Month()), day: time.Now().Day()},
-		{year: time.Now().Year(), month: int(time.Now().Month()), day: time.Now().Day() - 1},
-	})
-	if quit {
-		fmt.Println(quitMsg)
-		return nil
+func addCoffeePurchase(ctx context.Context, db DB) error {
+	session := sessionFromContext(ctx)
+	fmt.Println("Adding new coffee purchase (Enter " + session.quit + " to quit, " + session.back + " to go back):")
+
+	var (
+		createCoffee    bool
+		purchasedCoffee draftCoffee
+		boughtDate      string
+		roastDate       string
+	)
+
+	steps := []formStep{
+		{key: "Create new coffee", value: &createCoffee, prompt: func(exits promptExits) (promptResult, error) {
+			var quit promptResult
+			fmt.Print("Do you want to create a new coffee first? (true or false): ")
+			createCoffee, quit = validateBoolInput(exits, true)
+			return quit, nil
+		}},
+		{key: "Coffee", value: &purchasedCoffee, prompt: func(exits promptExits) (promptResult, error) {
+			if !createCoffee {
+				c, quit, err := getExistingCoffeeWithSuggestions(ctx, db, exits, "\nContinuing with the new coffee purchase (Enter "+session.quit+" to quit, "+session.back+" to go back):")
+				purchasedCoffee = draftCoffee{Name: c.name, Roaster: c.roaster}
+				return quit, err
+			}
+
+			addedCoffee, result, err := addCoffee(ctx, db, "", "")
+			if err != nil {
+				return answered, fmt.Errorf("buna: coffee_purchase: failed to create new coffee: %w", err)
+			}
+			if result != answered {
+				return result, nil
+			}
+			purchasedCoffee = draftCoffee{Name: addedCoffee.name, Roaster: addedCoffee.roaster}
+
+			fmt.Println("\nAdding new coffee purchase for the just added coffee (Enter " + session.quit + " to quit, " + session.back + " to go back):")
+			return answered, nil
+		}},
+		coffeePurchaseFields.step(ctx, db, "bought_date", &boughtDate, nil),
+		coffeePurchaseFields.step(ctx, db, "roast_date", &roastDate, nil),
+	}
+
+	quit, err := runForm(ctx, nil, steps)
+	if err != nil {
+		return fmt.Errorf("buna: coffee_purchase: failed to run coffee purchase form: %w", err)
 	}
-
-	roastDate, quit := getDateInput(quitStr, true, "Enter roast ?: ", []date{})
-	if quit {
+	if quit != answered {
 		fmt.Println(quitMsg)
 		return nil
 	}
 
 	coffeePurchase := coffeePurchase{
-		coffeeName:    name,
-		coffeeRoaster: roaster,
-		boughtDate:    createDateString(boughtDate),
-		roastDate:     createDateString(roastDate),
+		coffeeName:    purchasedCoffee.Name,
+		coffeeRoaster: purchasedCoffee.Roaster,
+		boughtDate:    boughtDate,
+		roastDate:     roastDate,
 	}
 
 	if err := db.insertCoffeePurchase(ctx, coffeePurchase); err != nil {
@@ -90,16 +95,17 @@ func retrieveCoffeePurchase(ctx context.Context, db DB) error {
 		0: "Retrieve coffee purchases ordered by last added",
 	}
 
-	fmt.Println("Retrieving coffee purchase (Enter # to quit):")
-	if err := displayIntOptions(options); err != nil {
+	session := sessionFromContext(ctx)
+	fmt.Println("Retrieving coffee purchase (Enter " + session.quit + " to quit):")
+	if err := displayIntOptions(ctx, options); err != nil {
 		return fmt.Errorf("buna: coffee_purchases: failed to display int options: %w", err)
 	}
 
-	selection, quit, err := getIntSelection(options, quitStr)
+	selection, quit, err := getIntSelection(ctx, options, quitExits(ctx))
 	if err != nil {
 		return fmt.Errorf("buna: coffee_purchases: failed to get int selection: %w", err)
 	}
-	if quit {
+	if quit != answered {
 		fmt.Println(quitMsg)
 		return nil
 	}
@@ -123,29 +129,53 @@ func runRetrieveCoffeePurchaseSelection(ctx context.Context, selection int, db D
 	return nil
 }
 
-// Promts user for an optional limit.
+// Promts user for an optional page size.
 func displayCoffeePurchasesByLastAdded(ctx context.Context, db DB) error {
-	const defaultDisplayAmount = 20
-	const maxDisplayAmount = 60
+	session := sessionFromContext(ctx)
+	defaultPageSize := session.config.intValue("display.purchases_page_size")
+	const maxPageSize = 60
 
-	fmt.Println("Displaying coffee purchases by last added (Enter # to quit):")
+	fmt.Println("Displaying coffee purchases by last added (Enter " + session.quit + " to quit):")
 
-	fmt.Print("Enter a limit for the number of coffee purchases to display: ")
-	limit, quit := validateIntInput(quitStr, true, 1, maxDisplayAmount, []int{})
-	if quit {
+	fmt.Print("Enter the number of coffee purchases to display per page: ")
+	pageSize, quit := validateIntInput(quitExits(ctx), true, 1, maxPageSize, []int{})
+	if quit != answered {
 		fmt.Println(quitMsg)
 		return nil
 	}
 
-	if limit == 0 {
-		limit = defaultDisplayAmount
+	if pageSize == 0 {
+		pageSize = defaultPageSize
 	}
 
-	coffeePurchases, err := db.getCoffeePurchasesByLastAdded(ctx, limit)
-	if err != nil {
-		return fmt.Errorf("buna: coffee_purchases: failed to get coffee purchases by last added: %w", err)
+	var coffeePurchases []coffeePurchase
+	listing := pagedListing{
+		fetch: func(after *pageCursor, limit int) ([]pageCursor, error) {
+			var err error
+			coffeePurchases, err = db.getCoffeePurchasesByLastAdded(ctx, after, limit)
+			if err != nil {
+				return nil, fmt.Errorf("buna: coffee_purchases: failed to get coffee purchases by last added: %w", err)
+			}
+
+			cursors := make([]pageCursor, len(coffeePurchases))
+			for i := range coffeePurchases {
+				cursors[i].id = coffeePurchases[i].id
+			}
+			return cursors, nil
+		},
+		display: func(n int) error {
+			return displayCoffeePurchases(ctx, coffeePurchases[:n])
+		},
+	}
+
+	if err := browsePages(quitExits(ctx), listing, pageSize); err != nil {
+		return fmt.Errorf("buna: coffee_purchases: failed to browse coffee purchases by last added: %w", err)
 	}
 
+	return nil
+}
+
+func displayCoffeePurchases(ctx context.Context, coffeePurchases []coffeePurchase) error {
 	t := table.NewWriter()
 
 	t.AppendHeader(table.Row{
@@ -159,7 +189,7 @@ func displayCoffeePurchasesByLastAdded(ctx context.Context, db DB) error {
 		row := table.Row{
 			coffeePurchase.coffeeName,
 			coffeePurchase.coffeeRoaster,
-			coffeePurchase.boughtDate,
+			timestampDate(coffeePurchase.boughtDate),
 			coffeePurchase.roastDate,
 		}
 
@@ -174,7 +204,7 @@ func displayCoffeePurchasesByLastAdded(ctx context.Context, db DB) error {
 	t.SetAllowedRowLength(terminalWidth)
 
 	t.SetOutputMirror(os.Stdout)
-	t.Render()
+	renderTable(ctx, t)
 
 	return nil
 }
@@ -0,0 +1,427 @@
+package buna
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/jedib0t/go-pretty/table"
+)
+
+// A setting of the config file, named <section>.<key>, e.g. display.brewings_page_size
+type configSetting struct {
+	name         string
+	help         string
+	defaultValue string
+	isInt        bool
+	min          int // Bounds of int settings
+	max          int
+	options      []string
+}
+
+// Section of the config file whose keys are user-defined shortcuts for the main menu.
+const aliasesSection = "aliases"
+
+// All settings of the config file except for the aliases.
+var configSettings = []configSetting{
+	{name: "database.path", help: "SQLite database file used when -db is not given", defaultValue: "bunaDB.db"},
+	{name: "defaults.method", help: "Brewing method used when its prompt is left empty"},
+	{name: "defaults.grinder", help: "Grinder used when its prompt is left empty"},
+	{name: "defaults.units", help: "Unit system used until one is set with the Set unit system option", defaultValue: "metric", options: []string{"metric", "imperial"}},
+	{name: "defaults.user", help: "Profile used when -user is not given, no profile prompt is shown then"},
+	{name: "display.brewings_page_size", help: "Brewings per page", defaultValue: "5", isInt: true, min: 1, max: 30},
+	{name: "display.brewing_suggestions", help: "Brewing suggestions to display", defaultValue: "6", isInt: true, min: 1, max: 20},
+	{name: "display.coffees_page_size", help: "Coffees per page", defaultValue: "15", isInt: true, min: 1, max: 60},
+	{name: "display.cuppings_page_size", help: "Cuppings per page", defaultValue: "3", isInt: true, min: 1, max: 10},
+	{name: "display.purchases_page_size", help: "Coffee purchases per page", defaultValue: "20", isInt: true, min: 1, max: 60},
+	{name: "display.methods_page_size", help: "Brewing methods per page", defaultValue: "20", isInt: true, min: 1, max: 60},
+	{name: "display.grinders_page_size", help: "Grinders per page", defaultValue: "20", isInt: true, min: 1, max: 60},
+	{name: "display.search_hits", help: "Note search hits to display", defaultValue: "10", isInt: true, min: 1, max: 50},
+	{name: "display.coffee_suggestions", help: "Coffee names suggested when entering a coffee", defaultValue: "8", isInt: true, min: 0, max: 20},
+	{name: "display.roaster_suggestions", help: "Roaster names suggested when entering a roaster", defaultValue: "5", isInt: true, min: 0, max: 20},
+	{name: "display.method_suggestions", help: "Brewing methods suggested when entering a brewing method", defaultValue: "5", isInt: true, min: 0, max: 20},
+	{name: "display.grinder_suggestions", help: "Grinders suggested when entering a grinder", defaultValue: "3", isInt: true, min: 0, max: 20},
+	{name: "display.weight_suggestions", help: "Coffee and water weights suggested when entering a weight", defaultValue: "5", isInt: true, min: 0, max: 20},
+	{name: "display.output", help: "Format of tables", defaultValue: "table", options: []string{"table", "markdown", "csv"}},
+	{name: "display.colors", help: "Colors of tables", defaultValue: "none", options: []string{"none", "dark", "bright"}},
+	{name: "input.quit", help: "Input that quits the current entry", defaultValue: "#"},
+	{name: "input.back", help: "Input that goes back to the previous prompt", defaultValue: "<"},
+}
+
+// Config holds the settings read from the config file, which uses a small subset of TOML:
+//
+//	[defaults]
+//	method = "v60"
+//
+//	# Shortcuts for the main menu
+//	[aliases]
+//	last = "B0 1"
+//
+// Settings that are not in the file have their default value.
+type Config struct {
+	values  map[string]string // Set settings by name
+	aliases map[string]string
+}
+
+// DefaultConfigPath returns the path of the config file in the user's config directory,
+// or an empty string if there is no such directory.
+func DefaultConfigPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+
+	return filepath.Join(dir, "buna", "config.toml")
+}
+
+// LoadConfig reads the config file at path. A missing file is not an error and results in the default settings.
+func LoadConfig(path string) (Config, error) {
+	cfg := Config{values: map[string]string{}, aliases: map[string]string{}}
+	if path == "" {
+		return cfg, nil
+	}
+
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return cfg, nil
+	}
+	if err != nil {
+		return Config{}, fmt.Errorf("buna: config: failed to open config file: %w", err)
+	}
+	defer file.Close()
+
+	section := ""
+	scanner := bufio.NewScanner(file)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(stripConfigComment(scanner.Text()))
+		if line == "" {
+			continue
+		}
+
+		if name, ok := parseConfigSection(line); ok {
+			section = name
+			continue
+		}
+
+		key, value, err := parseConfigEntry(line)
+		if err != nil {
+			return Config{}, fmt.Errorf("buna: config: %s:%d: %w", path, lineNumber, err)
+		}
+
+		if err := cfg.set(section+"."+key, value); err != nil {
+			return Config{}, fmt.Errorf("buna: config: %s:%d: %w", path, lineNumber, err)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return Config{}, fmt.Errorf("buna: config: failed to read config file: %w", err)
+	}
+
+	return cfg, nil
+}
+
+// DBPath returns the path of the SQLite database file.
+func (c Config) DBPath() string {
+	return c.text("database.path")
+}
+
+// Returns the value of the setting with name, or its default value if it is not set.
+func (c Config) text(name string) string {
+	if value, ok := c.values[name]; ok {
+		return value
+	}
+
+	setting, _ := lookupConfigSetting(name)
+	return setting.defaultValue
+}
+
+// Returns the value of the int setting with name.
+func (c Config) intValue(name string) int {
+	i, _ := strconv.Atoi(c.text(name))
+	return i
+}
+
+// Validates the value and sets the setting or alias with name to it.
+func (c Config) set(name string, value string) error {
+	if strings.HasPrefix(name, aliasesSection+".") {
+		alias := strings.ToLower(strings.TrimPrefix(name, aliasesSection+"."))
+		if alias == "" || strings.ContainsAny(alias, " \t") {
+			return fmt.Errorf("invalid alias name %q", alias)
+		}
+
+		c.aliases[alias] = value
+		return nil
+	}
+
+	setting, ok := lookupConfigSetting(name)
+	if !ok {
+		return fmt.Errorf("unknown setting %q", name)
+	}
+
+	if err := setting.validate(value); err != nil {
+		return err
+	}
+
+	c.values[name] = value
+	return nil
+}
+
+// Returns the setting with name, ok
+func lookupConfigSetting(name string) (configSetting, bool) {
+	for _, setting := range configSettings {
+		if setting.name == name {
+			return setting, true
+		}
+	}
+
+	return configSetting{}, false
+}
+
+func (s configSetting) validate(value string) error {
+	switch {
+	case s.isInt:
+		i, err := strconv.Atoi(value)
+		if err != nil || i < s.min || i > s.max {
+			return fmt.Errorf("%v must be a whole number from %v to %v", s.name, s.min, s.max)
+		}
+	case len(s.options) > 0:
+		if !containsString(s.options, value) {
+			return fmt.Errorf("%v must be one of %v", s.name, strings.Join(s.options, ", "))
+		}
+	case strings.HasPrefix(s.name, "input.") && strings.TrimSpace(value) == "":
+		return fmt.Errorf("%v must not be empty", s.name)
+	}
+
+	return nil
+}
+
+// Checks the settings that depend on each other.
+func (c Config) check() error {
+	if c.text("input.quit") == c.text("input.back") {
+		return errors.New("buna: config: input.quit and input.back must differ")
+	}
+
+	return nil
+}
+
+// Returns the section name of a [section] line, ok
+func parseConfigSection(line string) (string, bool) {
+	if !strings.HasPrefix(line, "[") || !strings.HasSuffix(line, "]") {
+		return "", false
+	}
+
+	return strings.TrimSpace(line[1 : len(line)-1]), true
+}
+
+// Parses a key = value line. Values are quoted strings or, for int settings, plain numbers.
+func parseConfigEntry(line string) (string, string, error) {
+	eq := strings.Index(line, "=")
+	if eq < 0 {
+		return "", "", errors.New("expected key = value")
+	}
+
+	key := strings.TrimSpace(line[:eq])
+	if unquoted, err := strconv.Unquote(key); err == nil {
+		key = unquoted
+	}
+
+	value := strings.TrimSpace(line[eq+1:])
+	if unquoted, err := strconv.Unquote(value); err == nil {
+		value = unquoted
+	} else if _, err := strconv.Atoi(value); err != nil {
+		return "", "", fmt.Errorf("value of %s must be a quoted string or a number", key)
+	}
+
+	return key, value, nil
+}
+
+// Removes a # comment from line, unless the # is part of a quoted string.
+func stripConfigComment(line string) string {
+	inString := false
+	for i, r := range line {
+		switch {
+		case r == '"' && (i == 0 || line[i-1] != '\\'):
+			inString = !inString
+		case r == '#' && !inString:
+			return line[:i]
+		}
+	}
+
+	return line
+}
+
+// Returns the value formatted for the config file: ints as they are and anything else quoted.
+func formatConfigValue(name string, value string) string {
+	if setting, ok := lookupConfigSetting(name); ok && setting.isInt {
+		return value
+	}
+
+	return strconv.Quote(value)
+}
+
+// Sets the setting with name to value in the config file at path, keeping everything else in the file as it is.
+// The file is created if it does not exist yet.
+func writeConfigSetting(path string, name string, value string) error {
+	dot := strings.Index(name, ".")
+	if dot < 0 {
+		return fmt.Errorf("buna: config: setting %q must be named <section>.<key>", name)
+	}
+	section, key := name[:dot], name[dot+1:]
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("buna: config: failed to read config file: %w", err)
+	}
+
+	var lines []string
+	if len(data) > 0 {
+		lines = strings.Split(strings.TrimRight(string(data), "\n"), "\n")
+	}
+
+	entry := key + " = " + formatConfigValue(name, value)
+
+	currentSection := ""
+	sectionEnd := -1 // Index after the last entry of section
+	replaced := false
+	for i, line := range lines {
+		content := strings.TrimSpace(stripConfigComment(line))
+		if s, ok := parseConfigSection(content); ok {
+			currentSection = s
+			if s == section {
+				sectionEnd = i + 1
+			}
+			continue
+		}
+		if currentSection != section || content == "" {
+			continue
+		}
+
+		sectionEnd = i + 1
+		if k, _, err := parseConfigEntry(content); err == nil && k == key {
+			// Keep a trailing comment
+			comment := strings.TrimSpace(line[len(stripConfigComment(line)):])
+			lines[i] = entry
+			if comment != "" {
+				lines[i] += " " + comment
+			}
+			replaced = true
+		}
+	}
+
+	switch {
+	case replaced:
+	case sectionEnd >= 0:
+		lines = append(lines[:sectionEnd], append([]string{entry}, lines[sectionEnd:]...)...)
+	default:
+		if len(lines) > 0 {
+			lines = append(lines, "")
+		}
+		lines = append(lines, "["+section+"]", entry)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("buna: config: failed to create config directory: %w", err)
+	}
+
+	if err := ioutil.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), 0644); err != nil {
+		return fmt.Errorf("buna: config: failed to write config file: %w", err)
+	}
+
+	return nil
+}
+
+// RunConfigCommand runs the config command, which shows and changes the settings in the config file at path.
+//
+// Usage: config get [<setting>] | config set <setting> <value>
+func RunConfigCommand(path string, args []string) error {
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		return fmt.Errorf("buna: config: failed to load config: %w", err)
+	}
+
+	if len(args) == 0 {
+		return errors.New("buna: config: usage: config get [<setting>] | config set <setting> <value>")
+	}
+
+	switch args[0] {
+	case "get":
+		switch len(args) {
+		case 1:
+			displayConfig(withSession(context.Background(), newSession(cfg)), path)
+		case 2:
+			name := args[1]
+			if strings.HasPrefix(name, aliasesSection+".") {
+				alias, ok := cfg.aliases[strings.ToLower(strings.TrimPrefix(name, aliasesSection+"."))]
+				if !ok {
+					return fmt.Errorf("buna: config: no alias %q", name)
+				}
+				fmt.Println(alias)
+				return nil
+			}
+
+			if _, ok := lookupConfigSetting(name); !ok {
+				return fmt.Errorf("buna: config: unknown setting %q", name)
+			}
+			fmt.Println(cfg.text(name))
+		default:
+			return errors.New("buna: config: usage: config get [<setting>]")
+		}
+	case "set":
+		if len(args) != 3 {
+			return errors.New("buna: config: usage: config set <setting> <value>")
+		}
+		if path == "" {
+			return errors.New("buna: config: no config file path, use -config")
+		}
+
+		name, value := args[1], args[2]
+		if err := cfg.set(name, value); err != nil {
+			return fmt.Errorf("buna: config: %w", err)
+		}
+		if err := cfg.check(); err != nil {
+			return err
+		}
+
+		if err := writeConfigSetting(path, name, value); err != nil {
+			return fmt.Errorf("buna: config: failed to set %v: %w", name, err)
+		}
+	default:
+		return fmt.Errorf("buna: config: unknown config command %q", args[0])
+	}
+
+	return nil
+}
+
+// Displays all settings with their values, followed by the aliases.
+func displayConfig(ctx context.Context, path string) {
+	cfg := sessionFromContext(ctx).config
+	fmt.Println("Config file: " + path)
+
+	t := table.NewWriter()
+	t.AppendHeader(table.Row{"Setting", "Value", "Description"})
+	for _, setting := range configSettings {
+		value := cfg.text(setting.name)
+		if _, ok := cfg.values[setting.name]; !ok {
+			value += " (default)"
+		}
+		t.AppendRow(table.Row{setting.name, value, setting.help})
+	}
+
+	var aliases []string
+	for alias := range cfg.aliases {
+		aliases = append(aliases, alias)
+	}
+	sort.Strings(aliases)
+	for _, alias := range aliases {
+		t.AppendRow(table.Row{aliasesSection + "." + alias, cfg.aliases[alias], "Main menu alias"})
+	}
+
+	t.SetOutputMirror(os.Stdout)
+	renderTable(ctx, t)
+}
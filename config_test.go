@@ -0,0 +1,202 @@
+package buna
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// Returns the path of a config file in a new temporary directory and a function that removes the directory.
+// The file is created with content unless content is empty.
+func tempConfigFile(t *testing.T, content string) (string, func()) {
+	t.Helper()
+
+	dir, err := ioutil.TempDir("", "buna-config")
+	if err != nil {
+		t.Fatalf("failed to create temporary directory: %v", err)
+	}
+	path := filepath.Join(dir, "config.toml")
+
+	if content != "" {
+		if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+			os.RemoveAll(dir)
+			t.Fatalf("failed to write config file: %v", err)
+		}
+	}
+
+	return path, func() { os.RemoveAll(dir) }
+}
+
+func TestStripConfigComment(t *testing.T) {
+	tests := []struct {
+		line string
+		want string
+	}{
+		{`method = "v60"`, `method = "v60"`},
+		{`method = "v60" # my usual`, `method = "v60" `},
+		{`# whole line comment`, ``},
+		{`[aliases] # shortcuts`, `[aliases] `},
+		{`quit = "#"`, `quit = "#"`},
+		{`quit = "#" # default`, `quit = "#" `},
+		{`last = "B0 #1"`, `last = "B0 #1"`},
+		{`note = "say \"#1\"" # escaped quotes`, `note = "say \"#1\"" `},
+		{`brewings_page_size = 5#no space`, `brewings_page_size = 5`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.line, func(t *testing.T) {
+			if got := stripConfigComment(tt.line); got != tt.want {
+				t.Errorf("stripConfigComment(%q) = %q, want %q", tt.line, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadConfig(t *testing.T) {
+	tests := []struct {
+		name        string
+		content     string
+		wantValues  map[string]string
+		wantAliases map[string]string
+		wantErr     bool
+	}{
+		{
+			name:        "missing file",
+			wantValues:  map[string]string{},
+			wantAliases: map[string]string{},
+		},
+		{
+			name: "settings, aliases and comments",
+			content: `# buna config
+[defaults]
+method = "v60" # my usual
+units = "imperial"
+
+[display]
+brewings_page_size = 10
+
+[input]
+quit = "#" # a quoted # is not a comment
+back = "<"
+
+[aliases]
+Last = "B0 1"
+`,
+			wantValues: map[string]string{
+				"defaults.method":            "v60",
+				"defaults.units":             "imperial",
+				"display.brewings_page_size": "10",
+				"input.quit":                 "#",
+				"input.back":                 "<",
+			},
+			wantAliases: map[string]string{"last": "B0 1"},
+		},
+		{name: "unknown setting", content: "[defaults]\nmachine = \"x\"\n", wantErr: true},
+		{name: "int out of range", content: "[display]\nbrewings_page_size = 100\n", wantErr: true},
+		{name: "unquoted string", content: "[defaults]\nmethod = v60\n", wantErr: true},
+		{name: "option not allowed", content: "[display]\noutput = \"html\"\n", wantErr: true},
+		{name: "missing equals sign", content: "[defaults]\nmethod\n", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path, cleanup := tempConfigFile(t, tt.content)
+			defer cleanup()
+
+			cfg, err := LoadConfig(path)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("LoadConfig() error = nil, want an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("LoadConfig() error = %v", err)
+			}
+
+			if !reflect.DeepEqual(cfg.values, tt.wantValues) {
+				t.Errorf("LoadConfig() values = %v, want %v", cfg.values, tt.wantValues)
+			}
+			if !reflect.DeepEqual(cfg.aliases, tt.wantAliases) {
+				t.Errorf("LoadConfig() aliases = %v, want %v", cfg.aliases, tt.wantAliases)
+			}
+		})
+	}
+}
+
+func TestWriteConfigSetting(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		setting string
+		value   string
+		want    string
+	}{
+		{
+			name:    "new file",
+			setting: "defaults.method",
+			value:   "v60",
+			want:    "[defaults]\nmethod = \"v60\"\n",
+		},
+		{
+			name:    "replaced value keeps its comment",
+			content: "# buna config\n[defaults]\nmethod = \"v60\" # my usual\ngrinder = \"comandante\"\n",
+			setting: "defaults.method",
+			value:   "aeropress",
+			want:    "# buna config\n[defaults]\nmethod = \"aeropress\" # my usual\ngrinder = \"comandante\"\n",
+		},
+		{
+			name:    "quoted # is kept",
+			content: "[input]\nquit = \"#\" # default\nback = \"<\"\n",
+			setting: "input.back",
+			value:   "b",
+			want:    "[input]\nquit = \"#\" # default\nback = \"b\"\n",
+		},
+		{
+			name:    "new key appended to its section",
+			content: "[defaults]\nmethod = \"v60\"\n\n# Page sizes\n[display]\ncoffees_page_size = 15\n",
+			setting: "defaults.grinder",
+			value:   "comandante",
+			want:    "[defaults]\nmethod = \"v60\"\ngrinder = \"comandante\"\n\n# Page sizes\n[display]\ncoffees_page_size = 15\n",
+		},
+		{
+			name:    "ints are not quoted",
+			content: "[defaults]\nmethod = \"v60\"\n",
+			setting: "display.brewings_page_size",
+			value:   "10",
+			want:    "[defaults]\nmethod = \"v60\"\n\n[display]\nbrewings_page_size = 10\n",
+		},
+		{
+			name:    "aliases",
+			content: "[aliases]\nlast = \"B0 1\" # last brewings\n",
+			setting: "aliases.last",
+			value:   "B0 2",
+			want:    "[aliases]\nlast = \"B0 2\" # last brewings\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path, cleanup := tempConfigFile(t, tt.content)
+			defer cleanup()
+
+			if err := writeConfigSetting(path, tt.setting, tt.value); err != nil {
+				t.Fatalf("writeConfigSetting() error = %v", err)
+			}
+
+			data, err := ioutil.ReadFile(path)
+			if err != nil {
+				t.Fatalf("failed to read config file: %v", err)
+			}
+			if got := string(data); got != tt.want {
+				t.Errorf("writeConfigSetting() wrote\n%s\nwant\n%s", got, tt.want)
+			}
+
+			if _, err := LoadConfig(path); err != nil {
+				t.Errorf("LoadConfig() of the written file error = %v", err)
+			}
+		})
+	}
+}
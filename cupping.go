@@ -2,19 +2,20 @@ package buna
 
 import (
 	"context"
+	"database/sql"
 	"errors"
 	"fmt"
 	"math"
 	"os"
 	"strconv"
-	"time"
 
 	"github.com/jedib0t/go-pretty/table"
 	"golang.org/x/crypto/ssh/terminal"
 )
 
 type cupping struct {
-	date          string
+	id            int
+	date          string // RFC 3339 timestamp
 	durationMin   int
 	cuppedCoffees []cuppedCoffee
 	notes         string
@@ -25,92 +26,147 @@ type cuppedCoffee struct {
 	roaster string
 	rank    int
 	notes   string
+	flavors []string
 }
 
 func addCupping(ctx context.Context, db DB) error {
-	fmt.Println("Adding new cupping (Enter # to quit):")
-
-	cuppingDate, quit := getDateInput(quitStr, false, "Enter cupping ?: ", []date{
-		{year: time.Now().Year(), month: int(time.Now().Month()), day: time.Now().Day()},
-		{year: time.Now().Year(), month: int(time.Now().Month()), day: time.Now().Day() - 1},
-	})
-	if quit {
-		fmt.Println(quitMsg)
-		return nil
+	r, quit, err := startDraft(ctx, db, cuppingDraftFlow)
+	if err != nil {
+		return fmt.Errorf("buna: cupping: failed to start cupping draft: %w", err)
 	}
-
-	fmt.Print("Enter cupping duration in minutes: ")
-	cuppingDurationMin, quit := validateIntInput(quitStr, false, 1, math.MaxInt64, nil)
-	if quit {
+	if quit != answered {
 		fmt.Println(quitMsg)
 		return nil
 	}
 
-	cuppingNotes, quit := getNotes(quitStr, false, "general cupping")
-	if quit {
-		fmt.Println(quitMsg)
-		return nil
-	}
+	return addCuppingFromDraft(ctx, db, r)
+}
 
-	fmt.Print("Enter number of coffees in this cupping: ")
-	coffeeNumber, quit := validateIntInput(quitStr, false, 2, 30, nil)
-	if quit {
-		fmt.Println(quitMsg)
-		return nil
-	}
+// Maximum number of coffees in a cupping
+const maxCuppedCoffees = 30
 
-	cuppedCoffees := make([]cuppedCoffee, coffeeNumber)
-	for i := 0; i < coffeeNumber; i++ {
-		fmt.Println("\nAdding " + strconv.Itoa(i+1) + ". cupped coffee (Enter # to quit):")
+// Fields of a cupping that are entered directly
+var cuppingFields = fieldSet{
+	{name: "date", key: "Cupping date", prompt: "Enter cupping date", help: "Cupping date with an optional time of day", kind: timestampField},
+	{name: "duration_min", key: "Duration (min)", prompt: "Enter cupping duration in minutes", help: "Duration in minutes", kind: intField, min: 1, max: 24 * 60},
+	{name: "notes", key: "General notes", prompt: "Enter some general cupping notes", help: "General notes", kind: textField},
+	{name: "coffees", key: "Number of coffees", prompt: "Enter number of coffees in this cupping", help: "Number of cupped coffees", kind: intField, min: 2, max: maxCuppedCoffees},
+}
 
-		coffeeName, quit, err := getCoffeeNameWithSuggestions(ctx, db, quitStr, false)
-		if err != nil {
-			return fmt.Errorf("buna: brewing: failed to get coffee name: %w", err)
-		}
-		if quit {
-			fmt.Println(quitMsg)
-			return nil
-		}
+// Fields of each coffee of a cupping. Their keys are prefixed with the number of the coffee, e.g. "Coffee 1 rank".
+// The coffee is looked up by name and the flavors consist of several values, so they are asked for with hand-written steps.
+var cuppedCoffeeFields = fieldSet{
+	// The upper bound is the number of coffees in the cupping
+	{name: "rank", key: "rank", prompt: "Enter this coffees rank (1 = highest)", help: "Rank of the coffee, 1 being the highest", kind: intField, min: 1, max: maxCuppedCoffees},
+	{name: "notes", key: "notes", prompt: "Enter some cupped coffee notes", help: "Notes of the coffee", kind: textField},
+}
 
-		coffeeRoaster, quit, err := getCoffeeRoasterWithSuggestions(ctx, db, quitStr, coffeeName)
-		if err != nil {
-			return fmt.Errorf("buna: brewing: failed to get coffee roaster: %w", err)
-		}
-		if quit {
-			fmt.Println(quitMsg)
-			return nil
-		}
+// Prompts for every answer of the cupping that is not in the draft yet.
+// The draft is kept until the cupping was inserted, so that quitting or a failed insert does not lose any answers.
+func addCuppingFromDraft(ctx context.Context, db DB, r *draftRecorder) error {
+	session := sessionFromContext(ctx)
+	fmt.Println("Adding new cupping (Enter " + session.quit + " to quit, " + session.back + " to go back):")
+
+	var (
+		cuppingDate        string
+		cuppingDurationMin int
+		cuppingNotes       string
+		coffeeNumber       int
+	)
+
+	steps := []formStep{
+		cuppingFields.step(ctx, db, "date", &cuppingDate, nil),
+		cuppingFields.step(ctx, db, "duration_min", &cuppingDurationMin, nil),
+		cuppingFields.step(ctx, db, "notes", &cuppingNotes, nil),
+		cuppingFields.step(ctx, db, "coffees", &coffeeNumber, nil),
+	}
 
-		fmt.Print("Enter this coffees rank (1 = highest): ")
-		coffeeRank, quit := validateIntInput(quitStr, false, 1, coffeeNumber, nil)
-		if quit {
-			fmt.Println(quitMsg)
-			return nil
-		}
+	notesField, ok := cuppedCoffeeFields.field("notes")
+	if !ok {
+		return unknownFieldError("notes")
+	}
 
-		coffeeNotes, quit := getNotes(quitStr, false, "cupped coffee")
-		if quit {
-			fmt.Println(quitMsg)
-			return nil
+	// The steps of every possible cupped coffee are declared, the ones beyond the number of coffees are skipped
+	cuppedCoffees := make([]cuppedCoffee, maxCuppedCoffees)
+	existingCoffees := make([]draftCoffee, maxCuppedCoffees)
+	for i := 0; i < maxCuppedCoffees; i++ {
+		i := i
+		keyPrefix := "Coffee " + strconv.Itoa(i+1)
+		skip := func() bool {
+			return i >= coffeeNumber
 		}
 
-		cuppedCoffees[i] = cuppedCoffee{
-			name:    coffeeName,
-			roaster: coffeeRoaster,
-			rank:    coffeeRank,
-			notes:   coffeeNotes,
-		}
+		coffeeNotesField := notesField
+		coffeeNotesField.key = keyPrefix + " " + notesField.key
+		notesStep := coffeeNotesField.step(ctx, db, &cuppedCoffees[i].notes, nil)
+		notesStep.skip = skip
+
+		steps = append(steps,
+			formStep{key: keyPrefix, value: &existingCoffees[i], skip: skip, prompt: func(exits promptExits) (promptResult, error) {
+				fmt.Println("\nAdding " + strconv.Itoa(i+1) + ". cupped coffee (Enter " + session.quit + " to quit, " + session.back + " to go back):")
+
+				c, quit, err := getExistingCoffeeWithSuggestions(ctx, db, exits, "\nContinuing with the "+strconv.Itoa(i+1)+". cupped coffee (Enter "+session.quit+" to quit, "+session.back+" to go back):")
+				existingCoffees[i] = draftCoffee{Name: c.name, Roaster: c.roaster}
+				return quit, err
+			}, check: func() (bool, error) {
+				return referenceExists(db.getCoffeeIDByNameRoaster(ctx, existingCoffees[i].Name, existingCoffees[i].Roaster))
+			}},
+			formStep{key: keyPrefix + " rank", value: &cuppedCoffees[i].rank, skip: skip, prompt: func(exits promptExits) (promptResult, error) {
+				f, ok := cuppedCoffeeFields.field("rank")
+				if !ok {
+					return answered, unknownFieldError("rank")
+				}
+				f.max = float64(coffeeNumber)
+
+				var quit promptResult
+				var err error
+				cuppedCoffees[i].rank, quit, err = f.askInt(ctx, db, exits, nil)
+				return quit, err
+			}},
+			notesStep,
+			formStep{key: keyPrefix + " flavors", value: &cuppedCoffees[i].flavors, skip: skip, prompt: func(exits promptExits) (promptResult, error) {
+				var quit promptResult
+				cuppedCoffees[i].flavors, quit = getFlavorsInput(exits, "cupped coffee")
+				return quit, nil
+			}},
+		)
+	}
+
+	quit, err := runForm(ctx, r, steps)
+	if err != nil {
+		return fmt.Errorf("buna: cupping: failed to run cupping form: %w", err)
+	}
+	if quit != answered {
+		r.printQuitMsg()
+		return nil
+	}
+
+	cuppedCoffees = cuppedCoffees[:coffeeNumber]
+	for i := range cuppedCoffees {
+		cuppedCoffees[i].name = existingCoffees[i].Name
+		cuppedCoffees[i].roaster = existingCoffees[i].Roaster
 	}
 
 	newCupping := cupping{
-		date:          createDateString(cuppingDate),
+		date:          cuppingDate,
 		durationMin:   cuppingDurationMin,
 		cuppedCoffees: cuppedCoffees,
 		notes:         cuppingNotes,
 	}
 
-	if err := db.insertCupping(ctx, newCupping); err != nil {
-		return fmt.Errorf("buna: cupping: failed to insert cupping: %w", err)
+	// A cupped coffee might have been removed or renamed since it was entered
+	err = db.insertCupping(ctx, newCupping)
+	if errors.Is(err, sql.ErrNoRows) {
+		fmt.Println("Unable to add the cupping, as one of its coffees no longer exists")
+		fmt.Println("Your answers are kept as a draft. Resume it to enter the coffee again.")
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("buna: cupping: failed to insert cupping, the answers are kept as a draft: %w", err)
+	}
+
+	if err := r.discard(ctx); err != nil {
+		return fmt.Errorf("buna: cupping: failed to discard cupping draft: %w", err)
 	}
 
 	fmt.Println("Added cupping successfully")
@@ -120,18 +176,23 @@ func addCupping(ctx context.Context, db DB) error {
 func retrieveCupping(ctx context.Context, db DB) error {
 	options := map[int]string{
 		0: "Retrieve cuppings ordered by last added",
+		1: "Retrieve cupping details",
+		2: "Retrieve cuppings containing a coffee",
+		3: "Retrieve cuppings in a date range",
+		4: "Retrieve cuppings where a roaster placed first",
 	}
 
-	fmt.Println("Retrieving cuppings (Enter # to quit):")
-	if err := displayIntOptions(options); err != nil {
+	session := sessionFromContext(ctx)
+	fmt.Println("Retrieving cuppings (Enter " + session.quit + " to quit):")
+	if err := displayIntOptions(ctx, options); err != nil {
 		return fmt.Errorf("buna: cupping: failed to display int options: %w", err)
 	}
 
-	selection, quit, err := getIntSelection(options, quitStr)
+	selection, quit, err := getIntSelection(ctx, options, quitExits(ctx))
 	if err != nil {
 		return fmt.Errorf("buna: cupping: failed to get int selection: %w", err)
 	}
-	if quit {
+	if quit != answered {
 		fmt.Println(quitMsg)
 		return nil
 	}
@@ -149,31 +210,163 @@ func runRetrieveCuppingSelection(ctx context.Context, selection int, db DB) erro
 		if err := displayCuppingsByLastAdded(ctx, db); err != nil {
 			return fmt.Errorf("buna: cupping: failed to display cuppings by last added: %w", err)
 		}
+	case 1:
+		if err := displayCuppingDetails(ctx, db); err != nil {
+			return fmt.Errorf("buna: cupping: failed to display cupping details: %w", err)
+		}
+	case 2:
+		if err := displayCuppingsByCoffee(ctx, db); err != nil {
+			return fmt.Errorf("buna: cupping: failed to display cuppings by coffee: %w", err)
+		}
+	case 3:
+		if err := displayCuppingsByDateRange(ctx, db); err != nil {
+			return fmt.Errorf("buna: cupping: failed to display cuppings by date range: %w", err)
+		}
+	case 4:
+		if err := displayCuppingsByWinningRoaster(ctx, db); err != nil {
+			return fmt.Errorf("buna: cupping: failed to display cuppings by winning roaster: %w", err)
+		}
 	default:
 		return errors.New("buna: cupping: invalid retrieve selection")
 	}
 	return nil
 }
 
-// Promts user for an optional limit.
+// Promts user for an optional page size.
 func displayCuppingsByLastAdded(ctx context.Context, db DB) error {
-	const defaultDisplayAmount = 3
-	const maxDisplayAmount = 10
-	const maxNoteFieldWidth = 100
+	session := sessionFromContext(ctx)
+	fmt.Println("Displaying cuppings by last added (Enter " + session.quit + " to quit):")
+
+	pageSize, quit := getCuppingPageSize(ctx)
+	if quit != answered {
+		fmt.Println(quitMsg)
+		return nil
+	}
+
+	if err := browseCuppings(ctx, pageSize, func(after *pageCursor, limit int) ([]cupping, error) {
+		return db.getCuppingsByLastAdded(ctx, after, limit)
+	}, false); err != nil {
+		return fmt.Errorf("buna: cupping: failed to browse cuppings by last added: %w", err)
+	}
+
+	return nil
+}
+
+// Promts user for a coffee and an optional page size.
+func displayCuppingsByCoffee(ctx context.Context, db DB) error {
+	session := sessionFromContext(ctx)
+	fmt.Println("Displaying cuppings containing a coffee (Enter " + session.quit + " to quit):")
+
+	coffeeName, quit, err := getCoffeeNameWithSuggestions(ctx, db, quitExits(ctx), false)
+	if err != nil {
+		return fmt.Errorf("buna: cupping: failed to get coffee name: %w", err)
+	}
+	if quit != answered {
+		fmt.Println(quitMsg)
+		return nil
+	}
+
+	coffeeRoaster, quit, err := getCoffeeRoasterWithSuggestions(ctx, db, quitExits(ctx), coffeeName)
+	if err != nil {
+		return fmt.Errorf("buna: cupping: failed to get coffee roaster: %w", err)
+	}
+	if quit != answered {
+		fmt.Println(quitMsg)
+		return nil
+	}
+
+	pageSize, quit := getCuppingPageSize(ctx)
+	if quit != answered {
+		fmt.Println(quitMsg)
+		return nil
+	}
+
+	if err := browseCuppings(ctx, pageSize, func(after *pageCursor, limit int) ([]cupping, error) {
+		return db.getCuppingsByCoffee(ctx, coffeeName, coffeeRoaster, after, limit)
+	}, false); err != nil {
+		return fmt.Errorf("buna: cupping: failed to browse cuppings by coffee: %w", err)
+	}
+
+	return nil
+}
+
+// Promts user for an optional start date, an optional end date and an optional page size.
+func displayCuppingsByDateRange(ctx context.Context, db DB) error {
+	session := sessionFromContext(ctx)
+	fmt.Println("Displaying cuppings in a date range (Enter " + session.quit + " to quit):")
+
+	fromDate, quit := getDateInput(quitExits(ctx), true, "Enter first cupping date (Leave empty for no lower bound): ", nil)
+	if quit != answered {
+		fmt.Println(quitMsg)
+		return nil
+	}
+
+	toDate, quit := getDateInput(quitExits(ctx), true, "Enter last cupping date (Leave empty for no upper bound): ", nil)
+	if quit != answered {
+		fmt.Println(quitMsg)
+		return nil
+	}
+
+	pageSize, quit := getCuppingPageSize(ctx)
+	if quit != answered {
+		fmt.Println(quitMsg)
+		return nil
+	}
+
+	var fromDateStr, toDateStr string
+	if fromDate.year != 0 {
+		fromDateStr = createDateString(fromDate)
+	}
+	if toDate.year != 0 {
+		toDateStr = createDateString(toDate)
+	}
+
+	if err := browseCuppings(ctx, pageSize, func(after *pageCursor, limit int) ([]cupping, error) {
+		return db.getCuppingsByDateRange(ctx, fromDateStr, toDateStr, after, limit)
+	}, true); err != nil {
+		return fmt.Errorf("buna: cupping: failed to browse cuppings by date range: %w", err)
+	}
 
-	fmt.Println("Displaying cuppings by last added (Enter # to quit):")
-	fmt.Print("Enter a limit for the number of cuppings to display: ")
-	limit, quit := validateIntInput(quitStr, true, 1, maxDisplayAmount, []int{})
-	if quit {
+	return nil
+}
+
+// Promts user for a roaster and an optional page size.
+func displayCuppingsByWinningRoaster(ctx context.Context, db DB) error {
+	session := sessionFromContext(ctx)
+	fmt.Println("Displaying cuppings where a roaster placed first (Enter " + session.quit + " to quit):")
+
+	roaster, quit, err := getRoasterNameWithSuggestions(ctx, db, quitExits(ctx), false)
+	if err != nil {
+		return fmt.Errorf("buna: cupping: failed to get roaster name: %w", err)
+	}
+	if quit != answered {
+		fmt.Println(quitMsg)
+		return nil
+	}
+
+	pageSize, quit := getCuppingPageSize(ctx)
+	if quit != answered {
 		fmt.Println(quitMsg)
 		return nil
 	}
 
-	if limit == 0 {
-		limit = defaultDisplayAmount
+	if err := browseCuppings(ctx, pageSize, func(after *pageCursor, limit int) ([]cupping, error) {
+		return db.getCuppingsByWinningRoaster(ctx, roaster, after, limit)
+	}, false); err != nil {
+		return fmt.Errorf("buna: cupping: failed to browse cuppings by winning roaster: %w", err)
 	}
 
-	cuppings, err := db.getCuppingsByLastAdded(ctx, limit)
+	return nil
+}
+
+// Lists the most recent cuppings and promts user for the ID of the cupping to display.
+func displayCuppingDetails(ctx context.Context, db DB) error {
+	const overviewAmount = 10
+
+	session := sessionFromContext(ctx)
+	fmt.Println("Displaying cupping details (Enter " + session.quit + " to quit):")
+
+	cuppings, err := db.getCuppingsByLastAdded(ctx, nil, overviewAmount)
 	if err != nil {
 		return fmt.Errorf("buna: cupping: failed to get cuppings by last added: %w", err)
 	}
@@ -183,41 +376,173 @@ func displayCuppingsByLastAdded(ctx context.Context, db DB) error {
 		return nil
 	}
 
+	t := table.NewWriter()
+
+	t.AppendHeader(table.Row{"ID", "Date", "Coffees", "Winner"})
+
+	for _, cupping := range cuppings {
+		winner := cupping.cuppedCoffees[0]
+		t.AppendRow(table.Row{cupping.id, timestampDate(cupping.date), len(cupping.cuppedCoffees), winner.name + " (" + winner.roaster + ")"})
+	}
+
+	terminalWidth, _, err := terminal.GetSize(int(os.Stdin.Fd()))
+	if err != nil {
+		return fmt.Errorf("buna: cupping: failed to get terminal width: %w", err)
+	}
+	t.SetAllowedRowLength(terminalWidth)
+
+	t.SetOutputMirror(os.Stdout)
+	renderTable(ctx, t)
+
+	fmt.Print("Enter the ID of the cupping to display: ")
+	id, quit := validateIntInput(quitExits(ctx), false, 1, math.MaxInt64, nil)
+	if quit != answered {
+		fmt.Println(quitMsg)
+		return nil
+	}
+
+	cupping, err := db.getCuppingByID(ctx, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		fmt.Println("No cupping with this ID exists")
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("buna: cupping: failed to get cupping by id: %w", err)
+	}
+
+	if err := displayCupping(ctx, cupping); err != nil {
+		return fmt.Errorf("buna: cupping: failed to display cupping: %w", err)
+	}
+
+	return nil
+}
+
+// Displays a single cupping with the cupped coffees side by side, ordered by rank.
+func displayCupping(ctx context.Context, cupping cupping) error {
+	terminalWidth, _, err := terminal.GetSize(int(os.Stdin.Fd()))
+	if err != nil {
+		return fmt.Errorf("buna: cupping: failed to get terminal width: %w", err)
+	}
+
+	renderCuppingTables(ctx, cupping, terminalWidth)
+
+	return nil
+}
+
+func displayCuppings(ctx context.Context, cuppings []cupping) error {
+	if len(cuppings) == 0 {
+		fmt.Println("No cuppings to display")
+		return nil
+	}
+
 	terminalWidth, _, err := terminal.GetSize(int(os.Stdin.Fd()))
 	if err != nil {
 		return fmt.Errorf("buna: cupping: failed to get terminal width: %w", err)
 	}
 
 	for _, cupping := range cuppings {
-		// Cupping table
-		t := table.NewWriter()
+		renderCuppingTables(ctx, cupping, terminalWidth)
+	}
 
-		t.AppendHeader(table.Row{"Date", "Duration (min)", "General notes"})
+	return nil
+}
 
-		cuppingNotes := splitTextIntoField(cupping.notes, maxNoteFieldWidth)
+// Renders a table of the cupping followed by a table of its coffees side by side, one column per coffee.
+func renderCuppingTables(ctx context.Context, cupping cupping, terminalWidth int) {
+	const maxNoteFieldWidth = 100
+	const minNoteFieldWidth = 15
 
-		t.AppendRow(table.Row{cupping.date, cupping.durationMin, cuppingNotes})
+	// Cupping table
+	t := table.NewWriter()
 
-		t.SetAllowedRowLength(terminalWidth)
-		t.SetOutputMirror(os.Stdout)
-		t.Render()
+	t.AppendHeader(table.Row{"ID", "Date", "Duration (min)", "General notes"})
 
-		// Cupped coffees table
-		t = table.NewWriter()
+	cuppingNotes := splitTextIntoField(cupping.notes, maxNoteFieldWidth)
 
-		t.AppendHeader(table.Row{"Coffee name", "Rank (1 = best)", "Coffee notes"})
+	t.AppendRow(table.Row{cupping.id, timestampDate(cupping.date), cupping.durationMin, cuppingNotes})
 
-		for _, cuppedCoffee := range cupping.cuppedCoffees {
-			cuppedCoffeeNotes := splitTextIntoField(cuppedCoffee.notes, maxNoteFieldWidth)
+	t.SetAllowedRowLength(terminalWidth)
+	t.SetOutputMirror(os.Stdout)
+	renderTable(ctx, t)
 
-			t.AppendRow(table.Row{cuppedCoffee.name, cuppedCoffee.rank, cuppedCoffeeNotes})
-			t.AppendSeparator()
-		}
+	// Cupped coffees table with one column per coffee
+	noteFieldWidth := terminalWidth/(len(cupping.cuppedCoffees)+1) - 3
+	if noteFieldWidth < minNoteFieldWidth {
+		noteFieldWidth = minNoteFieldWidth
+	}
+
+	header := table.Row{"Rank (1 = best)"}
+	names := table.Row{"Coffee"}
+	roasters := table.Row{"Roaster"}
+	notes := table.Row{"Notes"}
+	for _, cuppedCoffee := range cupping.cuppedCoffees {
+		header = append(header, cuppedCoffee.rank)
+		names = append(names, splitTextIntoField(cuppedCoffee.name, noteFieldWidth))
+		roasters = append(roasters, splitTextIntoField(cuppedCoffee.roaster, noteFieldWidth))
+		notes = append(notes, splitTextIntoField(cuppedCoffee.notes, noteFieldWidth))
+	}
+
+	t = table.NewWriter()
+
+	t.AppendHeader(header)
+	t.AppendRows([]table.Row{names, roasters, notes})
+	t.AppendSeparator()
+
+	t.SetAllowedRowLength(terminalWidth)
+	t.SetOutputMirror(os.Stdout)
+	renderTable(ctx, t)
+	fmt.Println()
+}
+
+// Returns pageSize, promptResult
+func getCuppingPageSize(ctx context.Context) (int, promptResult) {
+	defaultPageSize := sessionFromContext(ctx).config.intValue("display.cuppings_page_size")
+	const maxPageSize = 10
+
+	fmt.Print("Enter the number of cuppings to display per page: ")
+	pageSize, quit := validateIntInput(quitExits(ctx), true, 1, maxPageSize, []int{})
+	if quit != answered {
+		return 0, quit
+	}
+
+	if pageSize == 0 {
+		pageSize = defaultPageSize
+	}
+
+	return pageSize, answered
+}
+
+// Displays the cuppings retrieved by getCuppings page by page.
+// byDate is true if the cuppings are ordered by date and id instead of only by id, which allows jumping to a date.
+func browseCuppings(ctx context.Context, pageSize int, getCuppings func(after *pageCursor, limit int) ([]cupping, error), byDate bool) error {
+	var cuppings []cupping
+	listing := pagedListing{
+		fetch: func(after *pageCursor, limit int) ([]pageCursor, error) {
+			var err error
+			cuppings, err = getCuppings(after, limit)
+			if err != nil {
+				return nil, fmt.Errorf("buna: cupping: failed to get cuppings: %w", err)
+			}
+
+			cursors := make([]pageCursor, len(cuppings))
+			for i, cupping := range cuppings {
+				cursors[i].id = cupping.id
+				if byDate {
+					cursors[i].key = utcTimestamp(cupping.date)
+				}
+			}
+			return cursors, nil
+		},
+		display: func(n int) error {
+			return displayCuppings(ctx, cuppings[:n])
+		},
+	}
+	if byDate {
+		listing.dateCursor = descendingDateCursor
+	}
 
-		t.SetAllowedRowLength(terminalWidth)
-		t.SetOutputMirror(os.Stdout)
-		t.Render()
-		fmt.Println()
+	if err := browsePages(quitExits(ctx), listing, pageSize); err != nil {
+		return fmt.Errorf("buna: cupping: failed to browse pages: %w", err)
 	}
 
 	return nil
@@ -0,0 +1,289 @@
+package buna
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Layouts of the dates that can be entered as calendar dates
+var dateInputLayouts = []string{"2006-01-02", "2006-1-2", "2006/01/02", "2006/1/2"}
+
+// Example inputs shown when an entered date is not understood
+const dateInputExamples = "2020-03-05, today, yesterday, 3d ago, 2 weeks ago, friday or last friday"
+
+// Returns the calendar date of t in its location.
+func dateFromTime(t time.Time) date {
+	return date{year: t.Year(), month: int(t.Month()), day: t.Day()}
+}
+
+// Returns today and yesterday, the dates most things are logged on.
+func recentDateSuggestions() []date {
+	now := time.Now()
+	return []date{dateFromTime(now), dateFromTime(now.AddDate(0, 0, -1))}
+}
+
+// Parses a date that is entered as
+//   - a calendar date, e.g. "2020-03-05"
+//   - "today" or "yesterday"
+//   - a number of days or weeks ago, e.g. "3d ago", "3 days ago", "1w ago" or "2 weeks ago"
+//   - a weekday name or its abbreviation, e.g. "friday" or "fri", for the most recent such day up to and including today
+//   - "last" followed by a weekday name, e.g. "last friday", for the most recent such day before today
+//
+// Relative dates are resolved against now.
+// Returns false if the input is not a date or the date is after now.
+func parseDateInput(input string, now time.Time) (date, bool) {
+	input = strings.Join(strings.Fields(strings.ToLower(input)), " ")
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+
+	var t time.Time
+	switch {
+	case input == "today":
+		t = today
+	case input == "yesterday":
+		t = today.AddDate(0, 0, -1)
+	case strings.HasSuffix(input, " ago"):
+		days, ok := parseDaysAgo(strings.TrimSuffix(input, " ago"))
+		if !ok {
+			return date{}, false
+		}
+		t = today.AddDate(0, 0, -days)
+	case strings.HasPrefix(input, "last "):
+		weekday, ok := parseWeekday(strings.TrimPrefix(input, "last "))
+		if !ok {
+			return date{}, false
+		}
+		t = lastWeekday(today.AddDate(0, 0, -1), weekday)
+	default:
+		if weekday, ok := parseWeekday(input); ok {
+			t = lastWeekday(today, weekday)
+			break
+		}
+
+		parsed := false
+		for _, layout := range dateInputLayouts {
+			// Parsing validates the calendar date, so e.g. February 29 is only accepted in leap years
+			var err error
+			t, err = time.ParseInLocation(layout, input, now.Location())
+			if err == nil {
+				parsed = true
+				break
+			}
+		}
+		if !parsed {
+			return date{}, false
+		}
+	}
+
+	if t.After(today) {
+		return date{}, false
+	}
+
+	return dateFromTime(t), true
+}
+
+// Parses a number of days or weeks, e.g. "3d", "3 days", "1w" or "2 weeks", into days.
+func parseDaysAgo(input string) (int, bool) {
+	units := []struct {
+		suffixes []string
+		days     int
+	}{
+		{[]string{"days", "day", "d"}, 1},
+		{[]string{"weeks", "week", "w"}, 7},
+	}
+
+	for _, unit := range units {
+		for _, suffix := range unit.suffixes {
+			if !strings.HasSuffix(input, suffix) {
+				continue
+			}
+
+			n, err := strconv.Atoi(strings.TrimSpace(strings.TrimSuffix(input, suffix)))
+			if err != nil || n < 0 {
+				return 0, false
+			}
+			return n * unit.days, true
+		}
+	}
+
+	return 0, false
+}
+
+// Parses a weekday name or an abbreviation of at least three letters, e.g. "friday", "fri" or "thurs".
+func parseWeekday(input string) (time.Weekday, bool) {
+	if len(input) < 3 {
+		return 0, false
+	}
+
+	for weekday := time.Sunday; weekday <= time.Saturday; weekday++ {
+		if strings.HasPrefix(strings.ToLower(weekday.String()), input) {
+			return weekday, true
+		}
+	}
+
+	return 0, false
+}
+
+// Returns the most recent day up to and including t that is on the weekday.
+func lastWeekday(t time.Time, weekday time.Weekday) time.Time {
+	daysBack := (int(t.Weekday()) - int(weekday) + 7) % 7
+	return t.AddDate(0, 0, -daysBack)
+}
+
+// Returns the suggestion input selects by its number, ok
+// Only the numbers of suggestions are selections, any other input is a date.
+func selectDateSuggestion(input string, suggestions []date) (date, bool) {
+	num, err := strconv.Atoi(input)
+	if err != nil || num < 1 || num > len(suggestions) {
+		return date{}, false
+	}
+
+	return suggestions[num-1], true
+}
+
+// Used to get a date input by promting the user for a single date, see parseDateInput for the accepted formats.
+// inputMsg is used as the message for the user and should end with ": ", for it to make sense to the user.
+// If there are suggestions, the user can also select one of them, see selectDateSuggestion.
+// Second return value is how the prompt was left.
+// Optional dates default to date{}.
+func getDateInput(exits promptExits, isOptional bool, inputMsg string, suggestions []date) (date, promptResult) {
+	scanner := exits.input
+
+	suggestionNum := len(suggestions)
+	if suggestionNum > 0 {
+		fmt.Println(inputMsg)
+		fmt.Printf("Select one of the following (1 to %v) or enter a date:\n", suggestionNum)
+		for i, suggestion := range suggestions {
+			fmt.Printf("%v. %v\n", i+1, createDateString(suggestion))
+		}
+	} else {
+		fmt.Print(inputMsg)
+	}
+
+	for {
+		scanner.Scan()
+		input := strings.TrimSpace(scanner.Text())
+
+		if result := exitResult(exits, input); result != answered {
+			return date{}, result
+		}
+
+		if input == "" {
+			if isOptional {
+				return date{}, answered
+			}
+
+			fmt.Print("A value is required. Please try again: ")
+			continue
+		}
+
+		if suggestion, ok := selectDateSuggestion(input, suggestions); ok {
+			return suggestion, answered
+		}
+
+		inputDate, ok := parseDateInput(input, time.Now())
+		if !ok {
+			if suggestionNum > 0 {
+				fmt.Printf("Input invalid, select 1 to %v or enter a date up to today (e.g. %v). Please try again: ", suggestionNum, dateInputExamples)
+				continue
+			}
+
+			fmt.Printf("Input invalid, enter a date up to today (e.g. %v). Please try again: ", dateInputExamples)
+			continue
+		}
+
+		fmt.Printf("Using %v\n", createDateString(inputDate))
+		return inputDate, answered
+	}
+}
+
+// Layouts of the times of day that can be entered
+var timeOfDayInputLayouts = []string{"15:04", "15.04", "3:04pm", "3pm"}
+
+// Returns the date part of a stored timestamp in the format "YYYY-MM-DD".
+func timestampDate(timestamp string) string {
+	const layout = "2006-01-02"
+	if len(timestamp) < len(layout) {
+		return timestamp
+	}
+	return timestamp[:len(layout)]
+}
+
+// Returns a stored timestamp for display in the time zone it was entered in, e.g. "2020-03-05 08:15".
+// Only the date is returned if the time of day is unknown.
+func formatTimestamp(timestamp string, timeKnown bool) string {
+	t, err := time.Parse(time.RFC3339, timestamp)
+	if err != nil || !timeKnown {
+		return timestampDate(timestamp)
+	}
+	return t.Format("2006-01-02 15:04")
+}
+
+// Returns a stored timestamp in UTC, e.g. "2020-03-05T07:15:00Z".
+// Unlike the stored timestamps, which keep the offset of the time zone they were entered in,
+// timestamps in UTC sort chronologically as text. Invalid timestamps are returned unchanged.
+func utcTimestamp(timestamp string) string {
+	t, err := time.Parse(time.RFC3339, timestamp)
+	if err != nil {
+		return timestamp
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+// Parses a time of day, e.g. "08:15", "8.15", "8:15am" or "8pm".
+// Returns hour, minute, ok
+func parseTimeOfDayInput(input string) (int, int, bool) {
+	input = strings.ToLower(strings.ReplaceAll(input, " ", ""))
+	for _, layout := range timeOfDayInputLayouts {
+		if t, err := time.Parse(layout, input); err == nil {
+			return t.Hour(), t.Minute(), true
+		}
+	}
+
+	return 0, 0, false
+}
+
+// Used to get a timestamp input by promting the user for the date with getDateInput and then for the time of day,
+// which defaults to the current time. Going back from the time of day asks for the date again.
+// inputMsg is passed on to getDateInput.
+// Returns the timestamp in RFC 3339 format in the local time zone, promptResult
+func getTimestampInput(exits promptExits, inputMsg string, suggestions []date) (string, promptResult) {
+	for {
+		inputDate, quit := getDateInput(exits, false, inputMsg, suggestions)
+		if quit != answered {
+			return "", quit
+		}
+
+		now := time.Now()
+		fmt.Printf("Enter the time (Leave empty for %v): ", now.Format("15:04"))
+
+		scanner := exits.input
+		for {
+			scanner.Scan()
+			input := strings.TrimSpace(scanner.Text())
+
+			result := exitResult(exits, input)
+			if result == quitPrompt {
+				return "", quitPrompt
+			}
+			if result == backPrompt {
+				break
+			}
+
+			hour, minute, second := now.Hour(), now.Minute(), now.Second()
+			if input != "" {
+				var ok bool
+				hour, minute, ok = parseTimeOfDayInput(input)
+				if !ok {
+					fmt.Print("Input invalid (e.g. 08:15 or 8:15pm). Please try again: ")
+					continue
+				}
+				second = 0
+			}
+
+			t := time.Date(inputDate.year, time.Month(inputDate.month), inputDate.day, hour, minute, second, 0, time.Local)
+			return t.Format(time.RFC3339), answered
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package buna
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseDateInput(t *testing.T) {
+	// A Wednesday
+	now := time.Date(2020, time.March, 11, 15, 4, 0, 0, time.UTC)
+	firstOfMonth := time.Date(2020, time.March, 1, 8, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		input  string
+		now    time.Time
+		want   date
+		wantOK bool
+	}{
+		{"2020-03-05", now, date{2020, 3, 5}, true},
+		{"2020-3-5", now, date{2020, 3, 5}, true},
+		{"2020/03/05", now, date{2020, 3, 5}, true},
+		{"2020/3/5", now, date{2020, 3, 5}, true},
+		{"2020-02-29", now, date{2020, 2, 29}, true},
+		{"2019-02-29", now, date{}, false},
+		{"2020-03-12", now, date{}, false},
+		{"today", now, date{2020, 3, 11}, true},
+		{"  Yesterday ", now, date{2020, 3, 10}, true},
+		{"yesterday", firstOfMonth, date{2020, 2, 29}, true},
+		{"0d ago", now, date{2020, 3, 11}, true},
+		{"3d ago", now, date{2020, 3, 8}, true},
+		{"1 day ago", now, date{2020, 3, 10}, true},
+		{"3  days   ago", now, date{2020, 3, 8}, true},
+		{"1w ago", now, date{2020, 3, 4}, true},
+		{"2 weeks ago", now, date{2020, 2, 26}, true},
+		{"-1d ago", now, date{}, false},
+		{"3x ago", now, date{}, false},
+		{"ago", now, date{}, false},
+		{"wednesday", now, date{2020, 3, 11}, true},
+		{"wed", now, date{2020, 3, 11}, true},
+		{"Friday", now, date{2020, 3, 6}, true},
+		{"thurs", now, date{2020, 3, 5}, true},
+		{"sun", firstOfMonth, date{2020, 3, 1}, true},
+		{"last wednesday", now, date{2020, 3, 4}, true},
+		{"last fri", now, date{2020, 3, 6}, true},
+		{"last sunday", firstOfMonth, date{2020, 2, 23}, true},
+		{"we", now, date{}, false},
+		{"last", now, date{}, false},
+		{"last week", now, date{}, false},
+		{"tomorrow", now, date{}, false},
+		{"", now, date{}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, ok := parseDateInput(tt.input, tt.now)
+			if ok != tt.wantOK || got != tt.want {
+				t.Errorf("parseDateInput(%q, %v) = %v, %v, want %v, %v", tt.input, tt.now, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestSelectDateSuggestion(t *testing.T) {
+	suggestions := []date{{2020, 3, 11}, {2020, 3, 10}}
+
+	tests := []struct {
+		input       string
+		suggestions []date
+		want        date
+		wantOK      bool
+	}{
+		{"1", suggestions, date{2020, 3, 11}, true},
+		{"2", suggestions, date{2020, 3, 10}, true},
+		{"0", suggestions, date{}, false},
+		{"3", suggestions, date{}, false},
+		{"-1", suggestions, date{}, false},
+		{"1", nil, date{}, false},
+		{"today", suggestions, date{}, false},
+		{"2020-03-05", suggestions, date{}, false},
+		{"", suggestions, date{}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, ok := selectDateSuggestion(tt.input, tt.suggestions)
+			if ok != tt.wantOK || got != tt.want {
+				t.Errorf("selectDateSuggestion(%q, %v) = %v, %v, want %v, %v", tt.input, tt.suggestions, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
@@ -13,29 +13,82 @@ type DB interface {
 	insertCoffeePurchase(ctx context.Context, coffeePurchase coffeePurchase) error
 	insertCupping(ctx context.Context, cupping cupping) error
 	insertGrinder(ctx context.Context, grinder grinder) error
+	insertRoaster(ctx context.Context, roaster roaster) error
+	insertUser(ctx context.Context, u user) error
 
 	// retrieve
-	getBrewingMethodsByLastAdded(ctx context.Context, limit int) ([]brewingMethod, error)
-	getBrewingsOrderByDesc(ctx context.Context, limit int, orderByName string) ([]brewing, error)
-	getBrewingSuggestions(ctx context.Context, limit int, brewingFilter brewing) ([]brewing, error)
+	getBrewingMethodsByLastAdded(ctx context.Context, after *pageCursor, limit int) ([]brewingMethod, error)
+	getBrewingByID(ctx context.Context, id int) (brewing, error)
+	getBrewingsByQuery(ctx context.Context, query brewingQuery, after *pageCursor, limit int) ([]brewing, error)
+	getBrewingsOrderByDesc(ctx context.Context, after *pageCursor, limit int, orderByName string) ([]brewing, error)
+	getBrewingSuggestions(ctx context.Context, limit int, brewingFilter brewing, band ratioBand) ([]brewing, error)
+	getCoffeeByNameRoaster(ctx context.Context, name string, roaster string) (coffee, error)
 	getCoffeeIDByNameRoaster(ctx context.Context, name string, roaster string) (int, error)
-	getCoffeePurchasesByLastAdded(ctx context.Context, limit int) ([]coffeePurchase, error)
+	getCoffeePurchaseOfBrewing(ctx context.Context, brewing brewing) (coffeePurchase, error)
+	getCoffeePurchasesByLastAdded(ctx context.Context, after *pageCursor, limit int) ([]coffeePurchase, error)
 	getCoffeeNameSuggestions(ctx context.Context, limit int) ([]string, error)
-	getCoffeesByLastAdded(ctx context.Context, limit int) ([]coffee, error)
-	getCuppingsByLastAdded(ctx context.Context, limit int) ([]cupping, error)
+	getCoffeesAlphabetically(ctx context.Context, after *pageCursor, limit int) ([]coffee, error)
+	getCoffeesByLastAdded(ctx context.Context, after *pageCursor, limit int) ([]coffee, error)
+	getCoffeesByName(ctx context.Context, name string, after *pageCursor, limit int) ([]coffee, error)
+	getCoffeesByOrigin(ctx context.Context, countryCode string, region string, after *pageCursor, limit int) ([]coffee, error)
+	getCoffeesByProcess(ctx context.Context, process string, after *pageCursor, limit int) ([]coffee, error)
+	getCoffeesByRoaster(ctx context.Context, roaster string, after *pageCursor, limit int) ([]coffee, error)
+	getDrafts(ctx context.Context, flow string) ([]draft, error)
+	getDecafCoffeesAlphabetically(ctx context.Context, after *pageCursor, limit int) ([]coffee, error)
+	getDecafCoffeesByLastAdded(ctx context.Context, after *pageCursor, limit int) ([]coffee, error)
+	getCuppingByID(ctx context.Context, id int) (cupping, error)
+	getCuppingsByCoffee(ctx context.Context, coffeeName string, coffeeRoaster string, after *pageCursor, limit int) ([]cupping, error)
+	getCuppingsByDateRange(ctx context.Context, fromDate string, toDate string, after *pageCursor, limit int) ([]cupping, error)
+	getCuppingsByLastAdded(ctx context.Context, after *pageCursor, limit int) ([]cupping, error)
+	getCuppingsByWinningRoaster(ctx context.Context, roaster string, after *pageCursor, limit int) ([]cupping, error)
 	getGrinderIDByName(ctx context.Context, name string) (int, error)
-	getGrindersByLastAdded(ctx context.Context, limit int) ([]grinder, error)
+	getGrindersByLastAdded(ctx context.Context, after *pageCursor, limit int) ([]grinder, error)
 	getMethodIDByName(ctx context.Context, name string) (int, error)
 	getLastCoffeeRoastDate(ctx context.Context, coffeeName string) (date, error)
 	getMostRecentlyUsedBrewingMethodNames(ctx context.Context, limit int) ([]string, error)
 	getMostRecentlyUsedCoffeeGrinderNames(ctx context.Context, limit int) ([]string, error)
+	getMostRecentlyUsedCountryCodes(ctx context.Context, limit int) ([]string, error)
 	getMostRecentlyUsedCoffeeWeights(ctx context.Context, brewingMethodName string, coffeeGrinderName string, limit int) ([]float64, error)
 	getMostRecentlyUsedWaterWeights(ctx context.Context, brewingMethodName string, coffeeGrinderName string, limit int) ([]float64, error)
+	getNextBrewingOfCoffee(ctx context.Context, brewing brewing) (brewing, error)
+	getNoteSearchHits(ctx context.Context, query string, highlightStart string, highlightEnd string, limit int) ([]noteSearchHit, error)
+	getNoteSearchAvailable(ctx context.Context) (bool, error)
+	getPreference(ctx context.Context, name string) (string, error)
+	getPreviousBrewingOfCoffee(ctx context.Context, brewing brewing) (brewing, error)
+	getRatedBrewings(ctx context.Context, brewingFilter brewing, band ratioBand, limit int) ([]brewing, error)
+	getRoasterByName(ctx context.Context, name string) (roaster, error)
+	getRoasterMergeCandidates(ctx context.Context) ([][2]string, error)
+	getRoasterNameSuggestions(ctx context.Context, limit int) ([]string, error)
+	getRoastersAlphabetically(ctx context.Context, after *pageCursor, limit int) ([]roaster, error)
 	getRoastersByCoffeeName(ctx context.Context, name string, limit int) ([]string, error)
+	getRoastersByLastAdded(ctx context.Context, after *pageCursor, limit int) ([]roaster, error)
+	getSimilarBrewingMethodNames(ctx context.Context, name string, limit int) ([]string, error)
+	getSimilarCoffees(ctx context.Context, name string, roaster string, limit int) ([]coffee, error)
+	getSimilarGrinderNames(ctx context.Context, name string, limit int) ([]string, error)
+	getSimilarRoasterNames(ctx context.Context, name string, limit int) ([]string, error)
+	getUserByName(ctx context.Context, name string) (user, error)
+	getUsers(ctx context.Context) ([]user, error)
+
+	// update
+	assignUnattributedEntries(ctx context.Context, userID int) error
+	deleteDraft(ctx context.Context, id int) error
+	dismissRoasterMerge(ctx context.Context, name string, otherName string) error
+	mergeRoasters(ctx context.Context, fromName string, intoName string) error
+	saveDraft(ctx context.Context, d draft) (int, error)
+	setPreference(ctx context.Context, name string, value string) error
 
 	// statistics
-	getAverageBrewingRating(ctx context.Context, brewingFilter brewing) (float64, error)
+	getAverageBrewingScores(ctx context.Context, brewingFilter brewing, band ratioBand) (brewingScoreAverages, error)
+	getBrewingTrends(ctx context.Context, brewingFilter brewing, band ratioBand, fromDate string, toDate string, period trendPeriod) ([]brewingTrend, error)
+	getCoffeeFlavorCounts(ctx context.Context, coffeeName string, coffeeRoaster string) ([]flavorCount, error)
+	getFlavorCountsByOrigin(ctx context.Context, limitPerOrigin int) ([]flavorCount, error)
+	getFlavorCountsByProcess(ctx context.Context, limitPerProcess int) ([]flavorCount, error)
+	getRatioBandStatistics(ctx context.Context, brewingFilter brewing) ([]ratioBandStatistics, error)
+	getRoasterStatistics(ctx context.Context) ([]roasterStatistics, error)
+	getTimeOfDayStatistics(ctx context.Context, brewingFilter brewing, band ratioBand) ([]brewingTimeStatistics, error)
 	getTotalCount(ctx context.Context, entity dbEntity) (int, error)
+	getUserRatingComparisons(ctx context.Context, userID int, otherUserID int) ([]userRatingComparison, error)
+	getWeekdayStatistics(ctx context.Context, brewingFilter brewing, band ratioBand) ([]brewingTimeStatistics, error)
 
 	// general
 	TransactContext(ctx context.Context, f func(ctx context.Context, tx *sql.Tx) error) error
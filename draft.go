@@ -0,0 +1,391 @@
+package buna
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/jedib0t/go-pretty/table"
+	"golang.org/x/crypto/ssh/terminal"
+)
+
+// Add flows that keep their answers in drafts
+const (
+	brewingDraftFlow = "brewing"
+	cuppingDraftFlow = "cupping"
+)
+
+var draftFlowNames = map[string]string{
+	brewingDraftFlow: "Brewing",
+	cuppingDraftFlow: "Cupping",
+}
+
+// An unfinished entry of an add flow.
+type draft struct {
+	id        int
+	flow      string
+	answers   string // JSON array of draftAnswer
+	createdAt string // RFC 3339 timestamp
+	updatedAt string // RFC 3339 timestamp
+}
+
+// The answer to a prompt of an add flow, stored in the order the prompts were answered.
+type draftAnswer struct {
+	Key   string          `json:"key"`
+	Value json.RawMessage `json:"value"`
+}
+
+// A coffee as it is kept in draft answers
+type draftCoffee struct {
+	Name    string `json:"name"`
+	Roaster string `json:"roaster"`
+}
+
+// Returns the answer value for display, e.g. "Kiambu (Square Mile)" for a coffee.
+// Values other than strings and coffees are displayed as JSON.
+func formatDraftValue(value json.RawMessage) string {
+	var s string
+	if err := json.Unmarshal(value, &s); err == nil {
+		if _, err := time.Parse(time.RFC3339, s); err == nil {
+			return formatTimestamp(s, true)
+		}
+		return s
+	}
+
+	var c draftCoffee
+	if err := json.Unmarshal(value, &c); err == nil && c.Name != "" {
+		return c.Name + " (" + c.Roaster + ")"
+	}
+
+	return string(value)
+}
+
+// Keeps the answers of an add flow in a draft, which is saved after every answered prompt.
+// The draft is only created once the first prompt is answered.
+type draftRecorder struct {
+	db      DB
+	draft   draft
+	answers []draftAnswer
+}
+
+func newDraftRecorder(db DB, d draft) (*draftRecorder, error) {
+	r := &draftRecorder{db: db, draft: d}
+	if d.answers != "" {
+		if err := json.Unmarshal([]byte(d.answers), &r.answers); err != nil {
+			return nil, fmt.Errorf("buna: draft: failed to decode draft answers: %w", err)
+		}
+	}
+
+	return r, nil
+}
+
+// Restores the answer to the prompt with key from the draft into v, which must be a pointer.
+// Returns whether the draft has an answer to the prompt, error
+func (r *draftRecorder) restore(key string, v interface{}) (bool, error) {
+	for _, a := range r.answers {
+		if a.Key != key {
+			continue
+		}
+
+		if err := json.Unmarshal(a.Value, v); err != nil {
+			return false, fmt.Errorf("buna: draft: failed to decode answer %q: %w", key, err)
+		}
+
+		return true, nil
+	}
+
+	return false, nil
+}
+
+// Adds the answer v to the prompt with key to the draft, or replaces the answer that failed its check, and saves it.
+func (r *draftRecorder) record(ctx context.Context, key string, v interface{}) error {
+	value, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("buna: draft: failed to encode answer %q: %w", key, err)
+	}
+
+	replaced := false
+	for i, a := range r.answers {
+		if a.Key == key {
+			r.answers[i].Value = value
+			replaced = true
+			break
+		}
+	}
+	if !replaced {
+		r.answers = append(r.answers, draftAnswer{Key: key, Value: value})
+	}
+
+	if err := r.save(ctx); err != nil {
+		return fmt.Errorf("buna: draft: failed to save answer %q: %w", key, err)
+	}
+
+	return nil
+}
+
+// Removes the answer to the prompt with key and all answers given after it, as they might depend on it.
+func (r *draftRecorder) forgetFrom(ctx context.Context, key string) error {
+	for i, a := range r.answers {
+		if a.Key != key {
+			continue
+		}
+
+		r.answers = r.answers[:i]
+		if err := r.save(ctx); err != nil {
+			return fmt.Errorf("buna: draft: failed to save draft without answer %q: %w", key, err)
+		}
+
+		return nil
+	}
+
+	return nil
+}
+
+func (r *draftRecorder) save(ctx context.Context) error {
+	answers, err := json.Marshal(r.answers)
+	if err != nil {
+		return fmt.Errorf("buna: draft: failed to encode draft answers: %w", err)
+	}
+
+	now := time.Now().Format(time.RFC3339)
+	if r.draft.createdAt == "" {
+		r.draft.createdAt = now
+	}
+	r.draft.updatedAt = now
+	r.draft.answers = string(answers)
+
+	id, err := r.db.saveDraft(ctx, r.draft)
+	if err != nil {
+		return fmt.Errorf("buna: draft: failed to save draft: %w", err)
+	}
+	r.draft.id = id
+
+	return nil
+}
+
+// Deletes the draft once its entry was added.
+func (r *draftRecorder) discard(ctx context.Context) error {
+	if r.draft.id == 0 {
+		return nil
+	}
+
+	if err := r.db.deleteDraft(ctx, r.draft.id); err != nil {
+		return fmt.Errorf("buna: draft: failed to delete draft: %w", err)
+	}
+	r.draft.id = 0
+
+	return nil
+}
+
+// Returns whether a reference looked up from a draft answer, e.g. by getCoffeeIDByNameRoaster, still exists.
+func referenceExists(_ int, err error) (bool, error) {
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("buna: draft: failed to look up reference: %w", err)
+	}
+
+	return true, nil
+}
+
+// Prints the quit message, mentioning that the answers so far are kept if there are any.
+func (r *draftRecorder) printQuitMsg() {
+	fmt.Println(quitMsg)
+	if r.draft.id != 0 {
+		fmt.Println("Your answers so far are kept as a draft, which you can resume the next time")
+	}
+}
+
+// Offers to resume or discard the existing drafts of the flow before a new entry is started.
+// Returns draftRecorder, promptResult, error
+func startDraft(ctx context.Context, db DB, flow string) (*draftRecorder, promptResult, error) {
+	drafts, err := db.getDrafts(ctx, flow)
+	if err != nil {
+		return nil, answered, fmt.Errorf("buna: draft: failed to get drafts: %w", err)
+	}
+
+	if len(drafts) == 0 {
+		r, err := newDraftRecorder(db, draft{flow: flow})
+		return r, answered, err
+	}
+
+	options := make(map[int]string, len(drafts)+2)
+	for i, d := range drafts {
+		options[i] = "Resume draft last edited " + formatTimestamp(d.updatedAt, true) + ": " + summarizeDraft(d)
+	}
+	newEntry := len(drafts)
+	discardAll := len(drafts) + 1
+	options[newEntry] = "Start a new entry and keep the drafts"
+	options[discardAll] = "Discard the drafts and start a new entry"
+
+	session := sessionFromContext(ctx)
+	fmt.Println("There are unfinished drafts of this entry (Enter " + session.quit + " to quit):")
+	if err := displayIntOptions(ctx, options); err != nil {
+		return nil, answered, fmt.Errorf("buna: draft: failed to display int options: %w", err)
+	}
+
+	selection, quit, err := getIntSelection(ctx, options, quitExits(ctx))
+	if err != nil {
+		return nil, answered, fmt.Errorf("buna: draft: failed to get int selection: %w", err)
+	}
+	if quit != answered {
+		return nil, quit, nil
+	}
+
+	switch selection {
+	case newEntry:
+		r, err := newDraftRecorder(db, draft{flow: flow})
+		return r, answered, err
+	case discardAll:
+		for _, d := range drafts {
+			if err := db.deleteDraft(ctx, d.id); err != nil {
+				return nil, answered, fmt.Errorf("buna: draft: failed to delete draft: %w", err)
+			}
+		}
+
+		r, err := newDraftRecorder(db, draft{flow: flow})
+		return r, answered, err
+	default:
+		r, err := newDraftRecorder(db, drafts[selection])
+		return r, answered, err
+	}
+}
+
+// Returns the first few answers of the draft, e.g. "Brewing date: 2020-03-01 08:15, Coffee: Kiambu (Square Mile)".
+func summarizeDraft(d draft) string {
+	const maxSummaryAnswers = 3
+
+	var answers []draftAnswer
+	if err := json.Unmarshal([]byte(d.answers), &answers); err != nil {
+		return "unreadable answers"
+	}
+
+	var parts []string
+	for i, a := range answers {
+		if i == maxSummaryAnswers {
+			parts = append(parts, fmt.Sprintf("... (%d answers)", len(answers)))
+			break
+		}
+
+		parts = append(parts, a.Key+": "+formatDraftValue(a.Value))
+	}
+
+	return strings.Join(parts, ", ")
+}
+
+// Lists all drafts and lets the user resume or discard one of them.
+func displayDrafts(ctx context.Context, db DB) error {
+	session := sessionFromContext(ctx)
+	fmt.Println("Displaying drafts (Enter " + session.quit + " to quit):")
+
+	drafts, err := db.getDrafts(ctx, "")
+	if err != nil {
+		return fmt.Errorf("buna: draft: failed to get drafts: %w", err)
+	}
+
+	if len(drafts) == 0 {
+		fmt.Println("There are no drafts")
+		return nil
+	}
+
+	t := table.NewWriter()
+
+	t.AppendHeader(table.Row{"#", "Entry", "Started", "Last\nEdited", "Answers"})
+	for i, d := range drafts {
+		t.AppendRow(table.Row{
+			i + 1,
+			draftFlowNames[d.flow],
+			formatTimestamp(d.createdAt, true),
+			formatTimestamp(d.updatedAt, true),
+			summarizeDraft(d),
+		})
+		t.AppendSeparator()
+	}
+
+	terminalWidth, _, err := terminal.GetSize(int(os.Stdin.Fd()))
+	if err != nil {
+		return fmt.Errorf("buna: draft: failed to get terminal width: %w", err)
+	}
+	t.SetAllowedRowLength(terminalWidth)
+
+	t.SetOutputMirror(os.Stdout)
+	renderTable(ctx, t)
+
+	options := map[int]string{
+		0: "Resume a draft",
+		1: "Discard a draft",
+		2: "Discard all drafts",
+	}
+
+	if err := displayIntOptions(ctx, options); err != nil {
+		return fmt.Errorf("buna: draft: failed to display int options: %w", err)
+	}
+
+	selection, quit, err := getIntSelection(ctx, options, quitExits(ctx))
+	if err != nil {
+		return fmt.Errorf("buna: draft: failed to get int selection: %w", err)
+	}
+	if quit != answered {
+		fmt.Println(quitMsg)
+		return nil
+	}
+
+	if selection == 2 {
+		for _, d := range drafts {
+			if err := db.deleteDraft(ctx, d.id); err != nil {
+				return fmt.Errorf("buna: draft: failed to delete draft: %w", err)
+			}
+		}
+
+		fmt.Println("Discarded all drafts successfully")
+		return nil
+	}
+
+	fmt.Print("Enter the # of the draft: ")
+	number, quit := validateIntInput(quitExits(ctx), false, 1, len(drafts), nil)
+	if quit != answered {
+		fmt.Println(quitMsg)
+		return nil
+	}
+	d := drafts[number-1]
+
+	switch selection {
+	case 0:
+		r, err := newDraftRecorder(db, d)
+		if err != nil {
+			return fmt.Errorf("buna: draft: failed to read draft: %w", err)
+		}
+
+		if err := resumeDraft(ctx, db, r); err != nil {
+			return fmt.Errorf("buna: draft: failed to resume draft: %w", err)
+		}
+	case 1:
+		if err := db.deleteDraft(ctx, d.id); err != nil {
+			return fmt.Errorf("buna: draft: failed to delete draft: %w", err)
+		}
+
+		fmt.Println("Discarded draft successfully")
+	default:
+		return errors.New("buna: draft: invalid draft selection")
+	}
+
+	return nil
+}
+
+// Continues the add flow of the draft.
+func resumeDraft(ctx context.Context, db DB, r *draftRecorder) error {
+	switch r.draft.flow {
+	case brewingDraftFlow:
+		return addBrewingFromDraft(ctx, db, r)
+	case cuppingDraftFlow:
+		return addCuppingFromDraft(ctx, db, r)
+	default:
+		return fmt.Errorf("buna: draft: unknown draft flow %q", r.draft.flow)
+	}
+}
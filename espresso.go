@@ -5,64 +5,63 @@ import (
 	"errors"
 	"fmt"
 	"os"
-	"time"
 
 	"github.com/jedib0t/go-pretty/table"
 	"golang.org/x/crypto/ssh/terminal"
 )
 
+// Fields of an espresso, which are the brewing fields with the espresso weight as the water weight
+var espressoFields = brewingFields.
+	with(field{name: "date", key: "Dialing in date", prompt: "Enter dialing in date", help: "Dialing in date with an optional time of day, defaults to now", kind: timestampField, optional: true}).
+	with(field{name: "water_grams", key: "Espresso weight (g)", prompt: "Enter the espresso weight", help: "Espresso weight with an optional unit", kind: quantityField, quantity: waterWeight, min: 10, max: 100}).
+	with(field{name: "notes", key: "Notes", prompt: "Enter some espresso notes", help: "Espresso notes", kind: textField, optional: true})
+
 func addEspressoDialingIn(ctx context.Context, db DB) error {
-	fmt.Println("Adding new espresso dialing in (Enter # to quit):")
-	dialingInDate, quit := getDateInput(quitStr, false, "Enter dialing in ?: ", []date{
-		{year: time.Now().Year(), month: int(time.Now().Month()), day: time.Now().Day()},
-		{year: time.Now().Year(), month: int(time.Now().Month()), day: time.Now().Day() - 1},
-	})
-	if quit {
-		fmt.Println(quitMsg)
-		return nil
-	}
+	session := sessionFromContext(ctx)
+	fmt.Println("Adding new espresso dialing in (Enter " + session.quit + " to quit, " + session.back + " to go back):")
 
-	coffeeName, quit, err := getCoffeeNameWithSuggestions(ctx, db, quitStr, false)
-	if err != nil {
-		return fmt.Errorf("buna: espresso: failed to get coffee name: %w", err)
-	}
-	if quit {
-		fmt.Println(quitMsg)
-		return nil
-	}
+	resumeMsg := "\nContinuing with the new espresso dialing in (Enter " + session.quit + " to quit, " + session.back + " to go back):"
 
-	coffeeRoaster, quit, err := getCoffeeRoasterWithSuggestions(ctx, db, quitStr, coffeeName)
-	if err != nil {
-		return fmt.Errorf("buna: espresso: failed to get coffee roaster: %w", err)
-	}
-	if quit {
-		fmt.Println(quitMsg)
-		return nil
-	}
+	var (
+		dialingInDate     string
+		brewedCoffee      draftCoffee
+		brewingMethodName string
+		roastDate         string
+		grinderName       string
+	)
 
-	brewingMethodName, quit, err := getBrewingMethodNameWithSuggestions(ctx, db, quitStr, false)
-	if err != nil {
-		return fmt.Errorf("buna: espresso: failed to get brewing method name: %w", err)
-	}
-	if quit {
-		fmt.Println(quitMsg)
-		return nil
+	// Answers the suggestions of later steps depend on
+	answers := func() fieldValues {
+		return fieldValues{"coffee": brewedCoffee.Name, "method": brewingMethodName, "grinder": grinderName}
 	}
 
-	roastDate, quit, err := getCoffeeRoastDateWithSuggestions(ctx, db, quitStr, coffeeName)
-	if err != nil {
-		return fmt.Errorf("buna: espresso: failed to get coffee roast date: %w", err)
-	}
-	if quit {
-		fmt.Println(quitMsg)
-		return nil
+	steps := []formStep{
+		espressoFields.step(ctx, db, "date", &dialingInDate, nil),
+		{key: "Coffee", value: &brewedCoffee, prompt: func(exits promptExits) (promptResult, error) {
+			c, quit, err := getExistingCoffeeWithSuggestions(ctx, db, exits, resumeMsg)
+			brewedCoffee = draftCoffee{Name: c.name, Roaster: c.roaster}
+			return quit, err
+		}},
+		{key: "Brewing method", value: &brewingMethodName, prompt: func(exits promptExits) (promptResult, error) {
+			var quit promptResult
+			var err error
+			brewingMethodName, quit, err = getExistingBrewingMethodNameWithSuggestions(ctx, db, exits, resumeMsg)
+			return quit, err
+		}},
+		espressoFields.step(ctx, db, "roast_date", &roastDate, answers),
+		{key: "Grinder", value: &grinderName, prompt: func(exits promptExits) (promptResult, error) {
+			var quit promptResult
+			var err error
+			grinderName, quit, err = getExistingGrinderNameWithSuggestions(ctx, db, exits, resumeMsg)
+			return quit, err
+		}},
 	}
 
-	grinderName, quit, err := getCoffeeGrinderNameWithSuggestions(ctx, db, quitStr, false)
+	quit, err := runForm(ctx, nil, steps)
 	if err != nil {
-		return fmt.Errorf("buna: espresso: failed to get coffee grinder name: %w", err)
+		return fmt.Errorf("buna: espresso: failed to run dialing in form: %w", err)
 	}
-	if quit {
+	if quit != answered {
 		fmt.Println(quitMsg)
 		return nil
 	}
@@ -73,66 +72,63 @@ func addEspressoDialingIn(ctx context.Context, db DB) error {
 	)
 	espressoCount := 1
 	for !finishedDialingIn {
-		fmt.Printf("Entering %v. espresso (Enter # to save the previous espressos and quit):\n", espressoCount)
-
-		grindSetting, quit := getCoffeeGrindSettingWithSuggestions(quitStr)
-		if quit {
-			fmt.Println(quitMsg)
-			return nil
-		}
-
-		totalBrewingTimeSec, quit := getTotalCoffeeBrewingTimeSecWithSuggestions(quitStr)
-		if quit {
-			fmt.Println(quitMsg)
-			return nil
-		}
-
-		coffeeGrams, quit, err := getCoffeeWeightWithSuggestions(ctx, db, quitStr, brewingMethodName, grinderName, false)
+		fmt.Printf("Entering %v. espresso (Enter "+session.quit+" to save the previous espressos and quit, "+session.back+" to go back):\n", espressoCount)
+
+		var (
+			grindSetting                           int
+			totalBrewingTimeSec                    int
+			coffeeGrams                            float64
+			waterGrams                             float64
+			rating                                 int
+			scores                                 brewingScores
+			extraction                             string
+			recommendedGrindSettingAdjustment      string
+			recommendedCoffeeWeightAdjustmentGrams float64
+			notes                                  string
+			flavors                                []string
+		)
+
+		espressoSteps := []formStep{
+			espressoFields.step(ctx, db, "grind_setting", &grindSetting, nil),
+			espressoFields.step(ctx, db, "total_brewing_time_sec", &totalBrewingTimeSec, nil),
+			espressoFields.step(ctx, db, "coffee_grams", &coffeeGrams, answers),
+			espressoFields.step(ctx, db, "water_grams", &waterGrams, nil),
+			espressoFields.step(ctx, db, "rating", &rating, nil),
+			{key: "Detailed scores", value: &scores, prompt: func(exits promptExits) (promptResult, error) {
+				var quit promptResult
+				scores, quit = getBrewingScoresInput(exits)
+				return quit, nil
+			}},
+			espressoFields.step(ctx, db, "extraction", &extraction, nil),
+			{key: "Recommended grind setting adjustment", value: &recommendedGrindSettingAdjustment, prompt: func(exits promptExits) (promptResult, error) {
+				var quit promptResult
+				recommendedGrindSettingAdjustment, quit = getRecommendedGrindSettingAdjustmentWithSuggestions(exits, extraction)
+				return quit, nil
+			}},
+			espressoFields.step(ctx, db, "recommended_coffee_weight_adjustment_grams", &recommendedCoffeeWeightAdjustmentGrams, nil),
+			espressoFields.step(ctx, db, "notes", &notes, nil),
+			{key: "Flavors", value: &flavors, prompt: func(exits promptExits) (promptResult, error) {
+				var quit promptResult
+				flavors, quit = getFlavorsInput(exits, "espresso")
+				return quit, nil
+			}},
+		}
+
+		quit, err := runForm(ctx, nil, espressoSteps)
 		if err != nil {
-			return fmt.Errorf("buna: espresso: failed to get coffee weight: %w", err)
-		}
-		if quit {
-			fmt.Println(quitMsg)
-			return nil
-		}
-
-		fmt.Print("Enter the coffee weight used in grams: ")
-		waterGrams, quit := validateFloatInput(quitStr, false, 10, 100, nil)
-		if quit {
-			fmt.Println(quitMsg)
-			return nil
+			return fmt.Errorf("buna: espresso: failed to run espresso form: %w", err)
 		}
-
-		rating, quit := getCoffeeRatingWithSuggestions(quitStr)
-		if quit {
-			fmt.Println(quitMsg)
-			return nil
-		}
-
-		recommendedGrindSettingAdjustment, quit := getRecommendedGrindSettingAdjustmentWithSuggestions(quitStr)
-		if quit {
-			fmt.Println(quitMsg)
-			return nil
-		}
-
-		recommendedCoffeeWeightAdjustmentGrams, quit := getRecommendedCoffeeWeightAdjustmentGramsWithSuggestions(quitStr)
-		if quit {
-			fmt.Println(quitMsg)
-			return nil
-		}
-
-		notes, quit := getNotes(quitStr, true, "espresso")
-		if quit {
+		if quit != answered {
 			fmt.Println(quitMsg)
 			return nil
 		}
 
 		espresso := brewing{
-			date:                                   createDateString(dialingInDate),
-			coffeeName:                             coffeeName,
-			coffeeRoaster:                          coffeeRoaster,
+			date:                                   dialingInDate,
+			coffeeName:                             brewedCoffee.Name,
+			coffeeRoaster:                          brewedCoffee.Roaster,
 			brewingMethodName:                      brewingMethodName,
-			roastDate:                              createDateString(roastDate),
+			roastDate:                              roastDate,
 			grinderName:                            grinderName,
 			grindSetting:                           grindSetting,
 			totalBrewingTimeSec:                    totalBrewingTimeSec,
@@ -140,9 +136,12 @@ func addEspressoDialingIn(ctx context.Context, db DB) error {
 			waterGrams:                             waterGrams,
 			v60FilterType:                          "",
 			rating:                                 rating,
+			scores:                                 scores,
+			extraction:                             extraction,
 			recommendedGrindSettingAdjustment:      recommendedGrindSettingAdjustment,
 			recommendedCoffeeWeightAdjustmentGrams: recommendedCoffeeWeightAdjustmentGrams,
 			notes:                                  notes,
+			flavors:                                flavors,
 		}
 
 		if err := db.insertBrewing(ctx, espresso); err != nil {
@@ -151,7 +150,7 @@ func addEspressoDialingIn(ctx context.Context, db DB) error {
 		previousEspressos = append(previousEspressos, espresso)
 
 		// Display espresso that was just entered
-		if err := displayPreviousDialingInEspressos([]brewing{espresso}); err != nil {
+		if err := displayPreviousDialingInEspressos(ctx, []brewing{espresso}); err != nil {
 			return fmt.Errorf("buna: espresso: failed to display disaling in espressos that was just entered: %w", err)
 		}
 
@@ -161,15 +160,15 @@ func addEspressoDialingIn(ctx context.Context, db DB) error {
 			2: "Finish dialing in",
 		}
 
-		if err := displayIntOptions(options); err != nil {
+		if err := displayIntOptions(ctx, options); err != nil {
 			return fmt.Errorf("buna: espresso: failed to display int dialing in options: %w", err)
 		}
 
-		selection, quit, err := getIntSelection(options, quitStr)
+		selection, quit, err := getIntSelection(ctx, options, quitExits(ctx))
 		if err != nil {
 			return fmt.Errorf("buna: espresso: failed to get int dialing in selection: %w", err)
 		}
-		if quit {
+		if quit != answered {
 			fmt.Println(quitMsg)
 			return nil
 		}
@@ -178,7 +177,7 @@ func addEspressoDialingIn(ctx context.Context, db DB) error {
 		case 0:
 			continue
 		case 1:
-			if err := displayPreviousDialingInEspressos(previousEspressos); err != nil {
+			if err := displayPreviousDialingInEspressos(ctx, previousEspressos); err != nil {
 				return fmt.Errorf("buna: espresso: failed to display previous disaling in espressos: %w", err)
 			}
 			continue
@@ -193,18 +192,20 @@ func addEspressoDialingIn(ctx context.Context, db DB) error {
 	return nil
 }
 
-func displayPreviousDialingInEspressos(espressos []brewing) error {
+func displayPreviousDialingInEspressos(ctx context.Context, espressos []brewing) error {
 	const maxNoteFieldWidth = 70
 
+	units := sessionFromContext(ctx).unitSystem
 	t := table.NewWriter()
 
 	t.AppendHeader(table.Row{
 		"Grind\nSetting",
 		"Time\n(s)",
-		"Coffee\nWeight\n(g)",
-		"Water\nWeight\n(g)",
+		units.quantityHeader("Coffee\nWeight", coffeeWeight),
+		units.quantityHeader("Water\nWeight", waterWeight),
+		"Ratio",
 		"Recommended\nGrind\nAdjustment",
-		"Recommended\nCoffee\nAdjustment (g)",
+		units.quantityHeader("Recommended\nCoffee\nAdjustment", coffeeWeight),
 		"Notes",
 		"Rating",
 	})
@@ -215,10 +216,11 @@ func displayPreviousDialingInEspressos(espressos []brewing) error {
 		row := table.Row{
 			espresso.grindSetting,
 			espresso.totalBrewingTimeSec,
-			espresso.coffeeGrams,
-			espresso.waterGrams,
+			units.formatQuantity(espresso.coffeeGrams, coffeeWeight),
+			units.formatQuantity(espresso.waterGrams, waterWeight),
+			formatBrewRatio(espresso),
 			espresso.recommendedGrindSettingAdjustment,
-			espresso.recommendedCoffeeWeightAdjustmentGrams,
+			units.formatQuantity(espresso.recommendedCoffeeWeightAdjustmentGrams, coffeeWeight),
 			notes,
 			espresso.rating,
 		}
@@ -234,7 +236,7 @@ func displayPreviousDialingInEspressos(espressos []brewing) error {
 	t.SetAllowedRowLength(terminalWidth)
 
 	t.SetOutputMirror(os.Stdout)
-	t.Render()
+	renderTable(ctx, t)
 
 	return nil
 }
@@ -0,0 +1,549 @@
+package buna
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type fieldKind int
+
+const (
+	textField      fieldKind = iota
+	intField                 // Whole numbers within min and max
+	quantityField            // Values of quantity within min and max, entered in the preferred unit and kept in SI units
+	timestampField           // RFC 3339 timestamps
+	dateField                // Dates in the format "YYYY-MM-DD"
+)
+
+// A value of an entity that is entered in its add flow and, for brewings and espressos, on the command line or as JSON.
+// The field is declared once and drives the prompt, the flag and the validation of the value.
+type field struct {
+	name     string // Name of the JSON key, with dashes instead of underscores also the name of the flag, e.g. "coffee_grams"
+	key      string // Name of the answer in forms and drafts, e.g. "Coffee weight (g)"
+	prompt   string // Asks for the value, e.g. "Enter the coffee weight used"
+	help     string // Describes the value in the usage of commands
+	kind     fieldKind
+	optional bool // Optional values default to the zero value of their kind
+
+	min      float64  // Lower bound of int and quantity fields, quantities in SI units
+	max      float64  // Upper bound of int and quantity fields, quantities in SI units
+	quantity quantity // Quantity of quantity fields
+	options  []string // Accepted values of text fields, any value is accepted if empty
+
+	defaultSetting string // Config setting whose value is used when a required text field is left empty, may be empty
+
+	// Maps inputs of text fields to the value, e.g. aliases, may be nil.
+	// Returns value, ok
+	normalize func(input string) (string, bool)
+	// Returns suggestions given the values entered so far, which are []string for text fields, []int for int fields,
+	// []float64 in SI units for quantity fields and []date for date and timestamp fields. May be nil.
+	suggest func(ctx context.Context, db DB, values fieldValues) (interface{}, error)
+}
+
+// Values of fields by field name. Text, timestamp and date values are strings, int values ints and quantity values float64s in SI units.
+type fieldValues map[string]interface{}
+
+// Returns the value of the field with name, or the zero value if it was not given.
+func (v fieldValues) textValue(name string) string {
+	s, _ := v[name].(string)
+	return s
+}
+
+func (v fieldValues) intValue(name string) int {
+	i, _ := v[name].(int)
+	return i
+}
+
+func (v fieldValues) quantityValue(name string) float64 {
+	f, _ := v[name].(float64)
+	return f
+}
+
+func (f field) flagName() string {
+	return strings.ReplaceAll(f.name, "_", "-")
+}
+
+func (f field) zero() interface{} {
+	switch f.kind {
+	case intField:
+		return 0
+	case quantityField:
+		return 0.0
+	default:
+		return ""
+	}
+}
+
+// Returns the value of the field's default setting, or an empty string if there is none.
+func (f field) defaultValue(ctx context.Context) string {
+	if f.defaultSetting == "" {
+		return ""
+	}
+
+	return sessionFromContext(ctx).config.text(f.defaultSetting)
+}
+
+// Returns the bounds of int and quantity fields for display, e.g. "5 g <= x <= 100 g".
+func (f field) bounds(ctx context.Context) string {
+	units := sessionFromContext(ctx).unitSystem
+	switch f.kind {
+	case intField:
+		return fmt.Sprintf("%v <= x <= %v", f.min, f.max)
+	case quantityField:
+		return fmt.Sprintf("%v <= x <= %v", units.formatQuantityWithUnit(f.min, f.quantity), units.formatQuantityWithUnit(f.max, f.quantity))
+	case textField:
+		return strings.Join(f.options, ", ")
+	default:
+		return ""
+	}
+}
+
+// Checks that the value is of the kind of the field and within its bounds and options.
+// The zero value is only valid for optional fields.
+func (f field) validate(ctx context.Context, value interface{}) error {
+	if value == f.zero() {
+		if !f.optional {
+			return fmt.Errorf("buna: field: %v is required", f.name)
+		}
+		return nil
+	}
+
+	switch f.kind {
+	case intField:
+		i, ok := value.(int)
+		if !ok {
+			return fmt.Errorf("buna: field: %v must be a whole number", f.name)
+		}
+		if float64(i) < f.min || float64(i) > f.max {
+			return fmt.Errorf("buna: field: %v must be in the range %v", f.name, f.bounds(ctx))
+		}
+	case quantityField:
+		q, ok := value.(float64)
+		if !ok {
+			return fmt.Errorf("buna: field: %v must be a number", f.name)
+		}
+		if q < f.min || q > f.max {
+			return fmt.Errorf("buna: field: %v must be in the range %v", f.name, f.bounds(ctx))
+		}
+	case textField:
+		s, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("buna: field: %v must be a string", f.name)
+		}
+		if len(f.options) > 0 && !containsString(f.options, s) {
+			return fmt.Errorf("buna: field: %v must be one of %v", f.name, f.bounds(ctx))
+		}
+	case timestampField:
+		s, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("buna: field: %v must be a string", f.name)
+		}
+		if _, err := time.Parse(time.RFC3339, s); err != nil {
+			return fmt.Errorf("buna: field: %v must be an RFC 3339 timestamp: %w", f.name, err)
+		}
+	case dateField:
+		s, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("buna: field: %v must be a string", f.name)
+		}
+		if _, err := time.Parse("2006-01-02", s); err != nil {
+			return fmt.Errorf("buna: field: %v must be a date (YYYY-MM-DD): %w", f.name, err)
+		}
+	default:
+		return fmt.Errorf("buna: field: unknown kind of %v", f.name)
+	}
+
+	return nil
+}
+
+// Parses a value of the field from the command line and validates it.
+// Quantities are in the preferred unit unless a unit suffix is given. Dates and timestamps accept the inputs
+// of date prompts, e.g. "yesterday", timestamps optionally followed by a time of day, e.g. "2020-03-05 08:15".
+func (f field) parse(ctx context.Context, input string) (interface{}, error) {
+	units := sessionFromContext(ctx).unitSystem
+	input = strings.TrimSpace(input)
+	if input == "" {
+		return f.zero(), f.validate(ctx, f.zero())
+	}
+
+	var value interface{}
+	switch f.kind {
+	case intField:
+		i, err := strconv.Atoi(input)
+		if err != nil {
+			return nil, fmt.Errorf("buna: field: %v must be a whole number", f.name)
+		}
+		value = i
+	case quantityField:
+		q, ok := units.parseQuantity(input, f.quantity)
+		if !ok {
+			return nil, fmt.Errorf("buna: field: %v must be a number with an optional unit, e.g. 15 or 0.5oz", f.name)
+		}
+		value = q
+	case textField:
+		value = input
+		if f.normalize != nil {
+			s, ok := f.normalize(input)
+			if !ok {
+				return nil, fmt.Errorf("buna: field: %q is not a valid %v", input, f.name)
+			}
+			value = s
+		}
+	case timestampField:
+		timestamp, ok := parseTimestamp(input, time.Now())
+		if !ok {
+			return nil, fmt.Errorf("buna: field: %v must be a date with an optional time of day, e.g. 2020-03-05 08:15", f.name)
+		}
+		value = timestamp
+	case dateField:
+		d, ok := parseDateInput(input, time.Now())
+		if !ok {
+			return nil, fmt.Errorf("buna: field: %v must be a date, e.g. 2020-03-05", f.name)
+		}
+		value = createDateString(d)
+	default:
+		return nil, fmt.Errorf("buna: field: unknown kind of %v", f.name)
+	}
+
+	if err := f.validate(ctx, value); err != nil {
+		return nil, err
+	}
+
+	return value, nil
+}
+
+// Decodes a value of the field from JSON and validates it.
+// Numbers are taken as is, quantities in SI units. Strings are parsed like command line values.
+func (f field) decode(ctx context.Context, raw json.RawMessage) (interface{}, error) {
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		return f.parse(ctx, s)
+	}
+
+	var value interface{}
+	switch f.kind {
+	case intField:
+		var i int
+		if err := json.Unmarshal(raw, &i); err != nil {
+			return nil, fmt.Errorf("buna: field: %v must be a whole number: %w", f.name, err)
+		}
+		value = i
+	case quantityField:
+		var q float64
+		if err := json.Unmarshal(raw, &q); err != nil {
+			return nil, fmt.Errorf("buna: field: %v must be a number: %w", f.name, err)
+		}
+		value = q
+	default:
+		return nil, fmt.Errorf("buna: field: %v must be a string", f.name)
+	}
+
+	if err := f.validate(ctx, value); err != nil {
+		return nil, err
+	}
+
+	return value, nil
+}
+
+// Prompts the user for a value of the field until a valid one is entered.
+// Returns value, promptResult, error
+func (f field) ask(ctx context.Context, db DB, exits promptExits, values fieldValues) (interface{}, promptResult, error) {
+	var suggestions interface{}
+	if f.suggest != nil {
+		var err error
+		suggestions, err = f.suggest(ctx, db, values)
+		if err != nil {
+			return nil, answered, fmt.Errorf("buna: field: failed to get %v suggestions: %w", f.name, err)
+		}
+	}
+
+	switch f.kind {
+	case intField:
+		fmt.Printf("%v (%v): ", f.prompt, f.bounds(ctx))
+		intSuggestions, _ := suggestions.([]int)
+		i, quit := validateIntInput(exits, f.optional, int(f.min), int(f.max), intSuggestions)
+		return i, quit, nil
+	case quantityField:
+		fmt.Printf("%v (%v): ", f.prompt, f.bounds(ctx))
+		quantitySuggestions, _ := suggestions.([]float64)
+		q, quit := validateQuantityInput(exits, sessionFromContext(ctx).unitSystem, f.optional, f.quantity, f.min, f.max, quantitySuggestions)
+		return q, quit, nil
+	case textField:
+		defaultValue := ""
+		if !f.optional {
+			defaultValue = f.defaultValue(ctx)
+		}
+
+		if defaultValue != "" {
+			fmt.Printf("%v (Leave empty for %v): ", f.prompt, defaultValue)
+		} else {
+			fmt.Print(f.prompt + ": ")
+		}
+		textSuggestions, _ := suggestions.([]string)
+		input, quit := validateStrInput(exits, f.optional || defaultValue != "", f.options, textSuggestions)
+		if quit == answered && input == "" && defaultValue != "" {
+			return defaultValue, answered, nil
+		}
+		for f.normalize != nil && quit == answered && input != "" {
+			if s, ok := f.normalize(input); ok {
+				return s, answered, nil
+			}
+
+			fmt.Printf("Not a valid %v. Please try again: ", strings.ToLower(f.key))
+			input, quit = validateStrInput(exits, f.optional, f.options, nil)
+		}
+		return input, quit, nil
+	case timestampField:
+		dateSuggestions, ok := suggestions.([]date)
+		if !ok {
+			dateSuggestions = recentDateSuggestions()
+		}
+		timestamp, quit := getTimestampInput(exits, f.prompt+": ", dateSuggestions)
+		return timestamp, quit, nil
+	case dateField:
+		dateSuggestions, _ := suggestions.([]date)
+		d, quit := getDateInput(exits, f.optional, f.prompt+": ", dateSuggestions)
+		if d.year == 0 {
+			return f.zero(), quit, nil
+		}
+		return createDateString(d), quit, nil
+	default:
+		return nil, answered, fmt.Errorf("buna: field: unknown kind of %v", f.name)
+	}
+}
+
+// Returns ask for text, timestamp and date fields with the value as a string.
+func (f field) askText(ctx context.Context, db DB, exits promptExits, values fieldValues) (string, promptResult, error) {
+	value, quit, err := f.ask(ctx, db, exits, values)
+	s, _ := value.(string)
+	return s, quit, err
+}
+
+func (f field) askInt(ctx context.Context, db DB, exits promptExits, values fieldValues) (int, promptResult, error) {
+	value, quit, err := f.ask(ctx, db, exits, values)
+	i, _ := value.(int)
+	return i, quit, err
+}
+
+func (f field) askQuantity(ctx context.Context, db DB, exits promptExits, values fieldValues) (float64, promptResult, error) {
+	value, quit, err := f.ask(ctx, db, exits, values)
+	q, _ := value.(float64)
+	return q, quit, err
+}
+
+// Returns the form step asking for the field, which stores the answer in value.
+// value must be a *string for text, timestamp and date fields, an *int for int fields and a *float64 for quantity fields.
+// values returns the answers the suggestions depend on and may be nil.
+func (f field) step(ctx context.Context, db DB, value interface{}, values func() fieldValues) formStep {
+	return formStep{key: f.key, value: value, prompt: func(exits promptExits) (promptResult, error) {
+		var answers fieldValues
+		if values != nil {
+			answers = values()
+		}
+
+		answer, quit, err := f.ask(ctx, db, exits, answers)
+		if err != nil || quit != answered {
+			return quit, err
+		}
+
+		switch v := value.(type) {
+		case *string:
+			*v, _ = answer.(string)
+		case *int:
+			*v, _ = answer.(int)
+		case *float64:
+			*v, _ = answer.(float64)
+		default:
+			return answered, fmt.Errorf("buna: field: unsupported value type %T of %v", value, f.name)
+		}
+
+		return answered, nil
+	}}
+}
+
+// The fields of an entity
+type fieldSet []field
+
+// Returns the field with name, ok
+func (s fieldSet) field(name string) (field, bool) {
+	for _, f := range s {
+		if f.name == name {
+			return f, true
+		}
+	}
+
+	return field{}, false
+}
+
+func unknownFieldError(name string) error {
+	return fmt.Errorf("buna: field: unknown field %q", name)
+}
+
+// Returns the form step asking for the field with name, see field.step.
+// The step of a field that is not declared fails with an error once it is asked for.
+func (s fieldSet) step(ctx context.Context, db DB, name string, value interface{}, values func() fieldValues) formStep {
+	f, ok := s.field(name)
+	if !ok {
+		return formStep{key: name, value: value, prompt: func(promptExits) (promptResult, error) {
+			return answered, unknownFieldError(name)
+		}}
+	}
+
+	return f.step(ctx, db, value, values)
+}
+
+// Returns a copy of the set with the field of the same name replaced by f.
+func (s fieldSet) with(f field) fieldSet {
+	fields := make(fieldSet, len(s))
+	copy(fields, s)
+	for i := range fields {
+		if fields[i].name == f.name {
+			fields[i] = f
+		}
+	}
+
+	return fields
+}
+
+// Defines a string flag for each field on flags.
+// Returns the flag values by field name, which are passed to parseFlags once flags are parsed.
+func (s fieldSet) defineFlags(ctx context.Context, flags *flag.FlagSet) map[string]*string {
+	inputs := make(map[string]*string, len(s))
+	for _, f := range s {
+		usage := f.help
+		if bounds := f.bounds(ctx); bounds != "" {
+			usage += " (" + bounds + ")"
+		}
+		if defaultValue := f.defaultValue(ctx); defaultValue != "" {
+			usage += ", defaults to " + defaultValue
+		} else if !f.optional {
+			usage += ", required"
+		}
+
+		inputs[f.name] = flags.String(f.flagName(), "", usage)
+	}
+
+	return inputs
+}
+
+// Parses the fields that were set on flags. The values of the fields that were not set are taken from values,
+// which may be nil, e.g. the values decoded from JSON. Missing optional fields default to their zero value.
+func (s fieldSet) parseFlags(ctx context.Context, flags *flag.FlagSet, inputs map[string]*string, values fieldValues) (fieldValues, error) {
+	if values == nil {
+		values = fieldValues{}
+	}
+
+	var err error
+	flags.Visit(func(fl *flag.Flag) {
+		for _, f := range s {
+			if err != nil || f.flagName() != fl.Name {
+				continue
+			}
+
+			values[f.name], err = f.parse(ctx, *inputs[f.name])
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.complete(ctx, values); err != nil {
+		return nil, err
+	}
+
+	return values, nil
+}
+
+// Decodes a JSON object of field values, e.g. {"grind_setting": 18, "coffee_grams": "15g"}.
+// Unknown keys are rejected. Missing fields are left out, so that they can still be given otherwise, see complete.
+func (s fieldSet) decodeJSON(ctx context.Context, data []byte) (fieldValues, error) {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("buna: field: failed to decode JSON object: %w", err)
+	}
+
+	names := make([]string, 0, len(raw))
+	for name := range raw {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	values := make(fieldValues, len(s))
+	for _, name := range names {
+		f, ok := s.field(name)
+		if !ok {
+			return nil, unknownFieldError(name)
+		}
+
+		value, err := f.decode(ctx, raw[name])
+		if err != nil {
+			return nil, err
+		}
+		values[name] = value
+	}
+
+	return values, nil
+}
+
+// Sets missing fields to the value of their default setting and missing optional fields to their zero value.
+// Returns an error naming all missing required fields.
+func (s fieldSet) complete(ctx context.Context, values fieldValues) error {
+	var missing []string
+	for _, f := range s {
+		if _, ok := values[f.name]; ok {
+			continue
+		}
+		if defaultValue := f.defaultValue(ctx); defaultValue != "" {
+			values[f.name] = defaultValue
+			continue
+		}
+		if !f.optional {
+			missing = append(missing, f.name)
+			continue
+		}
+
+		values[f.name] = f.zero()
+	}
+
+	if len(missing) > 0 {
+		return errors.New("buna: field: missing required fields " + strings.Join(missing, ", "))
+	}
+
+	return nil
+}
+
+// Parses a date input of getDateInput optionally followed by a time of day, or an RFC 3339 timestamp.
+// The time of day defaults to the time of now.
+// Returns the timestamp in RFC 3339 format in the local time zone, ok
+func parseTimestamp(input string, now time.Time) (string, bool) {
+	if t, err := time.Parse(time.RFC3339, input); err == nil {
+		return t.Format(time.RFC3339), true
+	}
+
+	datePart, timePart := input, ""
+	if i := strings.LastIndex(input, " "); i >= 0 {
+		if _, _, ok := parseTimeOfDayInput(input[i+1:]); ok {
+			datePart, timePart = strings.TrimSpace(input[:i]), input[i+1:]
+		}
+	}
+
+	d, ok := parseDateInput(datePart, now)
+	if !ok {
+		return "", false
+	}
+
+	hour, minute, second := now.Hour(), now.Minute(), now.Second()
+	if timePart != "" {
+		hour, minute, _ = parseTimeOfDayInput(timePart)
+		second = 0
+	}
+
+	return time.Date(d.year, time.Month(d.month), d.day, hour, minute, second, 0, time.Local).Format(time.RFC3339), true
+}
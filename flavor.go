@@ -0,0 +1,420 @@
+package buna
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/jedib0t/go-pretty/table"
+	"golang.org/x/crypto/ssh/terminal"
+)
+
+type flavorNode struct {
+	name     string
+	children []flavorNode
+}
+
+// Number of occurrences of a flavor tag.
+// group is the origin or process the count belongs to, if any.
+type flavorCount struct {
+	group  string
+	flavor string
+	count  int
+}
+
+// flavorWheel is the hierarchy of the SCA coffee taster's flavor wheel.
+// Intermediate levels that repeat the name of their category are flattened into the category,
+// so every flavor name is unique.
+var flavorWheel = []flavorNode{
+	{"fruity", []flavorNode{
+		{"berry", []flavorNode{{"blackberry", nil}, {"raspberry", nil}, {"blueberry", nil}, {"strawberry", nil}}},
+		{"dried fruit", []flavorNode{{"raisin", nil}, {"prune", nil}}},
+		{"other fruit", []flavorNode{{"coconut", nil}, {"cherry", nil}, {"pomegranate", nil}, {"pineapple", nil}, {"grape", nil}, {"apple", nil}, {"peach", nil}, {"pear", nil}}},
+		{"citrus fruit", []flavorNode{{"grapefruit", nil}, {"orange", nil}, {"lemon", nil}, {"lime", nil}}},
+	}},
+	{"sour/fermented", []flavorNode{
+		{"sour", []flavorNode{{"sour aromatics", nil}, {"acetic acid", nil}, {"butyric acid", nil}, {"isovaleric acid", nil}, {"citric acid", nil}, {"malic acid", nil}}},
+		{"alcohol/fermented", []flavorNode{{"winey", nil}, {"whiskey", nil}, {"fermented", nil}, {"overripe", nil}}},
+	}},
+	{"green/vegetative", []flavorNode{
+		{"olive oil", nil}, {"raw", nil}, {"under-ripe", nil}, {"peapod", nil}, {"fresh", nil}, {"dark green", nil}, {"vegetative", nil}, {"hay-like", nil}, {"herb-like", nil}, {"beany", nil},
+	}},
+	{"other", []flavorNode{
+		{"papery/musty", []flavorNode{{"stale", nil}, {"cardboard", nil}, {"papery", nil}, {"woody", nil}, {"moldy/damp", nil}, {"musty/dusty", nil}, {"musty/earthy", nil}, {"animalic", nil}, {"meaty brothy", nil}, {"phenolic", nil}}},
+		{"chemical", []flavorNode{{"bitter", nil}, {"salty", nil}, {"medicinal", nil}, {"petroleum", nil}, {"skunky", nil}, {"rubber", nil}}},
+	}},
+	{"roasted", []flavorNode{
+		{"pipe tobacco", nil},
+		{"tobacco", nil},
+		{"burnt", []flavorNode{{"acrid", nil}, {"ashy", nil}, {"smoky", nil}, {"brown roast", nil}}},
+		{"cereal", []flavorNode{{"grain", nil}, {"malt", nil}}},
+	}},
+	{"spices", []flavorNode{
+		{"pungent", nil},
+		{"pepper", nil},
+		{"brown spice", []flavorNode{{"anise", nil}, {"nutmeg", nil}, {"cinnamon", nil}, {"clove", nil}}},
+	}},
+	{"nutty/cocoa", []flavorNode{
+		{"nutty", []flavorNode{{"peanuts", nil}, {"hazelnut", nil}, {"almond", nil}}},
+		{"cocoa", []flavorNode{{"chocolate", nil}, {"dark chocolate", nil}}},
+	}},
+	{"sweet", []flavorNode{
+		{"brown sugar", []flavorNode{{"molasses", nil}, {"maple syrup", nil}, {"caramelized", nil}, {"honey", nil}}},
+		{"vanilla", nil},
+		{"vanillin", nil},
+		{"overall sweet", nil},
+		{"sweet aromatics", nil},
+	}},
+	{"floral", []flavorNode{
+		{"black tea", nil}, {"chamomile", nil}, {"rose", nil}, {"jasmine", nil},
+	}},
+}
+
+// Maps every flavor name to the name of its parent, or "" for the categories of the flavor wheel.
+// Flavor names are listed in flavorNames in the order of the flavor wheel.
+var (
+	flavorParents = map[string]string{}
+	flavorNames   []string
+)
+
+func init() {
+	var walk func(nodes []flavorNode, parent string)
+	walk = func(nodes []flavorNode, parent string) {
+		for _, node := range nodes {
+			flavorParents[node.name] = parent
+			flavorNames = append(flavorNames, node.name)
+			walk(node.children, node.name)
+		}
+	}
+	walk(flavorWheel, "")
+}
+
+// Returns the position of the flavor in the flavor wheel, e.g. "fruity > berry > blueberry".
+func flavorPath(flavor string) string {
+	path := []string{flavor}
+	for parent := flavorParents[flavor]; parent != ""; parent = flavorParents[parent] {
+		path = append([]string{parent}, path...)
+	}
+	return strings.Join(path, " > ")
+}
+
+// Returns the category of the flavor wheel the flavor belongs to.
+func flavorCategory(flavor string) string {
+	for flavorParents[flavor] != "" {
+		flavor = flavorParents[flavor]
+	}
+	return flavor
+}
+
+// Returns the flavors of the flavor wheel starting with the input or containing a word starting with it.
+// An exact match is returned on its own.
+func matchFlavors(input string) []string {
+	input = strings.ToLower(strings.TrimSpace(input))
+	if input == "" {
+		return nil
+	}
+
+	if _, ok := flavorParents[input]; ok {
+		return []string{input}
+	}
+
+	isSeparator := func(r rune) bool {
+		return r == ' ' || r == '/' || r == '-'
+	}
+
+	var matches []string
+	for _, name := range flavorNames {
+		for _, word := range append([]string{name}, strings.FieldsFunc(name, isSeparator)...) {
+			if strings.HasPrefix(word, input) {
+				matches = append(matches, name)
+				break
+			}
+		}
+	}
+
+	return matches
+}
+
+// Prompts the user for flavor tags one at a time until an empty line is entered.
+// Partial input is completed using the flavor wheel.
+// Returns flavors, promptResult
+func getFlavorsInput(exits promptExits, tagType string) ([]string, promptResult) {
+	const maxMatchesShown = 10
+
+	fmt.Println("Enter " + tagType + " flavors one at a time (Enter an empty line when done, ? to show the flavor wheel):")
+
+	var flavors []string
+	scanner := exits.input
+	for {
+		fmt.Print("Flavor: ")
+		scanner.Scan()
+		input := strings.TrimSpace(scanner.Text())
+
+		if result := exitResult(exits, input); result != answered {
+			return nil, result
+		}
+
+		switch input {
+		case "":
+			return flavors, answered
+		case "?":
+			displayFlavorWheel()
+			continue
+		}
+
+		matches := matchFlavors(input)
+		var flavor string
+		switch {
+		case len(matches) == 0:
+			fmt.Println("Unknown flavor. Try a more general term or enter ? to show the flavor wheel.")
+			continue
+		case len(matches) == 1:
+			flavor = matches[0]
+		default:
+			if len(matches) > maxMatchesShown {
+				matches = matches[:maxMatchesShown]
+			}
+
+			var quit promptResult
+			flavor, quit = validateStrInput(exits, true, matches, nil)
+			if quit != answered {
+				return nil, quit
+			}
+			if flavor == "" {
+				continue
+			}
+		}
+
+		isDuplicate := false
+		for _, f := range flavors {
+			if f == flavor {
+				isDuplicate = true
+				break
+			}
+		}
+		if isDuplicate {
+			fmt.Println("Already added " + flavor)
+			continue
+		}
+
+		flavors = append(flavors, flavor)
+		fmt.Println("Added " + flavorPath(flavor))
+	}
+}
+
+// Prints the categories of the flavor wheel with their flavors.
+func displayFlavorWheel() {
+	for _, category := range flavorWheel {
+		var children []string
+		for _, child := range category.children {
+			children = append(children, child.name)
+		}
+		fmt.Printf("%v: %v\n", category.name, strings.Join(children, ", "))
+	}
+}
+
+func retrieveFlavorStatistics(ctx context.Context, db DB) error {
+	options := map[int]string{
+		0: "Flavor profile of a coffee",
+		1: "Most common flavors by origin",
+		2: "Most common flavors by processing method",
+	}
+
+	session := sessionFromContext(ctx)
+	fmt.Println("Retrieving flavor statistics (Enter " + session.quit + " to quit):")
+	if err := displayIntOptions(ctx, options); err != nil {
+		return fmt.Errorf("buna: flavor: failed to display int options: %w", err)
+	}
+
+	selection, quit, err := getIntSelection(ctx, options, quitExits(ctx))
+	if err != nil {
+		return fmt.Errorf("buna: flavor: failed to get int selection: %w", err)
+	}
+	if quit != answered {
+		fmt.Println(quitMsg)
+		return nil
+	}
+
+	if err := runRetrieveFlavorStatisticsSelection(ctx, selection, db); err != nil {
+		return fmt.Errorf("buna: flavor: failed to run the retrieve selection: %w", err)
+	}
+
+	return nil
+}
+
+func runRetrieveFlavorStatisticsSelection(ctx context.Context, selection int, db DB) error {
+	switch selection {
+	case 0:
+		if err := displayCoffeeFlavorProfile(ctx, db); err != nil {
+			return fmt.Errorf("buna: flavor: failed to display coffee flavor profile: %w", err)
+		}
+	case 1:
+		if err := displayFlavorsByOrigin(ctx, db); err != nil {
+			return fmt.Errorf("buna: flavor: failed to display flavors by origin: %w", err)
+		}
+	case 2:
+		if err := displayFlavorsByProcess(ctx, db); err != nil {
+			return fmt.Errorf("buna: flavor: failed to display flavors by process: %w", err)
+		}
+	default:
+		return errors.New("buna: flavor: invalid retrieve selection")
+	}
+	return nil
+}
+
+// Prompts user for a coffee and displays its flavor tags aggregated across all brewings and cuppings.
+func displayCoffeeFlavorProfile(ctx context.Context, db DB) error {
+	session := sessionFromContext(ctx)
+	fmt.Println("Displaying coffee flavor profile (Enter " + session.quit + " to quit):")
+	coffeeName, quit, err := getCoffeeNameWithSuggestions(ctx, db, quitExits(ctx), false)
+	if err != nil {
+		return fmt.Errorf("buna: flavor: failed to get coffee name: %w", err)
+	}
+	if quit != answered {
+		fmt.Println(quitMsg)
+		return nil
+	}
+
+	coffeeRoaster, quit, err := getCoffeeRoasterWithSuggestions(ctx, db, quitExits(ctx), coffeeName)
+	if err != nil {
+		return fmt.Errorf("buna: flavor: failed to get coffee roaster: %w", err)
+	}
+	if quit != answered {
+		fmt.Println(quitMsg)
+		return nil
+	}
+
+	flavorCounts, err := db.getCoffeeFlavorCounts(ctx, coffeeName, coffeeRoaster)
+	if err != nil {
+		return fmt.Errorf("buna: flavor: failed to get coffee flavor counts: %w", err)
+	}
+
+	if len(flavorCounts) == 0 {
+		fmt.Println("No flavors have been tagged for this coffee")
+		return nil
+	}
+
+	total := 0
+	categoryCounts := map[string]int{}
+	for _, flavorCount := range flavorCounts {
+		total += flavorCount.count
+		categoryCounts[flavorCategory(flavorCount.flavor)] += flavorCount.count
+	}
+
+	categories := make([]string, 0, len(categoryCounts))
+	for category := range categoryCounts {
+		categories = append(categories, category)
+	}
+	sort.Slice(categories, func(i, j int) bool {
+		if categoryCounts[categories[i]] != categoryCounts[categories[j]] {
+			return categoryCounts[categories[i]] > categoryCounts[categories[j]]
+		}
+		return categories[i] < categories[j]
+	})
+
+	categoryTable := table.NewWriter()
+	categoryTable.AppendHeader(table.Row{"Category", "Tags", "Share"})
+	for _, category := range categories {
+		categoryTable.AppendRow(table.Row{
+			category,
+			categoryCounts[category],
+			fmt.Sprintf("%.0f%%", float64(categoryCounts[category])/float64(total)*100),
+		})
+	}
+
+	flavorTable := table.NewWriter()
+	flavorTable.AppendHeader(table.Row{"Flavor", "Tags"})
+	for _, flavorCount := range flavorCounts {
+		flavorTable.AppendRow(table.Row{flavorPath(flavorCount.flavor), flavorCount.count})
+	}
+
+	terminalWidth, _, err := terminal.GetSize(int(os.Stdin.Fd()))
+	if err != nil {
+		return fmt.Errorf("buna: flavor: failed to get terminal width: %w", err)
+	}
+
+	for _, t := range []table.Writer{categoryTable, flavorTable} {
+		t.SetAllowedRowLength(terminalWidth)
+		t.SetOutputMirror(os.Stdout)
+		renderTable(ctx, t)
+	}
+
+	return nil
+}
+
+func displayFlavorsByOrigin(ctx context.Context, db DB) error {
+	const flavorsPerOrigin = 5
+
+	flavorCounts, err := db.getFlavorCountsByOrigin(ctx, flavorsPerOrigin)
+	if err != nil {
+		return fmt.Errorf("buna: flavor: failed to get flavor counts by origin: %w", err)
+	}
+
+	for i := range flavorCounts {
+		flavorCounts[i].group = countryName(flavorCounts[i].group)
+	}
+
+	if err := displayGroupedFlavorCounts(ctx, flavorCounts, "Origin"); err != nil {
+		return fmt.Errorf("buna: flavor: failed to display grouped flavor counts: %w", err)
+	}
+
+	return nil
+}
+
+func displayFlavorsByProcess(ctx context.Context, db DB) error {
+	const flavorsPerProcess = 5
+
+	flavorCounts, err := db.getFlavorCountsByProcess(ctx, flavorsPerProcess)
+	if err != nil {
+		return fmt.Errorf("buna: flavor: failed to get flavor counts by process: %w", err)
+	}
+
+	if err := displayGroupedFlavorCounts(ctx, flavorCounts, "Processing method"); err != nil {
+		return fmt.Errorf("buna: flavor: failed to display grouped flavor counts: %w", err)
+	}
+
+	return nil
+}
+
+// flavorCounts must be ordered by group.
+func displayGroupedFlavorCounts(ctx context.Context, flavorCounts []flavorCount, groupHeader string) error {
+	if len(flavorCounts) == 0 {
+		fmt.Println("No flavors have been tagged yet")
+		return nil
+	}
+
+	t := table.NewWriter()
+	t.AppendHeader(table.Row{groupHeader, "Most common flavors"})
+
+	var group string
+	var flavors []string
+	appendGroup := func() {
+		if len(flavors) > 0 {
+			t.AppendRow(table.Row{group, strings.Join(flavors, "\n")})
+			t.AppendSeparator()
+		}
+	}
+	for _, flavorCount := range flavorCounts {
+		if flavorCount.group != group {
+			appendGroup()
+			group = flavorCount.group
+			flavors = nil
+		}
+		flavors = append(flavors, fmt.Sprintf("%v (%d)", flavorCount.flavor, flavorCount.count))
+	}
+	appendGroup()
+
+	terminalWidth, _, err := terminal.GetSize(int(os.Stdin.Fd()))
+	if err != nil {
+		return fmt.Errorf("buna: flavor: failed to get terminal width: %w", err)
+	}
+	t.SetAllowedRowLength(terminalWidth)
+
+	t.SetOutputMirror(os.Stdout)
+	renderTable(ctx, t)
+
+	return nil
+}
@@ -0,0 +1,124 @@
+package buna
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// A prompt of an add flow, which is run by runForm.
+type formStep struct {
+	key       string                                  // Name of the answer, used in drafts and when the answer is shown
+	value     interface{}                             // Pointer to the answer
+	prompt    func(promptExits) (promptResult, error) // Asks for the answer and stores it in value. Returns how the prompt was left, error
+	skip      func() bool                             // Whether the step does not apply given the previous answers, may be nil
+	check     func() (bool, error)                    // Whether an answer restored from a draft is still valid, e.g. whether its coffee still exists, may be nil
+	prefilled bool                                    // Whether value already holds the answer, which is then only shown
+}
+
+// Runs the steps in order.
+// Steps are pre-filled if they are marked as prefilled or their answer is in the draft of r, in which case the answer is shown instead of asked for.
+// Answers from the draft that fail the check of their step are asked for again.
+// The prompts of the steps can be left with the back input of the session, which goes back to the previous step that was not skipped.
+// That step is asked again even if it was pre-filled.
+// Going back from the first step leaves the form with backPrompt, e.g. to go back in an enclosing form.
+// r may be nil if the answers are not kept as a draft.
+// Returns how the form was left, answered if all steps were answered, error
+func runForm(ctx context.Context, r *draftRecorder, steps []formStep) (promptResult, error) {
+	prefilled := make([]bool, len(steps))
+	for i, step := range steps {
+		prefilled[i] = step.prefilled
+	}
+
+	session := sessionFromContext(ctx)
+	exits := promptExits{quit: session.quit, back: session.back, input: session.input}
+
+	// Indices of the answered steps, which are gone back to in reverse order
+	var answeredSteps []int
+	for i := 0; i < len(steps); {
+		step := steps[i]
+		if step.skip != nil && step.skip() {
+			i++
+			continue
+		}
+
+		if !prefilled[i] && r != nil {
+			ok, err := r.restore(step.key, step.value)
+			if err != nil {
+				return answered, fmt.Errorf("buna: form: failed to restore %v: %w", strings.ToLower(step.key), err)
+			}
+
+			if ok && step.check != nil {
+				valid, err := step.check()
+				if err != nil {
+					return answered, fmt.Errorf("buna: form: failed to check restored %v: %w", strings.ToLower(step.key), err)
+				}
+				if !valid {
+					fmt.Printf("%v of the draft no longer exists: %v\n", step.key, formatFormValue(step.value))
+					ok = false
+				}
+			}
+			prefilled[i] = ok
+		}
+
+		if prefilled[i] {
+			fmt.Printf("%v: %v\n", step.key, formatFormValue(step.value))
+			answeredSteps = append(answeredSteps, i)
+			i++
+			continue
+		}
+
+		result, err := step.prompt(exits)
+		if err != nil {
+			return answered, fmt.Errorf("buna: form: failed to get %v: %w", strings.ToLower(step.key), err)
+		}
+
+		if result == backPrompt {
+			// Going back from the first step leaves the form, e.g. to the step of an enclosing form
+			if len(answeredSteps) == 0 {
+				return backPrompt, nil
+			}
+
+			i = answeredSteps[len(answeredSteps)-1]
+			answeredSteps = answeredSteps[:len(answeredSteps)-1]
+
+			// Answers from the draft after the step are asked for again, as they might depend on it
+			for j := i; j < len(steps); j++ {
+				prefilled[j] = steps[j].prefilled && j != i
+			}
+			if r != nil {
+				if err := r.forgetFrom(ctx, steps[i].key); err != nil {
+					return answered, fmt.Errorf("buna: form: failed to forget %v: %w", strings.ToLower(steps[i].key), err)
+				}
+			}
+
+			fmt.Println("Going back to " + strings.ToLower(steps[i].key))
+			continue
+		}
+		if result == quitPrompt {
+			return quitPrompt, nil
+		}
+
+		if r != nil {
+			if err := r.record(ctx, step.key, step.value); err != nil {
+				return answered, fmt.Errorf("buna: form: failed to record %v: %w", strings.ToLower(step.key), err)
+			}
+		}
+
+		answeredSteps = append(answeredSteps, i)
+		i++
+	}
+
+	return answered, nil
+}
+
+// Returns the answer that value points to for display, see formatDraftValue.
+func formatFormValue(value interface{}) string {
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+
+	return formatDraftValue(encoded)
+}
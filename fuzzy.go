@@ -0,0 +1,150 @@
+package buna
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Words that are commonly appended to roaster names and do not help to tell roasters apart.
+var roasterNameFillerWords = map[string]bool{
+	"co":       true,
+	"coffee":   true,
+	"coffees":  true,
+	"company":  true,
+	"ltd":      true,
+	"roasters": true,
+	"roaster":  true,
+	"roastery": true,
+	"roasting": true,
+	"the":      true,
+}
+
+// Returns the number of single rune insertions, deletions or substitutions
+// required to change a into b.
+func levenshteinDistance(a string, b string) int {
+	ra := []rune(a)
+	rb := []rune(b)
+
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+
+			curr[j] = minInt(minInt(prev[j]+1, curr[j-1]+1), prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+
+	return prev[len(rb)]
+}
+
+// Lower cases the name and removes punctuation and filler words such as "coffee" or "roasters".
+func normalizeRoasterName(name string) string {
+	words := strings.FieldsFunc(strings.ToLower(name), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+
+	var kept []string
+	for _, word := range words {
+		if !roasterNameFillerWords[word] {
+			kept = append(kept, word)
+		}
+	}
+
+	return strings.Join(kept, " ")
+}
+
+// Two roaster names are considered similar if they are equal after normalization
+// or if they only differ by a small typo.
+func isSimilarRoasterName(a string, b string) bool {
+	const minTypoCheckLength = 6
+	const maxTypoDistance = 2
+
+	na := normalizeRoasterName(a)
+	nb := normalizeRoasterName(b)
+	if na == "" || nb == "" {
+		return false
+	}
+
+	if na == nb {
+		return true
+	}
+
+	if len(na) < minTypoCheckLength || len(nb) < minTypoCheckLength {
+		return false
+	}
+
+	return levenshteinDistance(na, nb) <= maxTypoDistance
+}
+
+// Lower cases the name and removes surrounding and repeated whitespace.
+func normalizeName(name string) string {
+	return strings.Join(strings.Fields(strings.ToLower(name)), " ")
+}
+
+// Returns the edit distance between the normalized names and whether candidate is close enough to name
+// to be suggested in its place. A candidate is close if it only differs by a few typos
+// or if one name contains the other, e.g. "Kenya Kiambu" and "Kiambu".
+func nameDistance(name string, candidate string) (int, bool) {
+	const minMaxTypoDistance = 2
+	// At most one in this many runes may be a typo in longer names
+	const runesPerTypo = 4
+
+	n := normalizeName(name)
+	c := normalizeName(candidate)
+	if n == "" || c == "" {
+		return 0, false
+	}
+
+	distance := levenshteinDistance(n, c)
+
+	maxTypoDistance := len([]rune(n)) / runesPerTypo
+	if maxTypoDistance < minMaxTypoDistance {
+		maxTypoDistance = minMaxTypoDistance
+	}
+
+	return distance, distance <= maxTypoDistance || strings.Contains(n, c) || strings.Contains(c, n)
+}
+
+// Returns up to limit of the candidates that are close to name, closest first.
+func closestNames(name string, candidates []string, limit int) []string {
+	type match struct {
+		name     string
+		distance int
+	}
+
+	var matches []match
+	for _, candidate := range candidates {
+		if distance, ok := nameDistance(name, candidate); ok {
+			matches = append(matches, match{candidate, distance})
+		}
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].distance < matches[j].distance
+	})
+
+	names := make([]string, 0, limit)
+	for i := 0; i < len(matches) && i < limit; i++ {
+		names = append(names, matches[i].name)
+	}
+
+	return names
+}
+
+func minInt(a int, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
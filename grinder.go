@@ -2,6 +2,7 @@ package buna
 
 import (
 	"context"
+	"database/sql"
 	"errors"
 	"fmt"
 	"os"
@@ -11,46 +12,100 @@ import (
 )
 
 type grinder struct {
+	id              int
 	name            string
 	company         string
 	maxGrindSetting int
 }
 
-func addGrinder(ctx context.Context, db DB) error {
-	fmt.Println("Adding new coffee grinder (Enter # to quit):")
-	fmt.Print("Enter grinder name: ")
-	name, quit := validateStrInput(quitStr, false, nil, nil)
-	if quit {
-		fmt.Println(quitMsg)
-		return nil
+// Returns the added grinder.
+// The user is only prompted for the grinder name if name is empty.
+func addGrinder(ctx context.Context, db DB, name string) (grinder, error) {
+	session := sessionFromContext(ctx)
+	fmt.Println("Adding new coffee grinder (Enter " + session.quit + " to quit):")
+
+	if name == "" {
+		var quit promptResult
+		fmt.Print("Enter grinder name: ")
+		name, quit = validateStrInput(quitExits(ctx), false, nil, nil)
+		if quit != answered {
+			fmt.Println(quitMsg)
+			return grinder{}, nil
+		}
+	} else {
+		fmt.Println("Grinder name: " + name)
 	}
 
 	fmt.Print("Enter grinder's company name: ")
-	company, quit := validateStrInput(quitStr, true, nil, nil)
-	if quit {
+	company, quit := validateStrInput(quitExits(ctx), true, nil, nil)
+	if quit != answered {
 		fmt.Println(quitMsg)
-		return nil
+		return grinder{}, nil
 	}
 
 	fmt.Print("Enter the maximum grind setting (Integer): ")
-	maxGrindSetting, quit := validateIntInput(quitStr, true, 0, 100, nil)
-	if quit {
+	maxGrindSetting, quit := validateIntInput(quitExits(ctx), true, 0, 100, nil)
+	if quit != answered {
 		fmt.Println(quitMsg)
-		return nil
+		return grinder{}, nil
 	}
 
-	grinder := grinder{
+	newGrinder := grinder{
 		name:            name,
 		company:         company,
 		maxGrindSetting: maxGrindSetting,
 	}
 
-	if err := db.insertGrinder(ctx, grinder); err != nil {
-		return fmt.Errorf("buna: grinder: failed to insert coffee grinder: %w", err)
+	if err := db.insertGrinder(ctx, newGrinder); err != nil {
+		return grinder{}, fmt.Errorf("buna: grinder: failed to insert coffee grinder: %w", err)
 	}
 
 	fmt.Println("Added coffee grinder successfully")
-	return nil
+	return newGrinder, nil
+}
+
+// Returns the name of the grinder.
+// If it does not exist, the user can choose a grinder with a similar name instead, create it and continue after printing resumeMsg
+// or enter a different name, in which case the returned name is empty.
+// Returns grinderName, promptResult, error
+func getExistingGrinderName(ctx context.Context, db DB, exits promptExits, name string, resumeMsg string) (string, promptResult, error) {
+	_, err := db.getGrinderIDByName(ctx, name)
+	if err == nil {
+		return name, answered, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return "", answered, fmt.Errorf("buna: grinder: failed to get grinder id by name: %w", err)
+	}
+
+	similarNames, err := db.getSimilarGrinderNames(ctx, name, maxSimilarNames)
+	if err != nil {
+		return "", answered, fmt.Errorf("buna: grinder: failed to get similar grinder names: %w", err)
+	}
+
+	selection, quit := getDidYouMeanSelection(exits, "grinder", name, similarNames)
+	if quit != answered {
+		return "", quit, nil
+	}
+	if selection == reenterSelection {
+		return "", answered, nil
+	}
+	if selection >= 0 {
+		return similarNames[selection], answered, nil
+	}
+
+	addedGrinder, err := addGrinder(ctx, db, name)
+	if err != nil {
+		return "", answered, fmt.Errorf("buna: grinder: failed to add grinder: %w", err)
+	}
+	if addedGrinder.name == "" {
+		return "", quitPrompt, nil
+	}
+
+	if resumeMsg != "" {
+		fmt.Println(resumeMsg)
+	}
+
+	return addedGrinder.name, answered, nil
 }
 
 func retrieveGrinder(ctx context.Context, db DB) error {
@@ -58,16 +113,17 @@ func retrieveGrinder(ctx context.Context, db DB) error {
 		0: "Retrieve grinders ordered by last added",
 	}
 
-	fmt.Println("Retrieving grinders (Enter # to quit):")
-	if err := displayIntOptions(options); err != nil {
+	session := sessionFromContext(ctx)
+	fmt.Println("Retrieving grinders (Enter " + session.quit + " to quit):")
+	if err := displayIntOptions(ctx, options); err != nil {
 		return fmt.Errorf("buna: grinder: failed to display int options: %w", err)
 	}
 
-	selection, quit, err := getIntSelection(options, quitStr)
+	selection, quit, err := getIntSelection(ctx, options, quitExits(ctx))
 	if err != nil {
 		return fmt.Errorf("buna: grinder: failed to get int selection: %w", err)
 	}
-	if quit {
+	if quit != answered {
 		fmt.Println(quitMsg)
 		return nil
 	}
@@ -91,29 +147,53 @@ func runRetrieveGrinderSelection(ctx context.Context, selection int, db DB) erro
 	return nil
 }
 
-// Promts user for an optional limit.
+// Promts user for an optional page size.
 func displayGrindersByLastAdded(ctx context.Context, db DB) error {
-	const defaultDisplayAmount = 20
-	const maxDisplayAmount = 60
+	session := sessionFromContext(ctx)
+	defaultPageSize := session.config.intValue("display.grinders_page_size")
+	const maxPageSize = 60
 
-	fmt.Println("Displaying grinders by last added (Enter # to quit):")
+	fmt.Println("Displaying grinders by last added (Enter " + session.quit + " to quit):")
 
-	fmt.Print("Enter a limit for the number of grinders to display: ")
-	limit, quit := validateIntInput(quitStr, true, 1, maxDisplayAmount, []int{})
-	if quit {
+	fmt.Print("Enter the number of grinders to display per page: ")
+	pageSize, quit := validateIntInput(quitExits(ctx), true, 1, maxPageSize, []int{})
+	if quit != answered {
 		fmt.Println(quitMsg)
 		return nil
 	}
 
-	if limit == 0 {
-		limit = defaultDisplayAmount
+	if pageSize == 0 {
+		pageSize = defaultPageSize
 	}
 
-	grinders, err := db.getGrindersByLastAdded(ctx, limit)
-	if err != nil {
-		return fmt.Errorf("buna: grinder: failed to get grinders by last added: %w", err)
+	var grinders []grinder
+	listing := pagedListing{
+		fetch: func(after *pageCursor, limit int) ([]pageCursor, error) {
+			var err error
+			grinders, err = db.getGrindersByLastAdded(ctx, after, limit)
+			if err != nil {
+				return nil, fmt.Errorf("buna: grinder: failed to get grinders by last added: %w", err)
+			}
+
+			cursors := make([]pageCursor, len(grinders))
+			for i := range grinders {
+				cursors[i].id = grinders[i].id
+			}
+			return cursors, nil
+		},
+		display: func(n int) error {
+			return displayGrinders(ctx, grinders[:n])
+		},
+	}
+
+	if err := browsePages(quitExits(ctx), listing, pageSize); err != nil {
+		return fmt.Errorf("buna: grinder: failed to browse grinders by last added: %w", err)
 	}
 
+	return nil
+}
+
+func displayGrinders(ctx context.Context, grinders []grinder) error {
 	t := table.NewWriter()
 
 	t.AppendHeader(table.Row{
@@ -138,7 +218,7 @@ func displayGrindersByLastAdded(ctx context.Context, db DB) error {
 	t.SetAllowedRowLength(terminalWidth)
 
 	t.SetOutputMirror(os.Stdout)
-	t.Render()
+	renderTable(ctx, t)
 
 	return nil
 }
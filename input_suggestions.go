@@ -5,6 +5,7 @@ import (
 	"database/sql"
 	"fmt"
 	"reflect"
+	"sort"
 )
 
 // limit determines the number of strings in the returned slice.
@@ -25,10 +26,12 @@ func (s *SQLiteDB) getCoffeeNameSuggestions(ctx context.Context, limit int) ([]s
 			FROM brewings as b
 			INNER JOIN coffees as c
 				ON b.coffee_id = c.id
+			WHERE b.user_id = :userID OR 0 = :userID
 			ORDER BY b.id DESC
 			LIMIT :limit
 		`,
 			sql.Named("limit", brewedLimit),
+			userArg(ctx),
 		)
 		if err != nil {
 			return fmt.Errorf("buna: input_suggestions: failed to retrieve recently brewed coffee names: %w", err)
@@ -53,10 +56,12 @@ func (s *SQLiteDB) getCoffeeNameSuggestions(ctx context.Context, limit int) ([]s
 			FROM purchases as p
 			INNER JOIN coffees as c
 				ON p.coffee_id = c.id
+			WHERE p.user_id = :userID OR 0 = :userID
 			ORDER BY p.id DESC
 			LIMIT :limit
 		`,
 			sql.Named("limit", purchasedLimit),
+			userArg(ctx),
 		)
 		if err != nil {
 			return fmt.Errorf("buna: input_suggestions: failed to retrieve recently purchased coffee names: %w", err)
@@ -156,10 +161,12 @@ func (s *SQLiteDB) getMostRecentlyUsedBrewingMethodNames(ctx context.Context, li
 			FROM brewings as b
 			INNER JOIN brewing_methods as m
 				ON b.method_id = m.id
+			WHERE b.user_id = :userID OR 0 = :userID
 			ORDER BY b.id DESC
 			LIMIT :limit
 		`,
 			sql.Named("limit", limit),
+			userArg(ctx),
 		)
 		if err != nil {
 			return fmt.Errorf("buna: input_suggestions: failed to retrieve brewing method name rows: %w", err)
@@ -196,10 +203,12 @@ func (s *SQLiteDB) getMostRecentlyUsedCoffeeGrinderNames(ctx context.Context, li
 			FROM brewings as b
 			INNER JOIN grinders as g
 				ON b.grinder_id = g.id
+			WHERE b.user_id = :userID OR 0 = :userID
 			ORDER BY b.id DESC
 			LIMIT :limit
 		`,
 			sql.Named("limit", limit),
+			userArg(ctx),
 		)
 		if err != nil {
 			return fmt.Errorf("buna: input_suggestions: failed to retrieve coffee grinder name rows: %w", err)
@@ -240,12 +249,14 @@ func (s *SQLiteDB) getMostRecentlyUsedCoffeeWeights(ctx context.Context, brewing
 			INNER JOIN grinders as g
 				ON b.grinder_id = g.id
 			WHERE m.name = :brewingMethodName AND g.name = :coffeeGrinderName
+			AND (b.user_id = :userID OR 0 = :userID)
 			ORDER BY b.id DESC
 			LIMIT :limit
 		`,
 			sql.Named("brewingMethodName", brewingMethodName),
 			sql.Named("coffeeGrinderName", coffeeGrinderName),
 			sql.Named("limit", limit),
+			userArg(ctx),
 		)
 		if err != nil {
 			return fmt.Errorf("buna: input_suggestions: failed to retrieve coffee weight rows: %w", err)
@@ -286,12 +297,14 @@ func (s *SQLiteDB) getMostRecentlyUsedWaterWeights(ctx context.Context, brewingM
 			INNER JOIN grinders as g
 				ON b.grinder_id = g.id
 			WHERE m.name = :brewingMethodName AND g.name = :coffeeGrinderName
+			AND (b.user_id = :userID OR 0 = :userID)
 			ORDER BY b.id DESC
 			LIMIT :limit
 		`,
 			sql.Named("brewingMethodName", brewingMethodName),
 			sql.Named("coffeeGrinderName", coffeeGrinderName),
 			sql.Named("limit", limit),
+			userArg(ctx),
 		)
 		if err != nil {
 			return fmt.Errorf("buna: input_suggestions: failed to retrieve coffee weight rows: %w", err)
@@ -324,10 +337,12 @@ func (s *SQLiteDB) getRoastersByCoffeeName(ctx context.Context, name string, lim
 	var roasters []string
 	if err := s.TransactContext(ctx, func(ctx context.Context, tx *sql.Tx) error {
 		rows, err := tx.QueryContext(ctx, `
-			SELECT roaster 
-			FROM coffees
-			WHERE name = :name
-			ORDER BY id DESC
+			SELECT r.name
+			FROM coffees AS c
+			INNER JOIN roasters AS r
+				ON r.id = c.roaster_id
+			WHERE c.name = :name
+			ORDER BY c.id DESC
 			LIMIT :limit
 		`,
 			sql.Named("name", name),
@@ -359,6 +374,89 @@ func (s *SQLiteDB) getRoastersByCoffeeName(ctx context.Context, name string, lim
 	return roasters, nil
 }
 
+// limit determines the number of strings in the returned slice.
+// Returns the country codes of the most recently added coffees.
+func (s *SQLiteDB) getMostRecentlyUsedCountryCodes(ctx context.Context, limit int) ([]string, error) {
+	var codes []string
+	if err := s.TransactContext(ctx, func(ctx context.Context, tx *sql.Tx) error {
+		rows, err := tx.QueryContext(ctx, `
+			SELECT country_code
+			FROM coffees
+			WHERE country_code IS NOT NULL
+			GROUP BY country_code
+			ORDER BY max(id) DESC
+			LIMIT :limit
+		`,
+			sql.Named("limit", limit),
+		)
+		if err != nil {
+			return fmt.Errorf("buna: input_suggestions: failed to retrieve country code rows: %w", err)
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var code string
+			if err := rows.Scan(&code); err != nil {
+				return fmt.Errorf("buna: input_suggestions: failed to scan row: %w", err)
+			}
+
+			codes = append(codes, code)
+		}
+
+		if err := rows.Err(); err != nil {
+			return fmt.Errorf("buna: input_suggestions: failed to scan last row: %w", err)
+		}
+
+		return nil
+	}); err != nil {
+		return nil, fmt.Errorf("buna: input_suggestions: getMostRecentlyUsedCountryCodes transaction failed: %w", err)
+	}
+
+	return codes, nil
+}
+
+// limit determines the number of strings in the returned slice.
+// Roasters of the most recently added coffees come first, followed by the most recently added roasters without coffees.
+func (s *SQLiteDB) getRoasterNameSuggestions(ctx context.Context, limit int) ([]string, error) {
+	var names []string
+	if err := s.TransactContext(ctx, func(ctx context.Context, tx *sql.Tx) error {
+		rows, err := tx.QueryContext(ctx, `
+			SELECT r.name
+			FROM roasters AS r
+			LEFT JOIN coffees AS c
+				ON c.roaster_id = r.id
+			GROUP BY r.id
+			ORDER BY max(c.id) DESC NULLS LAST, r.id DESC
+			LIMIT :limit
+		`,
+			sql.Named("limit", limit),
+		)
+		if err != nil {
+			return fmt.Errorf("buna: input_suggestions: failed to retrieve roaster name rows: %w", err)
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var name string
+			if err := rows.Scan(&name); err != nil {
+				return fmt.Errorf("buna: input_suggestions: failed to scan row: %w", err)
+			}
+
+			names = append(names, name)
+		}
+
+		if err := rows.Err(); err != nil {
+			return fmt.Errorf("buna: input_suggestions: failed to scan last row: %w", err)
+		}
+
+		return nil
+	}); err != nil {
+		return nil, fmt.Errorf("buna: input_suggestions: getRoasterNameSuggestions transaction failed: %w", err)
+	}
+
+	return names, nil
+}
+
 func removeStrDuplicates(strings []string) []string {
 	keys := make(map[string]bool)
 	var res []string
@@ -372,3 +470,132 @@ func removeStrDuplicates(strings []string) []string {
 
 	return res
 }
+
+// Returns up to limit coffees whose name is close to name, see nameDistance, closest first.
+// Coffees of the roaster or a similarly named roaster come first among equally close names.
+// Only the name and roaster fields of the returned coffees are set.
+func (s *SQLiteDB) getSimilarCoffees(ctx context.Context, name string, roaster string, limit int) ([]coffee, error) {
+	type match struct {
+		coffee         coffee
+		distance       int
+		isOtherRoaster bool
+	}
+
+	var matches []match
+	if err := s.TransactContext(ctx, func(ctx context.Context, tx *sql.Tx) error {
+		rows, err := tx.QueryContext(ctx, `
+			SELECT c.name, r.name
+			FROM coffees AS c
+			INNER JOIN roasters AS r
+				ON r.id = c.roaster_id
+			ORDER BY c.id DESC
+		`)
+		if err != nil {
+			return fmt.Errorf("buna: input_suggestions: failed to retrieve coffee names: %w", err)
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var c coffee
+			if err := rows.Scan(&c.name, &c.roaster); err != nil {
+				return fmt.Errorf("buna: input_suggestions: failed to scan row: %w", err)
+			}
+
+			distance, ok := nameDistance(name, c.name)
+			if !ok {
+				continue
+			}
+
+			isSameRoaster := normalizeName(roaster) == normalizeName(c.roaster) || isSimilarRoasterName(roaster, c.roaster)
+			matches = append(matches, match{c, distance, !isSameRoaster})
+		}
+
+		if err := rows.Err(); err != nil {
+			return fmt.Errorf("buna: input_suggestions: failed to scan last row: %w", err)
+		}
+
+		return nil
+	}); err != nil {
+		return nil, fmt.Errorf("buna: input_suggestions: getSimilarCoffees transaction failed: %w", err)
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].distance != matches[j].distance {
+			return matches[i].distance < matches[j].distance
+		}
+		return !matches[i].isOtherRoaster && matches[j].isOtherRoaster
+	})
+
+	coffees := make([]coffee, 0, limit)
+	for i := 0; i < len(matches) && i < limit; i++ {
+		coffees = append(coffees, matches[i].coffee)
+	}
+
+	return coffees, nil
+}
+
+// Returns up to limit grinder names that are close to name, see nameDistance, closest first.
+func (s *SQLiteDB) getSimilarGrinderNames(ctx context.Context, name string, limit int) ([]string, error) {
+	names, err := s.getSimilarNames(ctx, "grinders", name, limit)
+	if err != nil {
+		return nil, fmt.Errorf("buna: input_suggestions: failed to get similar grinder names: %w", err)
+	}
+
+	return names, nil
+}
+
+// Returns up to limit brewing method names that are close to name, see nameDistance, closest first.
+func (s *SQLiteDB) getSimilarBrewingMethodNames(ctx context.Context, name string, limit int) ([]string, error) {
+	names, err := s.getSimilarNames(ctx, "brewing_methods", name, limit)
+	if err != nil {
+		return nil, fmt.Errorf("buna: input_suggestions: failed to get similar brewing method names: %w", err)
+	}
+
+	return names, nil
+}
+
+// Returns up to limit roaster names that are close to name, see nameDistance, closest first.
+func (s *SQLiteDB) getSimilarRoasterNames(ctx context.Context, name string, limit int) ([]string, error) {
+	names, err := s.getSimilarNames(ctx, "roasters", name, limit)
+	if err != nil {
+		return nil, fmt.Errorf("buna: input_suggestions: failed to get similar roaster names: %w", err)
+	}
+
+	return names, nil
+}
+
+// Returns up to limit values of the name column of table that are close to name, closest first.
+// table is inserted into the query as is and must not contain user input.
+func (s *SQLiteDB) getSimilarNames(ctx context.Context, table string, name string, limit int) ([]string, error) {
+	var names []string
+	if err := s.TransactContext(ctx, func(ctx context.Context, tx *sql.Tx) error {
+		rows, err := tx.QueryContext(ctx, fmt.Sprintf(`
+			SELECT name
+			FROM %s
+			ORDER BY id DESC
+		`, table))
+		if err != nil {
+			return fmt.Errorf("buna: input_suggestions: failed to retrieve %v names: %w", table, err)
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var name string
+			if err := rows.Scan(&name); err != nil {
+				return fmt.Errorf("buna: input_suggestions: failed to scan row: %w", err)
+			}
+
+			names = append(names, name)
+		}
+
+		if err := rows.Err(); err != nil {
+			return fmt.Errorf("buna: input_suggestions: failed to scan last row: %w", err)
+		}
+
+		return nil
+	}); err != nil {
+		return nil, fmt.Errorf("buna: input_suggestions: getSimilarNames transaction failed: %w", err)
+	}
+
+	return closestNames(name, names, limit), nil
+}
@@ -8,7 +8,6 @@ import (
 	"os"
 	"strconv"
 	"strings"
-	"time"
 
 	"github.com/jedib0t/go-pretty/table"
 	"golang.org/x/crypto/ssh/terminal"
@@ -20,12 +19,79 @@ type date struct {
 	day   int
 }
 
-// Returns a 'true' boolean if quit
+// All prompts read stdin through a single buffered reader, so that input that arrives before it is prompted for,
+// e.g. pasted lines, is not lost between prompts.
+var stdinReader = bufio.NewReader(os.Stdin)
+
+// Reads the input of prompts line by line like a bufio.Scanner, starting with the queued inputs.
+type inputScanner struct {
+	// Answers to the next prompts, e.g. the sub-options of a command like "B0 1".
+	// They are used up before anything is read from stdin.
+	queued []string
+	text   string
+}
+
+func (s *inputScanner) Scan() bool {
+	if len(s.queued) > 0 {
+		s.text = s.queued[0]
+		s.queued = s.queued[1:]
+
+		// Queued inputs are shown as if they had been typed
+		fmt.Println(s.text)
+		return true
+	}
+
+	line, err := stdinReader.ReadString('\n')
+	s.text = strings.TrimRight(line, "\r\n")
+
+	return err == nil || line != ""
+}
+
+func (s *inputScanner) Text() string {
+	return s.text
+}
+
+// How a prompt was left
+type promptResult int
+
+const (
+	answered   promptResult = iota // A value was entered
+	quitPrompt                     // The quit input was entered
+	backPrompt                     // The back input was entered, see runForm
+)
+
+// The inputs that leave a prompt instead of answering it.
+type promptExits struct {
+	quit string
+	back string // Empty if there is no previous prompt to go back to
+	// Reads the input of the prompt, see session
+	input *inputScanner
+}
+
+// Returns the exits of prompts outside of forms, which can only be quit.
+func quitExits(ctx context.Context) promptExits {
+	s := sessionFromContext(ctx)
+	return promptExits{quit: s.quit, input: s.input}
+}
+
+// Returns how the input leaves a prompt with the exits, answered if it does not.
+func exitResult(exits promptExits, input string) promptResult {
+	switch {
+	case input == exits.quit:
+		return quitPrompt
+	case exits.back != "" && input == exits.back:
+		return backPrompt
+	default:
+		return answered
+	}
+}
+
+// Second return value is how the prompt was left.
 // Optional strings default to "".
 // Pass an empty slice for options if want to allow any string.
 // Otherwise, only strings that appear in options will be accepted (+ "" if isOptional is true).
 // If suggestions is empty and options is not empty, options will be used as suggestions.
-func validateStrInput(quitStr string, isOptional bool, options []string, suggestions []string) (string, bool) {
+func validateStrInput(exits promptExits, isOptional bool, options []string, suggestions []string) (string, promptResult) {
 	if len(suggestions) == 0 && len(options) > 0 {
 		suggestions = options
 	}
@@ -37,16 +103,16 @@ func validateStrInput(quitStr string, isOptional bool, options []string, suggest
 			fmt.Printf("%v. %v\n", i+1, suggestion)
 		}
 
-		scanner := bufio.NewScanner(os.Stdin)
+		scanner := exits.input
 		scanner.Scan()
 		input := scanner.Text()
 
-		if input == quitStr {
-			return "", true
+		if result := exitResult(exits, input); result != answered {
+			return "", result
 		}
 
 		if input == "" && isOptional {
-			return "", false
+			return "", answered
 		}
 
 		if input == "m" {
@@ -58,46 +124,46 @@ func validateStrInput(quitStr string, isOptional bool, options []string, suggest
 				fmt.Println("Not a valid option. Skipping to manual entry")
 				fmt.Print("Input: ")
 			} else {
-				return suggestions[num-1], false
+				return suggestions[num-1], answered
 			}
 		}
 	}
 
-	scanner := bufio.NewScanner(os.Stdin)
+	scanner := exits.input
 	scanner.Scan()
 	input := scanner.Text()
 
-	if input == quitStr {
-		return "", true
+	if result := exitResult(exits, input); result != answered {
+		return "", result
 	}
 
 	if input == "" {
 		if isOptional {
-			return "", false
+			return "", answered
 		}
 
 		fmt.Print("A value is required. Please try again: ")
-		return validateStrInput(quitStr, isOptional, options, nil)
+		return validateStrInput(exits, isOptional, options, nil)
 	}
 
 	if len(options) > 0 {
 		for _, option := range options {
 			if input == option {
-				return input, false
+				return input, answered
 			}
 		}
 
 		fmt.Print("Not a valid option. Please try again: ")
-		return validateStrInput(quitStr, isOptional, options, nil)
+		return validateStrInput(exits, isOptional, options, nil)
 	}
 
-	return input, false
+	return input, answered
 }
 
-// Returns a 'true' boolean if quit.
+// Second return value is how the prompt was left.
 // Optional integers default to 0.
 // The integer bounds are specified using min and max.
-func validateIntInput(quitStr string, isOptional bool, min int, max int, suggestions []int) (int, bool) {
+func validateIntInput(exits promptExits, isOptional bool, min int, max int, suggestions []int) (int, promptResult) {
 	suggestionNum := len(suggestions)
 	if suggestionNum > 0 {
 		fmt.Println("\nSelect one of the following (integer) or enter 'm' for manual entry:")
@@ -105,16 +171,16 @@ func validateIntInput(quitStr string, isOptional bool, min int, max int, suggest
 			fmt.Printf("%v. %v\n", i+1, suggestion)
 		}
 
-		scanner := bufio.NewScanner(os.Stdin)
+		scanner := exits.input
 		scanner.Scan()
 		input := scanner.Text()
 
-		if input == quitStr {
-			return 0, true
+		if result := exitResult(exits, input); result != answered {
+			return 0, result
 		}
 
 		if input == "" && isOptional {
-			return 0, false
+			return 0, answered
 		}
 
 		if input == "m" {
@@ -126,58 +192,59 @@ func validateIntInput(quitStr string, isOptional bool, min int, max int, suggest
 				fmt.Println("Not a valid option. Skipping to manual entry")
 				fmt.Print("Input: ")
 			} else {
-				return suggestions[num-1], false
+				return suggestions[num-1], answered
 			}
 		}
 	}
 
-	scanner := bufio.NewScanner(os.Stdin)
+	scanner := exits.input
 	scanner.Scan()
 	input := scanner.Text()
 
-	if input == quitStr {
-		return 0, true
+	if result := exitResult(exits, input); result != answered {
+		return 0, result
 	}
 
 	if input == "" {
 		if isOptional {
-			return 0, false
+			return 0, answered
 		}
 
 		fmt.Print("A value is required. Please try again: ")
-		return validateIntInput(quitStr, isOptional, min, max, nil)
+		return validateIntInput(exits, isOptional, min, max, nil)
 	}
 
 	num, err := strconv.Atoi(input)
 	if err != nil || num < min || num > max {
 		fmt.Print("Input invalid. Please try again: ")
-		return validateIntInput(quitStr, isOptional, min, max, nil)
+		return validateIntInput(exits, isOptional, min, max, nil)
 	}
 
-	return num, false
+	return num, answered
 }
 
-// Returns a 'true' boolean if quit.
+// Second return value is how the prompt was left.
 // Optional floats default to 0.
-// The float bounds are specified using min and max.
-func validateFloatInput(quitStr string, isOptional bool, min float64, max float64, suggestions []float64) (float64, bool) {
+// parse returns the value of an input and false if the input is invalid, e.g. out of bounds.
+// invalidMsg asks for another input after an invalid one. format displays the suggestions and may be nil without suggestions.
+func validateFloatInput(exits promptExits, isOptional bool, parse func(input string) (float64, bool), invalidMsg string, format func(value float64) string, suggestions []float64) (float64, promptResult) {
 	suggestionNum := len(suggestions)
 	if suggestionNum > 0 {
 		fmt.Println("\nSelect one of the following (integer) or enter 'm' for manual entry:")
 		for i, suggestion := range suggestions {
-			fmt.Printf("%v. %v\n", i+1, suggestion)
+			fmt.Printf("%v. %v\n", i+1, format(suggestion))
 		}
 
-		scanner := bufio.NewScanner(os.Stdin)
+		scanner := exits.input
 		scanner.Scan()
 		input := scanner.Text()
 
-		if input == quitStr {
-			return 0, true
+		if result := exitResult(exits, input); result != answered {
+			return 0, result
 		}
 
 		if input == "" && isOptional {
-			return 0, false
+			return 0, answered
 		}
 
 		if input == "m" {
@@ -189,357 +256,381 @@ func validateFloatInput(quitStr string, isOptional bool, min float64, max float6
 				fmt.Println("Not a valid option. Skipping to manual entry")
 				fmt.Print("Input: ")
 			} else {
-				return suggestions[num-1], false
+				return suggestions[num-1], answered
 			}
 		}
 	}
 
-	scanner := bufio.NewScanner(os.Stdin)
-	scanner.Scan()
-	input := scanner.Text()
+	scanner := exits.input
+	for {
+		scanner.Scan()
+		input := strings.TrimSpace(scanner.Text())
 
-	if input == quitStr {
-		return 0, true
-	}
+		if result := exitResult(exits, input); result != answered {
+			return 0, result
+		}
 
-	if input == "" {
-		if isOptional {
-			return 0, false
+		if input == "" {
+			if isOptional {
+				return 0, answered
+			}
+
+			fmt.Print("A value is required. Please try again: ")
+			continue
 		}
 
-		fmt.Print("A value is required. Please try again: ")
-		return validateFloatInput(quitStr, isOptional, min, max, nil)
-	}
+		value, ok := parse(input)
+		if !ok {
+			fmt.Print(invalidMsg)
+			continue
+		}
 
-	num, err := strconv.ParseFloat(input, 64)
-	if err != nil || num < min || num > max {
-		fmt.Print("Input invalid. Please try again: ")
-		return validateFloatInput(quitStr, isOptional, min, max, nil)
+		return value, answered
 	}
-
-	return num, false
 }
 
-// Second return boolean is 'true' if quit.
+// Second return value is how the prompt was left.
 // Optional booleans default to 'false'.
-func validateBoolInput(quitStr string, isOptional bool) (bool, bool) {
-	scanner := bufio.NewScanner(os.Stdin)
+func validateBoolInput(exits promptExits, isOptional bool) (bool, promptResult) {
+	scanner := exits.input
 	scanner.Scan()
 	input := scanner.Text()
 
-	if input == quitStr {
-		return false, true
+	if result := exitResult(exits, input); result != answered {
+		return false, result
 	}
 
 	if isOptional && input == "" {
-		return false, false
+		return false, answered
 	}
 
 	inputBool, err := strconv.ParseBool(input)
 	if err != nil {
 		fmt.Print("Invalid value. Please try again: ")
-		return validateBoolInput(quitStr, isOptional)
+		return validateBoolInput(exits, isOptional)
 	}
 
-	return inputBool, false
-}
-
-// Considers a year to be an integer value x such that 2020 <= x <= time.Year.
-// Returns a 'true' boolean if quit.
-func validateYearInput(quitStr string, isOptional bool) (int, bool) {
-	return validateIntInput(quitStr, isOptional, 2020, time.Now().Year(), []int{time.Now().Year()})
+	return inputBool, answered
 }
 
-// Considers a month to be an integer value x such that 1 <= x <= 12.
-// Returns a 'true' boolean if quit.
-func validateMonthInput(quitStr string, isOptional bool) (int, bool) {
-	currentMonth := int(time.Now().Month())
-	return validateIntInput(quitStr, isOptional, 1, 12, []int{currentMonth, currentMonth - 1})
-}
-
-// Considers a day to be an integer value x such that 1 <= x <= (max day in month, 29 for Feb).
-// Returns a 'true' boolean if quit.
-func validateDayInput(quitStr string, isOptional bool, month int) (int, bool) {
-	scanner := bufio.NewScanner(os.Stdin)
-	scanner.Scan()
-	input := scanner.Text()
+// Maximum number of similar names offered for a name that does not exist
+const maxSimilarNames = 5
 
-	if input == quitStr {
-		return 0, true
-	}
+// Selections returned by getDidYouMeanSelection besides the index of a similar name
+const (
+	createSelection  = -1
+	reenterSelection = -2
+)
 
-	if input == "" {
-		if isOptional {
-			return 0, false
+// Asks the user whether one of similarNames was meant instead of name, which does not exist,
+// whether to create it or whether to enter a different name.
+// entityName is the kind of thing that is named, e.g. "coffee".
+// Returns the index of the selected similar name, createSelection or reenterSelection, promptResult
+func getDidYouMeanSelection(exits promptExits, entityName string, name string, similarNames []string) (int, promptResult) {
+	if len(similarNames) == 0 {
+		fmt.Printf("The %v '%v' does not exist yet. Do you want to create it? (true or false to enter a different name): ", entityName, name)
+		create, quit := validateBoolInput(exits, false)
+		if quit != answered {
+			return 0, quit
 		}
-
-		fmt.Print("A value is required. Please try again: ")
-		return validateDayInput(quitStr, isOptional, month)
-	}
-
-	var max int
-	switch month {
-	case 1:
-		max = 31
-	case 2:
-		max = 29
-	case 3:
-		max = 31
-	case 4:
-		max = 30
-	case 5:
-		max = 31
-	case 6:
-		max = 30
-	case 7:
-		max = 31
-	case 8:
-		max = 31
-	case 9:
-		max = 30
-	case 10:
-		max = 31
-	case 11:
-		max = 30
-	case 12:
-		max = 31
-	default:
-		fmt.Println("buna: input_util: invalid month passed into day validator")
-		return 0, false
+		if !create {
+			return reenterSelection, answered
+		}
+		return createSelection, answered
 	}
 
-	day, err := strconv.Atoi(input)
-	if err != nil || day <= 0 || day > max {
-		fmt.Print("Day invalid. Please try again: ")
-		return validateDayInput(quitStr, isOptional, month)
+	fmt.Printf("The %v '%v' does not exist. Did you mean:\n", entityName, name)
+	for i, similarName := range similarNames {
+		fmt.Printf("%v. %v\n", i+1, similarName)
 	}
+	fmt.Printf("Select one of the above (integer), enter 'c' to create the %v '%v' or 'r' to enter a different name: ", entityName, name)
 
-	return day, false
-}
+	scanner := exits.input
+	for {
+		scanner.Scan()
+		input := strings.TrimSpace(scanner.Text())
 
-// Used to get a date input by promting the user for year, month and day separately.
-// The user is not prompted to enter month and day if date is optional and no value is entered for year
-// (same for day if no value is entered for month).
-// inputMsg is used as the message for the user. All '?' characters are replaced by year, month or day.
-// inputMsg must contain at least one '?' and should end with ": ", for it to make sense to the user.
-// Returns a 'true' boolean if quit.
-func getDateInput(quitStr string, isOptional bool, inputMsg string, suggestions []date) (date, bool) {
-	suggestionNum := len(suggestions)
-	if suggestionNum > 0 {
-		dateMsg := strings.ReplaceAll(inputMsg, "?", "date")
-		fmt.Println(dateMsg)
-		fmt.Println("Select one of the following (integer) or enter 'm' for manual entry:")
-		for i, suggestion := range suggestions {
-			fmt.Printf("%v. %v\n", i+1, createDateString(suggestion))
+		if result := exitResult(exits, input); result != answered {
+			return 0, result
 		}
 
-		scanner := bufio.NewScanner(os.Stdin)
-		scanner.Scan()
-		input := scanner.Text()
-
-		if input == quitStr {
-			return date{}, true
+		switch input {
+		case "c":
+			return createSelection, answered
+		case "r":
+			return reenterSelection, answered
 		}
 
-		if input == "m" {
-			fmt.Println("Skipping to manual entry")
-		} else {
-			num, err := strconv.Atoi(input)
-			if err != nil || num > suggestionNum || num <= 0 {
-				fmt.Println("Not a valid option. Skipping to manual entry")
-			} else {
-				return suggestions[num-1], false
-			}
+		num, err := strconv.Atoi(input)
+		if err != nil || num <= 0 || num > len(similarNames) {
+			fmt.Print("Not a valid option. Please try again: ")
+			continue
 		}
-	}
 
-	yearMsg := strings.ReplaceAll(inputMsg, "?", "year")
-	fmt.Print(yearMsg)
-	year, quit := validateYearInput(quitStr, isOptional)
-	if quit {
-		return date{}, true
+		return num - 1, answered
 	}
+}
 
-	if year == 0 {
-		return date{}, false
-	}
+// Prompts for a coffee name and roaster until they name an existing coffee, which the user can create on the way.
+// resumeMsg is printed after a coffee was created to lead back into the flow the coffee is entered for.
+// Returns coffee, promptResult, error
+func getExistingCoffeeWithSuggestions(ctx context.Context, db DB, exits promptExits, resumeMsg string) (coffee, promptResult, error) {
+	for {
+		coffeeName, quit, err := getCoffeeNameWithSuggestions(ctx, db, exits, false)
+		if err != nil || quit != answered {
+			return coffee{}, quit, err
+		}
 
-	monthMsg := strings.ReplaceAll(inputMsg, "?", "month")
-	fmt.Print(monthMsg)
-	month, quit := validateMonthInput(quitStr, isOptional)
-	if quit {
-		return date{}, true
-	}
+		coffeeRoaster, quit, err := getCoffeeRoasterWithSuggestions(ctx, db, exits, coffeeName)
+		if err != nil || quit != answered {
+			return coffee{}, quit, err
+		}
 
-	if month == 0 {
-		return date{}, false
+		existingCoffee, quit, err := getExistingCoffee(ctx, db, exits, coffeeName, coffeeRoaster, resumeMsg)
+		if err != nil || quit != answered {
+			return coffee{}, quit, err
+		}
+		if existingCoffee.name != "" {
+			return existingCoffee, answered, nil
+		}
 	}
+}
 
-	dayMsg := strings.ReplaceAll(inputMsg, "?", "day")
-	fmt.Print(dayMsg)
-	day, quit := validateDayInput(quitStr, isOptional, month)
-	if quit {
-		return date{}, true
-	}
+// Prompts for a brewing method name until it names an existing brewing method, which the user can create on the way.
+// resumeMsg is printed after a brewing method was created.
+// Returns brewingMethodName, promptResult, error
+func getExistingBrewingMethodNameWithSuggestions(ctx context.Context, db DB, exits promptExits, resumeMsg string) (string, promptResult, error) {
+	for {
+		brewingMethodName, quit, err := getBrewingMethodNameWithSuggestions(ctx, db, exits, false)
+		if err != nil || quit != answered {
+			return "", quit, err
+		}
 
-	return date{
-		year:  year,
-		month: month,
-		day:   day,
-	}, false
+		brewingMethodName, quit, err = getExistingBrewingMethodName(ctx, db, exits, brewingMethodName, resumeMsg)
+		if err != nil || quit != answered {
+			return "", quit, err
+		}
+		if brewingMethodName != "" {
+			return brewingMethodName, answered, nil
+		}
+	}
 }
 
-// Returns brewingMethodName, didQuit, error
-func getBrewingMethodNameWithSuggestions(ctx context.Context, db DB, quitStr string, isOptional bool) (string, bool, error) {
-	fmt.Print("Enter brewing method name: ")
+// Prompts for a grinder name until it names an existing grinder, which the user can create on the way.
+// resumeMsg is printed after a grinder was created.
+// Returns grinderName, promptResult, error
+func getExistingGrinderNameWithSuggestions(ctx context.Context, db DB, exits promptExits, resumeMsg string) (string, promptResult, error) {
+	for {
+		grinderName, quit, err := getCoffeeGrinderNameWithSuggestions(ctx, db, exits, false)
+		if err != nil || quit != answered {
+			return "", quit, err
+		}
 
-	brewingMethodSuggestions, err := db.getMostRecentlyUsedBrewingMethodNames(ctx, 5)
-	if err != nil {
-		return "", false, fmt.Errorf("buna: input_util: failed to get brewing method suggestions: %w", err)
+		grinderName, quit, err = getExistingGrinderName(ctx, db, exits, grinderName, resumeMsg)
+		if err != nil || quit != answered {
+			return "", quit, err
+		}
+		if grinderName != "" {
+			return grinderName, answered, nil
+		}
 	}
+}
 
-	brewingMethodName, quit := validateStrInput(quitStr, isOptional, nil, brewingMethodSuggestions)
+// Returns brewingMethodName, promptResult, error
+func getBrewingMethodNameWithSuggestions(ctx context.Context, db DB, exits promptExits, isOptional bool) (string, promptResult, error) {
+	f, ok := brewingFields.field("method")
+	if !ok {
+		return "", answered, unknownFieldError("method")
+	}
+	f.optional = isOptional
 
-	return brewingMethodName, quit, nil
+	return f.askText(ctx, db, exits, nil)
 }
 
-// Returns notes, didQuit
-func getNotes(quitStr string, optional bool, noteType string) (string, bool) {
+// Returns notes, promptResult
+func getNotes(exits promptExits, optional bool, noteType string) (string, promptResult) {
 	fmt.Print("Enter some " + noteType + " notes: ")
 
-	return validateStrInput(quitStr, optional, nil, nil)
+	return validateStrInput(exits, optional, nil, nil)
 }
 
-// Returns grinderName, didQuit, error
-func getCoffeeGrinderNameWithSuggestions(ctx context.Context, db DB, quitStr string, isOptional bool) (string, bool, error) {
-	fmt.Print("Enter coffee grinder name: ")
-
-	grinderSuggestions, err := db.getMostRecentlyUsedCoffeeGrinderNames(ctx, 3)
-	if err != nil {
-		return "", false, fmt.Errorf("buna: input_util: failed to get coffee grinder suggestions: %w", err)
+// Returns grinderName, promptResult, error
+func getCoffeeGrinderNameWithSuggestions(ctx context.Context, db DB, exits promptExits, isOptional bool) (string, promptResult, error) {
+	f, ok := brewingFields.field("grinder")
+	if !ok {
+		return "", answered, unknownFieldError("grinder")
 	}
+	f.optional = isOptional
 
-	grinderName, quit := validateStrInput(quitStr, isOptional, nil, grinderSuggestions)
+	return f.askText(ctx, db, exits, nil)
+}
+
+// Returns coffeeName, promptResult, error
+func getCoffeeNameWithSuggestions(ctx context.Context, db DB, exits promptExits, isOptional bool) (string, promptResult, error) {
+	f, ok := brewingFields.field("coffee")
+	if !ok {
+		return "", answered, unknownFieldError("coffee")
+	}
+	f.optional = isOptional
 
-	return grinderName, quit, nil
+	return f.askText(ctx, db, exits, nil)
 }
 
-// Returns grindSetting, didQuit
-func getCoffeeGrindSettingWithSuggestions(quitStr string) (int, bool) {
-	fmt.Print("Enter grind setting: ")
+// Returns coffeeRoasterName, promptResult, error
+func getCoffeeRoasterWithSuggestions(ctx context.Context, db DB, exits promptExits, coffeeName string) (string, promptResult, error) {
+	f, ok := brewingFields.field("roaster")
+	if !ok {
+		return "", answered, unknownFieldError("roaster")
+	}
 
-	// This assumes that every grinder has settings in the range 0 to 50
-	// An improvement would be to look up the possible grind settings using the grinder name
-	return validateIntInput(quitStr, false, 0, 50, nil)
+	return f.askText(ctx, db, exits, fieldValues{"coffee": coffeeName})
 }
 
-// Returns coffeeName, didQuit, error
-func getCoffeeNameWithSuggestions(ctx context.Context, db DB, quitStr string, isOptional bool) (string, bool, error) {
-	fmt.Print("Enter coffee name: ")
+// Returns roasterName, promptResult, error
+func getRoasterNameWithSuggestions(ctx context.Context, db DB, exits promptExits, isOptional bool) (string, promptResult, error) {
+	fmt.Print("Enter roaster name: ")
 
-	coffeeSuggestions, err := db.getCoffeeNameSuggestions(ctx, 8)
+	roasterSuggestions, err := db.getRoasterNameSuggestions(ctx, sessionFromContext(ctx).config.intValue("display.roaster_suggestions"))
 	if err != nil {
-		return "", false, fmt.Errorf("buna: input_util: failed to get coffee suggestions: %w", err)
+		return "", answered, fmt.Errorf("buna: input_util: failed to get roaster name suggestions: %w", err)
 	}
 
-	coffeeName, quit := validateStrInput(quitStr, isOptional, nil, coffeeSuggestions)
+	roasterName, quit := validateStrInput(exits, isOptional, nil, roasterSuggestions)
 
-	return coffeeName, quit, nil
+	return roasterName, quit, nil
 }
 
-// Returns rating, didQuit
-func getCoffeeRatingWithSuggestions(quitStr string) (int, bool) {
-	fmt.Print("Enter your rating for this brew (1 <= x <= 10): ")
+// Returns countryCode, promptResult, error
+// Country names, aliases and ISO 3166-1 alpha-2 codes are accepted.
+func getCountryCodeWithSuggestions(ctx context.Context, db DB, exits promptExits, isOptional bool) (string, promptResult, error) {
+	fmt.Print("Enter origin country: ")
 
-	return validateIntInput(quitStr, true, 1, 10, nil)
-}
-
-// Returns roastDate, didQuit, error
-func getCoffeeRoastDateWithSuggestions(ctx context.Context, db DB, quitStr string, coffeeName string) (date, bool, error) {
-	roastDateSuggestion, err := db.getLastCoffeeRoastDate(ctx, coffeeName)
+	countryCodes, err := db.getMostRecentlyUsedCountryCodes(ctx, 5)
 	if err != nil {
-		return date{}, false, fmt.Errorf("buna: input_util: failed to get roast date suggestions: %w", err)
+		return "", answered, fmt.Errorf("buna: input_util: failed to get country code suggestions: %w", err)
 	}
 
-	// Check if returned empty date
-	var roastDateSuggestions []date
-	if roastDateSuggestion.year != 0 {
-		roastDateSuggestions = append(roastDateSuggestions, roastDateSuggestion)
+	countrySuggestions := make([]string, 0, len(countryCodes))
+	for _, code := range countryCodes {
+		countrySuggestions = append(countrySuggestions, countryName(code))
 	}
 
-	roastDate, quit := getDateInput(quitStr, true, "Enter roast ?: ", roastDateSuggestions)
-
-	return roastDate, quit, nil
-}
-
-// Returns coffeeRoasterName, didQuit, error
-func getCoffeeRoasterWithSuggestions(ctx context.Context, db DB, quitStr string, coffeeName string) (string, bool, error) {
-	fmt.Print("Enter roaster/producer name: ")
+	country, quit := validateStrInput(exits, isOptional, nil, countrySuggestions)
+	for quit == answered && country != "" {
+		if code, ok := lookupCountryCode(country); ok {
+			return code, answered, nil
+		}
 
-	roasterSuggestions, err := db.getRoastersByCoffeeName(ctx, coffeeName, 5)
-	if err != nil {
-		return "", false, fmt.Errorf("buna: input_util: failed to get roaster suggestions: %w", err)
+		fmt.Print("Unknown country. Please try again: ")
+		country, quit = validateStrInput(exits, isOptional, nil, nil)
 	}
 
-	coffeeRoaster, quit := validateStrInput(quitStr, false, nil, roasterSuggestions)
-
-	return coffeeRoaster, quit, nil
+	return "", quit, nil
 }
 
-// Returns coffeeGrams, didQuit, error
-func getCoffeeWeightWithSuggestions(ctx context.Context, db DB, quitStr string, brewingMethodName string, grinderName string, isOptional bool) (float64, bool, error) {
-	fmt.Print("Enter the coffee weight used in grams: ")
-
-	coffeeWeightSuggestion, err := db.getMostRecentlyUsedCoffeeWeights(ctx, brewingMethodName, grinderName, 5)
-	if err != nil {
-		return 0, false, fmt.Errorf("buna: input_util: failed to get coffee weight suggestions: %w", err)
+// Returns process, processOther, promptResult
+// processOther is only prompted for if the process is otherProcess.
+func getProcessInput(exits promptExits, isOptional bool) (string, string, promptResult) {
+	fmt.Print("Enter processing method: ")
+	process, quit := validateStrInput(exits, isOptional, processes, nil)
+	if quit != answered || process != otherProcess {
+		return process, "", quit
 	}
 
-	coffeeGrams, quit := validateFloatInput(quitStr, isOptional, 5, 100, coffeeWeightSuggestion)
+	fmt.Print("Describe the processing method: ")
+	processOther, quit := validateStrInput(exits, true, nil, nil)
 
-	return coffeeGrams, quit, nil
+	return process, processOther, quit
 }
 
-// Returns recommendedGrindSettingAdjustment, didQuit
-func getRecommendedGrindSettingAdjustmentWithSuggestions(quitStr string) (string, bool) {
-	fmt.Print("Enter recommended grind setting adjustment: ")
+// Returns coffeeGrams, promptResult, error
+func getCoffeeWeightWithSuggestions(ctx context.Context, db DB, exits promptExits, brewingMethodName string, grinderName string, isOptional bool) (float64, promptResult, error) {
+	f, ok := brewingFields.field("coffee_grams")
+	if !ok {
+		return 0, answered, unknownFieldError("coffee_grams")
+	}
+	f.optional = isOptional
 
-	return validateStrInput(quitStr, true, []string{"lower", "higher"}, nil)
+	return f.askQuantity(ctx, db, exits, fieldValues{"method": brewingMethodName, "grinder": grinderName})
 }
 
-// Returns recommendedCoffeeWeightAdjustmentGrams, didQuit
-func getRecommendedCoffeeWeightAdjustmentGramsWithSuggestions(quitStr string) (float64, bool) {
-	fmt.Print("Enter recommended coffee weight adjustment in grams (-20 <= x <= 20): ")
+// Returns recommendedGrindSettingAdjustment, promptResult
+// The adjustment implied by the extraction verdict is suggested first and used if no adjustment is entered.
+func getRecommendedGrindSettingAdjustmentWithSuggestions(exits promptExits, extraction string) (string, promptResult) {
+	options := []string{"lower", "higher"}
+	suggestions := options
+
+	impliedAdjustment := grindAdjustmentForExtraction(extraction)
+	if impliedAdjustment != "" {
+		fmt.Printf("Enter recommended grind setting adjustment (%v-extracted, defaults to %v): ", extraction, impliedAdjustment)
+		if impliedAdjustment == "higher" {
+			suggestions = []string{"higher", "lower"}
+		}
+	} else {
+		fmt.Print("Enter recommended grind setting adjustment: ")
+	}
+
+	adjustment, quit := validateStrInput(exits, true, options, suggestions)
+	if adjustment == "" && quit == answered {
+		adjustment = impliedAdjustment
+	}
 
-	return validateFloatInput(quitStr, true, -20, 20, nil)
+	return adjustment, quit
 }
 
-// Returns totalCoffeeBrewingTimeSec, didQuit
-func getTotalCoffeeBrewingTimeSecWithSuggestions(quitStr string) (int, bool) {
-	fmt.Print("Enter the total brewing time in seconds: ")
+// Returns scores, promptResult
+// The user is asked whether to add sub-scores at all, as they are optional.
+func getBrewingScoresInput(exits promptExits) (brewingScores, promptResult) {
+	fmt.Print("Add detailed scores (true or false): ")
+	addScores, quit := validateBoolInput(exits, true)
+	if quit != answered || !addScores {
+		return brewingScores{}, quit
+	}
+
+	var scores brewingScores
+	for _, score := range []struct {
+		name  string
+		value *int
+	}{
+		{"sweetness", &scores.sweetness},
+		{"acidity", &scores.acidity},
+		{"bitterness", &scores.bitterness},
+		{"body", &scores.body},
+		{"clarity", &scores.clarity},
+		{"astringency", &scores.astringency},
+	} {
+		fmt.Printf("Enter %v (1 <= x <= 10): ", score.name)
+		*score.value, quit = validateIntInput(exits, true, 1, 10, nil)
+		if quit != answered {
+			return brewingScores{}, quit
+		}
+	}
 
-	return validateIntInput(quitStr, false, 10, 1800, nil)
+	return scores, answered
 }
 
-// Returns v60FilterType, didQuit
-func getV60FilterTypeWithSuggestions(quitStr string) (string, bool) {
-	fmt.Print("Enter v60 filter type: ")
+// Returns v60FilterType, promptResult, error
+func getV60FilterTypeWithSuggestions(exits promptExits) (string, promptResult, error) {
+	f, ok := brewingFields.field("v60_filter_type")
+	if !ok {
+		return "", answered, unknownFieldError("v60_filter_type")
+	}
+	fmt.Print(f.prompt + ": ")
+
+	v60FilterType, quit := validateStrInput(exits, true, f.options, nil)
 
-	return validateStrInput(quitStr, true, []string{"eu", "jp"}, nil)
+	return v60FilterType, quit, nil
 }
 
-// Returns waterGrams, didQuit, error
-func getWaterWeightWithSuggestions(ctx context.Context, db DB, quitStr string, brewingMethodName string, grinderName string, isOptional bool) (float64, bool, error) {
-	fmt.Print("Enter the water weight used in grams: ")
-
-	waterWeightSuggestion, err := db.getMostRecentlyUsedWaterWeights(ctx, brewingMethodName, grinderName, 5)
-	if err != nil {
-		return 0, false, fmt.Errorf("buna: input_util: failed to get water weight suggestions: %w", err)
+// Returns waterGrams, promptResult, error
+func getWaterWeightWithSuggestions(ctx context.Context, db DB, exits promptExits, brewingMethodName string, grinderName string, isOptional bool) (float64, promptResult, error) {
+	f, ok := brewingFields.field("water_grams")
+	if !ok {
+		return 0, answered, unknownFieldError("water_grams")
 	}
+	f.optional = isOptional
 
-	waterGrams, quit := validateFloatInput(quitStr, isOptional, 20, 2000, waterWeightSuggestion)
-
-	return waterGrams, quit, nil
+	return f.askQuantity(ctx, db, exits, fieldValues{"method": brewingMethodName, "grinder": grinderName})
 }
 
 // Creates a date sring in the format "YYYY-MM-DD".
@@ -582,11 +673,11 @@ func createDateFromDateString(dateStr string) (date, error) {
 	return date{year: dateIntSlice[0], month: dateIntSlice[1], day: dateIntSlice[2]}, nil
 }
 
-// Returns a 'true' boolean if quit.
-func getIntSelection(options map[int]string, quitStr string) (int, bool, error) {
+// Second return value is how the prompt was left.
+func getIntSelection(ctx context.Context, options map[int]string, exits promptExits) (int, promptResult, error) {
 	retry := func() error {
 		fmt.Println("Invalid option. The following options are available:")
-		if err := displayIntOptions(options); err != nil {
+		if err := displayIntOptions(ctx, options); err != nil {
 			return fmt.Errorf("buna: input_util: failed to display int options: %w", err)
 		}
 
@@ -598,18 +689,19 @@ func getIntSelection(options map[int]string, quitStr string) (int, bool, error)
 		inputLen = 2
 	}
 
+	scanner := exits.input
 	for {
 		fmt.Print("Enter option (integer): ")
-		var input string
-		fmt.Scanln(&input)
+		scanner.Scan()
+		input := strings.TrimSpace(scanner.Text())
 
-		if input == quitStr {
-			return 0, true, nil
+		if result := exitResult(exits, input); result != answered {
+			return 0, result, nil
 		}
 
 		if len(input) > inputLen {
 			if err := retry(); err != nil {
-				return 0, false, fmt.Errorf("buna: ui: failed to display int options (retry): %w", err)
+				return 0, answered, fmt.Errorf("buna: ui: failed to display int options (retry): %w", err)
 			}
 			continue
 		}
@@ -617,23 +709,23 @@ func getIntSelection(options map[int]string, quitStr string) (int, bool, error)
 		selection, err := strconv.Atoi(input)
 		if err != nil {
 			if err := retry(); err != nil {
-				return 0, false, fmt.Errorf("buna: ui: failed to display int options (retry): %w", err)
+				return 0, answered, fmt.Errorf("buna: ui: failed to display int options (retry): %w", err)
 			}
 			continue
 		}
 
 		if _, ok := options[selection]; !ok {
 			if err := retry(); err != nil {
-				return 0, false, fmt.Errorf("buna: ui: failed to display int options (retry): %w", err)
+				return 0, answered, fmt.Errorf("buna: ui: failed to display int options (retry): %w", err)
 			}
 			continue
 		}
 
-		return selection, false, nil
+		return selection, answered, nil
 	}
 }
 
-func displayIntOptions(options map[int]string) error {
+func displayIntOptions(ctx context.Context, options map[int]string) error {
 	t := table.NewWriter()
 
 	t.AppendHeader(table.Row{"Option", "Description"})
@@ -651,7 +743,7 @@ func displayIntOptions(options map[int]string) error {
 	t.SetAllowedRowLength(terminalWidth)
 
 	t.SetOutputMirror(os.Stdout)
-	t.Render()
+	renderTable(ctx, t)
 
 	return nil
 }
@@ -0,0 +1,200 @@
+package buna
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/jedib0t/go-pretty/table"
+	"golang.org/x/crypto/ssh/terminal"
+)
+
+// Sources of indexed notes
+const (
+	brewingNoteSource      = "brewing"
+	cuppingNoteSource      = "cupping"
+	cuppedCoffeeNoteSource = "cupped_coffee"
+)
+
+// ANSI escape codes used to highlight matched terms
+const (
+	highlightStart = "\x1b[1m"
+	highlightEnd   = "\x1b[0m"
+)
+
+const noteSearchUnavailableMsg = "Notes can only be searched if buna is built with the sqlite_fts5 tag"
+
+type noteSearchHit struct {
+	source string
+	// parentID is the id of the brewing or cupping the notes belong to.
+	// Notes of cupped coffees link back to their cupping.
+	parentID      int
+	date          string
+	coffeeName    string
+	coffeeRoaster string
+	snippet       string
+}
+
+// Returns a reference to the parent of the notes, e.g. "Brewing #12".
+func (hit noteSearchHit) link() string {
+	switch hit.source {
+	case brewingNoteSource:
+		return fmt.Sprintf("Brewing #%d", hit.parentID)
+	case cuppingNoteSource:
+		return fmt.Sprintf("Cupping #%d", hit.parentID)
+	case cuppedCoffeeNoteSource:
+		return fmt.Sprintf("Cupping #%d (coffee notes)", hit.parentID)
+	default:
+		return ""
+	}
+}
+
+// Prompts user for a search query and an optional limit.
+// A hit can be opened afterwards to display its brewing or cupping.
+func searchNotes(ctx context.Context, db DB) error {
+	session := sessionFromContext(ctx)
+	defaultDisplayAmount := session.config.intValue("display.search_hits")
+	const maxDisplayAmount = 50
+
+	available, err := db.getNoteSearchAvailable(ctx)
+	if err != nil {
+		return fmt.Errorf("buna: notes: failed to check whether notes can be searched: %w", err)
+	}
+	if !available {
+		fmt.Println(noteSearchUnavailableMsg)
+		return nil
+	}
+
+	fmt.Println("Searching notes (Enter " + session.quit + " to quit):")
+	fmt.Print("Enter search terms: ")
+	query, quit := validateStrInput(quitExits(ctx), false, nil, nil)
+	if quit != answered {
+		fmt.Println(quitMsg)
+		return nil
+	}
+
+	fmt.Print("Enter a limit for the number of hits to display: ")
+	limit, quit := validateIntInput(quitExits(ctx), true, 1, maxDisplayAmount, []int{})
+	if quit != answered {
+		fmt.Println(quitMsg)
+		return nil
+	}
+	if limit == 0 {
+		limit = defaultDisplayAmount
+	}
+
+	hits, err := db.getNoteSearchHits(ctx, buildNotesSearchQuery(query), highlightStart, highlightEnd, limit)
+	if err != nil {
+		return fmt.Errorf("buna: notes: failed to get note search hits: %w", err)
+	}
+
+	if len(hits) == 0 {
+		fmt.Println("No notes match the search terms")
+		return nil
+	}
+
+	if err := displayNoteSearchHits(ctx, hits); err != nil {
+		return fmt.Errorf("buna: notes: failed to display note search hits: %w", err)
+	}
+
+	fmt.Print("Enter the number of a hit to open it: ")
+	num, quit := validateIntInput(quitExits(ctx), true, 1, len(hits), nil)
+	if quit != answered {
+		fmt.Println(quitMsg)
+		return nil
+	}
+	if num == 0 {
+		return nil
+	}
+
+	if err := openNoteSearchHit(ctx, db, hits[num-1]); err != nil {
+		return fmt.Errorf("buna: notes: failed to open note search hit: %w", err)
+	}
+
+	return nil
+}
+
+func displayNoteSearchHits(ctx context.Context, hits []noteSearchHit) error {
+	const maxSnippetFieldWidth = 70
+
+	t := table.NewWriter()
+
+	t.AppendHeader(table.Row{"#", "Link", "Date", "Coffee", "Notes"})
+
+	for i, hit := range hits {
+		coffee := hit.coffeeName
+		if hit.coffeeRoaster != "" {
+			coffee += "\n(" + hit.coffeeRoaster + ")"
+		}
+
+		t.AppendRow(table.Row{
+			i + 1,
+			hit.link(),
+			hit.date,
+			coffee,
+			splitTextIntoField(hit.snippet, maxSnippetFieldWidth),
+		})
+		t.AppendSeparator()
+	}
+
+	terminalWidth, _, err := terminal.GetSize(int(os.Stdin.Fd()))
+	if err != nil {
+		return fmt.Errorf("buna: notes: failed to get terminal width: %w", err)
+	}
+	t.SetAllowedRowLength(terminalWidth)
+
+	t.SetOutputMirror(os.Stdout)
+	renderTable(ctx, t)
+
+	return nil
+}
+
+// Displays the brewing or cupping the notes of the hit belong to.
+func openNoteSearchHit(ctx context.Context, db DB, hit noteSearchHit) error {
+	if hit.source == brewingNoteSource {
+		b, err := db.getBrewingByID(ctx, hit.parentID)
+		if errors.Is(err, sql.ErrNoRows) {
+			fmt.Println("The brewing no longer exists")
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("buna: notes: failed to get brewing by id: %w", err)
+		}
+
+		if err := displayBrewingDetails(ctx, db, b); err != nil {
+			return fmt.Errorf("buna: notes: failed to display brewing details: %w", err)
+		}
+
+		return nil
+	}
+
+	cupping, err := db.getCuppingByID(ctx, hit.parentID)
+	if errors.Is(err, sql.ErrNoRows) {
+		fmt.Println("The cupping no longer exists")
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("buna: notes: failed to get cupping by id: %w", err)
+	}
+
+	if err := displayCupping(ctx, cupping); err != nil {
+		return fmt.Errorf("buna: notes: failed to display cupping: %w", err)
+	}
+
+	return nil
+}
+
+// Turns user input into an FTS5 query that matches notes containing all terms.
+// Every term is quoted, so FTS5 operators and punctuation in the input are matched literally,
+// and used as a prefix, so "blueberr" matches "blueberry".
+func buildNotesSearchQuery(input string) string {
+	terms := strings.Fields(input)
+	for i, term := range terms {
+		terms[i] = `"` + strings.ReplaceAll(term, `"`, `""`) + `"*`
+	}
+
+	return strings.Join(terms, " ")
+}
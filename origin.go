@@ -0,0 +1,281 @@
+package buna
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+const otherProcess = "other"
+
+// Highest altitude accepted for a coffee farm in metres.
+const maxAltitudeM = 6000
+
+// Controlled vocabulary of coffee processing methods.
+// otherProcess is used together with a free text description for everything else.
+var processes = []string{
+	"washed",
+	"natural",
+	"honey",
+	"pulped natural",
+	"semi-washed",
+	"wet-hulled",
+	"anaerobic",
+	"carbonic maceration",
+	otherProcess,
+}
+
+// ISO 3166-1 alpha-2 codes of coffee producing countries.
+var countryNames = map[string]string{
+	"AO": "Angola",
+	"AU": "Australia",
+	"BI": "Burundi",
+	"BJ": "Benin",
+	"BO": "Bolivia",
+	"BR": "Brazil",
+	"CD": "Democratic Republic of the Congo",
+	"CF": "Central African Republic",
+	"CG": "Congo",
+	"CI": "Côte d'Ivoire",
+	"CM": "Cameroon",
+	"CN": "China",
+	"CO": "Colombia",
+	"CR": "Costa Rica",
+	"CU": "Cuba",
+	"DO": "Dominican Republic",
+	"EC": "Ecuador",
+	"ET": "Ethiopia",
+	"GA": "Gabon",
+	"GH": "Ghana",
+	"GN": "Guinea",
+	"GQ": "Equatorial Guinea",
+	"GT": "Guatemala",
+	"GY": "Guyana",
+	"HN": "Honduras",
+	"HT": "Haiti",
+	"ID": "Indonesia",
+	"IN": "India",
+	"JM": "Jamaica",
+	"KE": "Kenya",
+	"KH": "Cambodia",
+	"LA": "Laos",
+	"LK": "Sri Lanka",
+	"LR": "Liberia",
+	"MG": "Madagascar",
+	"MM": "Myanmar",
+	"MW": "Malawi",
+	"MX": "Mexico",
+	"MY": "Malaysia",
+	"MZ": "Mozambique",
+	"NG": "Nigeria",
+	"NI": "Nicaragua",
+	"NP": "Nepal",
+	"PA": "Panama",
+	"PE": "Peru",
+	"PG": "Papua New Guinea",
+	"PH": "Philippines",
+	"PR": "Puerto Rico",
+	"PY": "Paraguay",
+	"RW": "Rwanda",
+	"SA": "Saudi Arabia",
+	"SL": "Sierra Leone",
+	"ST": "São Tomé and Príncipe",
+	"SV": "El Salvador",
+	"TG": "Togo",
+	"TH": "Thailand",
+	"TL": "Timor-Leste",
+	"TT": "Trinidad and Tobago",
+	"TW": "Taiwan",
+	"TZ": "Tanzania",
+	"UG": "Uganda",
+	"US": "United States",
+	"VE": "Venezuela",
+	"VN": "Vietnam",
+	"YE": "Yemen",
+	"ZM": "Zambia",
+	"ZW": "Zimbabwe",
+}
+
+// Alternative country names and well known growing regions that identify a country.
+var countryAliases = map[string]string{
+	"burma":                        "MM",
+	"congo":                        "CG",
+	"cote d'ivoire":                "CI",
+	"dr congo":                     "CD",
+	"drc":                          "CD",
+	"east timor":                   "TL",
+	"hawaii":                       "US",
+	"ivory coast":                  "CI",
+	"java":                         "ID",
+	"kona":                         "US",
+	"png":                          "PG",
+	"sulawesi":                     "ID",
+	"sumatra":                      "ID",
+	"usa":                          "US",
+	"democratic republic of congo": "CD",
+}
+
+// Returns the ISO 3166-1 alpha-2 code of a country name, alias or code.
+// The second return value is false if the country is unknown.
+func lookupCountryCode(country string) (string, bool) {
+	country = strings.TrimSpace(country)
+	if country == "" {
+		return "", false
+	}
+
+	code := strings.ToUpper(country)
+	if _, ok := countryNames[code]; ok {
+		return code, true
+	}
+
+	for code, name := range countryNames {
+		if strings.EqualFold(name, country) {
+			return code, true
+		}
+	}
+
+	if code, ok := countryAliases[strings.ToLower(country)]; ok {
+		return code, true
+	}
+
+	return "", false
+}
+
+// Returns the name of the country or the code itself if it is unknown.
+func countryName(code string) string {
+	if name, ok := countryNames[code]; ok {
+		return name
+	}
+	return code
+}
+
+// Returns the names of all known countries in alphabetical order.
+func sortedCountryNames() []string {
+	names := make([]string, 0, len(countryNames))
+	for _, name := range countryNames {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// Splits a freeform origin in the format "Region, Country" into a country code and a region.
+// If the country is not recognised, the whole origin is returned as the region.
+func parseOrigin(origin string) (string, string) {
+	origin = strings.TrimSpace(origin)
+	if origin == "" {
+		return "", ""
+	}
+
+	i := strings.LastIndex(origin, ",")
+	if i == -1 {
+		if code, ok := lookupCountryCode(origin); ok {
+			return code, ""
+		}
+		return "", origin
+	}
+
+	if code, ok := lookupCountryCode(origin[i+1:]); ok {
+		return code, strings.TrimSpace(origin[:i])
+	}
+
+	return "", origin
+}
+
+// Splits a comma separated list of varieties and removes empty and duplicate entries.
+func parseVarieties(varieties string) []string {
+	var parsed []string
+	for _, variety := range strings.Split(varieties, ",") {
+		variety = strings.TrimSpace(variety)
+		if variety == "" {
+			continue
+		}
+
+		isDuplicate := false
+		for _, p := range parsed {
+			if strings.EqualFold(p, variety) {
+				isDuplicate = true
+				break
+			}
+		}
+		if !isDuplicate {
+			parsed = append(parsed, variety)
+		}
+	}
+
+	return parsed
+}
+
+// Maps a freeform processing method onto the controlled vocabulary.
+// Returns the process and, if the process is otherProcess, the original description.
+func parseProcess(method string) (string, string) {
+	method = strings.TrimSpace(method)
+	lower := strings.ToLower(method)
+	if lower == "" {
+		return "", ""
+	}
+
+	for _, process := range processes {
+		if lower == process {
+			return process, ""
+		}
+	}
+
+	switch {
+	case strings.Contains(lower, "carbonic"):
+		return "carbonic maceration", ""
+	case strings.Contains(lower, "anaerobic"):
+		return "anaerobic", ""
+	case strings.Contains(lower, "pulped natural"):
+		return "pulped natural", ""
+	case strings.Contains(lower, "honey"):
+		return "honey", ""
+	case strings.Contains(lower, "wet hull"), strings.Contains(lower, "wet-hull"), strings.Contains(lower, "giling basah"):
+		return "wet-hulled", ""
+	case strings.Contains(lower, "semi"):
+		return "semi-washed", ""
+	case strings.Contains(lower, "natural"), strings.Contains(lower, "dry"):
+		return "natural", ""
+	case strings.Contains(lower, "wash"), strings.Contains(lower, "wet"):
+		return "washed", ""
+	}
+
+	return otherProcess, method
+}
+
+// Returns the process for display, using the description of otherProcess if there is one.
+func formatProcess(process string, processOther string) string {
+	if process == otherProcess && processOther != "" {
+		return processOther
+	}
+	return process
+}
+
+// Returns the origin for display in the format "Region, Country".
+func formatOrigin(region string, countryCode string) string {
+	return joinNonEmpty(", ", region, countryName(countryCode))
+}
+
+// Returns the altitude range for display, e.g. "1800-2000 masl".
+func formatAltitude(altitudeMinM int, altitudeMaxM int) string {
+	switch {
+	case altitudeMinM == 0 && altitudeMaxM == 0:
+		return ""
+	case altitudeMinM == altitudeMaxM || altitudeMaxM == 0:
+		return fmt.Sprintf("%d masl", altitudeMinM)
+	default:
+		return fmt.Sprintf("%d-%d masl", altitudeMinM, altitudeMaxM)
+	}
+}
+
+// Joins the non-empty strs using sep.
+func joinNonEmpty(sep string, strs ...string) string {
+	nonEmpty := make([]string, 0, len(strs))
+	for _, str := range strs {
+		if str != "" {
+			nonEmpty = append(nonEmpty, str)
+		}
+	}
+	return strings.Join(nonEmpty, sep)
+}
@@ -0,0 +1,144 @@
+package buna
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Position of a row in a listing ordered by a key and then by id.
+// Pages start after the cursor of the last row of the previous page.
+// A nil *pageCursor is before the first row.
+type pageCursor struct {
+	key interface{}
+	id  int
+}
+
+// A listing that can be browsed page by page.
+type pagedListing struct {
+	// Retrieves up to limit rows after the cursor and returns the cursor of every retrieved row.
+	fetch func(after *pageCursor, limit int) ([]pageCursor, error)
+	// Displays the first n rows retrieved by the last fetch.
+	display func(n int) error
+	// Returns the cursor after which the rows on date start, with date in the format "YYYY-MM-DD".
+	// nil if the listing is not ordered by date.
+	dateCursor func(date string) (pageCursor, error)
+}
+
+// Returns a cursor for the rows on or before date in a listing ordered by UTC timestamp and id in descending order.
+// date is a day in the local time zone.
+func descendingDateCursor(date string) (pageCursor, error) {
+	t, err := time.ParseInLocation("2006-01-02", date, time.Local)
+	if err != nil {
+		return pageCursor{}, fmt.Errorf("buna: pager: failed to parse date: %w", err)
+	}
+
+	// No row has an id below 0, so the page starts with the last row before the next day
+	return pageCursor{key: t.AddDate(0, 0, 1).UTC().Format(time.RFC3339), id: 0}, nil
+}
+
+// Returns a cursor for the rows on or after date in a listing ordered by UTC timestamp and id in ascending order.
+// date is a day in the local time zone.
+func ascendingDateCursor(date string) (pageCursor, error) {
+	t, err := time.ParseInLocation("2006-01-02", date, time.Local)
+	if err != nil {
+		return pageCursor{}, fmt.Errorf("buna: pager: failed to parse date: %w", err)
+	}
+
+	return pageCursor{key: t.UTC().Format(time.RFC3339), id: 0}, nil
+}
+
+// Displays the listing one page of pageSize rows at a time and lets the user move to the next or previous page
+// or, if the listing is ordered by date, jump to a date.
+func browsePages(exits promptExits, listing pagedListing, pageSize int) error {
+	// Start cursor of every page up to the current page
+	pageStarts := []*pageCursor{nil}
+
+	for {
+		start := pageStarts[len(pageStarts)-1]
+
+		// One extra row tells whether there is a next page
+		cursors, err := listing.fetch(start, pageSize+1)
+		if err != nil {
+			return fmt.Errorf("buna: pager: failed to fetch page: %w", err)
+		}
+
+		if len(cursors) == 0 {
+			if start == nil {
+				fmt.Println("Nothing to display")
+				return nil
+			}
+			fmt.Println("No rows on this page")
+		}
+
+		hasNext := len(cursors) > pageSize
+		rowCount := len(cursors)
+		if hasNext {
+			rowCount = pageSize
+		}
+
+		if rowCount > 0 {
+			if err := listing.display(rowCount); err != nil {
+				return fmt.Errorf("buna: pager: failed to display page: %w", err)
+			}
+		}
+
+		actions := []string{}
+		if hasNext {
+			actions = append(actions, "n: next page")
+		}
+		if len(pageStarts) > 1 {
+			actions = append(actions, "p: previous page")
+		}
+		if listing.dateCursor != nil {
+			actions = append(actions, "d: jump to date")
+		}
+		if len(actions) == 0 {
+			return nil
+		}
+
+		fmt.Printf("Page %d (%v, Enter %v to quit): ", len(pageStarts), strings.Join(actions, ", "), exits.quit)
+		next, quit, err := getPageNavigationInput(exits, listing, pageStarts, cursors, rowCount, hasNext)
+		if err != nil {
+			return fmt.Errorf("buna: pager: failed to get page navigation input: %w", err)
+		}
+		if quit {
+			return nil
+		}
+
+		pageStarts = next
+	}
+}
+
+// Prompts user until a valid page navigation is entered.
+// Returns the start cursors of the pages up to the page to display next, didQuit, error
+func getPageNavigationInput(exits promptExits, listing pagedListing, pageStarts []*pageCursor, cursors []pageCursor, rowCount int, hasNext bool) ([]*pageCursor, bool, error) {
+	scanner := exits.input
+	for {
+		scanner.Scan()
+		switch input := strings.TrimSpace(scanner.Text()); {
+		case input == exits.quit || input == "":
+			return nil, true, nil
+		case input == "n" && hasNext:
+			last := cursors[rowCount-1]
+			return append(pageStarts, &last), false, nil
+		case input == "p" && len(pageStarts) > 1:
+			return pageStarts[:len(pageStarts)-1], false, nil
+		case input == "d" && listing.dateCursor != nil:
+			jumpDate, quit := getDateInput(exits, false, "Enter the date to jump to: ", nil)
+			if quit != answered {
+				return nil, true, nil
+			}
+
+			cursor, err := listing.dateCursor(createDateString(jumpDate))
+			if err != nil {
+				return nil, false, fmt.Errorf("buna: pager: failed to get date cursor: %w", err)
+			}
+
+			// Going back from a jump returns to the page the jump was made from
+			return append(pageStarts, &cursor), false, nil
+		default:
+			fmt.Print("Invalid option. Please try again: ")
+		}
+	}
+}
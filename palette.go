@@ -0,0 +1,324 @@
+package buna
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"golang.org/x/crypto/ssh/terminal"
+)
+
+// A main menu option as it can be found by the command palette.
+type paletteCommand struct {
+	code      string
+	name      string
+	selection selection
+}
+
+// Returns all main menu options ordered by their code.
+func paletteCommands() []paletteCommand {
+	var commands []paletteCommand
+	for cat, catOptions := range options {
+		for idx, description := range catOptions {
+			commands = append(commands, paletteCommand{
+				code:      categoryRefs[cat] + strconv.Itoa(idx),
+				name:      description,
+				selection: selection{category: cat, index: idx},
+			})
+		}
+	}
+
+	sort.Slice(commands, func(i, j int) bool {
+		if commands[i].selection.category != commands[j].selection.category {
+			return commands[i].selection.category < commands[j].selection.category
+		}
+		return commands[i].selection.index < commands[j].selection.index
+	})
+
+	return commands
+}
+
+// Resolves the input of the main prompt to the matching options and the answers to queue for the prompts of the option.
+// The input starts with an option code like "B0", an alias from the config or words of an option's description
+// like "avg rating", and may be followed by answers, e.g. "B0 1" or "retrieve brewing 1".
+// More than one command is returned if the description words are ambiguous.
+func resolvePaletteInput(cfg Config, input string) ([]paletteCommand, []string) {
+	tokens := splitPaletteInput(input)
+	if len(tokens) == 0 {
+		return nil, nil
+	}
+
+	if alias, ok := cfg.aliases[strings.ToLower(tokens[0])]; ok {
+		tokens = append(splitPaletteInput(alias), tokens[1:]...)
+		if len(tokens) == 0 {
+			return nil, nil
+		}
+	}
+
+	commands := paletteCommands()
+	for _, command := range commands {
+		if strings.EqualFold(tokens[0], command.code) {
+			return []paletteCommand{command}, tokens[1:]
+		}
+	}
+
+	// Answers start at the first number, as the descriptions contain none
+	words := tokens
+	var answers []string
+	for i, token := range tokens {
+		if _, err := strconv.Atoi(token); err == nil {
+			words = tokens[:i]
+			answers = tokens[i:]
+			break
+		}
+	}
+
+	return matchPaletteCommands(commands, words), answers
+}
+
+// Returns the commands whose descriptions match all words, preferring the descriptions with the fewest other words.
+func matchPaletteCommands(commands []paletteCommand, words []string) []paletteCommand {
+	if len(words) == 0 {
+		return nil
+	}
+
+	var matches []paletteCommand
+	fewestUnmatched := -1
+	for _, command := range commands {
+		nameWords := strings.Fields(strings.ToLower(command.name))
+		matched := make([]bool, len(nameWords))
+
+		matchesAll := true
+		for _, word := range words {
+			word = strings.ToLower(word)
+			found := false
+			for i, nameWord := range nameWords {
+				if paletteWordMatches(word, nameWord) {
+					matched[i] = true
+					found = true
+				}
+			}
+			if !found {
+				matchesAll = false
+				break
+			}
+		}
+		if !matchesAll {
+			continue
+		}
+
+		unmatched := 0
+		for _, m := range matched {
+			if !m {
+				unmatched++
+			}
+		}
+
+		switch {
+		case fewestUnmatched < 0 || unmatched < fewestUnmatched:
+			matches = []paletteCommand{command}
+			fewestUnmatched = unmatched
+		case unmatched == fewestUnmatched:
+			matches = append(matches, command)
+		}
+	}
+
+	return matches
+}
+
+// A word of the input matches a word of a description if it is an abbreviation of it, e.g. "avg" for "average",
+// or if it only differs by a typo.
+func paletteWordMatches(word string, nameWord string) bool {
+	const minTypoCheckLength = 4
+
+	if word == "" || nameWord == "" {
+		return false
+	}
+
+	if word[0] == nameWord[0] && isSubsequence(word, nameWord) {
+		return true
+	}
+
+	return len(word) >= minTypoCheckLength && levenshteinDistance(word, nameWord) <= 1
+}
+
+// Whether all runes of sub appear in s in the same order.
+func isSubsequence(sub string, s string) bool {
+	rs := []rune(s)
+	i := 0
+	for _, r := range sub {
+		for i < len(rs) && rs[i] != r {
+			i++
+		}
+		if i == len(rs) {
+			return false
+		}
+		i++
+	}
+
+	return true
+}
+
+// Splits the input at whitespace, except within double quotes, so answers like "Square Mile" can be chained.
+func splitPaletteInput(input string) []string {
+	var tokens []string
+	var token strings.Builder
+	inQuotes := false
+	hasToken := false
+
+	for _, r := range input {
+		switch {
+		case r == '"':
+			inQuotes = !inQuotes
+			hasToken = true
+		case unicode.IsSpace(r) && !inQuotes:
+			if hasToken {
+				tokens = append(tokens, token.String())
+				token.Reset()
+				hasToken = false
+			}
+		default:
+			token.WriteRune(r)
+			hasToken = true
+		}
+	}
+	if hasToken {
+		tokens = append(tokens, token.String())
+	}
+
+	return tokens
+}
+
+// Returns the inputs that can be completed with Tab at the main prompt, i.e. option descriptions and aliases.
+func paletteCompletions(cfg Config) []string {
+	var completions []string
+	for _, command := range paletteCommands() {
+		completions = append(completions, strings.ToLower(command.name))
+	}
+	for alias := range cfg.aliases {
+		completions = append(completions, alias)
+	}
+	sort.Strings(completions)
+
+	return completions
+}
+
+// Reads a line of the main prompt. If stdin is a terminal and scanner has no queued answers, the line is read
+// in raw mode so that Tab can complete it from completions. It returns true if Ctrl-C or Ctrl-D was pressed.
+func readPaletteInput(scanner *inputScanner, prompt string, completions []string) (string, bool, error) {
+	fd := int(os.Stdin.Fd())
+	if len(scanner.queued) > 0 || !terminal.IsTerminal(fd) {
+		fmt.Print(prompt)
+		scanner.Scan()
+		return scanner.Text(), false, nil
+	}
+
+	oldState, err := terminal.MakeRaw(fd)
+	if err != nil {
+		return "", false, fmt.Errorf("buna: palette: failed to put terminal into raw mode: %w", err)
+	}
+	defer terminal.Restore(fd, oldState) // nolint:errcheck
+
+	// Output needs explicit carriage returns in raw mode
+	fmt.Print(prompt)
+	var line []rune
+	for {
+		r, _, err := stdinReader.ReadRune()
+		if err != nil {
+			fmt.Print("\r\n")
+			return string(line), false, nil
+		}
+
+		switch r {
+		case '\r', '\n':
+			fmt.Print("\r\n")
+			return string(line), false, nil
+		case 0x03, 0x04:
+			fmt.Print("\r\n")
+			return "", true, nil
+		case 0x7f, 0x08:
+			if len(line) > 0 {
+				line = line[:len(line)-1]
+				fmt.Print("\b \b")
+			}
+		case '\t':
+			matches := completionsWithPrefix(completions, string(line))
+			if len(matches) == 0 {
+				continue
+			}
+
+			if prefix := commonPrefix(matches); len([]rune(prefix)) > len(line) {
+				line = []rune(prefix)
+				if len(matches) == 1 {
+					line = append(line, ' ')
+				}
+			} else if len(matches) > 1 {
+				fmt.Print("\r\n" + strings.Join(matches, "  ") + "\r\n")
+			}
+			fmt.Print("\r" + prompt + string(line) + "\x1b[K")
+		case 0x1b:
+			skipEscapeSequence()
+		default:
+			if unicode.IsPrint(r) {
+				line = append(line, r)
+				fmt.Print(string(r))
+			}
+		}
+	}
+}
+
+// Drops the rest of an escape sequence such as an arrow key, whose escape has already been read.
+func skipEscapeSequence() {
+	if stdinReader.Buffered() == 0 {
+		return
+	}
+
+	b, err := stdinReader.ReadByte()
+	if err != nil || b != '[' && b != 'O' {
+		return
+	}
+
+	// The sequence ends with a byte in the range @ to ~
+	for stdinReader.Buffered() > 0 {
+		b, err := stdinReader.ReadByte()
+		if err != nil || b >= 0x40 && b <= 0x7e {
+			return
+		}
+	}
+}
+
+// Returns the completions starting with prefix, ignoring case.
+func completionsWithPrefix(completions []string, prefix string) []string {
+	prefix = strings.ToLower(prefix)
+
+	var matches []string
+	for _, completion := range completions {
+		if strings.HasPrefix(completion, prefix) {
+			matches = append(matches, completion)
+		}
+	}
+
+	return matches
+}
+
+// Returns the longest prefix shared by all strings.
+func commonPrefix(strs []string) string {
+	if len(strs) == 0 {
+		return ""
+	}
+
+	prefix := []rune(strs[0])
+	for _, s := range strs[1:] {
+		rs := []rune(s)
+		n := 0
+		for n < len(prefix) && n < len(rs) && prefix[n] == rs[n] {
+			n++
+		}
+		prefix = prefix[:n]
+	}
+
+	return string(prefix)
+}
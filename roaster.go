@@ -0,0 +1,452 @@
+package buna
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/jedib0t/go-pretty/table"
+	"golang.org/x/crypto/ssh/terminal"
+)
+
+type roaster struct {
+	id      int
+	name    string
+	country string
+	city    string
+	website string
+	notes   string
+}
+
+type roasterStatistics struct {
+	roasterName     string
+	coffeeCount     int
+	purchaseCount   int
+	averageRating   float64
+	favouriteOrigin string
+}
+
+// Returns the added roaster.
+// The user is only prompted for the roaster name if name is empty.
+func addRoaster(ctx context.Context, db DB, name string) (roaster, error) {
+	session := sessionFromContext(ctx)
+	fmt.Println("Adding new roaster (Enter " + session.quit + " to quit):")
+
+	if name == "" {
+		var quit promptResult
+		fmt.Print("Enter roaster name: ")
+		name, quit = validateStrInput(quitExits(ctx), false, nil, nil)
+		if quit != answered {
+			fmt.Println(quitMsg)
+			return roaster{}, nil
+		}
+	} else {
+		fmt.Println("Roaster name: " + name)
+	}
+
+	fmt.Print("Enter country: ")
+	country, quit := validateStrInput(quitExits(ctx), true, nil, nil)
+	if quit != answered {
+		fmt.Println(quitMsg)
+		return roaster{}, nil
+	}
+
+	fmt.Print("Enter city: ")
+	city, quit := validateStrInput(quitExits(ctx), true, nil, nil)
+	if quit != answered {
+		fmt.Println(quitMsg)
+		return roaster{}, nil
+	}
+
+	fmt.Print("Enter website: ")
+	website, quit := validateStrInput(quitExits(ctx), true, nil, nil)
+	if quit != answered {
+		fmt.Println(quitMsg)
+		return roaster{}, nil
+	}
+
+	notes, quit := getNotes(quitExits(ctx), true, "roaster")
+	if quit != answered {
+		fmt.Println(quitMsg)
+		return roaster{}, nil
+	}
+
+	newRoaster := roaster{
+		name:    name,
+		country: country,
+		city:    city,
+		website: website,
+		notes:   notes,
+	}
+
+	if err := db.insertRoaster(ctx, newRoaster); err != nil {
+		return roaster{}, fmt.Errorf("buna: roaster: failed to insert roaster: %w", err)
+	}
+
+	fmt.Println("Added roaster successfully")
+	return newRoaster, nil
+}
+
+// Returns the stored name of the roaster, which might differ in case from name.
+// If it does not exist, the user can choose a roaster with a similar name instead, create it and continue after printing resumeMsg
+// or enter a different name, in which case the returned name is empty.
+// Returns roasterName, promptResult, error
+func getExistingRoasterName(ctx context.Context, db DB, exits promptExits, name string, resumeMsg string) (string, promptResult, error) {
+	existingRoaster, err := db.getRoasterByName(ctx, name)
+	if err == nil {
+		return existingRoaster.name, answered, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return "", answered, fmt.Errorf("buna: roaster: failed to get roaster by name: %w", err)
+	}
+
+	similarNames, err := db.getSimilarRoasterNames(ctx, name, maxSimilarNames)
+	if err != nil {
+		return "", answered, fmt.Errorf("buna: roaster: failed to get similar roaster names: %w", err)
+	}
+
+	selection, quit := getDidYouMeanSelection(exits, "roaster", name, similarNames)
+	if quit != answered {
+		return "", quit, nil
+	}
+	if selection == reenterSelection {
+		return "", answered, nil
+	}
+	if selection >= 0 {
+		return similarNames[selection], answered, nil
+	}
+
+	addedRoaster, err := addRoaster(ctx, db, name)
+	if err != nil {
+		return "", answered, fmt.Errorf("buna: roaster: failed to add roaster: %w", err)
+	}
+	if addedRoaster.name == "" {
+		return "", quitPrompt, nil
+	}
+
+	if resumeMsg != "" {
+		fmt.Println(resumeMsg)
+	}
+
+	return addedRoaster.name, answered, nil
+}
+
+// Offers to merge every pair of roasters whose names look like the same roaster.
+// Pairs that the user chooses to keep separate are not offered again.
+func promptRoasterMerges(ctx context.Context, db DB) error {
+	candidates, err := db.getRoasterMergeCandidates(ctx)
+	if err != nil {
+		return fmt.Errorf("buna: roaster: failed to get roaster merge candidates: %w", err)
+	}
+
+	if len(candidates) == 0 {
+		return nil
+	}
+
+	session := sessionFromContext(ctx)
+	fmt.Println("Some roasters look like duplicates of each other (Enter " + session.quit + " to decide later):")
+	for _, candidate := range candidates {
+		options := map[int]string{
+			0: "Keep both roasters",
+			1: "Merge into '" + candidate[0] + "'",
+			2: "Merge into '" + candidate[1] + "'",
+		}
+
+		fmt.Println("\n'" + candidate[0] + "' and '" + candidate[1] + "'")
+		if err := displayIntOptions(ctx, options); err != nil {
+			return fmt.Errorf("buna: roaster: failed to display int options: %w", err)
+		}
+
+		selection, quit, err := getIntSelection(ctx, options, quitExits(ctx))
+		if err != nil {
+			return fmt.Errorf("buna: roaster: failed to get int selection: %w", err)
+		}
+		if quit != answered {
+			fmt.Println(quitMsg)
+			return nil
+		}
+
+		switch selection {
+		case 0:
+			if err := db.dismissRoasterMerge(ctx, candidate[0], candidate[1]); err != nil {
+				return fmt.Errorf("buna: roaster: failed to dismiss roaster merge: %w", err)
+			}
+		case 1:
+			if err := db.mergeRoasters(ctx, candidate[1], candidate[0]); err != nil {
+				return fmt.Errorf("buna: roaster: failed to merge roasters: %w", err)
+			}
+			fmt.Println("Merged roasters successfully")
+		case 2:
+			if err := db.mergeRoasters(ctx, candidate[0], candidate[1]); err != nil {
+				return fmt.Errorf("buna: roaster: failed to merge roasters: %w", err)
+			}
+			fmt.Println("Merged roasters successfully")
+		default:
+			return errors.New("buna: roaster: invalid merge selection")
+		}
+	}
+
+	// Earlier merges might have removed roasters of later candidates
+	return promptRoasterMerges(ctx, db)
+}
+
+func retrieveRoaster(ctx context.Context, db DB) error {
+	options := map[int]string{
+		0: "Retrieve roasters ordered by last added",
+		1: "Retrieve roasters ordered alphabetically",
+		2: "Retrieve roaster by name",
+	}
+
+	session := sessionFromContext(ctx)
+	fmt.Println("Retrieving roasters (Enter " + session.quit + " to quit):")
+	if err := displayIntOptions(ctx, options); err != nil {
+		return fmt.Errorf("buna: roaster: failed to display int options: %w", err)
+	}
+
+	selection, quit, err := getIntSelection(ctx, options, quitExits(ctx))
+	if err != nil {
+		return fmt.Errorf("buna: roaster: failed to get int selection: %w", err)
+	}
+	if quit != answered {
+		fmt.Println(quitMsg)
+		return nil
+	}
+
+	if err := runRetrieveRoasterSelection(ctx, selection, db); err != nil {
+		return fmt.Errorf("buna: roaster: failed to run the retrieve selection: %w", err)
+	}
+
+	return nil
+}
+
+func runRetrieveRoasterSelection(ctx context.Context, selection int, db DB) error {
+	switch selection {
+	case 0:
+		if err := displayRoastersByLastAdded(ctx, db); err != nil {
+			return fmt.Errorf("buna: roaster: failed to display roasters by last added: %w", err)
+		}
+	case 1:
+		if err := displayRoastersAlphabetically(ctx, db); err != nil {
+			return fmt.Errorf("buna: roaster: failed to display roasters alphabetically: %w", err)
+		}
+	case 2:
+		if err := displayRoasterByName(ctx, db); err != nil {
+			return fmt.Errorf("buna: roaster: failed to display roaster by name: %w", err)
+		}
+	default:
+		return errors.New("buna: roaster: invalid retrieve selection")
+	}
+	return nil
+}
+
+// Promts user for an optional page size.
+func displayRoastersByLastAdded(ctx context.Context, db DB) error {
+	session := sessionFromContext(ctx)
+	fmt.Println("Displaying roasters by last added (Enter " + session.quit + " to quit):")
+
+	pageSize, quit := getRoasterPageSize(ctx)
+	if quit != answered {
+		fmt.Println(quitMsg)
+		return nil
+	}
+
+	if err := browseRoasters(ctx, pageSize, func(after *pageCursor, limit int) ([]roaster, error) {
+		return db.getRoastersByLastAdded(ctx, after, limit)
+	}, false); err != nil {
+		return fmt.Errorf("buna: roaster: failed to browse roasters by last added: %w", err)
+	}
+
+	return nil
+}
+
+// Promts user for an optional page size.
+func displayRoastersAlphabetically(ctx context.Context, db DB) error {
+	session := sessionFromContext(ctx)
+	fmt.Println("Displaying roasters alphabetically (Enter " + session.quit + " to quit):")
+
+	pageSize, quit := getRoasterPageSize(ctx)
+	if quit != answered {
+		fmt.Println(quitMsg)
+		return nil
+	}
+
+	if err := browseRoasters(ctx, pageSize, func(after *pageCursor, limit int) ([]roaster, error) {
+		return db.getRoastersAlphabetically(ctx, after, limit)
+	}, true); err != nil {
+		return fmt.Errorf("buna: roaster: failed to browse roasters alphabetically: %w", err)
+	}
+
+	return nil
+}
+
+// Displays the roaster details followed by the roaster statistics.
+func displayRoasterByName(ctx context.Context, db DB) error {
+	session := sessionFromContext(ctx)
+	fmt.Println("Displaying roaster by name (Enter " + session.quit + " to quit):")
+
+	name, quit, err := getRoasterNameWithSuggestions(ctx, db, quitExits(ctx), false)
+	if err != nil {
+		return fmt.Errorf("buna: roaster: failed to get roaster name: %w", err)
+	}
+	if quit != answered {
+		fmt.Println(quitMsg)
+		return nil
+	}
+
+	r, err := db.getRoasterByName(ctx, name)
+	if errors.Is(err, sql.ErrNoRows) {
+		fmt.Println("No roaster with this name exists")
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("buna: roaster: failed to get roaster by name: %w", err)
+	}
+
+	if err := displayRoasters(ctx, []roaster{r}); err != nil {
+		return fmt.Errorf("buna: roaster: failed to display roaster: %w", err)
+	}
+
+	statistics, err := db.getRoasterStatistics(ctx)
+	if err != nil {
+		return fmt.Errorf("buna: roaster: failed to get roaster statistics: %w", err)
+	}
+
+	for _, stats := range statistics {
+		if stats.roasterName == r.name {
+			if err := displayRoasterStatistics(ctx, []roasterStatistics{stats}); err != nil {
+				return fmt.Errorf("buna: roaster: failed to display roaster statistics: %w", err)
+			}
+		}
+	}
+
+	return nil
+}
+
+func displayRoasters(ctx context.Context, roasters []roaster) error {
+	const maxNoteFieldWidth = 50
+
+	t := table.NewWriter()
+
+	t.AppendHeader(table.Row{
+		"Name",
+		"Country",
+		"City",
+		"Website",
+		"Notes",
+	})
+
+	for _, r := range roasters {
+		t.AppendRow(table.Row{
+			r.name,
+			r.country,
+			r.city,
+			r.website,
+			splitTextIntoField(r.notes, maxNoteFieldWidth),
+		})
+		t.AppendSeparator()
+	}
+
+	terminalWidth, _, err := terminal.GetSize(int(os.Stdin.Fd()))
+	if err != nil {
+		return fmt.Errorf("buna: roaster: failed to get terminal width: %w", err)
+	}
+	t.SetAllowedRowLength(terminalWidth)
+
+	t.SetOutputMirror(os.Stdout)
+	renderTable(ctx, t)
+
+	return nil
+}
+
+func displayRoasterStatistics(ctx context.Context, statistics []roasterStatistics) error {
+	t := table.NewWriter()
+
+	t.AppendHeader(table.Row{
+		"Roaster",
+		"Coffees",
+		"Coffees\nBought",
+		"Average\nRating",
+		"Favourite\nOrigin",
+	})
+
+	for _, stats := range statistics {
+		averageRating := "None"
+		if stats.averageRating != 0 {
+			averageRating = strconv.FormatFloat(stats.averageRating, 'f', 1, 64)
+		}
+
+		t.AppendRow(table.Row{
+			stats.roasterName,
+			stats.coffeeCount,
+			stats.purchaseCount,
+			averageRating,
+			stats.favouriteOrigin,
+		})
+		t.AppendSeparator()
+	}
+
+	terminalWidth, _, err := terminal.GetSize(int(os.Stdin.Fd()))
+	if err != nil {
+		return fmt.Errorf("buna: roaster: failed to get terminal width: %w", err)
+	}
+	t.SetAllowedRowLength(terminalWidth)
+
+	t.SetOutputMirror(os.Stdout)
+	renderTable(ctx, t)
+
+	return nil
+}
+
+// Returns pageSize, promptResult
+func getRoasterPageSize(ctx context.Context) (int, promptResult) {
+	const defaultPageSize = 20
+	const maxPageSize = 60
+
+	fmt.Print("Enter the number of roasters to display per page: ")
+	pageSize, quit := validateIntInput(quitExits(ctx), true, 1, maxPageSize, []int{})
+	if quit != answered {
+		return 0, quit
+	}
+
+	if pageSize == 0 {
+		pageSize = defaultPageSize
+	}
+
+	return pageSize, answered
+}
+
+// Displays the roasters retrieved by getRoasters page by page.
+// byName is true if the roasters are ordered by name and id instead of only by id.
+func browseRoasters(ctx context.Context, pageSize int, getRoasters func(after *pageCursor, limit int) ([]roaster, error), byName bool) error {
+	var roasters []roaster
+	listing := pagedListing{
+		fetch: func(after *pageCursor, limit int) ([]pageCursor, error) {
+			var err error
+			roasters, err = getRoasters(after, limit)
+			if err != nil {
+				return nil, fmt.Errorf("buna: roaster: failed to get roasters: %w", err)
+			}
+
+			cursors := make([]pageCursor, len(roasters))
+			for i, r := range roasters {
+				cursors[i].id = r.id
+				if byName {
+					cursors[i].key = r.name
+				}
+			}
+			return cursors, nil
+		},
+		display: func(n int) error {
+			return displayRoasters(ctx, roasters[:n])
+		},
+	}
+
+	if err := browsePages(quitExits(ctx), listing, pageSize); err != nil {
+		return fmt.Errorf("buna: roaster: failed to browse pages: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,61 @@
+package buna
+
+import (
+	"context"
+	"fmt"
+)
+
+// The settings a run of buna uses and the reader of its prompts.
+// Run, RunTUI and RunCommand start a session from the config and carry it in the context.
+type session struct {
+	config Config
+	// Inputs that quit an entry and go back to the previous prompt
+	quit string
+	back string
+	// Unit system used for input and display, see units.go
+	unitSystem unitSystem
+	// Reads the answers to all prompts of the session
+	input *inputScanner
+}
+
+type sessionContextKey struct{}
+
+// Returns a session with the settings of cfg and the metric unit system.
+func newSession(cfg Config) *session {
+	return &session{
+		config:     cfg,
+		quit:       cfg.text("input.quit"),
+		back:       cfg.text("input.back"),
+		unitSystem: metricUnits,
+		input:      &inputScanner{},
+	}
+}
+
+// Returns a context with a session that runs with cfg and the unit system of the stored preference.
+func startSession(ctx context.Context, db DB, cfg Config) (context.Context, error) {
+	if err := cfg.check(); err != nil {
+		return nil, fmt.Errorf("buna: session: invalid config: %w", err)
+	}
+
+	s := newSession(cfg)
+	system, err := getUnitSystemPreference(ctx, db, cfg)
+	if err != nil {
+		return nil, fmt.Errorf("buna: session: failed to get unit system preference: %w", err)
+	}
+	s.unitSystem = system
+
+	return withSession(ctx, s), nil
+}
+
+func withSession(ctx context.Context, s *session) context.Context {
+	return context.WithValue(ctx, sessionContextKey{}, s)
+}
+
+// Returns the session of the context, or a session with the default settings if there is none.
+func sessionFromContext(ctx context.Context) *session {
+	if s, ok := ctx.Value(sessionContextKey{}).(*session); ok {
+		return s
+	}
+
+	return newSession(Config{})
+}
@@ -15,6 +15,11 @@ type SQLiteDB struct {
 }
 
 func OpenSQLiteDB(ctx context.Context, logger *zap.Logger, dsn string) (*SQLiteDB, error) {
+	return openSQLiteDB(ctx, logger, dsn, migrations)
+}
+
+// Opens the database and brings its schema up to the version after the last of migrations.
+func openSQLiteDB(ctx context.Context, logger *zap.Logger, dsn string, migrations []func(ctx context.Context, tx *sql.Tx) error) (*SQLiteDB, error) {
 	db, err := sql.Open("sqlite3", dsn)
 	if err != nil {
 		return nil, fmt.Errorf("buna: sqlite_db_general: failed to open sqlite db: %w", err)
@@ -30,7 +35,7 @@ func OpenSQLiteDB(ctx context.Context, logger *zap.Logger, dsn string) (*SQLiteD
 		logger: logger,
 	}
 
-	if err := s.migrate(ctx); err != nil {
+	if err := s.migrate(ctx, migrations); err != nil {
 		s.Close()
 		return nil, fmt.Errorf("buna: sqlite_db_general: failed to migrate SQLite database: %w", err)
 	}
@@ -38,7 +43,7 @@ func OpenSQLiteDB(ctx context.Context, logger *zap.Logger, dsn string) (*SQLiteD
 	return s, nil
 }
 
-func (s *SQLiteDB) migrate(ctx context.Context) error {
+func (s *SQLiteDB) migrate(ctx context.Context, migrations []func(ctx context.Context, tx *sql.Tx) error) error {
 	if err := s.TransactContext(ctx, func(ctx context.Context, tx *sql.Tx) error {
 		if _, err := tx.ExecContext(ctx, `
 			CREATE TABLE IF NOT EXISTS coffees (
@@ -162,6 +167,30 @@ func (s *SQLiteDB) migrate(ctx context.Context) error {
 			return fmt.Errorf("buna: sqlite_db_general: failed to create cupped_coffees table: %w", err)
 		}
 
+		var version int
+		if err := tx.QueryRowContext(ctx, "PRAGMA user_version").Scan(&version); err != nil {
+			return fmt.Errorf("buna: sqlite_db_general: failed to retrieve schema version: %w", err)
+		}
+
+		if version > len(migrations) {
+			return fmt.Errorf("buna: sqlite_db_general: database schema version %v is newer than the supported version %v", version, len(migrations))
+		}
+
+		for i := version; i < len(migrations); i++ {
+			if err := migrations[i](ctx, tx); err != nil {
+				return fmt.Errorf("buna: sqlite_db_general: failed to migrate to schema version %v: %w", i+1, err)
+			}
+		}
+
+		if err := syncNotesSearch(ctx, tx); err != nil {
+			return fmt.Errorf("buna: sqlite_db_general: failed to sync notes search: %w", err)
+		}
+
+		// PRAGMA statements do not support parameters
+		if _, err := tx.ExecContext(ctx, fmt.Sprintf("PRAGMA user_version = %d", len(migrations))); err != nil {
+			return fmt.Errorf("buna: sqlite_db_general: failed to set schema version: %w", err)
+		}
+
 		return nil
 	}); err != nil {
 		return fmt.Errorf("buna: sqlite_db_general: transaction failed: %w", err)
@@ -3,35 +3,34 @@ package buna
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 )
 
 func (s *SQLiteDB) insertBrewing(ctx context.Context, brewing brewing) error {
 	if err := s.TransactContext(ctx, func(ctx context.Context, tx *sql.Tx) error {
-		coffeeID, err := s.getCoffeeIDByNameRoaster(ctx, brewing.coffeeName, brewing.coffeeRoaster)
+		coffeeID, err := getCoffeeID(ctx, tx, brewing.coffeeName, brewing.coffeeRoaster)
 		if err != nil {
-			fmt.Println("Unable to link this brewing to an existing coffee. Please create a new coffee first and then try again.")
-			return nil
+			return fmt.Errorf("buna: sqlite_db_insert: unable to link the brewing to a coffee: %w", err)
 		}
 
-		methodID, err := s.getMethodIDByName(ctx, brewing.brewingMethodName)
+		methodID, err := getIDByName(ctx, tx, "brewing_methods", "brewing method", brewing.brewingMethodName)
 		if err != nil {
-			fmt.Println("Unable to link this brewing to an existing brewing method. Please create a new brewing method first and then try again.")
-			return nil
+			return fmt.Errorf("buna: sqlite_db_insert: unable to link the brewing to a brewing method: %w", err)
 		}
 
-		grinderID, err := s.getGrinderIDByName(ctx, brewing.grinderName)
+		grinderID, err := getIDByName(ctx, tx, "grinders", "grinder", brewing.grinderName)
 		if err != nil {
-			fmt.Println("Unable to link this brewing to an existing coffee grinder. Please create a new coffee grinder first and then try again.")
-			return nil
+			return fmt.Errorf("buna: sqlite_db_insert: unable to link the brewing to a grinder: %w", err)
 		}
 
-		if _, err := tx.ExecContext(ctx, `
+		res, err := tx.ExecContext(ctx, `
 			INSERT INTO brewings(
 				coffee_id,
 				method_id,
 				grinder_id,
 				date,
+				date_utc,
 				roast_date,
 				grind_setting,
 				total_brewing_time_sec,
@@ -39,31 +38,49 @@ func (s *SQLiteDB) insertBrewing(ctx context.Context, brewing brewing) error {
 				coffee_grams,
 				v60_filter_type,
 				rating,
+				sweetness,
+				acidity,
+				bitterness,
+				body,
+				clarity,
+				astringency,
+				extraction,
 				recommended_grind_setting_adjustment,
 				recommended_coffee_weight_adjustment_grams,
-				notes
+				notes,
+				user_id
 			)
 			VALUES (
 				:coffeeID,
 				:methodID,
 				:grinderID,
 				:date,
-				:roastDate,
+				:dateUTC,
+				NULLIF(:roastDate, ""),
 				:grindSetting,
 				:totalBrewingTimeSec,
 				:waterGrams,
 				:coffeeGrams,
-				:v60FilterType,
-				:rating,
-				:recommendedGrindSettingAdjustment,
+				NULLIF(:v60FilterType, ""),
+				NULLIF(:rating, 0),
+				NULLIF(:sweetness, 0),
+				NULLIF(:acidity, 0),
+				NULLIF(:bitterness, 0),
+				NULLIF(:body, 0),
+				NULLIF(:clarity, 0),
+				NULLIF(:astringency, 0),
+				NULLIF(:extraction, ""),
+				NULLIF(:recommendedGrindSettingAdjustment, ""),
 				:recommendedCoffeeWeightAdjustmentGrams,
-				:notes
+				:notes,
+				NULLIF(:userID, 0)
 			)
 		`,
 			sql.Named("coffeeID", coffeeID),
 			sql.Named("methodID", methodID),
 			sql.Named("grinderID", grinderID),
 			sql.Named("date", brewing.date),
+			sql.Named("dateUTC", utcTimestamp(brewing.date)),
 			sql.Named("roastDate", brewing.roastDate),
 			sql.Named("grindSetting", brewing.grindSetting),
 			sql.Named("totalBrewingTimeSec", brewing.totalBrewingTimeSec),
@@ -71,25 +88,29 @@ func (s *SQLiteDB) insertBrewing(ctx context.Context, brewing brewing) error {
 			sql.Named("coffeeGrams", brewing.coffeeGrams),
 			sql.Named("v60FilterType", brewing.v60FilterType),
 			sql.Named("rating", brewing.rating),
+			sql.Named("sweetness", brewing.scores.sweetness),
+			sql.Named("acidity", brewing.scores.acidity),
+			sql.Named("bitterness", brewing.scores.bitterness),
+			sql.Named("body", brewing.scores.body),
+			sql.Named("clarity", brewing.scores.clarity),
+			sql.Named("astringency", brewing.scores.astringency),
+			sql.Named("extraction", brewing.extraction),
 			sql.Named("recommendedGrindSettingAdjustment", brewing.recommendedGrindSettingAdjustment),
 			sql.Named("recommendedCoffeeWeightAdjustmentGrams", brewing.recommendedCoffeeWeightAdjustmentGrams),
 			sql.Named("notes", brewing.notes),
-		); err != nil {
+			userArg(ctx),
+		)
+		if err != nil {
 			return fmt.Errorf("buna: sqlite_db_insert: failed to insert coffee brewing into db: %w", err)
 		}
 
-		if _, err := tx.ExecContext(ctx, `
-			UPDATE brewings
-			SET roast_date = NULLIF(roast_date, "0-00-00"),
-				v60_filter_type = NULLIF(v60_filter_type, ""),
-				rating = NULLIF(rating, 0),
-				recommended_grind_setting_adjustment = NULLIF(recommended_grind_setting_adjustment, "")
-			WHERE coffee_id = :coffeeID AND date = :date
-		`,
-			sql.Named("coffeeID", coffeeID),
-			sql.Named("date", brewing.date),
-		); err != nil {
-			return fmt.Errorf("buna: sqlite_db_insert: failed to set null values: %w", err)
+		brewingID, err := res.LastInsertId()
+		if err != nil {
+			return fmt.Errorf("buna: sqlite_db_insert: failed to get brewing id: %w", err)
+		}
+
+		if err := insertBrewingFlavors(ctx, tx, int(brewingID), brewing.flavors); err != nil {
+			return fmt.Errorf("buna: sqlite_db_insert: failed to insert brewing flavors: %w", err)
 		}
 
 		return nil
@@ -119,31 +140,65 @@ func (s *SQLiteDB) insertBrewingMethod(ctx context.Context, brewingMethod brewin
 
 func (s *SQLiteDB) insertCoffee(ctx context.Context, coffee coffee) error {
 	if err := s.TransactContext(ctx, func(ctx context.Context, tx *sql.Tx) error {
-		if _, err := tx.ExecContext(ctx, `
-			INSERT INTO coffees(name, roaster, region, variety, method, decaf)
-			VALUES (:name, :roaster, :region, :variety, :method, :decaf)
+		res, err := tx.ExecContext(ctx, `
+			INSERT INTO coffees(
+				name,
+				roaster_id,
+				country_code,
+				region,
+				farm,
+				producer,
+				altitude_min_m,
+				altitude_max_m,
+				process,
+				process_other,
+				decaf
+			)
+			SELECT 	:name,
+					id,
+					NULLIF(:countryCode, ""),
+					NULLIF(:region, ""),
+					NULLIF(:farm, ""),
+					NULLIF(:producer, ""),
+					NULLIF(:altitudeMinM, 0),
+					NULLIF(:altitudeMaxM, 0),
+					NULLIF(:process, ""),
+					NULLIF(:processOther, ""),
+					:decaf
+			FROM roasters
+			WHERE name = :roaster COLLATE NOCASE
 		`,
 			sql.Named("name", coffee.name),
 			sql.Named("roaster", coffee.roaster),
+			sql.Named("countryCode", coffee.countryCode),
 			sql.Named("region", coffee.region),
-			sql.Named("variety", coffee.variety),
-			sql.Named("method", coffee.method),
+			sql.Named("farm", coffee.farm),
+			sql.Named("producer", coffee.producer),
+			sql.Named("altitudeMinM", coffee.altitudeMinM),
+			sql.Named("altitudeMaxM", coffee.altitudeMaxM),
+			sql.Named("process", coffee.process),
+			sql.Named("processOther", coffee.processOther),
 			sql.Named("decaf", coffee.decaf),
-		); err != nil {
+		)
+		if err != nil {
 			return fmt.Errorf("buna: sqlite_db_insert: failed to insert coffee into db: %w", err)
 		}
 
-		if _, err := tx.ExecContext(ctx, `
-			UPDATE coffees
-			SET roaster = NULLIF(roaster, ""),
-				region = NULLIF(region, ""),
-				variety = NULLIF(variety, ""),
-				method = NULLIF(method, "")
-			WHERE name = :name
-		`,
-			sql.Named("name", coffee.name),
-		); err != nil {
-			return fmt.Errorf("buna: sqlite_db_insert: failed to set null values: %w", err)
+		inserted, err := res.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("buna: sqlite_db_insert: failed to get number of inserted coffees: %w", err)
+		}
+		if inserted == 0 {
+			return errors.New("buna: sqlite_db_insert: roaster of coffee does not exist")
+		}
+
+		coffeeID, err := res.LastInsertId()
+		if err != nil {
+			return fmt.Errorf("buna: sqlite_db_insert: failed to get coffee id: %w", err)
+		}
+
+		if err := insertCoffeeVarieties(ctx, tx, int(coffeeID), coffee.varieties); err != nil {
+			return fmt.Errorf("buna: sqlite_db_insert: failed to insert coffee varieties: %w", err)
 		}
 
 		return nil
@@ -153,41 +208,52 @@ func (s *SQLiteDB) insertCoffee(ctx context.Context, coffee coffee) error {
 	return nil
 }
 
-func (s *SQLiteDB) insertCoffeePurchase(ctx context.Context, coffeePurchase coffeePurchase) error {
-	if err := s.TransactContext(ctx, func(ctx context.Context, tx *sql.Tx) error {
-		var coffeeID int
-		if err := tx.QueryRowContext(ctx, `
-			SELECT id
-			FROM coffees
-			WHERE name = :coffeeName AND (roaster = :coffeeRoaster OR (:coffeeRoaster = "" AND roaster IS NULL))
+// Links the coffee to the varieties, creating varieties that do not exist yet.
+func insertCoffeeVarieties(ctx context.Context, tx *sql.Tx, coffeeID int, varieties []string) error {
+	for _, variety := range varieties {
+		if _, err := tx.ExecContext(ctx, `
+			INSERT OR IGNORE INTO varieties(name)
+			VALUES (:name)
 		`,
-			sql.Named("coffeeName", coffeePurchase.coffeeName),
-			sql.Named("coffeeRoaster", coffeePurchase.coffeeRoaster),
-		).Scan(&coffeeID); err != nil {
-			fmt.Println("Unable to link the purchased coffee to an existing coffee. Please create a new coffee first and then try again.")
-			return nil
+			sql.Named("name", variety),
+		); err != nil {
+			return fmt.Errorf("buna: sqlite_db_insert: failed to insert variety into db: %w", err)
 		}
 
 		if _, err := tx.ExecContext(ctx, `
-			INSERT INTO purchases(coffee_id, bought_date, roast_date)
-			VALUES (:coffeeID, :boughtDate, :roastDate)
+			INSERT OR IGNORE INTO coffee_varieties(coffee_id, variety_id)
+			SELECT :coffeeID, id
+			FROM varieties
+			WHERE name = :name COLLATE NOCASE
 		`,
 			sql.Named("coffeeID", coffeeID),
-			sql.Named("boughtDate", coffeePurchase.boughtDate),
-			sql.Named("roastDate", coffeePurchase.roastDate),
+			sql.Named("name", variety),
 		); err != nil {
-			return fmt.Errorf("buna: sqlite_db_insert: failed to insert coffee purchase into db: %w", err)
+			return fmt.Errorf("buna: sqlite_db_insert: failed to insert coffee variety into db: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func (s *SQLiteDB) insertCoffeePurchase(ctx context.Context, coffeePurchase coffeePurchase) error {
+	if err := s.TransactContext(ctx, func(ctx context.Context, tx *sql.Tx) error {
+		coffeeID, err := getCoffeeID(ctx, tx, coffeePurchase.coffeeName, coffeePurchase.coffeeRoaster)
+		if err != nil {
+			return fmt.Errorf("buna: sqlite_db_insert: unable to link the purchase to a coffee: %w", err)
 		}
 
 		if _, err := tx.ExecContext(ctx, `
-			UPDATE purchases
-			SET roast_date = NULLIF(roast_date, "0-00-00")
-			WHERE coffee_id = :coffeeID AND bought_date = :boughtDate
+			INSERT INTO purchases(coffee_id, bought_date, bought_date_utc, roast_date, user_id)
+			VALUES (:coffeeID, :boughtDate, :boughtDateUTC, NULLIF(:roastDate, ""), NULLIF(:userID, 0))
 		`,
 			sql.Named("coffeeID", coffeeID),
 			sql.Named("boughtDate", coffeePurchase.boughtDate),
+			sql.Named("boughtDateUTC", utcTimestamp(coffeePurchase.boughtDate)),
+			sql.Named("roastDate", coffeePurchase.roastDate),
+			userArg(ctx),
 		); err != nil {
-			return fmt.Errorf("buna: sqlite_db_insert: failed to set null values: %w", err)
+			return fmt.Errorf("buna: sqlite_db_insert: failed to insert coffee purchase into db: %w", err)
 		}
 
 		return nil
@@ -200,12 +266,14 @@ func (s *SQLiteDB) insertCoffeePurchase(ctx context.Context, coffeePurchase coff
 func (s *SQLiteDB) insertCupping(ctx context.Context, cupping cupping) error {
 	if err := s.TransactContext(ctx, func(ctx context.Context, tx *sql.Tx) error {
 		res, err := tx.ExecContext(ctx, `
-			INSERT INTO cuppings(date, duration_min, notes)
-			VALUES (:cuppingDate, :cuppingDurationMin, :cuppingNotes)
+			INSERT INTO cuppings(date, date_utc, duration_min, notes, user_id)
+			VALUES (:cuppingDate, :cuppingDateUTC, :cuppingDurationMin, :cuppingNotes, NULLIF(:userID, 0))
 		`,
 			sql.Named("cuppingDate", cupping.date),
+			sql.Named("cuppingDateUTC", utcTimestamp(cupping.date)),
 			sql.Named("cuppingDurationMin", cupping.durationMin),
 			sql.Named("cuppingNotes", cupping.notes),
+			userArg(ctx),
 		)
 		if err != nil {
 			return fmt.Errorf("buna: sqlite_db_insert: failed to insert cupping into db: %w", err)
@@ -217,9 +285,9 @@ func (s *SQLiteDB) insertCupping(ctx context.Context, cupping cupping) error {
 		}
 
 		for _, cuppedCoffee := range cupping.cuppedCoffees {
-			coffeeID, err := s.getCoffeeIDByNameRoaster(ctx, cuppedCoffee.name, cuppedCoffee.roaster)
+			coffeeID, err := getCoffeeID(ctx, tx, cuppedCoffee.name, cuppedCoffee.roaster)
 			if err != nil {
-				return fmt.Errorf("buna: sqlite_db_insert: failed to retrieve coffee id from db: %w", err)
+				return fmt.Errorf("buna: sqlite_db_insert: unable to link the cupped coffee to a coffee: %w", err)
 			}
 
 			if _, err := tx.ExecContext(ctx, `
@@ -233,6 +301,10 @@ func (s *SQLiteDB) insertCupping(ctx context.Context, cupping cupping) error {
 			); err != nil {
 				return fmt.Errorf("buna: sqlite_db_insert: failed to insert cupped coffee into db: %w", err)
 			}
+
+			if err := insertCuppedCoffeeFlavors(ctx, tx, int(cuppingID), coffeeID, cuppedCoffee.flavors); err != nil {
+				return fmt.Errorf("buna: sqlite_db_insert: failed to insert cupped coffee flavors: %w", err)
+			}
 		}
 
 		return nil
@@ -242,6 +314,66 @@ func (s *SQLiteDB) insertCupping(ctx context.Context, cupping cupping) error {
 	return nil
 }
 
+// Every flavor must be part of the flavor wheel.
+func insertBrewingFlavors(ctx context.Context, tx *sql.Tx, brewingID int, flavors []string) error {
+	for _, flavor := range flavors {
+		flavorID, err := getFlavorID(ctx, tx, flavor)
+		if err != nil {
+			return fmt.Errorf("buna: sqlite_db_insert: failed to get flavor id: %w", err)
+		}
+
+		if _, err := tx.ExecContext(ctx, `
+			INSERT OR IGNORE INTO brewing_flavors(brewing_id, flavor_id)
+			VALUES (:brewingID, :flavorID)
+		`,
+			sql.Named("brewingID", brewingID),
+			sql.Named("flavorID", flavorID),
+		); err != nil {
+			return fmt.Errorf("buna: sqlite_db_insert: failed to insert brewing flavor into db: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// Every flavor must be part of the flavor wheel.
+func insertCuppedCoffeeFlavors(ctx context.Context, tx *sql.Tx, cuppingID int, coffeeID int, flavors []string) error {
+	for _, flavor := range flavors {
+		flavorID, err := getFlavorID(ctx, tx, flavor)
+		if err != nil {
+			return fmt.Errorf("buna: sqlite_db_insert: failed to get flavor id: %w", err)
+		}
+
+		if _, err := tx.ExecContext(ctx, `
+			INSERT OR IGNORE INTO cupped_coffee_flavors(cupping_id, coffee_id, flavor_id)
+			VALUES (:cuppingID, :coffeeID, :flavorID)
+		`,
+			sql.Named("cuppingID", cuppingID),
+			sql.Named("coffeeID", coffeeID),
+			sql.Named("flavorID", flavorID),
+		); err != nil {
+			return fmt.Errorf("buna: sqlite_db_insert: failed to insert cupped coffee flavor into db: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func getFlavorID(ctx context.Context, tx *sql.Tx, flavor string) (int, error) {
+	var flavorID int
+	if err := tx.QueryRowContext(ctx, `
+		SELECT id
+		FROM flavors
+		WHERE name = :flavor COLLATE NOCASE
+	`,
+		sql.Named("flavor", flavor),
+	).Scan(&flavorID); err != nil {
+		return 0, fmt.Errorf("buna: sqlite_db_insert: failed to find flavor %q: %w", flavor, err)
+	}
+
+	return flavorID, nil
+}
+
 func (s *SQLiteDB) insertGrinder(ctx context.Context, grinder grinder) error {
 	if err := s.TransactContext(ctx, func(ctx context.Context, tx *sql.Tx) error {
 		if _, err := tx.ExecContext(ctx, `
@@ -272,3 +404,62 @@ func (s *SQLiteDB) insertGrinder(ctx context.Context, grinder grinder) error {
 	}
 	return nil
 }
+
+func (s *SQLiteDB) insertRoaster(ctx context.Context, roaster roaster) error {
+	if err := s.TransactContext(ctx, func(ctx context.Context, tx *sql.Tx) error {
+		res, err := tx.ExecContext(ctx, `
+			INSERT INTO roasters(name, country, city, website, notes)
+			VALUES (:name, :country, :city, :website, :notes)
+		`,
+			sql.Named("name", roaster.name),
+			sql.Named("country", roaster.country),
+			sql.Named("city", roaster.city),
+			sql.Named("website", roaster.website),
+			sql.Named("notes", roaster.notes),
+		)
+		if err != nil {
+			return fmt.Errorf("buna: sqlite_db_insert: failed to insert roaster into db: %w", err)
+		}
+
+		roasterID, err := res.LastInsertId()
+		if err != nil {
+			return fmt.Errorf("buna: sqlite_db_insert: failed to get roaster id: %w", err)
+		}
+
+		if _, err := tx.ExecContext(ctx, `
+			UPDATE roasters
+			SET country = NULLIF(country, ""),
+				city = NULLIF(city, ""),
+				website = NULLIF(website, ""),
+				notes = NULLIF(notes, "")
+			WHERE id = :roasterID
+		`,
+			sql.Named("roasterID", roasterID),
+		); err != nil {
+			return fmt.Errorf("buna: sqlite_db_insert: failed to set null values: %w", err)
+		}
+
+		return nil
+	}); err != nil {
+		return fmt.Errorf("buna: sqlite_db_insert: insertRoaster transaction failed: %w", err)
+	}
+	return nil
+}
+
+func (s *SQLiteDB) insertUser(ctx context.Context, u user) error {
+	if err := s.TransactContext(ctx, func(ctx context.Context, tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO users(name)
+			VALUES (:name)
+		`,
+			sql.Named("name", u.name),
+		); err != nil {
+			return fmt.Errorf("buna: sqlite_db_insert: failed to insert user into db: %w", err)
+		}
+
+		return nil
+	}); err != nil {
+		return fmt.Errorf("buna: sqlite_db_insert: insertUser transaction failed: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,684 @@
+package buna
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// migrations upgrade the schema created in migrate one version at a time.
+// The schema version after running migrations[i] is i+1.
+// Migrations must only ever be appended to this slice.
+var migrations = []func(ctx context.Context, tx *sql.Tx) error{
+	migrateRoasters,
+	migrateCoffeeOrigins,
+	migrateFlavors,
+	migrateBrewingScores,
+	migratePreferences,
+	migrateTimestamps,
+	migrateDrafts,
+	migrateUsers,
+}
+
+// Moves the roaster TEXT column of coffees into a separate roasters table.
+// Roaster names that only differ in case or surrounding whitespace are merged into a single roaster.
+func migrateRoasters(ctx context.Context, tx *sql.Tx) error {
+	if _, err := tx.ExecContext(ctx, `
+		CREATE TABLE roasters (
+			id INTEGER NOT NULL PRIMARY KEY,
+			name TEXT NOT NULL,
+			country TEXT NULL,
+			city TEXT NULL,
+			website TEXT NULL,
+			notes TEXT NULL,
+			UNIQUE(name COLLATE NOCASE)
+		)
+	`); err != nil {
+		return fmt.Errorf("buna: sqlite_db_migrate: failed to create roasters table: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		CREATE TABLE roaster_merge_dismissals (
+			roaster_id INTEGER NOT NULL,
+			other_roaster_id INTEGER NOT NULL,
+			PRIMARY KEY (roaster_id, other_roaster_id),
+			FOREIGN KEY (roaster_id)
+				REFERENCES roasters (id)
+					ON DELETE CASCADE,
+			FOREIGN KEY (other_roaster_id)
+				REFERENCES roasters (id)
+					ON DELETE CASCADE
+		)
+	`); err != nil {
+		return fmt.Errorf("buna: sqlite_db_migrate: failed to create roaster_merge_dismissals table: %w", err)
+	}
+
+	// The first added spelling of a roaster name is kept
+	if _, err := tx.ExecContext(ctx, `
+		INSERT OR IGNORE INTO roasters(name)
+		SELECT trim(roaster)
+		FROM coffees
+		WHERE roaster IS NOT NULL AND trim(roaster) <> ""
+		ORDER BY id
+	`); err != nil {
+		return fmt.Errorf("buna: sqlite_db_migrate: failed to insert existing roasters: %w", err)
+	}
+
+	// Coffees that only differed in the spelling of their roaster become duplicates
+	rows, err := tx.QueryContext(ctx, `
+		SELECT c.id, min(o.id)
+		FROM coffees AS c
+		INNER JOIN coffees AS o
+			ON o.name = c.name
+			AND lower(trim(o.roaster)) = lower(trim(c.roaster))
+		GROUP BY c.id
+		HAVING min(o.id) <> c.id
+	`)
+	if err != nil {
+		return fmt.Errorf("buna: sqlite_db_migrate: failed to retrieve duplicate coffees: %w", err)
+	}
+	defer rows.Close()
+
+	duplicates := make(map[int]int)
+	for rows.Next() {
+		var fromID, intoID int
+		if err := rows.Scan(&fromID, &intoID); err != nil {
+			return fmt.Errorf("buna: sqlite_db_migrate: failed to scan row: %w", err)
+		}
+
+		duplicates[fromID] = intoID
+	}
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("buna: sqlite_db_migrate: failed to scan last row: %w", err)
+	}
+
+	for fromID, intoID := range duplicates {
+		if err := mergeCoffees(ctx, tx, fromID, intoID); err != nil {
+			return fmt.Errorf("buna: sqlite_db_migrate: failed to merge duplicate coffees: %w", err)
+		}
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		CREATE TABLE coffees_new (
+			id INTEGER NOT NULL PRIMARY KEY,
+			name TEXT NOT NULL,
+			roaster_id INTEGER NOT NULL,
+			region TEXT NULL,
+			variety TEXT NULL,
+			method TEXT NULL,
+			decaf BOOLEAN NULL
+				CHECK (decaf IN (0,1)),
+			UNIQUE(name, roaster_id),
+			FOREIGN KEY (roaster_id)
+				REFERENCES roasters (id)
+					ON DELETE RESTRICT
+		)
+	`); err != nil {
+		return fmt.Errorf("buna: sqlite_db_migrate: failed to create new coffees table: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO coffees_new(id, name, roaster_id, region, variety, method, decaf)
+		SELECT c.id, c.name, r.id, c.region, c.variety, c.method, c.decaf
+		FROM coffees AS c
+		INNER JOIN roasters AS r
+			ON r.name = trim(c.roaster) COLLATE NOCASE
+	`); err != nil {
+		return fmt.Errorf("buna: sqlite_db_migrate: failed to copy coffees: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, `DROP TABLE coffees`); err != nil {
+		return fmt.Errorf("buna: sqlite_db_migrate: failed to drop old coffees table: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, `ALTER TABLE coffees_new RENAME TO coffees`); err != nil {
+		return fmt.Errorf("buna: sqlite_db_migrate: failed to rename new coffees table: %w", err)
+	}
+
+	return nil
+}
+
+// Replaces the freeform region, variety and method columns of coffees with structured origin data.
+// Existing values are parsed on a best effort basis, see parseOrigin, parseVarieties and parseProcess.
+func migrateCoffeeOrigins(ctx context.Context, tx *sql.Tx) error {
+	if _, err := tx.ExecContext(ctx, `
+		CREATE TABLE varieties (
+			id INTEGER NOT NULL PRIMARY KEY,
+			name TEXT NOT NULL,
+			UNIQUE(name COLLATE NOCASE)
+		)
+	`); err != nil {
+		return fmt.Errorf("buna: sqlite_db_migrate: failed to create varieties table: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		CREATE TABLE coffee_varieties (
+			coffee_id INTEGER NOT NULL,
+			variety_id INTEGER NOT NULL,
+			PRIMARY KEY (coffee_id, variety_id),
+			FOREIGN KEY (coffee_id)
+				REFERENCES coffees (id)
+					ON DELETE CASCADE,
+			FOREIGN KEY (variety_id)
+				REFERENCES varieties (id)
+					ON DELETE RESTRICT
+		)
+	`); err != nil {
+		return fmt.Errorf("buna: sqlite_db_migrate: failed to create coffee_varieties table: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		CREATE TABLE coffees_new (
+			id INTEGER NOT NULL PRIMARY KEY,
+			name TEXT NOT NULL,
+			roaster_id INTEGER NOT NULL,
+			country_code TEXT NULL
+				CHECK (length(country_code) = 2),
+			region TEXT NULL,
+			farm TEXT NULL,
+			producer TEXT NULL,
+			altitude_min_m INTEGER NULL
+				CHECK (altitude_min_m >= 0),
+			altitude_max_m INTEGER NULL
+				CHECK (altitude_max_m >= altitude_min_m),
+			process TEXT NULL
+				CHECK (process IN (
+					"washed",
+					"natural",
+					"honey",
+					"pulped natural",
+					"semi-washed",
+					"wet-hulled",
+					"anaerobic",
+					"carbonic maceration",
+					"other"
+				)),
+			process_other TEXT NULL,
+			decaf BOOLEAN NULL
+				CHECK (decaf IN (0,1)),
+			UNIQUE(name, roaster_id),
+			FOREIGN KEY (roaster_id)
+				REFERENCES roasters (id)
+					ON DELETE RESTRICT
+		)
+	`); err != nil {
+		return fmt.Errorf("buna: sqlite_db_migrate: failed to create new coffees table: %w", err)
+	}
+
+	type oldCoffee struct {
+		id        int
+		name      string
+		roasterID int
+		region    sql.NullString
+		variety   sql.NullString
+		method    sql.NullString
+		decaf     sql.NullBool
+	}
+
+	rows, err := tx.QueryContext(ctx, `
+		SELECT id, name, roaster_id, region, variety, method, decaf
+		FROM coffees
+	`)
+	if err != nil {
+		return fmt.Errorf("buna: sqlite_db_migrate: failed to retrieve coffee rows: %w", err)
+	}
+	defer rows.Close()
+
+	var oldCoffees []oldCoffee
+	for rows.Next() {
+		var c oldCoffee
+		if err := rows.Scan(&c.id, &c.name, &c.roasterID, &c.region, &c.variety, &c.method, &c.decaf); err != nil {
+			return fmt.Errorf("buna: sqlite_db_migrate: failed to scan row: %w", err)
+		}
+
+		oldCoffees = append(oldCoffees, c)
+	}
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("buna: sqlite_db_migrate: failed to scan last row: %w", err)
+	}
+
+	for _, c := range oldCoffees {
+		countryCode, region := parseOrigin(c.region.String)
+		process, processOther := parseProcess(c.method.String)
+
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO coffees_new(id, name, roaster_id, country_code, region, process, process_other, decaf)
+			VALUES (
+				:id,
+				:name,
+				:roasterID,
+				NULLIF(:countryCode, ""),
+				NULLIF(:region, ""),
+				NULLIF(:process, ""),
+				NULLIF(:processOther, ""),
+				:decaf
+			)
+		`,
+			sql.Named("id", c.id),
+			sql.Named("name", c.name),
+			sql.Named("roasterID", c.roasterID),
+			sql.Named("countryCode", countryCode),
+			sql.Named("region", region),
+			sql.Named("process", process),
+			sql.Named("processOther", processOther),
+			sql.Named("decaf", c.decaf),
+		); err != nil {
+			return fmt.Errorf("buna: sqlite_db_migrate: failed to copy coffee: %w", err)
+		}
+
+		if err := insertCoffeeVarieties(ctx, tx, c.id, parseVarieties(c.variety.String)); err != nil {
+			return fmt.Errorf("buna: sqlite_db_migrate: failed to insert coffee varieties: %w", err)
+		}
+	}
+
+	if _, err := tx.ExecContext(ctx, `DROP TABLE coffees`); err != nil {
+		return fmt.Errorf("buna: sqlite_db_migrate: failed to drop old coffees table: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, `ALTER TABLE coffees_new RENAME TO coffees`); err != nil {
+		return fmt.Errorf("buna: sqlite_db_migrate: failed to rename new coffees table: %w", err)
+	}
+
+	return nil
+}
+
+// Sources of the notes in the full-text index.
+// For brewings and cuppings, source_id is the id of the row and coffee_id is NULL.
+// For cupped coffees, source_id is the cupping_id and coffee_id the coffee_id of the row.
+var notesSearchSources = []struct {
+	source         string
+	table          string
+	idColumn       string
+	coffeeIDColumn string
+}{
+	{source: "brewing", table: "brewings", idColumn: "id"},
+	{source: "cupping", table: "cuppings", idColumn: "id"},
+	{source: "cupped_coffee", table: "cupped_coffees", idColumn: "cupping_id", coffeeIDColumn: "coffee_id"},
+}
+
+// The full-text index is not a migration since it needs SQLite to be compiled with FTS5 (build tag sqlite_fts5).
+// Without FTS5, the triggers that keep the index in sync are dropped so that notes can still be written.
+// The index is then rebuilt the next time the database is opened with FTS5.
+func syncNotesSearch(ctx context.Context, tx *sql.Tx) error {
+	var available bool
+	if err := tx.QueryRowContext(ctx, `SELECT sqlite_compileoption_used("ENABLE_FTS5")`).Scan(&available); err != nil {
+		return fmt.Errorf("buna: sqlite_db_migrate: failed to check for FTS5: %w", err)
+	}
+
+	var triggers []string
+	var triggerArgs []interface{}
+	for _, s := range notesSearchSources {
+		for _, event := range []string{"insert", "update", "delete"} {
+			trigger := s.table + "_notes_fts_" + event
+			triggers = append(triggers, trigger)
+			triggerArgs = append(triggerArgs, trigger)
+		}
+	}
+
+	if !available {
+		for _, trigger := range triggers {
+			if _, err := tx.ExecContext(ctx, "DROP TRIGGER IF EXISTS "+trigger); err != nil {
+				return fmt.Errorf("buna: sqlite_db_migrate: failed to drop %v trigger: %w", trigger, err)
+			}
+		}
+		return nil
+	}
+
+	var existing int
+	if err := tx.QueryRowContext(ctx, `
+		SELECT count(*)
+		FROM sqlite_master
+		WHERE type = "trigger" AND name IN (`+strings.Repeat(`?, `, len(triggers)-1)+`?)
+	`, triggerArgs...).Scan(&existing); err != nil {
+		return fmt.Errorf("buna: sqlite_db_migrate: failed to count notes triggers: %w", err)
+	}
+	if existing == len(triggers) {
+		return nil
+	}
+
+	for _, trigger := range triggers {
+		if _, err := tx.ExecContext(ctx, "DROP TRIGGER IF EXISTS "+trigger); err != nil {
+			return fmt.Errorf("buna: sqlite_db_migrate: failed to drop %v trigger: %w", trigger, err)
+		}
+	}
+	if _, err := tx.ExecContext(ctx, `DROP TABLE IF EXISTS notes_fts`); err != nil {
+		return fmt.Errorf("buna: sqlite_db_migrate: failed to drop stale notes_fts table: %w", err)
+	}
+
+	if err := createNotesSearch(ctx, tx); err != nil {
+		return fmt.Errorf("buna: sqlite_db_migrate: failed to create notes search: %w", err)
+	}
+
+	return nil
+}
+
+// Creates a full-text index over the notes of brewings, cuppings and cupped coffees.
+// The index is kept in sync with triggers, so the notes tables must never be rebuilt without recreating them.
+func createNotesSearch(ctx context.Context, tx *sql.Tx) error {
+	if _, err := tx.ExecContext(ctx, `
+		CREATE VIRTUAL TABLE notes_fts USING fts5(
+			notes,
+			source UNINDEXED,
+			source_id UNINDEXED,
+			coffee_id UNINDEXED,
+			tokenize = "porter unicode61"
+		)
+	`); err != nil {
+		return fmt.Errorf("buna: sqlite_db_migrate: failed to create notes_fts table: %w", err)
+	}
+
+	for _, s := range notesSearchSources {
+		// Returns the key columns of the row as SQL expressions
+		keys := func(row string) (string, string) {
+			coffeeID := "NULL"
+			if s.coffeeIDColumn != "" {
+				coffeeID = row + "." + s.coffeeIDColumn
+			}
+			return row + "." + s.idColumn, coffeeID
+		}
+
+		// Only changes of the notes and, for cupped coffees, of the key need reindexing.
+		// The key of a cupped coffee changes when its coffee is merged into another one.
+		updateColumns := "notes"
+		if s.coffeeIDColumn != "" {
+			updateColumns += ", " + s.idColumn + ", " + s.coffeeIDColumn
+		}
+
+		rowID, rowCoffeeID := keys(s.table)
+		if _, err := tx.ExecContext(ctx, fmt.Sprintf(`
+			INSERT INTO notes_fts(notes, source, source_id, coffee_id)
+			SELECT notes, "%s", %s, %s
+			FROM %s
+			WHERE notes IS NOT NULL AND notes != ""
+		`, s.source, rowID, rowCoffeeID, s.table)); err != nil {
+			return fmt.Errorf("buna: sqlite_db_migrate: failed to index %v notes: %w", s.source, err)
+		}
+
+		newID, newCoffeeID := keys("new")
+		if _, err := tx.ExecContext(ctx, fmt.Sprintf(`
+			CREATE TRIGGER %[2]s_notes_fts_insert AFTER INSERT ON %[2]s
+			WHEN new.notes IS NOT NULL AND new.notes != ""
+			BEGIN
+				INSERT INTO notes_fts(notes, source, source_id, coffee_id)
+				VALUES (new.notes, "%[1]s", %[3]s, %[4]s);
+			END
+		`, s.source, s.table, newID, newCoffeeID)); err != nil {
+			return fmt.Errorf("buna: sqlite_db_migrate: failed to create %v notes insert trigger: %w", s.source, err)
+		}
+
+		oldID, oldCoffeeID := keys("old")
+		if _, err := tx.ExecContext(ctx, fmt.Sprintf(`
+			CREATE TRIGGER %[2]s_notes_fts_update AFTER UPDATE OF %[7]s ON %[2]s
+			BEGIN
+				DELETE FROM notes_fts
+				WHERE source = "%[1]s" AND source_id = %[3]s AND coffee_id IS %[4]s;
+				INSERT INTO notes_fts(notes, source, source_id, coffee_id)
+				SELECT new.notes, "%[1]s", %[5]s, %[6]s
+				WHERE new.notes IS NOT NULL AND new.notes != "";
+			END
+		`, s.source, s.table, oldID, oldCoffeeID, newID, newCoffeeID, updateColumns)); err != nil {
+			return fmt.Errorf("buna: sqlite_db_migrate: failed to create %v notes update trigger: %w", s.source, err)
+		}
+
+		if _, err := tx.ExecContext(ctx, fmt.Sprintf(`
+			CREATE TRIGGER %[2]s_notes_fts_delete AFTER DELETE ON %[2]s
+			BEGIN
+				DELETE FROM notes_fts
+				WHERE source = "%[1]s" AND source_id = %[3]s AND coffee_id IS %[4]s;
+			END
+		`, s.source, s.table, oldID, oldCoffeeID)); err != nil {
+			return fmt.Errorf("buna: sqlite_db_migrate: failed to create %v notes delete trigger: %w", s.source, err)
+		}
+	}
+
+	return nil
+}
+
+// Creates the flavor wheel vocabulary and the flavor tags of brewings and cupped coffees.
+func migrateFlavors(ctx context.Context, tx *sql.Tx) error {
+	if _, err := tx.ExecContext(ctx, `
+		CREATE TABLE flavors (
+			id INTEGER NOT NULL PRIMARY KEY,
+			name TEXT NOT NULL,
+			parent_id INTEGER NULL,
+			UNIQUE(name COLLATE NOCASE),
+			FOREIGN KEY (parent_id)
+				REFERENCES flavors (id)
+					ON DELETE RESTRICT
+		)
+	`); err != nil {
+		return fmt.Errorf("buna: sqlite_db_migrate: failed to create flavors table: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		CREATE TABLE brewing_flavors (
+			brewing_id INTEGER NOT NULL,
+			flavor_id INTEGER NOT NULL,
+			PRIMARY KEY (brewing_id, flavor_id),
+			FOREIGN KEY (brewing_id)
+				REFERENCES brewings (id)
+					ON DELETE CASCADE,
+			FOREIGN KEY (flavor_id)
+				REFERENCES flavors (id)
+					ON DELETE RESTRICT
+		)
+	`); err != nil {
+		return fmt.Errorf("buna: sqlite_db_migrate: failed to create brewing_flavors table: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		CREATE TABLE cupped_coffee_flavors (
+			cupping_id INTEGER NOT NULL,
+			coffee_id INTEGER NOT NULL,
+			flavor_id INTEGER NOT NULL,
+			PRIMARY KEY (cupping_id, coffee_id, flavor_id),
+			FOREIGN KEY (cupping_id, coffee_id)
+				REFERENCES cupped_coffees (cupping_id, coffee_id)
+					ON DELETE CASCADE,
+			FOREIGN KEY (flavor_id)
+				REFERENCES flavors (id)
+					ON DELETE RESTRICT
+		)
+	`); err != nil {
+		return fmt.Errorf("buna: sqlite_db_migrate: failed to create cupped_coffee_flavors table: %w", err)
+	}
+
+	var insertFlavors func(nodes []flavorNode, parentID interface{}) error
+	insertFlavors = func(nodes []flavorNode, parentID interface{}) error {
+		for _, node := range nodes {
+			res, err := tx.ExecContext(ctx, `
+				INSERT INTO flavors(name, parent_id)
+				VALUES (:name, :parentID)
+			`,
+				sql.Named("name", node.name),
+				sql.Named("parentID", parentID),
+			)
+			if err != nil {
+				return fmt.Errorf("buna: sqlite_db_migrate: failed to insert flavor %v: %w", node.name, err)
+			}
+
+			id, err := res.LastInsertId()
+			if err != nil {
+				return fmt.Errorf("buna: sqlite_db_migrate: failed to get flavor id: %w", err)
+			}
+
+			if err := insertFlavors(node.children, id); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	if err := insertFlavors(flavorWheel, nil); err != nil {
+		return fmt.Errorf("buna: sqlite_db_migrate: failed to insert flavor wheel: %w", err)
+	}
+
+	return nil
+}
+
+// Adds the optional sub-scores and the extraction verdict to brewings.
+func migrateBrewingScores(ctx context.Context, tx *sql.Tx) error {
+	for _, column := range []string{"sweetness", "acidity", "bitterness", "body", "clarity", "astringency"} {
+		if _, err := tx.ExecContext(ctx, fmt.Sprintf(`
+			ALTER TABLE brewings
+			ADD COLUMN %[1]s INTEGER NULL
+				CHECK (%[1]s >= 1 AND %[1]s <= 10)
+		`, column)); err != nil {
+			return fmt.Errorf("buna: sqlite_db_migrate: failed to add %v column to brewings: %w", column, err)
+		}
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		ALTER TABLE brewings
+		ADD COLUMN extraction TEXT NULL
+			CHECK (extraction IN ("under", "balanced", "over"))
+	`); err != nil {
+		return fmt.Errorf("buna: sqlite_db_migrate: failed to add extraction column to brewings: %w", err)
+	}
+
+	return nil
+}
+
+// Creates the preferences table, which stores user preferences like the unit system by name.
+func migratePreferences(ctx context.Context, tx *sql.Tx) error {
+	if _, err := tx.ExecContext(ctx, `
+		CREATE TABLE preferences (
+			name TEXT NOT NULL PRIMARY KEY,
+			value TEXT NOT NULL
+		)
+	`); err != nil {
+		return fmt.Errorf("buna: sqlite_db_migrate: failed to create preferences table: %w", err)
+	}
+
+	return nil
+}
+
+// Replaces the "YYYY-MM-DD" dates of brewings, purchases and cuppings with RFC 3339 timestamps in the local time zone.
+// Existing dates become timestamps at midnight. Brewings get a time_known column, which is false for these brewings,
+// so that they can be left out of time of day statistics.
+// Each timestamp also gets a UTC copy, which is what the timestamps are sorted and compared by.
+func migrateTimestamps(ctx context.Context, tx *sql.Tx) error {
+	if _, err := tx.ExecContext(ctx, `
+		ALTER TABLE brewings
+		ADD COLUMN time_known BOOLEAN NOT NULL DEFAULT 1
+			CHECK (time_known IN (0,1))
+	`); err != nil {
+		return fmt.Errorf("buna: sqlite_db_migrate: failed to add time_known column to brewings: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, `UPDATE brewings SET time_known = 0`); err != nil {
+		return fmt.Errorf("buna: sqlite_db_migrate: failed to mark times of existing brewings as unknown: %w", err)
+	}
+
+	// table, date column and UTC column of every date that becomes a timestamp
+	columns := [][3]string{
+		{"brewings", "date", "date_utc"},
+		{"purchases", "bought_date", "bought_date_utc"},
+		{"cuppings", "date", "date_utc"},
+	}
+
+	for _, column := range columns {
+		table, dateColumn, utcColumn := column[0], column[1], column[2]
+
+		// Every row gets its UTC timestamp below and every insert sets it
+		if _, err := tx.ExecContext(ctx, fmt.Sprintf(`ALTER TABLE %s ADD COLUMN %s TEXT NULL`, table, utcColumn)); err != nil {
+			return fmt.Errorf("buna: sqlite_db_migrate: failed to add %v column to %v: %w", utcColumn, table, err)
+		}
+
+		rows, err := tx.QueryContext(ctx, fmt.Sprintf(`SELECT DISTINCT %s FROM %s`, dateColumn, table))
+		if err != nil {
+			return fmt.Errorf("buna: sqlite_db_migrate: failed to retrieve %v dates: %w", table, err)
+		}
+
+		var dates []string
+		for rows.Next() {
+			var date string
+			if err := rows.Scan(&date); err != nil {
+				rows.Close()
+				return fmt.Errorf("buna: sqlite_db_migrate: failed to scan row: %w", err)
+			}
+
+			dates = append(dates, date)
+		}
+
+		err = rows.Err()
+		rows.Close()
+		if err != nil {
+			return fmt.Errorf("buna: sqlite_db_migrate: failed to scan last row: %w", err)
+		}
+
+		for _, date := range dates {
+			d, err := createDateFromDateString(date)
+			if err != nil {
+				return fmt.Errorf("buna: sqlite_db_migrate: invalid %v date %q: %w", table, date, err)
+			}
+
+			// Day 0, which was suggested as yesterday on the first of a month, normalizes to the last day of the previous month.
+			// The offset of the local time zone depends on the date.
+			t := time.Date(d.year, time.Month(d.month), d.day, 0, 0, 0, 0, time.Local)
+
+			if _, err := tx.ExecContext(ctx, fmt.Sprintf(`
+				UPDATE %[1]s
+				SET %[2]s = :timestamp, %[3]s = :timestampUTC
+				WHERE %[2]s = :date
+			`, table, dateColumn, utcColumn),
+				sql.Named("timestamp", t.Format(time.RFC3339)),
+				sql.Named("timestampUTC", t.UTC().Format(time.RFC3339)),
+				sql.Named("date", date),
+			); err != nil {
+				return fmt.Errorf("buna: sqlite_db_migrate: failed to convert %v date %q: %w", table, date, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// Creates the drafts table, which keeps the answers of unfinished add flows.
+func migrateDrafts(ctx context.Context, tx *sql.Tx) error {
+	if _, err := tx.ExecContext(ctx, `
+		CREATE TABLE drafts (
+			id INTEGER NOT NULL PRIMARY KEY,
+			flow TEXT NOT NULL,
+			answers TEXT NOT NULL,
+			created_at TEXT NOT NULL,
+			updated_at TEXT NOT NULL
+		)
+	`); err != nil {
+		return fmt.Errorf("buna: sqlite_db_migrate: failed to create drafts table: %w", err)
+	}
+
+	return nil
+}
+
+// Creates the users table and attributes brewings, purchases, cuppings and drafts to users.
+// Existing entries are not attributed to anyone.
+func migrateUsers(ctx context.Context, tx *sql.Tx) error {
+	if _, err := tx.ExecContext(ctx, `
+		CREATE TABLE users (
+			id INTEGER NOT NULL PRIMARY KEY,
+			name TEXT NOT NULL,
+			UNIQUE(name COLLATE NOCASE)
+		)
+	`); err != nil {
+		return fmt.Errorf("buna: sqlite_db_migrate: failed to create users table: %w", err)
+	}
+
+	for _, table := range []string{"brewings", "purchases", "cuppings", "drafts"} {
+		if _, err := tx.ExecContext(ctx, fmt.Sprintf(`
+			ALTER TABLE %s
+			ADD COLUMN user_id INTEGER NULL
+				REFERENCES users (id)
+					ON DELETE RESTRICT
+		`, table)); err != nil {
+			return fmt.Errorf("buna: sqlite_db_migrate: failed to add user_id column to %v: %w", table, err)
+		}
+	}
+
+	return nil
+}
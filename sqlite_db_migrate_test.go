@@ -0,0 +1,231 @@
+package buna
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Rows of a database created before the first migration
+var baselineRows = []string{
+	`INSERT INTO coffees(id, name, roaster) VALUES
+		(1, "Kiambu", "Square Mile"),
+		(2, "Huila", "Square Mile"),
+		(3, "Kiambu", " square mile ")`,
+	`INSERT INTO brewing_methods(id, name) VALUES (1, "v60")`,
+	`INSERT INTO grinders(id, name) VALUES (1, "comandante")`,
+	`INSERT INTO purchases(coffee_id, bought_date, roast_date) VALUES (3, "2020-01-01", "2019-12-20")`,
+	`INSERT INTO brewings(id, coffee_id, method_id, date, grinder_id, grind_setting, total_brewing_time_sec, water_grams, coffee_grams, rating, notes) VALUES
+		(1, 1, 1, "2020-01-02", 1, 22, 180, 250, 15, 8, "Juicy with lots of blueberry"),
+		(2, 3, 1, "2020-01-03", 1, 22, 180, 250, 15, 7, ""),
+		(3, 2, 1, "2020-01-01", 1, 20, 200, 250, 16, 6, "Chocolate and nuts")`,
+	`INSERT INTO cuppings(id, date, duration_min, notes) VALUES (1, "2020-01-04", 30, "Blueberry bomb against chocolate")`,
+	`INSERT INTO cupped_coffees(cupping_id, coffee_id, rank, notes) VALUES
+		(1, 2, 2, "milk chocolate"),
+		(1, 3, 1, "blueberry jam")`,
+}
+
+func TestMigrateFromBaseline(t *testing.T) {
+	ctx := context.Background()
+
+	dir, err := ioutil.TempDir("", "buna-migrate")
+	if err != nil {
+		t.Fatalf("failed to create temporary directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "buna.db")
+
+	// Without migrations, only the baseline schema is created
+	s, err := openSQLiteDB(ctx, zap.NewNop(), path, nil)
+	if err != nil {
+		t.Fatalf("failed to create baseline database: %v", err)
+	}
+	for _, query := range baselineRows {
+		if _, err := s.db.ExecContext(ctx, query); err != nil {
+			s.Close()
+			t.Fatalf("failed to insert baseline rows: %v", err)
+		}
+	}
+	s.Close()
+
+	s, err = OpenSQLiteDB(ctx, zap.NewNop(), path)
+	if err != nil {
+		t.Fatalf("failed to migrate baseline database: %v", err)
+	}
+	defer s.Close()
+
+	var version int
+	if err := s.db.QueryRowContext(ctx, "PRAGMA user_version").Scan(&version); err != nil {
+		t.Fatalf("failed to retrieve schema version: %v", err)
+	}
+	if version != len(migrations) {
+		t.Errorf("schema version = %v, want %v", version, len(migrations))
+	}
+
+	t.Run("roasters and duplicate coffees are merged", func(t *testing.T) {
+		var roasters, coffees, kiambuBrewings, kiambuPurchases int
+		if err := s.db.QueryRowContext(ctx, `
+			SELECT	(SELECT count(*) FROM roasters),
+					(SELECT count(*) FROM coffees),
+					(SELECT count(*) FROM brewings WHERE coffee_id = 1),
+					(SELECT count(*) FROM purchases WHERE coffee_id = 1)
+		`).Scan(&roasters, &coffees, &kiambuBrewings, &kiambuPurchases); err != nil {
+			t.Fatalf("failed to count rows: %v", err)
+		}
+
+		if roasters != 1 || coffees != 2 || kiambuBrewings != 2 || kiambuPurchases != 1 {
+			t.Errorf("roasters, coffees, Kiambu brewings, Kiambu purchases = %v, %v, %v, %v, want 1, 2, 2, 1",
+				roasters, coffees, kiambuBrewings, kiambuPurchases)
+		}
+	})
+
+	t.Run("dates become timestamps", func(t *testing.T) {
+		var date, dateUTC string
+		var timeKnown bool
+		if err := s.db.QueryRowContext(ctx, `
+			SELECT date, date_utc, time_known
+			FROM brewings
+			WHERE id = 1
+		`).Scan(&date, &dateUTC, &timeKnown); err != nil {
+			t.Fatalf("failed to retrieve brewing date: %v", err)
+		}
+
+		want := time.Date(2020, time.January, 2, 0, 0, 0, 0, time.Local).Format(time.RFC3339)
+		if date != want || dateUTC != utcTimestamp(want) || timeKnown {
+			t.Errorf("date, date_utc, time_known = %v, %v, %v, want %v, %v, false", date, dateUTC, timeKnown, want, utcTimestamp(want))
+		}
+
+		var boughtDate, boughtDateUTC string
+		if err := s.db.QueryRowContext(ctx, `
+			SELECT bought_date, bought_date_utc
+			FROM purchases
+		`).Scan(&boughtDate, &boughtDateUTC); err != nil {
+			t.Fatalf("failed to retrieve purchase date: %v", err)
+		}
+
+		want = time.Date(2020, time.January, 1, 0, 0, 0, 0, time.Local).Format(time.RFC3339)
+		if boughtDate != want || boughtDateUTC != utcTimestamp(want) {
+			t.Errorf("bought_date, bought_date_utc = %v, %v, want %v, %v", boughtDate, boughtDateUTC, want, utcTimestamp(want))
+		}
+	})
+
+	t.Run("brewings are ordered by date", func(t *testing.T) {
+		brewings, err := s.getBrewingsByQuery(ctx, brewingQuery{sortBy: "date", ascending: true}, nil, 10)
+		if err != nil {
+			t.Fatalf("getBrewingsByQuery() error = %v", err)
+		}
+
+		var ids []int
+		for _, b := range brewings {
+			ids = append(ids, b.id)
+		}
+		if want := []int{3, 1, 2}; !reflect.DeepEqual(ids, want) {
+			t.Errorf("brewing ids = %v, want %v", ids, want)
+		}
+	})
+
+	searchable, err := s.getNoteSearchAvailable(ctx)
+	if err != nil {
+		t.Fatalf("getNoteSearchAvailable() error = %v", err)
+	}
+
+	t.Run("notes are searchable", func(t *testing.T) {
+		if !searchable {
+			t.Skip("SQLite is built without FTS5")
+		}
+
+		tests := []struct {
+			query string
+			want  []noteSearchHit
+		}{
+			{"blueberry", []noteSearchHit{
+				{source: "brewing", parentID: 1, coffeeName: "Kiambu"},
+				{source: "cupping", parentID: 1},
+				{source: "cupped_coffee", parentID: 1, coffeeName: "Kiambu"},
+			}},
+			{"milk", []noteSearchHit{
+				{source: "cupped_coffee", parentID: 1, coffeeName: "Huila"},
+			}},
+		}
+
+		for _, tt := range tests {
+			hits, err := s.getNoteSearchHits(ctx, tt.query, "", "", 10)
+			if err != nil {
+				t.Fatalf("getNoteSearchHits(%q) error = %v", tt.query, err)
+			}
+			if !sameNoteSearchHits(hits, tt.want) {
+				t.Errorf("getNoteSearchHits(%q) = %+v, want %+v", tt.query, hits, tt.want)
+			}
+		}
+	})
+
+	t.Run("cupped coffee notes follow updates", func(t *testing.T) {
+		if !searchable {
+			t.Skip("SQLite is built without FTS5")
+		}
+
+		for _, query := range []string{
+			`UPDATE cupped_coffees SET rank = 3 WHERE coffee_id = 2`,
+			`UPDATE cupped_coffees SET notes = "dark chocolate" WHERE coffee_id = 2`,
+			`UPDATE brewings SET rating = 9 WHERE id = 1`,
+		} {
+			if _, err := s.db.ExecContext(ctx, query); err != nil {
+				t.Fatalf("failed to update notes: %v", err)
+			}
+		}
+
+		var entries int
+		if err := s.db.QueryRowContext(ctx, `SELECT count(*) FROM notes_fts`).Scan(&entries); err != nil {
+			t.Fatalf("failed to count notes_fts entries: %v", err)
+		}
+		if entries != 5 {
+			t.Errorf("notes_fts entries = %v, want 5", entries)
+		}
+
+		hits, err := s.getNoteSearchHits(ctx, "dark", "", "", 10)
+		if err != nil {
+			t.Fatalf("getNoteSearchHits() error = %v", err)
+		}
+		want := []noteSearchHit{{source: "cupped_coffee", parentID: 1, coffeeName: "Huila"}}
+		if !sameNoteSearchHits(hits, want) {
+			t.Errorf("getNoteSearchHits() = %+v, want %+v", hits, want)
+		}
+	})
+
+	// Opening a database that is up to date runs no migrations
+	reopened, err := OpenSQLiteDB(ctx, zap.NewNop(), path)
+	if err != nil {
+		t.Fatalf("failed to reopen migrated database: %v", err)
+	}
+	reopened.Close()
+}
+
+// Returns whether the hits are the wanted hits in any order, comparing only their source, parent and coffee name.
+func sameNoteSearchHits(hits []noteSearchHit, want []noteSearchHit) bool {
+	if len(hits) != len(want) {
+		return false
+	}
+
+	remaining := append([]noteSearchHit(nil), want...)
+	for _, hit := range hits {
+		found := false
+		for i, w := range remaining {
+			if hit.source == w.source && hit.parentID == w.parentID && hit.coffeeName == w.coffeeName {
+				remaining = append(remaining[:i], remaining[i+1:]...)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	return true
+}
@@ -5,18 +5,21 @@ import (
 	"database/sql"
 	"fmt"
 	"reflect"
+	"strings"
 )
 
-func (s *SQLiteDB) getBrewingMethodsByLastAdded(ctx context.Context, limit int) ([]brewingMethod, error) {
+func (s *SQLiteDB) getBrewingMethodsByLastAdded(ctx context.Context, after *pageCursor, limit int) ([]brewingMethod, error) {
 	brewingMethods := make([]brewingMethod, 0, limit)
 	if err := s.TransactContext(ctx, func(ctx context.Context, tx *sql.Tx) error {
-		rows, err := tx.QueryContext(ctx, `
-			SELECT name
+		afterCursor, args := afterCursorCondition(after, "", false, "id", true)
+		rows, err := tx.QueryContext(ctx, fmt.Sprintf(`
+			SELECT id, name
 			FROM brewing_methods
+			WHERE %s
 			ORDER BY id DESC
 			LIMIT :limit
-		`,
-			sql.Named("limit", limit),
+		`, afterCursor),
+			append(args, sql.Named("limit", limit))...,
 		)
 		if err != nil {
 			return fmt.Errorf("buna: sqlite_db_retrieve: failed to retrieve brewing method rows: %w", err)
@@ -25,7 +28,7 @@ func (s *SQLiteDB) getBrewingMethodsByLastAdded(ctx context.Context, limit int)
 
 		for rows.Next() {
 			var brewingMethod brewingMethod
-			if err := rows.Scan(&brewingMethod.name); err != nil {
+			if err := rows.Scan(&brewingMethod.id, &brewingMethod.name); err != nil {
 				return fmt.Errorf("buna: sqlite_db_retrieve: failed to scan row: %w", err)
 			}
 
@@ -44,13 +47,199 @@ func (s *SQLiteDB) getBrewingMethodsByLastAdded(ctx context.Context, limit int)
 	return brewingMethods, nil
 }
 
-func (s *SQLiteDB) getBrewingsOrderByDesc(ctx context.Context, limit int, orderByName string) ([]brewing, error) {
+// orderByName must be "id" or "rating". Unrated brewings come last when ordering by rating.
+func (s *SQLiteDB) getBrewingsOrderByDesc(ctx context.Context, after *pageCursor, limit int, orderByName string) ([]brewing, error) {
+	keyExpr := ""
+	if orderByName != "id" {
+		keyExpr = fmt.Sprintf("ifnull(b.%s, 0)", orderByName)
+	}
+
+	afterCursor, args := afterCursorCondition(after, keyExpr, true, "b.id", true)
+	brewings, err := s.getBrewingsWhere(ctx, afterCursor, fmt.Sprintf("ifnull(b.%s, 0) DESC, b.id DESC", orderByName), limit, args...)
+	if err != nil {
+		return nil, fmt.Errorf("buna: sqlite_db_retrieve: failed to get brewings ordered by %v: %w", orderByName, err)
+	}
+
+	return brewings, nil
+}
+
+// SQL expressions brewings are sorted by for each of brewingSortKeys.
+// Expressions must not be NULL, so that they can be used as page cursor keys.
+var brewingSortKeyToSQL = map[string]string{
+	"date":          "b.date_utc",
+	"rating":        "ifnull(b.rating, 0)",
+	"coffee":        "c.name COLLATE NOCASE",
+	"roaster":       "r.name COLLATE NOCASE",
+	"method":        "m.name COLLATE NOCASE",
+	"grinder":       "g.name COLLATE NOCASE",
+	"grind":         "b.grind_setting",
+	"time":          "b.total_brewing_time_sec",
+	"ratio":         "ifnull(b.water_grams / NULLIF(b.coffee_grams, 0), 0)",
+	"coffee-weight": "b.coffee_grams",
+	"water-weight":  "b.water_grams",
+	"id":            "b.id",
+}
+
+// Returns the brewings matching all criteria of the query in the order of the query.
+// The cursor key must be the value returned by brewingSortKeyValue for the sort key of the query.
+func (s *SQLiteDB) getBrewingsByQuery(ctx context.Context, query brewingQuery, after *pageCursor, limit int) ([]brewing, error) {
+	sortBy := query.sortBy
+	if sortBy == "" {
+		sortBy = defaultBrewingSortKey
+	}
+	orderByExpr, ok := brewingSortKeyToSQL[sortBy]
+	if !ok {
+		return nil, fmt.Errorf("buna: sqlite_db_retrieve: unknown brewing sort key %q", sortBy)
+	}
+
+	direction := "DESC"
+	if query.ascending {
+		direction = "ASC"
+	}
+
+	afterCursor, args := afterCursorCondition(after, orderByExpr, !query.ascending, "b.id", !query.ascending)
+	args = append(args,
+		sql.Named("fromDate", query.fromDate),
+		sql.Named("toDate", query.toDate),
+		sql.Named("coffeeName", escapeLike(query.coffeeName)),
+		sql.Named("coffeeRoaster", escapeLike(query.coffeeRoaster)),
+		sql.Named("brewingMethodName", escapeLike(query.brewingMethodName)),
+		sql.Named("grinderName", escapeLike(query.grinderName)),
+		sql.Named("notes", escapeLike(query.notes)),
+		sql.Named("v60FilterType", query.v60FilterType),
+		sql.Named("minRating", query.minRating),
+		sql.Named("maxRating", query.maxRating),
+		sql.Named("minGrindSetting", query.minGrindSetting),
+		sql.Named("maxGrindSetting", query.maxGrindSetting),
+		sql.Named("minRatio", query.minRatio),
+		sql.Named("maxRatio", query.maxRatio),
+	)
+
+	brewings, err := s.getBrewingsWhere(ctx, afterCursor+`
+		AND (substr(b.date, 1, 10) >= :fromDate OR "" = :fromDate)
+		AND (substr(b.date, 1, 10) <= :toDate OR "" = :toDate)
+		AND (c.name LIKE "%" || :coffeeName || "%" ESCAPE "\" OR "" = :coffeeName)
+		AND (r.name LIKE "%" || :coffeeRoaster || "%" ESCAPE "\" OR "" = :coffeeRoaster)
+		AND (m.name LIKE "%" || :brewingMethodName || "%" ESCAPE "\" OR "" = :brewingMethodName)
+		AND (g.name LIKE "%" || :grinderName || "%" ESCAPE "\" OR "" = :grinderName)
+		AND (b.notes LIKE "%" || :notes || "%" ESCAPE "\" OR "" = :notes)
+		AND (b.v60_filter_type = :v60FilterType OR "" = :v60FilterType)
+		AND (b.rating >= :minRating OR 0 = :minRating)
+		AND (b.rating <= :maxRating OR 0 = :maxRating)
+		AND (b.grind_setting >= :minGrindSetting OR 0 = :minGrindSetting)
+		AND (b.grind_setting <= :maxGrindSetting OR 0 = :maxGrindSetting)
+		AND (b.water_grams / NULLIF(b.coffee_grams, 0) >= :minRatio OR 0 = :minRatio)
+		AND (b.water_grams / NULLIF(b.coffee_grams, 0) <= :maxRatio OR 0 = :maxRatio)
+	`, fmt.Sprintf("%[1]s %[2]s, b.id %[2]s", orderByExpr, direction), limit, args...)
+	if err != nil {
+		return nil, fmt.Errorf("buna: sqlite_db_retrieve: failed to get brewings by query: %w", err)
+	}
+
+	return brewings, nil
+}
+
+// Returns the rated brewings matching the filter and in the ratio band, most recent first.
+// The following fields are used from the brewingFilter argument:
+// brewingMethodName, v60FilterType, coffeeName, coffeeRoaster, grinderName
+func (s *SQLiteDB) getRatedBrewings(ctx context.Context, brewingFilter brewing, band ratioBand, limit int) ([]brewing, error) {
+	brewings, err := s.getBrewingsWhere(ctx, `
+		b.rating IS NOT NULL
+		AND (m.name = :brewingMethodName OR "" = :brewingMethodName)
+		AND (b.v60_filter_type = :v60FilterType OR "" = :v60FilterType)
+		AND (c.name = :coffeeName OR "" = :coffeeName)
+		AND (r.name = :coffeeRoaster COLLATE NOCASE OR "" = :coffeeRoaster)
+		AND (g.name = :grinderName OR "" = :grinderName)
+		AND (b.water_grams / NULLIF(b.coffee_grams, 0) >= :minRatio OR 0 = :minRatio)
+		AND (b.water_grams / NULLIF(b.coffee_grams, 0) < :maxRatio OR 0 = :maxRatio)
+	`, "b.id DESC", limit,
+		sql.Named("brewingMethodName", brewingFilter.brewingMethodName),
+		sql.Named("v60FilterType", brewingFilter.v60FilterType),
+		sql.Named("coffeeName", brewingFilter.coffeeName),
+		sql.Named("coffeeRoaster", brewingFilter.coffeeRoaster),
+		sql.Named("grinderName", brewingFilter.grinderName),
+		sql.Named("minRatio", band.min),
+		sql.Named("maxRatio", band.max),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("buna: sqlite_db_retrieve: failed to get rated brewings: %w", err)
+	}
+
+	return brewings, nil
+}
+
+// Returns sql.ErrNoRows if the brewing does not exist.
+func (s *SQLiteDB) getBrewingByID(ctx context.Context, id int) (brewing, error) {
+	brewings, err := s.getBrewingsWhere(ctx, "b.id = :id", "b.id", 1,
+		sql.Named("id", id),
+	)
+	if err != nil {
+		return brewing{}, fmt.Errorf("buna: sqlite_db_retrieve: failed to get brewing by id: %w", err)
+	}
+	if len(brewings) == 0 {
+		return brewing{}, fmt.Errorf("buna: sqlite_db_retrieve: brewing does not exist: %w", sql.ErrNoRows)
+	}
+
+	return brewings[0], nil
+}
+
+// Returns the brewing of the same coffee brewed right before the brewing.
+// Returns sql.ErrNoRows if there is none.
+func (s *SQLiteDB) getPreviousBrewingOfCoffee(ctx context.Context, b brewing) (brewing, error) {
+	brewings, err := s.getBrewingsWhere(ctx, `
+		c.name = :coffeeName
+		AND r.name = :coffeeRoaster COLLATE NOCASE
+		AND (b.date_utc, b.id) < (:dateUTC, :id)
+	`, "b.date_utc DESC, b.id DESC", 1,
+		sql.Named("coffeeName", b.coffeeName),
+		sql.Named("coffeeRoaster", b.coffeeRoaster),
+		sql.Named("dateUTC", utcTimestamp(b.date)),
+		sql.Named("id", b.id),
+	)
+	if err != nil {
+		return brewing{}, fmt.Errorf("buna: sqlite_db_retrieve: failed to get previous brewing of coffee: %w", err)
+	}
+	if len(brewings) == 0 {
+		return brewing{}, fmt.Errorf("buna: sqlite_db_retrieve: no previous brewing of coffee: %w", sql.ErrNoRows)
+	}
+
+	return brewings[0], nil
+}
+
+// Returns the brewing of the same coffee brewed right after the brewing.
+// Returns sql.ErrNoRows if there is none.
+func (s *SQLiteDB) getNextBrewingOfCoffee(ctx context.Context, b brewing) (brewing, error) {
+	brewings, err := s.getBrewingsWhere(ctx, `
+		c.name = :coffeeName
+		AND r.name = :coffeeRoaster COLLATE NOCASE
+		AND (b.date_utc, b.id) > (:dateUTC, :id)
+	`, "b.date_utc, b.id", 1,
+		sql.Named("coffeeName", b.coffeeName),
+		sql.Named("coffeeRoaster", b.coffeeRoaster),
+		sql.Named("dateUTC", utcTimestamp(b.date)),
+		sql.Named("id", b.id),
+	)
+	if err != nil {
+		return brewing{}, fmt.Errorf("buna: sqlite_db_retrieve: failed to get next brewing of coffee: %w", err)
+	}
+	if len(brewings) == 0 {
+		return brewing{}, fmt.Errorf("buna: sqlite_db_retrieve: no next brewing of coffee: %w", sql.ErrNoRows)
+	}
+
+	return brewings[0], nil
+}
+
+// where and orderBy are inserted into the query as is and must not contain user input.
+// User input must be passed using named args instead.
+func (s *SQLiteDB) getBrewingsWhere(ctx context.Context, where string, orderBy string, limit int, args ...interface{}) ([]brewing, error) {
 	brewings := make([]brewing, 0, limit)
 	if err := s.TransactContext(ctx, func(ctx context.Context, tx *sql.Tx) error {
+		args = append(args, sql.Named("limit", limit), userArg(ctx))
 		rows, err := tx.QueryContext(ctx, fmt.Sprintf(`
-			SELECT 	b.date,
+			SELECT 	b.id,
+					b.date,
+					b.time_known,
 					c.name,
-					c.roaster,
+					r.name,
 					m.name,
 					b.roast_date,
 					g.name,
@@ -60,20 +249,41 @@ func (s *SQLiteDB) getBrewingsOrderByDesc(ctx context.Context, limit int, orderB
 					b.water_grams,
 					b.v60_filter_type,
 					b.rating,
+					b.sweetness,
+					b.acidity,
+					b.bitterness,
+					b.body,
+					b.clarity,
+					b.astringency,
+					b.extraction,
 					b.recommended_grind_setting_adjustment,
 					b.recommended_coffee_weight_adjustment_grams,
-					b.notes
+					b.notes,
+					(
+						SELECT group_concat(f.name, ", ")
+						FROM brewing_flavors AS bf
+						INNER JOIN flavors AS f
+							ON f.id = bf.flavor_id
+						WHERE bf.brewing_id = b.id
+					),
+					u.name
 			FROM brewings AS b
 			INNER JOIN coffees AS c
 				ON c.id = b.coffee_id
+			INNER JOIN roasters AS r
+				ON r.id = c.roaster_id
 			INNER JOIN brewing_methods AS m
 				ON m.id = b.method_id
 			INNER JOIN grinders AS g
 				ON g.id = b.grinder_id
-			ORDER BY b.%s DESC, b.id DESC
+			LEFT JOIN users AS u
+				ON u.id = b.user_id
+			WHERE (%s)
+			AND (b.user_id = :userID OR 0 = :userID)
+			ORDER BY %s
 			LIMIT :limit
-		`, orderByName),
-			sql.Named("limit", limit),
+		`, where, orderBy),
+			args...,
 		)
 		if err != nil {
 			return fmt.Errorf("buna: sqlite_db_retrieve: failed to retrieve brewing rows: %w", err)
@@ -83,8 +293,11 @@ func (s *SQLiteDB) getBrewingsOrderByDesc(ctx context.Context, limit int, orderB
 		for rows.Next() {
 			var brewing brewing
 			var roastDate, v60FilterType, rating, recommendedGrindSettingAdjustment, recommendedCoffeeWeightAdjustmentGrams, notes interface{}
+			var sweetness, acidity, bitterness, body, clarity, astringency, extraction, flavors, userName interface{}
 			if err := rows.Scan(
+				&brewing.id,
 				&brewing.date,
+				&brewing.timeKnown,
 				&brewing.coffeeName,
 				&brewing.coffeeRoaster,
 				&brewing.brewingMethodName,
@@ -96,9 +309,18 @@ func (s *SQLiteDB) getBrewingsOrderByDesc(ctx context.Context, limit int, orderB
 				&brewing.waterGrams,
 				&v60FilterType,
 				&rating,
+				&sweetness,
+				&acidity,
+				&bitterness,
+				&body,
+				&clarity,
+				&astringency,
+				&extraction,
 				&recommendedGrindSettingAdjustment,
 				&recommendedCoffeeWeightAdjustmentGrams,
 				&notes,
+				&flavors,
+				&userName,
 			); err != nil {
 				return fmt.Errorf("buna: sqlite_db_retrieve: failed to scan row: %w", err)
 			}
@@ -135,6 +357,20 @@ func (s *SQLiteDB) getBrewingsOrderByDesc(ctx context.Context, limit int, orderB
 				brewing.notes = "None"
 			}
 
+			brewing.scores = brewingScores{
+				sweetness:   nullableIntOr(sweetness, 0),
+				acidity:     nullableIntOr(acidity, 0),
+				bitterness:  nullableIntOr(bitterness, 0),
+				body:        nullableIntOr(body, 0),
+				clarity:     nullableIntOr(clarity, 0),
+				astringency: nullableIntOr(astringency, 0),
+			}
+			brewing.extraction = nullableStringOr(extraction, "")
+			if flavors, ok := flavors.(string); ok {
+				brewing.flavors = strings.Split(flavors, ", ")
+			}
+			brewing.userName = nullableStringOr(userName, "")
+
 			brewings = append(brewings, brewing)
 		}
 
@@ -144,15 +380,16 @@ func (s *SQLiteDB) getBrewingsOrderByDesc(ctx context.Context, limit int, orderB
 
 		return nil
 	}); err != nil {
-		return nil, fmt.Errorf("buna: sqlite_db_retrieve: getBrewingsByLastAdded transaction failed: %w", err)
+		return nil, fmt.Errorf("buna: sqlite_db_retrieve: getBrewingsWhere transaction failed: %w", err)
 	}
 
 	return brewings, nil
 }
 
+// Only brewings in the ratio band are suggested.
 // The following fields are used from the brewingFilter argument:
 // brewingMethodName, v60FilterType, coffeeName, coffeeRoaster, coffeeGrams, waterGrams, grinderName
-func (s *SQLiteDB) getBrewingSuggestions(ctx context.Context, limit int, brewingFilter brewing) ([]brewing, error) {
+func (s *SQLiteDB) getBrewingSuggestions(ctx context.Context, limit int, brewingFilter brewing, band ratioBand) ([]brewing, error) {
 	brewings := make([]brewing, 0, limit)
 	if err := s.TransactContext(ctx, func(ctx context.Context, tx *sql.Tx) error {
 		rows, err := tx.QueryContext(ctx, `
@@ -171,6 +408,8 @@ func (s *SQLiteDB) getBrewingSuggestions(ctx context.Context, limit int, brewing
 			FROM brewings AS b
 			INNER JOIN coffees AS c
 				ON c.id = b.coffee_id
+			INNER JOIN roasters AS r
+				ON r.id = c.roaster_id
 			INNER JOIN brewing_methods AS m
 				ON m.id = b.method_id
 			INNER JOIN grinders AS g
@@ -178,10 +417,13 @@ func (s *SQLiteDB) getBrewingSuggestions(ctx context.Context, limit int, brewing
 			WHERE (m.name = :brewingMethodName)
 			AND (b.v60_filter_type = :v60FilterType OR "" = :v60FilterType)
 			AND (c.name = :coffeeName OR "" = :coffeeName)
-			AND (c.roaster = :coffeeRoaster OR "" = :coffeeRoaster)
+			AND (r.name = :coffeeRoaster COLLATE NOCASE OR "" = :coffeeRoaster)
 			AND (b.coffee_grams = :coffeeGrams OR 0 = :coffeeGrams)
 			AND (b.water_grams = :waterGrams OR 0 = :waterGrams)
 			AND (g.name = :grinderName OR "" = :grinderName)
+			AND (b.water_grams / NULLIF(b.coffee_grams, 0) >= :minRatio OR 0 = :minRatio)
+			AND (b.water_grams / NULLIF(b.coffee_grams, 0) < :maxRatio OR 0 = :maxRatio)
+			AND (b.user_id = :userID OR 0 = :userID)
 			ORDER BY b.id DESC
 			LIMIT :limit
 		`,
@@ -193,6 +435,9 @@ func (s *SQLiteDB) getBrewingSuggestions(ctx context.Context, limit int, brewing
 			sql.Named("coffeeGrams", brewingFilter.coffeeGrams),
 			sql.Named("waterGrams", brewingFilter.waterGrams),
 			sql.Named("grinderName", brewingFilter.grinderName),
+			sql.Named("minRatio", band.min),
+			sql.Named("maxRatio", band.max),
+			userArg(ctx),
 		)
 		if err != nil {
 			return fmt.Errorf("buna: sqlite_db_retrieve: failed to retrieve brewing suggestion rows: %w", err)
@@ -261,17 +506,16 @@ func (s *SQLiteDB) getBrewingSuggestions(ctx context.Context, limit int, brewing
 	return brewings, nil
 }
 
+// Names are matched case-insensitively and ignoring surrounding whitespace. An exact match is preferred.
+// Returns sql.ErrNoRows if there is no such coffee, see getSimilarCoffees for close matches.
+// Returns sql.ErrNoRows, wrapped together with similar coffee names, if no coffee of the roaster has the name.
 func (s *SQLiteDB) getCoffeeIDByNameRoaster(ctx context.Context, name string, roaster string) (int, error) {
 	var coffeeID int
 	if err := s.TransactContext(ctx, func(ctx context.Context, tx *sql.Tx) error {
-		if err := tx.QueryRowContext(ctx, `
-			SELECT id from coffees
-			WHERE name = :name AND roaster = :roaster
-		`,
-			sql.Named("name", name),
-			sql.Named("roaster", roaster),
-		).Scan(&coffeeID); err != nil {
-			return fmt.Errorf("buna: sqlite_db_retrieve: failed to retrieve coffee id from db: %w", err)
+		var err error
+		coffeeID, err = getCoffeeID(ctx, tx, name, roaster)
+		if err != nil {
+			return fmt.Errorf("buna: sqlite_db_retrieve: failed to get coffee id: %w", err)
 		}
 
 		return nil
@@ -282,18 +526,156 @@ func (s *SQLiteDB) getCoffeeIDByNameRoaster(ctx context.Context, name string, ro
 	return coffeeID, nil
 }
 
-func (s *SQLiteDB) getCoffeePurchasesByLastAdded(ctx context.Context, limit int) ([]coffeePurchase, error) {
+// Looks up a coffee ignoring case and repeated or surrounding whitespace, preferring the exact name.
+// Returns sql.ErrNoRows, wrapped together with similar coffee names, if there is none.
+func getCoffeeID(ctx context.Context, tx *sql.Tx, name string, roaster string) (int, error) {
+	rows, err := tx.QueryContext(ctx, `
+		SELECT c.id, c.name, r.name
+		FROM coffees AS c
+		INNER JOIN roasters AS r
+			ON r.id = c.roaster_id
+		ORDER BY c.id DESC
+	`)
+	if err != nil {
+		return 0, fmt.Errorf("buna: sqlite_db_retrieve: failed to retrieve coffees: %w", err)
+	}
+	defer rows.Close()
+
+	coffeeID := 0
+	var names []string
+	for rows.Next() {
+		var id int
+		var coffeeName, roasterName string
+		if err := rows.Scan(&id, &coffeeName, &roasterName); err != nil {
+			return 0, fmt.Errorf("buna: sqlite_db_retrieve: failed to scan row: %w", err)
+		}
+		names = append(names, coffeeName)
+
+		if normalizeName(coffeeName) != normalizeName(name) || normalizeName(roasterName) != normalizeName(roaster) {
+			continue
+		}
+		if coffeeID == 0 || coffeeName == name {
+			coffeeID = id
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("buna: sqlite_db_retrieve: failed to scan last row: %w", err)
+	}
+
+	if coffeeID == 0 {
+		return 0, noSuchNameError("coffee", name+" ("+roaster+")", closestNames(name, removeStrDuplicates(names), maxSimilarNames))
+	}
+
+	return coffeeID, nil
+}
+
+// Looks up the id of the row of table with the name ignoring case and repeated or surrounding whitespace,
+// preferring the exact name. Returns sql.ErrNoRows, wrapped together with similar names, if there is none.
+// table is inserted into the query as is and must not contain user input.
+func getIDByName(ctx context.Context, tx *sql.Tx, table string, entityName string, name string) (int, error) {
+	rows, err := tx.QueryContext(ctx, fmt.Sprintf(`
+		SELECT id, name
+		FROM %s
+		ORDER BY id DESC
+	`, table))
+	if err != nil {
+		return 0, fmt.Errorf("buna: sqlite_db_retrieve: failed to retrieve %v: %w", table, err)
+	}
+	defer rows.Close()
+
+	matchID := 0
+	var names []string
+	for rows.Next() {
+		var id int
+		var rowName string
+		if err := rows.Scan(&id, &rowName); err != nil {
+			return 0, fmt.Errorf("buna: sqlite_db_retrieve: failed to scan row: %w", err)
+		}
+		names = append(names, rowName)
+
+		if normalizeName(rowName) != normalizeName(name) {
+			continue
+		}
+		if matchID == 0 || rowName == name {
+			matchID = id
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("buna: sqlite_db_retrieve: failed to scan last row: %w", err)
+	}
+
+	if matchID == 0 {
+		return 0, noSuchNameError(entityName, name, closestNames(name, names, maxSimilarNames))
+	}
+
+	return matchID, nil
+}
+
+// Wraps sql.ErrNoRows so that callers can still tell a missing name from other errors.
+func noSuchNameError(entityName string, name string, similarNames []string) error {
+	if len(similarNames) == 0 {
+		return fmt.Errorf("buna: sqlite_db_retrieve: no %v named %q: %w", entityName, name, sql.ErrNoRows)
+	}
+
+	return fmt.Errorf("buna: sqlite_db_retrieve: no %v named %q, did you mean %v?: %w", entityName, name, strings.Join(similarNames, " or "), sql.ErrNoRows)
+}
+
+// Returns the purchase the coffee of the brewing most likely came from:
+// the purchase with the same roast date or else the last purchase bought on or before the brewing date.
+// Purchases of all users are considered, as a bag is shared by everyone brewing from it.
+// Returns sql.ErrNoRows if there is none.
+func (s *SQLiteDB) getCoffeePurchaseOfBrewing(ctx context.Context, b brewing) (coffeePurchase, error) {
+	var coffeePurchase coffeePurchase
+	if err := s.TransactContext(ctx, func(ctx context.Context, tx *sql.Tx) error {
+		var roastDate interface{}
+		if err := tx.QueryRowContext(ctx, `
+			SELECT p.id, c.name, r.name, p.bought_date, p.roast_date
+			FROM purchases AS p
+			INNER JOIN coffees AS c
+				ON p.coffee_id = c.id
+			INNER JOIN roasters AS r
+				ON r.id = c.roaster_id
+			WHERE c.name = :coffeeName
+				AND r.name = :coffeeRoaster COLLATE NOCASE
+				AND (p.roast_date = :roastDate OR p.bought_date_utc <= :dateUTC)
+			ORDER BY ifnull(p.roast_date = :roastDate, 0) DESC, p.bought_date_utc DESC, p.id DESC
+			LIMIT 1
+		`,
+			sql.Named("coffeeName", b.coffeeName),
+			sql.Named("coffeeRoaster", b.coffeeRoaster),
+			sql.Named("roastDate", b.roastDate),
+			sql.Named("dateUTC", utcTimestamp(b.date)),
+		).Scan(&coffeePurchase.id, &coffeePurchase.coffeeName, &coffeePurchase.coffeeRoaster, &coffeePurchase.boughtDate, &roastDate); err != nil {
+			return fmt.Errorf("buna: sqlite_db_retrieve: failed to retrieve coffee purchase: %w", err)
+		}
+
+		coffeePurchase.roastDate = nullableStringOr(roastDate, "Unknown")
+
+		return nil
+	}); err != nil {
+		return coffeePurchase, fmt.Errorf("buna: sqlite_db_retrieve: getCoffeePurchaseOfBrewing transaction failed: %w", err)
+	}
+
+	return coffeePurchase, nil
+}
+
+func (s *SQLiteDB) getCoffeePurchasesByLastAdded(ctx context.Context, after *pageCursor, limit int) ([]coffeePurchase, error) {
 	coffeePurchases := make([]coffeePurchase, 0, limit)
 	if err := s.TransactContext(ctx, func(ctx context.Context, tx *sql.Tx) error {
-		rows, err := tx.QueryContext(ctx, `
-			SELECT c.name, c.roaster, p.bought_date, p.roast_date
+		afterCursor, args := afterCursorCondition(after, "", false, "p.id", true)
+		rows, err := tx.QueryContext(ctx, fmt.Sprintf(`
+			SELECT p.id, c.name, r.name, p.bought_date, p.roast_date
 			FROM purchases AS p
 			INNER JOIN coffees AS c
 				ON p.coffee_id = c.id
+			INNER JOIN roasters AS r
+				ON r.id = c.roaster_id
+			WHERE %s
+			AND (p.user_id = :userID OR 0 = :userID)
 			ORDER BY p.id DESC
 			LIMIT :limit
-		`,
-			sql.Named("limit", limit),
+		`, afterCursor),
+			append(args, sql.Named("limit", limit), userArg(ctx))...,
 		)
 		if err != nil {
 			return fmt.Errorf("buna: sqlite_db_retrieve: failed to retrieve coffee purchase rows: %w", err)
@@ -303,7 +685,7 @@ func (s *SQLiteDB) getCoffeePurchasesByLastAdded(ctx context.Context, limit int)
 		for rows.Next() {
 			var coffeePurchase coffeePurchase
 			var roastDate interface{}
-			if err := rows.Scan(&coffeePurchase.coffeeName, &coffeePurchase.coffeeRoaster, &coffeePurchase.boughtDate, &roastDate); err != nil {
+			if err := rows.Scan(&coffeePurchase.id, &coffeePurchase.coffeeName, &coffeePurchase.coffeeRoaster, &coffeePurchase.boughtDate, &roastDate); err != nil {
 				return fmt.Errorf("buna: sqlite_db_retrieve: failed to scan row: %w", err)
 			}
 
@@ -329,16 +711,191 @@ func (s *SQLiteDB) getCoffeePurchasesByLastAdded(ctx context.Context, limit int)
 	return coffeePurchases, nil
 }
 
-func (s *SQLiteDB) getCoffeesByLastAdded(ctx context.Context, limit int) ([]coffee, error) {
+func (s *SQLiteDB) getCoffeesByLastAdded(ctx context.Context, after *pageCursor, limit int) ([]coffee, error) {
+	afterCursor, args := afterCursorCondition(after, "", false, "c.id", true)
+	coffees, err := s.getCoffeesWhere(ctx, afterCursor, "c.id DESC", limit, args...)
+	if err != nil {
+		return nil, fmt.Errorf("buna: sqlite_db_retrieve: failed to get coffees by last added: %w", err)
+	}
+
+	return coffees, nil
+}
+
+// name is matched case-insensitively and partially.
+// The cursor key is the coffee name.
+func (s *SQLiteDB) getCoffeesByName(ctx context.Context, name string, after *pageCursor, limit int) ([]coffee, error) {
+	afterCursor, args := afterCursorCondition(after, "c.name COLLATE NOCASE", false, "c.id", false)
+	coffees, err := s.getCoffeesWhere(ctx, afterCursor+` AND c.name LIKE "%" || :name || "%" ESCAPE "\"`, "c.name COLLATE NOCASE, c.id", limit,
+		append(args, sql.Named("name", escapeLike(name)))...,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("buna: sqlite_db_retrieve: failed to get coffees by name: %w", err)
+	}
+
+	return coffees, nil
+}
+
+// Returns sql.ErrNoRows if the preference is not set.
+func (s *SQLiteDB) getPreference(ctx context.Context, name string) (string, error) {
+	var value string
+	if err := s.TransactContext(ctx, func(ctx context.Context, tx *sql.Tx) error {
+		if err := tx.QueryRowContext(ctx, `
+			SELECT value
+			FROM preferences
+			WHERE name = :name
+		`,
+			sql.Named("name", name),
+		).Scan(&value); err != nil {
+			return fmt.Errorf("buna: sqlite_db_retrieve: failed to retrieve preference: %w", err)
+		}
+
+		return nil
+	}); err != nil {
+		return "", fmt.Errorf("buna: sqlite_db_retrieve: getPreference transaction failed: %w", err)
+	}
+
+	return value, nil
+}
+
+// Returns sql.ErrNoRows if the coffee does not exist.
+// Names are matched case-insensitively and ignoring surrounding whitespace. An exact match is preferred.
+func (s *SQLiteDB) getCoffeeByNameRoaster(ctx context.Context, name string, roaster string) (coffee, error) {
+	coffees, err := s.getCoffeesWhere(ctx, "c.name = trim(:name) COLLATE NOCASE AND r.name = trim(:roaster) COLLATE NOCASE", "c.name = :name DESC, c.id", 1,
+		sql.Named("name", name),
+		sql.Named("roaster", roaster),
+	)
+	if err != nil {
+		return coffee{}, fmt.Errorf("buna: sqlite_db_retrieve: failed to get coffee by name and roaster: %w", err)
+	}
+	if len(coffees) == 0 {
+		return coffee{}, fmt.Errorf("buna: sqlite_db_retrieve: coffee does not exist: %w", sql.ErrNoRows)
+	}
+
+	return coffees[0], nil
+}
+
+// The cursor key is the coffee name.
+func (s *SQLiteDB) getCoffeesAlphabetically(ctx context.Context, after *pageCursor, limit int) ([]coffee, error) {
+	afterCursor, args := afterCursorCondition(after, "c.name COLLATE NOCASE", false, "c.id", false)
+	coffees, err := s.getCoffeesWhere(ctx, afterCursor, "c.name COLLATE NOCASE, c.id", limit, args...)
+	if err != nil {
+		return nil, fmt.Errorf("buna: sqlite_db_retrieve: failed to get coffees alphabetically: %w", err)
+	}
+
+	return coffees, nil
+}
+
+// roaster is matched case-insensitively and partially.
+// The cursor key is the roaster name.
+func (s *SQLiteDB) getCoffeesByRoaster(ctx context.Context, roaster string, after *pageCursor, limit int) ([]coffee, error) {
+	afterCursor, args := afterCursorCondition(after, "r.name COLLATE NOCASE", false, "c.id", true)
+	coffees, err := s.getCoffeesWhere(ctx, afterCursor+` AND r.name LIKE "%" || :roaster || "%" ESCAPE "\"`, "r.name COLLATE NOCASE, c.id DESC", limit,
+		append(args, sql.Named("roaster", escapeLike(roaster)))...,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("buna: sqlite_db_retrieve: failed to get coffees by roaster: %w", err)
+	}
+
+	return coffees, nil
+}
+
+func (s *SQLiteDB) getDecafCoffeesByLastAdded(ctx context.Context, after *pageCursor, limit int) ([]coffee, error) {
+	afterCursor, args := afterCursorCondition(after, "", false, "c.id", true)
+	coffees, err := s.getCoffeesWhere(ctx, afterCursor+" AND c.decaf = 1", "c.id DESC", limit, args...)
+	if err != nil {
+		return nil, fmt.Errorf("buna: sqlite_db_retrieve: failed to get decaf coffees by last added: %w", err)
+	}
+
+	return coffees, nil
+}
+
+// The cursor key is the coffee name.
+func (s *SQLiteDB) getDecafCoffeesAlphabetically(ctx context.Context, after *pageCursor, limit int) ([]coffee, error) {
+	afterCursor, args := afterCursorCondition(after, "c.name COLLATE NOCASE", false, "c.id", false)
+	coffees, err := s.getCoffeesWhere(ctx, afterCursor+" AND c.decaf = 1", "c.name COLLATE NOCASE, c.id", limit, args...)
+	if err != nil {
+		return nil, fmt.Errorf("buna: sqlite_db_retrieve: failed to get decaf coffees alphabetically: %w", err)
+	}
+
+	return coffees, nil
+}
+
+// An empty countryCode matches every country.
+// region is matched case-insensitively and partially, so an empty region matches every region.
+func (s *SQLiteDB) getCoffeesByOrigin(ctx context.Context, countryCode string, region string, after *pageCursor, limit int) ([]coffee, error) {
+	afterCursor, args := afterCursorCondition(after, "", false, "c.id", true)
+	coffees, err := s.getCoffeesWhere(ctx, afterCursor+`
+		AND (c.country_code = :countryCode OR "" = :countryCode)
+		AND (c.region LIKE "%" || :region || "%" ESCAPE "\" OR "" = :region)
+	`, "c.id DESC", limit,
+		append(args,
+			sql.Named("countryCode", countryCode),
+			sql.Named("region", escapeLike(region)),
+		)...,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("buna: sqlite_db_retrieve: failed to get coffees by origin: %w", err)
+	}
+
+	return coffees, nil
+}
+
+// process is matched case-insensitively and partially against the process and its description.
+func (s *SQLiteDB) getCoffeesByProcess(ctx context.Context, process string, after *pageCursor, limit int) ([]coffee, error) {
+	afterCursor, args := afterCursorCondition(after, "", false, "c.id", true)
+	coffees, err := s.getCoffeesWhere(ctx, afterCursor+`
+		AND (
+			c.process LIKE "%" || :process || "%" ESCAPE "\"
+			OR c.process_other LIKE "%" || :process || "%" ESCAPE "\"
+		)
+	`, "c.id DESC", limit,
+		append(args, sql.Named("process", escapeLike(process)))...,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("buna: sqlite_db_retrieve: failed to get coffees by process: %w", err)
+	}
+
+	return coffees, nil
+}
+
+// where and orderBy are inserted into the query as is and must not contain user input.
+// User input must be passed using named args instead.
+func (s *SQLiteDB) getCoffeesWhere(ctx context.Context, where string, orderBy string, limit int, args ...interface{}) ([]coffee, error) {
 	coffees := make([]coffee, 0, limit)
 	if err := s.TransactContext(ctx, func(ctx context.Context, tx *sql.Tx) error {
-		rows, err := tx.QueryContext(ctx, `
-			SELECT name, roaster, region, variety, method, decaf
-			FROM coffees
-			ORDER BY id DESC
+		args = append(args, sql.Named("limit", limit), userArg(ctx))
+		rows, err := tx.QueryContext(ctx, fmt.Sprintf(`
+			SELECT 	c.id,
+					c.name,
+					r.name,
+					c.country_code,
+					c.region,
+					c.farm,
+					c.producer,
+					c.altitude_min_m,
+					c.altitude_max_m,
+					(
+						SELECT group_concat(v.name, ", ")
+						FROM coffee_varieties AS cv
+						INNER JOIN varieties AS v
+							ON v.id = cv.variety_id
+						WHERE cv.coffee_id = c.id
+					),
+					c.process,
+					c.process_other,
+					c.decaf,
+					(SELECT count(*) FROM brewings WHERE coffee_id = c.id AND (user_id = :userID OR 0 = :userID)),
+					(SELECT avg(rating) FROM brewings WHERE coffee_id = c.id AND (user_id = :userID OR 0 = :userID)),
+					(SELECT substr(date, 1, 10) FROM brewings WHERE coffee_id = c.id AND (user_id = :userID OR 0 = :userID) ORDER BY date_utc DESC LIMIT 1),
+					(SELECT count(*) FROM purchases WHERE coffee_id = c.id AND (user_id = :userID OR 0 = :userID))
+			FROM coffees AS c
+			INNER JOIN roasters AS r
+				ON r.id = c.roaster_id
+			WHERE %s
+			ORDER BY %s
 			LIMIT :limit
-		`,
-			sql.Named("limit", limit),
+		`, where, orderBy),
+			args...,
 		)
 		if err != nil {
 			return fmt.Errorf("buna: sqlite_db_retrieve: failed to retrieve coffee rows: %w", err)
@@ -347,30 +904,52 @@ func (s *SQLiteDB) getCoffeesByLastAdded(ctx context.Context, limit int) ([]coff
 
 		for rows.Next() {
 			var coffee coffee
-			var region, variety, method, decaf interface{}
-			if err := rows.Scan(&coffee.name, &coffee.roaster, &region, &variety, &method, &decaf); err != nil {
+			var countryCode, region, farm, producer, altitudeMinM, altitudeMaxM, varieties, process, processOther, decaf, averageRating, lastBrewedDate interface{}
+			if err := rows.Scan(
+				&coffee.id,
+				&coffee.name,
+				&coffee.roaster,
+				&countryCode,
+				&region,
+				&farm,
+				&producer,
+				&altitudeMinM,
+				&altitudeMaxM,
+				&varieties,
+				&process,
+				&processOther,
+				&decaf,
+				&coffee.brewingCount,
+				&averageRating,
+				&lastBrewedDate,
+				&coffee.purchaseCount,
+			); err != nil {
 				return fmt.Errorf("buna: sqlite_db_retrieve: failed to scan row: %w", err)
 			}
 
 			// Deal with possible NULL values
-			if v := reflect.ValueOf(region); v.Kind() == reflect.String {
-				coffee.region = region.(string)
-			} else {
-				coffee.region = "Unknown"
+			coffee.countryCode = nullableStringOr(countryCode, "")
+			coffee.region = nullableStringOr(region, "")
+			coffee.farm = nullableStringOr(farm, "")
+			coffee.producer = nullableStringOr(producer, "")
+			if v := reflect.ValueOf(altitudeMinM); v.Kind() == reflect.Int64 {
+				coffee.altitudeMinM = int(altitudeMinM.(int64))
 			}
-			if v := reflect.ValueOf(variety); v.Kind() == reflect.String {
-				coffee.variety = variety.(string)
-			} else {
-				coffee.variety = "Unknown"
+			if v := reflect.ValueOf(altitudeMaxM); v.Kind() == reflect.Int64 {
+				coffee.altitudeMaxM = int(altitudeMaxM.(int64))
 			}
-			if v := reflect.ValueOf(method); v.Kind() == reflect.String {
-				coffee.method = method.(string)
-			} else {
-				coffee.method = "Unknown"
+			if v := reflect.ValueOf(varieties); v.Kind() == reflect.String {
+				coffee.varieties = strings.Split(varieties.(string), ", ")
 			}
+			coffee.process = nullableStringOr(process, "")
+			coffee.processOther = nullableStringOr(processOther, "")
 			if v := reflect.ValueOf(decaf); v.Kind() == reflect.Bool {
 				coffee.decaf = decaf.(bool)
 			}
+			if v := reflect.ValueOf(averageRating); v.Kind() == reflect.Float64 {
+				coffee.averageRating = averageRating.(float64)
+			}
+			coffee.lastBrewedDate = nullableStringOr(lastBrewedDate, "")
 
 			coffees = append(coffees, coffee)
 		}
@@ -381,118 +960,310 @@ func (s *SQLiteDB) getCoffeesByLastAdded(ctx context.Context, limit int) ([]coff
 
 		return nil
 	}); err != nil {
-		return nil, fmt.Errorf("buna: sqlite_db_retrieve: getCoffeesByLastAdded transaction failed: %w", err)
+		return nil, fmt.Errorf("buna: sqlite_db_retrieve: getCoffeesWhere transaction failed: %w", err)
 	}
 
 	return coffees, nil
 }
 
-func (s *SQLiteDB) getCuppingsByLastAdded(ctx context.Context, limit int) ([]cupping, error) {
+// Returns sql.ErrNoRows if the cupping does not exist.
+func (s *SQLiteDB) getCuppingByID(ctx context.Context, id int) (cupping, error) {
 	var cuppings []cupping
 	if err := s.TransactContext(ctx, func(ctx context.Context, tx *sql.Tx) error {
-		var realLimit interface{}
-		if err := tx.QueryRowContext(ctx, `
-			SELECT sum(coffeesPerCupping)
-			FROM (
-				SELECT cu.id, count(*) as coffeesPerCupping
-				FROM cuppings AS cu
-				INNER JOIN cupped_coffees AS cc
-					ON cu.id = cc.cupping_id
-				GROUP BY cu.id
+		rows, err := tx.QueryContext(ctx, `
+			SELECT cu.id, cu.date, cu.duration_min, cu.notes, c.name, r.name, cc.rank, cc.notes
+			FROM cuppings AS cu
+			INNER JOIN cupped_coffees AS cc
+				ON cu.id = cc.cupping_id
+			INNER JOIN coffees AS c
+				ON c.id = cc.coffee_id
+			INNER JOIN roasters AS r
+				ON r.id = c.roaster_id
+			WHERE cu.id = :id
+			AND (cu.user_id = :userID OR 0 = :userID)
+			ORDER BY cc.rank
+		`,
+			sql.Named("id", id),
+			userArg(ctx),
+		)
+		if err != nil {
+			return fmt.Errorf("buna: sqlite_db_retrieve: failed to retrieve cupping rows: %w", err)
+		}
+		defer rows.Close()
+
+		cuppings, err = scanCuppingRows(rows)
+		if err != nil {
+			return fmt.Errorf("buna: sqlite_db_retrieve: failed to scan cupping rows: %w", err)
+		}
+
+		return nil
+	}); err != nil {
+		return cupping{}, fmt.Errorf("buna: sqlite_db_retrieve: getCuppingByID transaction failed: %w", err)
+	}
+
+	if len(cuppings) == 0 {
+		return cupping{}, fmt.Errorf("buna: sqlite_db_retrieve: cupping does not exist: %w", sql.ErrNoRows)
+	}
+
+	return cuppings[0], nil
+}
+
+func (s *SQLiteDB) getCuppingsByLastAdded(ctx context.Context, after *pageCursor, limit int) ([]cupping, error) {
+	var cuppings []cupping
+	if err := s.TransactContext(ctx, func(ctx context.Context, tx *sql.Tx) error {
+		afterCursor, args := afterCursorCondition(after, "", false, "id", true)
+		rows, err := tx.QueryContext(ctx, fmt.Sprintf(`
+			SELECT cu.id, cu.date, cu.duration_min, cu.notes, c.name, r.name, cc.rank, cc.notes
+			FROM cuppings AS cu
+			INNER JOIN cupped_coffees AS cc
+				ON cu.id = cc.cupping_id
+			INNER JOIN coffees AS c
+				ON c.id = cc.coffee_id
+			INNER JOIN roasters AS r
+				ON r.id = c.roaster_id
+			WHERE cu.id IN (
+				SELECT id
+				FROM cuppings
+				WHERE %s
+				AND (user_id = :userID OR 0 = :userID)
+				ORDER BY id DESC
 				LIMIT :limit
 			)
-		`,
-			sql.Named("limit", limit),
-		).Scan(&realLimit); err != nil {
-			return fmt.Errorf("buna: sqlite_db_retrieve: failed to retrieve real cupping limit from db: %w", err)
+			ORDER BY cu.id DESC, cc.rank
+		`, afterCursor),
+			append(args, sql.Named("limit", limit), userArg(ctx))...,
+		)
+		if err != nil {
+			return fmt.Errorf("buna: sqlite_db_retrieve: failed to retrieve cupping rows: %w", err)
 		}
+		defer rows.Close()
 
-		var realLimitInt int64
-		if v := reflect.ValueOf(realLimit); v.Kind() == reflect.Int64 {
-			realLimitInt = realLimit.(int64)
-		} else {
-			// realLimit == NULL
-			return nil
+		cuppings, err = scanCuppingRows(rows)
+		if err != nil {
+			return fmt.Errorf("buna: sqlite_db_retrieve: failed to scan cupping rows: %w", err)
 		}
 
-		rows, err := tx.QueryContext(ctx, `
-			SELECT cu.id, cu.date, cu.duration_min, cu.notes, c.name, cc.rank, cc.notes
+		return nil
+	}); err != nil {
+		return nil, fmt.Errorf("buna: sqlite_db_retrieve: getCuppingsByLastAdded transaction failed: %w", err)
+	}
+
+	return cuppings, nil
+}
+
+// Returns the cuppings that contain the coffee, ordered by last added.
+func (s *SQLiteDB) getCuppingsByCoffee(ctx context.Context, coffeeName string, coffeeRoaster string, after *pageCursor, limit int) ([]cupping, error) {
+	var cuppings []cupping
+	if err := s.TransactContext(ctx, func(ctx context.Context, tx *sql.Tx) error {
+		afterCursor, args := afterCursorCondition(after, "", false, "icc.cupping_id", true)
+		rows, err := tx.QueryContext(ctx, fmt.Sprintf(`
+			SELECT cu.id, cu.date, cu.duration_min, cu.notes, c.name, r.name, cc.rank, cc.notes
 			FROM cuppings AS cu
 			INNER JOIN cupped_coffees AS cc
 				ON cu.id = cc.cupping_id
 			INNER JOIN coffees AS c
 				ON c.id = cc.coffee_id
+			INNER JOIN roasters AS r
+				ON r.id = c.roaster_id
+			WHERE cu.id IN (
+				SELECT icc.cupping_id
+				FROM cupped_coffees AS icc
+				INNER JOIN cuppings AS icu
+					ON icu.id = icc.cupping_id
+				INNER JOIN coffees AS ic
+					ON ic.id = icc.coffee_id
+				INNER JOIN roasters AS ir
+					ON ir.id = ic.roaster_id
+				WHERE %s
+				AND (icu.user_id = :userID OR 0 = :userID)
+				AND ic.name = :coffeeName
+				AND (ir.name = :coffeeRoaster COLLATE NOCASE OR "" = :coffeeRoaster)
+				ORDER BY icc.cupping_id DESC
+				LIMIT :limit
+			)
 			ORDER BY cu.id DESC, cc.rank
-			LIMIT :limit
-		`,
-			sql.Named("limit", realLimitInt),
+		`, afterCursor),
+			append(args,
+				sql.Named("coffeeName", coffeeName),
+				sql.Named("coffeeRoaster", coffeeRoaster),
+				sql.Named("limit", limit),
+				userArg(ctx),
+			)...,
 		)
 		if err != nil {
 			return fmt.Errorf("buna: sqlite_db_retrieve: failed to retrieve cupping rows: %w", err)
 		}
 		defer rows.Close()
 
-		isFirstRow := true
-		var prevCuppingID int
-		var cupping cupping
-		var cuppedCoffees []cuppedCoffee
-		for rows.Next() {
-			var cuppingID int
-			var coffee cuppedCoffee
-			if err := rows.Scan(
-				&cuppingID,
-				&cupping.date,
-				&cupping.durationMin,
-				&cupping.notes,
-				&coffee.name,
-				&coffee.rank,
-				&coffee.notes,
-			); err != nil {
-				return fmt.Errorf("buna: sqlite_db_retrieve: failed to scan cupping row: %w", err)
-			}
+		cuppings, err = scanCuppingRows(rows)
+		if err != nil {
+			return fmt.Errorf("buna: sqlite_db_retrieve: failed to scan cupping rows: %w", err)
+		}
 
-			if isFirstRow {
-				prevCuppingID = cuppingID
-				isFirstRow = false
-			}
+		return nil
+	}); err != nil {
+		return nil, fmt.Errorf("buna: sqlite_db_retrieve: getCuppingsByCoffee transaction failed: %w", err)
+	}
 
-			if prevCuppingID != cuppingID {
-				cupping.cuppedCoffees = make([]cuppedCoffee, len(cuppedCoffees))
-				copy(cupping.cuppedCoffees, cuppedCoffees)
-				cuppings = append(cuppings, cupping)
+	return cuppings, nil
+}
 
-				cuppedCoffees = cuppedCoffees[:0]
-				prevCuppingID = cuppingID
-			}
+// fromDate and toDate are inclusive and in the format "YYYY-MM-DD".
+// An empty fromDate or toDate leaves that side of the range open.
+// The cursor key is the cupping date in UTC.
+func (s *SQLiteDB) getCuppingsByDateRange(ctx context.Context, fromDate string, toDate string, after *pageCursor, limit int) ([]cupping, error) {
+	var cuppings []cupping
+	if err := s.TransactContext(ctx, func(ctx context.Context, tx *sql.Tx) error {
+		afterCursor, args := afterCursorCondition(after, "date_utc", true, "id", true)
+		rows, err := tx.QueryContext(ctx, fmt.Sprintf(`
+			SELECT cu.id, cu.date, cu.duration_min, cu.notes, c.name, r.name, cc.rank, cc.notes
+			FROM cuppings AS cu
+			INNER JOIN cupped_coffees AS cc
+				ON cu.id = cc.cupping_id
+			INNER JOIN coffees AS c
+				ON c.id = cc.coffee_id
+			INNER JOIN roasters AS r
+				ON r.id = c.roaster_id
+			WHERE cu.id IN (
+				SELECT id
+				FROM cuppings
+				WHERE %s
+				AND (substr(date, 1, 10) >= :fromDate OR "" = :fromDate)
+				AND (substr(date, 1, 10) <= :toDate OR "" = :toDate)
+				AND (user_id = :userID OR 0 = :userID)
+				ORDER BY date_utc DESC, id DESC
+				LIMIT :limit
+			)
+			ORDER BY cu.date_utc DESC, cu.id DESC, cc.rank
+		`, afterCursor),
+			append(args,
+				sql.Named("fromDate", fromDate),
+				sql.Named("toDate", toDate),
+				sql.Named("limit", limit),
+				userArg(ctx),
+			)...,
+		)
+		if err != nil {
+			return fmt.Errorf("buna: sqlite_db_retrieve: failed to retrieve cupping rows: %w", err)
+		}
+		defer rows.Close()
 
-			cuppedCoffees = append(cuppedCoffees, coffee)
+		cuppings, err = scanCuppingRows(rows)
+		if err != nil {
+			return fmt.Errorf("buna: sqlite_db_retrieve: failed to scan cupping rows: %w", err)
 		}
-		cupping.cuppedCoffees = cuppedCoffees
-		cuppings = append(cuppings, cupping)
 
-		if err := rows.Err(); err != nil {
-			return fmt.Errorf("buna: sqlite_db_retrieve: failed to scan last row: %w", err)
+		return nil
+	}); err != nil {
+		return nil, fmt.Errorf("buna: sqlite_db_retrieve: getCuppingsByDateRange transaction failed: %w", err)
+	}
+
+	return cuppings, nil
+}
+
+// Returns the cuppings in which a coffee from the roaster was ranked first, ordered by last added.
+func (s *SQLiteDB) getCuppingsByWinningRoaster(ctx context.Context, roaster string, after *pageCursor, limit int) ([]cupping, error) {
+	var cuppings []cupping
+	if err := s.TransactContext(ctx, func(ctx context.Context, tx *sql.Tx) error {
+		afterCursor, args := afterCursorCondition(after, "", false, "icc.cupping_id", true)
+		rows, err := tx.QueryContext(ctx, fmt.Sprintf(`
+			SELECT cu.id, cu.date, cu.duration_min, cu.notes, c.name, r.name, cc.rank, cc.notes
+			FROM cuppings AS cu
+			INNER JOIN cupped_coffees AS cc
+				ON cu.id = cc.cupping_id
+			INNER JOIN coffees AS c
+				ON c.id = cc.coffee_id
+			INNER JOIN roasters AS r
+				ON r.id = c.roaster_id
+			WHERE cu.id IN (
+				SELECT icc.cupping_id
+				FROM cupped_coffees AS icc
+				INNER JOIN cuppings AS icu
+					ON icu.id = icc.cupping_id
+				INNER JOIN coffees AS ic
+					ON ic.id = icc.coffee_id
+				INNER JOIN roasters AS ir
+					ON ir.id = ic.roaster_id
+				WHERE %s
+				AND (icu.user_id = :userID OR 0 = :userID)
+				AND icc.rank = 1
+				AND ir.name = :roaster COLLATE NOCASE
+				ORDER BY icc.cupping_id DESC
+				LIMIT :limit
+			)
+			ORDER BY cu.id DESC, cc.rank
+		`, afterCursor),
+			append(args,
+				sql.Named("roaster", roaster),
+				sql.Named("limit", limit),
+				userArg(ctx),
+			)...,
+		)
+		if err != nil {
+			return fmt.Errorf("buna: sqlite_db_retrieve: failed to retrieve cupping rows: %w", err)
+		}
+		defer rows.Close()
+
+		cuppings, err = scanCuppingRows(rows)
+		if err != nil {
+			return fmt.Errorf("buna: sqlite_db_retrieve: failed to scan cupping rows: %w", err)
 		}
 
 		return nil
 	}); err != nil {
-		return nil, fmt.Errorf("buna: sqlite_db_retrieve: getCuppingsByLastAdded transaction failed: %w", err)
+		return nil, fmt.Errorf("buna: sqlite_db_retrieve: getCuppingsByWinningRoaster transaction failed: %w", err)
 	}
 
 	return cuppings, nil
 }
 
+// Groups the joined cupping and cupped coffee rows into cuppings.
+// Expects the rows to be ordered by cupping first and to contain the columns
+// cupping id, date, duration, notes, coffee name, coffee roaster, rank and coffee notes.
+func scanCuppingRows(rows *sql.Rows) ([]cupping, error) {
+	var cuppings []cupping
+	for rows.Next() {
+		var current cupping
+		var coffee cuppedCoffee
+		if err := rows.Scan(
+			&current.id,
+			&current.date,
+			&current.durationMin,
+			&current.notes,
+			&coffee.name,
+			&coffee.roaster,
+			&coffee.rank,
+			&coffee.notes,
+		); err != nil {
+			return nil, fmt.Errorf("buna: sqlite_db_retrieve: failed to scan cupping row: %w", err)
+		}
+
+		if last := len(cuppings) - 1; last >= 0 && cuppings[last].id == current.id {
+			cuppings[last].cuppedCoffees = append(cuppings[last].cuppedCoffees, coffee)
+			continue
+		}
+
+		current.cuppedCoffees = []cuppedCoffee{coffee}
+		cuppings = append(cuppings, current)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("buna: sqlite_db_retrieve: failed to scan last cupping row: %w", err)
+	}
+
+	return cuppings, nil
+}
+
+// Names are matched case-insensitively and ignoring surrounding whitespace. An exact match is preferred.
+// Returns sql.ErrNoRows if there is no such grinder, see getSimilarGrinderNames for close matches.
+// Returns sql.ErrNoRows, wrapped together with similar grinder names, if no grinder has the name.
 func (s *SQLiteDB) getGrinderIDByName(ctx context.Context, name string) (int, error) {
 	var grinderID int
 	if err := s.TransactContext(ctx, func(ctx context.Context, tx *sql.Tx) error {
-		if err := tx.QueryRowContext(ctx, `
-			SELECT id
-			FROM grinders
-			WHERE name = :grinderName
-		`,
-			sql.Named("grinderName", name),
-		).Scan(&grinderID); err != nil {
-			return fmt.Errorf("buna: sqlite_db_retrieve: failed to retrieve grinder id from db: %w", err)
+		var err error
+		grinderID, err = getIDByName(ctx, tx, "grinders", "grinder", name)
+		if err != nil {
+			return fmt.Errorf("buna: sqlite_db_retrieve: failed to get grinder id: %w", err)
 		}
 
 		return nil
@@ -503,16 +1274,18 @@ func (s *SQLiteDB) getGrinderIDByName(ctx context.Context, name string) (int, er
 	return grinderID, nil
 }
 
-func (s *SQLiteDB) getGrindersByLastAdded(ctx context.Context, limit int) ([]grinder, error) {
+func (s *SQLiteDB) getGrindersByLastAdded(ctx context.Context, after *pageCursor, limit int) ([]grinder, error) {
 	grinders := make([]grinder, 0, limit)
 	if err := s.TransactContext(ctx, func(ctx context.Context, tx *sql.Tx) error {
-		rows, err := tx.QueryContext(ctx, `
-			SELECT name, company, max_grind_setting
+		afterCursor, args := afterCursorCondition(after, "", false, "id", true)
+		rows, err := tx.QueryContext(ctx, fmt.Sprintf(`
+			SELECT id, name, company, max_grind_setting
 			FROM grinders
+			WHERE %s
 			ORDER BY id DESC
 			LIMIT :limit
-		`,
-			sql.Named("limit", limit),
+		`, afterCursor),
+			append(args, sql.Named("limit", limit))...,
 		)
 		if err != nil {
 			return fmt.Errorf("buna: sqlite_db_retrieve: failed to retrieve brewing method rows: %w", err)
@@ -522,7 +1295,7 @@ func (s *SQLiteDB) getGrindersByLastAdded(ctx context.Context, limit int) ([]gri
 		for rows.Next() {
 			var grinder grinder
 			var company, maxGrindSetting interface{}
-			if err := rows.Scan(&grinder.name, &company, &maxGrindSetting); err != nil {
+			if err := rows.Scan(&grinder.id, &grinder.name, &company, &maxGrindSetting); err != nil {
 				return fmt.Errorf("buna: sqlite_db_retrieve: failed to scan row: %w", err)
 			}
 
@@ -553,17 +1326,16 @@ func (s *SQLiteDB) getGrindersByLastAdded(ctx context.Context, limit int) ([]gri
 	return grinders, nil
 }
 
+// Names are matched case-insensitively and ignoring surrounding whitespace. An exact match is preferred.
+// Returns sql.ErrNoRows if there is no such brewing method, see getSimilarBrewingMethodNames for close matches.
+// Returns sql.ErrNoRows, wrapped together with similar brewing method names, if no brewing method has the name.
 func (s *SQLiteDB) getMethodIDByName(ctx context.Context, name string) (int, error) {
 	var methodID int
 	if err := s.TransactContext(ctx, func(ctx context.Context, tx *sql.Tx) error {
-		if err := tx.QueryRowContext(ctx, `
-			SELECT id
-			FROM brewing_methods
-			WHERE name = :brewingMethodName
-		`,
-			sql.Named("brewingMethodName", name),
-		).Scan(&methodID); err != nil {
-			return fmt.Errorf("buna: sqlite_db_retrieve: failed to retrieve method id from db: %w", err)
+		var err error
+		methodID, err = getIDByName(ctx, tx, "brewing_methods", "brewing method", name)
+		if err != nil {
+			return fmt.Errorf("buna: sqlite_db_retrieve: failed to get method id: %w", err)
 		}
 
 		return nil
@@ -573,3 +1345,418 @@ func (s *SQLiteDB) getMethodIDByName(ctx context.Context, name string) (int, err
 
 	return methodID, nil
 }
+
+func (s *SQLiteDB) getRoasterByName(ctx context.Context, name string) (roaster, error) {
+	var r roaster
+	if err := s.TransactContext(ctx, func(ctx context.Context, tx *sql.Tx) error {
+		var country, city, website, notes interface{}
+		if err := tx.QueryRowContext(ctx, `
+			SELECT name, country, city, website, notes
+			FROM roasters
+			WHERE name = :name COLLATE NOCASE
+		`,
+			sql.Named("name", strings.TrimSpace(name)),
+		).Scan(&r.name, &country, &city, &website, &notes); err != nil {
+			return fmt.Errorf("buna: sqlite_db_retrieve: failed to retrieve roaster from db: %w", err)
+		}
+
+		// Deal with possible NULL values
+		r.country = nullableStringOr(country, "Unknown")
+		r.city = nullableStringOr(city, "Unknown")
+		r.website = nullableStringOr(website, "Unknown")
+		r.notes = nullableStringOr(notes, "None")
+
+		return nil
+	}); err != nil {
+		return roaster{}, fmt.Errorf("buna: sqlite_db_retrieve: getRoasterByName transaction failed: %w", err)
+	}
+
+	return r, nil
+}
+
+// Returns pairs of roaster names that are similar enough to possibly be the same roaster.
+// Pairs that the user previously decided to keep separate are excluded.
+func (s *SQLiteDB) getRoasterMergeCandidates(ctx context.Context) ([][2]string, error) {
+	var candidates [][2]string
+	if err := s.TransactContext(ctx, func(ctx context.Context, tx *sql.Tx) error {
+		rows, err := tx.QueryContext(ctx, `
+			SELECT id, name
+			FROM roasters
+			ORDER BY id
+		`)
+		if err != nil {
+			return fmt.Errorf("buna: sqlite_db_retrieve: failed to retrieve roaster rows: %w", err)
+		}
+		defer rows.Close()
+
+		var ids []int
+		var names []string
+		for rows.Next() {
+			var id int
+			var name string
+			if err := rows.Scan(&id, &name); err != nil {
+				return fmt.Errorf("buna: sqlite_db_retrieve: failed to scan row: %w", err)
+			}
+
+			ids = append(ids, id)
+			names = append(names, name)
+		}
+
+		if err := rows.Err(); err != nil {
+			return fmt.Errorf("buna: sqlite_db_retrieve: failed to scan last row: %w", err)
+		}
+
+		dRows, err := tx.QueryContext(ctx, `
+			SELECT roaster_id, other_roaster_id
+			FROM roaster_merge_dismissals
+		`)
+		if err != nil {
+			return fmt.Errorf("buna: sqlite_db_retrieve: failed to retrieve roaster merge dismissal rows: %w", err)
+		}
+		defer dRows.Close()
+
+		dismissed := make(map[[2]int]bool)
+		for dRows.Next() {
+			var id, otherID int
+			if err := dRows.Scan(&id, &otherID); err != nil {
+				return fmt.Errorf("buna: sqlite_db_retrieve: failed to scan dRow: %w", err)
+			}
+
+			dismissed[[2]int{id, otherID}] = true
+			dismissed[[2]int{otherID, id}] = true
+		}
+
+		if err := dRows.Err(); err != nil {
+			return fmt.Errorf("buna: sqlite_db_retrieve: failed to scan last dRow: %w", err)
+		}
+
+		for i := range names {
+			for j := i + 1; j < len(names); j++ {
+				if dismissed[[2]int{ids[i], ids[j]}] {
+					continue
+				}
+
+				if isSimilarRoasterName(names[i], names[j]) {
+					candidates = append(candidates, [2]string{names[i], names[j]})
+				}
+			}
+		}
+
+		return nil
+	}); err != nil {
+		return nil, fmt.Errorf("buna: sqlite_db_retrieve: getRoasterMergeCandidates transaction failed: %w", err)
+	}
+
+	return candidates, nil
+}
+
+func (s *SQLiteDB) getRoastersByLastAdded(ctx context.Context, after *pageCursor, limit int) ([]roaster, error) {
+	afterCursor, args := afterCursorCondition(after, "", false, "id", true)
+	roasters, err := s.getRoastersWhere(ctx, afterCursor, "id DESC", limit, args...)
+	if err != nil {
+		return nil, fmt.Errorf("buna: sqlite_db_retrieve: failed to get roasters ordered by id: %w", err)
+	}
+
+	return roasters, nil
+}
+
+// The cursor key is the roaster name.
+func (s *SQLiteDB) getRoastersAlphabetically(ctx context.Context, after *pageCursor, limit int) ([]roaster, error) {
+	afterCursor, args := afterCursorCondition(after, "name COLLATE NOCASE", false, "id", false)
+	roasters, err := s.getRoastersWhere(ctx, afterCursor, "name COLLATE NOCASE, id", limit, args...)
+	if err != nil {
+		return nil, fmt.Errorf("buna: sqlite_db_retrieve: failed to get roasters ordered by name: %w", err)
+	}
+
+	return roasters, nil
+}
+
+// where and orderBy are inserted into the query as is and must not contain user input.
+// User input must be passed using named args instead.
+func (s *SQLiteDB) getRoastersWhere(ctx context.Context, where string, orderBy string, limit int, args ...interface{}) ([]roaster, error) {
+	roasters := make([]roaster, 0, limit)
+	if err := s.TransactContext(ctx, func(ctx context.Context, tx *sql.Tx) error {
+		args = append(args, sql.Named("limit", limit))
+		rows, err := tx.QueryContext(ctx, fmt.Sprintf(`
+			SELECT id, name, country, city, website, notes
+			FROM roasters
+			WHERE %s
+			ORDER BY %s
+			LIMIT :limit
+		`, where, orderBy),
+			args...,
+		)
+		if err != nil {
+			return fmt.Errorf("buna: sqlite_db_retrieve: failed to retrieve roaster rows: %w", err)
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var r roaster
+			var country, city, website, notes interface{}
+			if err := rows.Scan(&r.id, &r.name, &country, &city, &website, &notes); err != nil {
+				return fmt.Errorf("buna: sqlite_db_retrieve: failed to scan row: %w", err)
+			}
+
+			// Deal with possible NULL values
+			r.country = nullableStringOr(country, "Unknown")
+			r.city = nullableStringOr(city, "Unknown")
+			r.website = nullableStringOr(website, "Unknown")
+			r.notes = nullableStringOr(notes, "None")
+
+			roasters = append(roasters, r)
+		}
+
+		if err := rows.Err(); err != nil {
+			return fmt.Errorf("buna: sqlite_db_retrieve: failed to scan last row: %w", err)
+		}
+
+		return nil
+	}); err != nil {
+		return nil, fmt.Errorf("buna: sqlite_db_retrieve: getRoastersWhere transaction failed: %w", err)
+	}
+
+	return roasters, nil
+}
+
+// Returns a condition that selects the rows after the cursor in a listing ordered by keyExpr and then by idExpr,
+// and the named args used by the condition. Every row is after a nil cursor.
+// keyExpr is empty if the listing is only ordered by id.
+// keyExpr and idExpr are inserted into the condition as is and must not contain user input.
+func afterCursorCondition(after *pageCursor, keyExpr string, keyDescending bool, idExpr string, idDescending bool) (string, []interface{}) {
+	if after == nil {
+		return "1", nil
+	}
+
+	keyOp, idOp := ">", ">"
+	if keyDescending {
+		keyOp = "<"
+	}
+	if idDescending {
+		idOp = "<"
+	}
+
+	args := []interface{}{sql.Named("cursorID", after.id)}
+	if keyExpr == "" {
+		return fmt.Sprintf("%s %s :cursorID", idExpr, idOp), args
+	}
+
+	condition := fmt.Sprintf("(%[1]s %[2]s :cursorKey OR (%[1]s = :cursorKey AND %[3]s %[4]s :cursorID))", keyExpr, keyOp, idExpr, idOp)
+	return condition, append(args, sql.Named("cursorKey", after.key))
+}
+
+// Returns val if it is a string and fallback if it is NULL.
+func nullableStringOr(val interface{}, fallback string) string {
+	if v := reflect.ValueOf(val); v.Kind() == reflect.String {
+		return val.(string)
+	}
+	return fallback
+}
+
+// Returns val if it is an integer and fallback if it is NULL.
+func nullableIntOr(val interface{}, fallback int) int {
+	if v := reflect.ValueOf(val); v.Kind() == reflect.Int64 {
+		return int(val.(int64))
+	}
+	return fallback
+}
+
+// Escapes the wildcards of LIKE in s, so that s is matched literally by LIKE ... ESCAPE "\".
+func escapeLike(s string) string {
+	return likeEscaper.Replace(s)
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// Returns val if it is a float and fallback if it is NULL.
+func nullableFloatOr(val interface{}, fallback float64) float64 {
+	if v := reflect.ValueOf(val); v.Kind() == reflect.Float64 {
+		return val.(float64)
+	}
+	return fallback
+}
+
+// Notes are only indexed if SQLite is compiled with FTS5 (build tag sqlite_fts5).
+func (s *SQLiteDB) getNoteSearchAvailable(ctx context.Context) (bool, error) {
+	var available bool
+	if err := s.db.QueryRowContext(ctx, `SELECT sqlite_compileoption_used("ENABLE_FTS5")`).Scan(&available); err != nil {
+		return false, fmt.Errorf("buna: sqlite_db_retrieve: failed to check for FTS5: %w", err)
+	}
+
+	return available, nil
+}
+
+// query must use the FTS5 query syntax.
+// Hits are ordered by relevance and matched terms in the snippets are wrapped in highlightStart and highlightEnd.
+func (s *SQLiteDB) getNoteSearchHits(ctx context.Context, query string, highlightStart string, highlightEnd string, limit int) ([]noteSearchHit, error) {
+	hits := make([]noteSearchHit, 0, limit)
+	if err := s.TransactContext(ctx, func(ctx context.Context, tx *sql.Tx) error {
+		rows, err := tx.QueryContext(ctx, `
+			SELECT 	n.source,
+					n.source_id,
+					substr(coalesce(b.date, cu.date, ccu.date), 1, 10),
+					coalesce(bc.name, ccc.name),
+					coalesce(br.name, ccr.name),
+					snippet(notes_fts, 0, :highlightStart, :highlightEnd, "...", 12)
+			FROM notes_fts AS n
+			LEFT JOIN brewings AS b
+				ON n.source = "brewing" AND b.id = n.source_id
+			LEFT JOIN coffees AS bc
+				ON bc.id = b.coffee_id
+			LEFT JOIN roasters AS br
+				ON br.id = bc.roaster_id
+			LEFT JOIN cuppings AS cu
+				ON n.source = "cupping" AND cu.id = n.source_id
+			LEFT JOIN cupped_coffees AS cc
+				ON n.source = "cupped_coffee" AND cc.cupping_id = n.source_id AND cc.coffee_id = n.coffee_id
+			LEFT JOIN cuppings AS ccu
+				ON ccu.id = cc.cupping_id
+			LEFT JOIN coffees AS ccc
+				ON ccc.id = cc.coffee_id
+			LEFT JOIN roasters AS ccr
+				ON ccr.id = ccc.roaster_id
+			WHERE notes_fts MATCH :query
+			AND (coalesce(b.user_id, cu.user_id, ccu.user_id) = :userID OR 0 = :userID)
+			ORDER BY n.rank
+			LIMIT :limit
+		`,
+			sql.Named("query", query),
+			sql.Named("highlightStart", highlightStart),
+			sql.Named("highlightEnd", highlightEnd),
+			sql.Named("limit", limit),
+			userArg(ctx),
+		)
+		if err != nil {
+			return fmt.Errorf("buna: sqlite_db_retrieve: failed to retrieve note search hit rows: %w", err)
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var hit noteSearchHit
+			var date, coffeeName, coffeeRoaster interface{}
+			if err := rows.Scan(
+				&hit.source,
+				&hit.parentID,
+				&date,
+				&coffeeName,
+				&coffeeRoaster,
+				&hit.snippet,
+			); err != nil {
+				return fmt.Errorf("buna: sqlite_db_retrieve: failed to scan row: %w", err)
+			}
+
+			// Deal with possible NULL values
+			hit.date = nullableStringOr(date, "")
+			hit.coffeeName = nullableStringOr(coffeeName, "")
+			hit.coffeeRoaster = nullableStringOr(coffeeRoaster, "")
+
+			hits = append(hits, hit)
+		}
+
+		if err := rows.Err(); err != nil {
+			return fmt.Errorf("buna: sqlite_db_retrieve: failed to scan last row: %w", err)
+		}
+
+		return nil
+	}); err != nil {
+		return nil, fmt.Errorf("buna: sqlite_db_retrieve: getNoteSearchHits transaction failed: %w", err)
+	}
+
+	return hits, nil
+}
+
+// Returns the drafts of the flow, or of all flows if flow is empty, with the last edited first.
+func (s *SQLiteDB) getDrafts(ctx context.Context, flow string) ([]draft, error) {
+	var drafts []draft
+	if err := s.TransactContext(ctx, func(ctx context.Context, tx *sql.Tx) error {
+		rows, err := tx.QueryContext(ctx, `
+			SELECT id, flow, answers, created_at, updated_at
+			FROM drafts
+			WHERE (flow = :flow OR "" = :flow)
+			AND (user_id = :userID OR 0 = :userID)
+			ORDER BY updated_at DESC, id DESC
+		`,
+			sql.Named("flow", flow),
+			userArg(ctx),
+		)
+		if err != nil {
+			return fmt.Errorf("buna: sqlite_db_retrieve: failed to retrieve draft rows: %w", err)
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var d draft
+			if err := rows.Scan(&d.id, &d.flow, &d.answers, &d.createdAt, &d.updatedAt); err != nil {
+				return fmt.Errorf("buna: sqlite_db_retrieve: failed to scan row: %w", err)
+			}
+
+			drafts = append(drafts, d)
+		}
+
+		if err := rows.Err(); err != nil {
+			return fmt.Errorf("buna: sqlite_db_retrieve: failed to scan last row: %w", err)
+		}
+
+		return nil
+	}); err != nil {
+		return nil, fmt.Errorf("buna: sqlite_db_retrieve: getDrafts transaction failed: %w", err)
+	}
+
+	return drafts, nil
+}
+
+// Returns all users ordered by name.
+func (s *SQLiteDB) getUsers(ctx context.Context) ([]user, error) {
+	var users []user
+	if err := s.TransactContext(ctx, func(ctx context.Context, tx *sql.Tx) error {
+		rows, err := tx.QueryContext(ctx, `
+			SELECT id, name
+			FROM users
+			ORDER BY name COLLATE NOCASE
+		`)
+		if err != nil {
+			return fmt.Errorf("buna: sqlite_db_retrieve: failed to retrieve user rows: %w", err)
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var u user
+			if err := rows.Scan(&u.id, &u.name); err != nil {
+				return fmt.Errorf("buna: sqlite_db_retrieve: failed to scan user row: %w", err)
+			}
+
+			users = append(users, u)
+		}
+		if err := rows.Err(); err != nil {
+			return fmt.Errorf("buna: sqlite_db_retrieve: failed to iterate user rows: %w", err)
+		}
+
+		return nil
+	}); err != nil {
+		return nil, fmt.Errorf("buna: sqlite_db_retrieve: getUsers transaction failed: %w", err)
+	}
+
+	return users, nil
+}
+
+// Returns sql.ErrNoRows, wrapped, if no user has the name.
+func (s *SQLiteDB) getUserByName(ctx context.Context, name string) (user, error) {
+	var u user
+	if err := s.TransactContext(ctx, func(ctx context.Context, tx *sql.Tx) error {
+		if err := tx.QueryRowContext(ctx, `
+			SELECT id, name
+			FROM users
+			WHERE name = :name COLLATE NOCASE
+		`,
+			sql.Named("name", strings.TrimSpace(name)),
+		).Scan(&u.id, &u.name); err != nil {
+			return fmt.Errorf("buna: sqlite_db_retrieve: failed to retrieve user from db: %w", err)
+		}
+
+		return nil
+	}); err != nil {
+		return user{}, fmt.Errorf("buna: sqlite_db_retrieve: getUserByName transaction failed: %w", err)
+	}
+
+	return u, nil
+}
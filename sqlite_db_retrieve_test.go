@@ -0,0 +1,64 @@
+package buna
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+func TestGetCoffeesByNameMatchesLiterally(t *testing.T) {
+	ctx := context.Background()
+
+	dir, err := ioutil.TempDir("", "buna-retrieve")
+	if err != nil {
+		t.Fatalf("failed to create temporary directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	s, err := OpenSQLiteDB(ctx, zap.NewNop(), filepath.Join(dir, "buna.db"))
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	defer s.Close()
+
+	if err := s.insertRoaster(ctx, roaster{name: "Square Mile"}); err != nil {
+		t.Fatalf("insertRoaster() error = %v", err)
+	}
+	for _, name := range []string{"100% Kiambu", "1000 Kiambu", "Red_Bourbon", "Red Bourbon", `Back\slash`} {
+		if err := s.insertCoffee(ctx, coffee{name: name, roaster: "Square Mile"}); err != nil {
+			t.Fatalf("insertCoffee(%q) error = %v", name, err)
+		}
+	}
+
+	tests := []struct {
+		name string
+		want []string
+	}{
+		{"100%", []string{"100% Kiambu"}},
+		{"red_", []string{"Red_Bourbon"}},
+		{`k\s`, []string{`Back\slash`}},
+		{"bourbon", []string{"Red Bourbon", "Red_Bourbon"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			coffees, err := s.getCoffeesByName(ctx, tt.name, nil, 10)
+			if err != nil {
+				t.Fatalf("getCoffeesByName() error = %v", err)
+			}
+
+			var names []string
+			for _, c := range coffees {
+				names = append(names, c.name)
+			}
+			if !reflect.DeepEqual(names, tt.want) {
+				t.Errorf("getCoffeesByName(%q) = %v, want %v", tt.name, names, tt.want)
+			}
+		})
+	}
+}
@@ -17,6 +17,8 @@ const (
 	coffeePurchases
 	cuppings
 	grinders
+	roasters
+	users
 )
 
 var (
@@ -27,6 +29,8 @@ var (
 		coffeePurchases: "purchases",
 		cuppings:        "cuppings",
 		grinders:        "grinders",
+		roasters:        "roasters",
+		users:           "users",
 	}
 
 	dbEntityToName = map[dbEntity]string{
@@ -36,20 +40,49 @@ var (
 		coffeePurchases: "coffee purchases",
 		cuppings:        "cuppings",
 		grinders:        "grinders",
+		roasters:        "roasters",
+		users:           "users",
+	}
+
+	// Entities that belong to the user who added them and are only counted for the current user
+	userDBEntities = map[dbEntity]bool{
+		brewings:        true,
+		coffeePurchases: true,
+		cuppings:        true,
+	}
+
+	// SQL expressions for the first day of the period a brewing is in
+	trendPeriodToSQL = map[trendPeriod]string{
+		weekly:  `date(substr(b.date, 1, 10), "weekday 0", "-6 days")`,
+		monthly: `date(substr(b.date, 1, 10), "start of month")`,
 	}
 )
 
+// Only brewings in the ratio band are averaged.
 // The following fields are used from the brewingFilter argument:
 // brewingMethodName, v60FilterType, coffeeName, coffeeRoaster, grinderName
-func (s *SQLiteDB) getAverageBrewingRating(ctx context.Context, brewingFilter brewing) (float64, error) {
-	var averageBrewingRatingFloat float64
+func (s *SQLiteDB) getAverageBrewingScores(ctx context.Context, brewingFilter brewing, band ratioBand) (brewingScoreAverages, error) {
+	var averages brewingScoreAverages
 	if err := s.TransactContext(ctx, func(ctx context.Context, tx *sql.Tx) error {
-		var averageBrewingRating interface{}
+		var rating, sweetness, acidity, bitterness, body, clarity, astringency interface{}
+		var underCount, balancedCount, overCount int
 		if err := tx.QueryRowContext(ctx, `
-			SELECT avg(b.rating)
+			SELECT 	count(*),
+					avg(b.rating),
+					avg(b.sweetness),
+					avg(b.acidity),
+					avg(b.bitterness),
+					avg(b.body),
+					avg(b.clarity),
+					avg(b.astringency),
+					count(CASE b.extraction WHEN "under" THEN 1 END),
+					count(CASE b.extraction WHEN "balanced" THEN 1 END),
+					count(CASE b.extraction WHEN "over" THEN 1 END)
 			FROM brewings AS b
 			INNER JOIN coffees AS c
 				ON c.id = b.coffee_id
+			INNER JOIN roasters AS r
+				ON r.id = c.roaster_id
 			INNER JOIN brewing_methods AS m
 				ON m.id = b.method_id
 			INNER JOIN grinders AS g
@@ -57,31 +90,346 @@ func (s *SQLiteDB) getAverageBrewingRating(ctx context.Context, brewingFilter br
 			WHERE (m.name = :brewingMethodName OR "" = :brewingMethodName)
 			AND (b.v60_filter_type = :v60FilterType OR "" = :v60FilterType)
 			AND (c.name = :coffeeName OR "" = :coffeeName)
-			AND (c.roaster = :coffeeRoaster OR "" = :coffeeRoaster)
+			AND (r.name = :coffeeRoaster COLLATE NOCASE OR "" = :coffeeRoaster)
 			AND (g.name = :grinderName OR "" = :grinderName)
+			AND (b.user_id = :userID OR 0 = :userID)
+			AND (b.water_grams / NULLIF(b.coffee_grams, 0) >= :minRatio OR 0 = :minRatio)
+			AND (b.water_grams / NULLIF(b.coffee_grams, 0) < :maxRatio OR 0 = :maxRatio)
 		`,
 			sql.Named("brewingMethodName", brewingFilter.brewingMethodName),
 			sql.Named("v60FilterType", brewingFilter.v60FilterType),
 			sql.Named("coffeeName", brewingFilter.coffeeName),
 			sql.Named("coffeeRoaster", brewingFilter.coffeeRoaster),
 			sql.Named("grinderName", brewingFilter.grinderName),
-		).Scan(&averageBrewingRating); err != nil {
-			return fmt.Errorf("buna: sqlite_db_statistics: failed to retrieve average brewing rating from db: %w", err)
+			sql.Named("minRatio", band.min),
+			sql.Named("maxRatio", band.max),
+			userArg(ctx),
+		).Scan(
+			&averages.brewingCount,
+			&rating,
+			&sweetness,
+			&acidity,
+			&bitterness,
+			&body,
+			&clarity,
+			&astringency,
+			&underCount,
+			&balancedCount,
+			&overCount,
+		); err != nil {
+			return fmt.Errorf("buna: sqlite_db_statistics: failed to retrieve average brewing scores from db: %w", err)
+		}
+
+		// Averages are NULL if no brewing has the score
+		averages.rating = nullableFloatOr(rating, 0)
+		averages.sweetness = nullableFloatOr(sweetness, 0)
+		averages.acidity = nullableFloatOr(acidity, 0)
+		averages.bitterness = nullableFloatOr(bitterness, 0)
+		averages.body = nullableFloatOr(body, 0)
+		averages.clarity = nullableFloatOr(clarity, 0)
+		averages.astringency = nullableFloatOr(astringency, 0)
+		averages.extractionCounts = map[string]int{
+			underExtracted:    underCount,
+			balancedExtracted: balancedCount,
+			overExtracted:     overCount,
+		}
+
+		return nil
+	}); err != nil {
+		return brewingScoreAverages{}, fmt.Errorf("buna: sqlite_db_statistics: getAverageBrewingScores transaction failed: %w", err)
+	}
+
+	return averages, nil
+}
+
+// Returns the number of brewings, the average rating and the average brew ratio of every brewing method in every period,
+// ordered by period. Periods without brewings are left out.
+// Only brewings in the ratio band are included.
+// The following fields are used from the brewingFilter argument:
+// brewingMethodName, v60FilterType, coffeeName, coffeeRoaster, grinderName
+func (s *SQLiteDB) getBrewingTrends(ctx context.Context, brewingFilter brewing, band ratioBand, fromDate string, toDate string, period trendPeriod) ([]brewingTrend, error) {
+	periodStart, ok := trendPeriodToSQL[period]
+	if !ok {
+		return nil, fmt.Errorf("buna: sqlite_db_statistics: unable to map trendPeriod to sql")
+	}
+
+	var trends []brewingTrend
+	if err := s.TransactContext(ctx, func(ctx context.Context, tx *sql.Tx) error {
+		query := `
+			SELECT 	? AS period_start,
+					m.name,
+					count(*),
+					count(b.rating),
+					avg(b.rating),
+					avg(CASE WHEN b.coffee_grams > 0 THEN b.water_grams / b.coffee_grams END)
+			FROM brewings AS b
+			INNER JOIN coffees AS c
+				ON c.id = b.coffee_id
+			INNER JOIN roasters AS r
+				ON r.id = c.roaster_id
+			INNER JOIN brewing_methods AS m
+				ON m.id = b.method_id
+			INNER JOIN grinders AS g
+				ON g.id = b.grinder_id
+			WHERE (m.name = :brewingMethodName OR "" = :brewingMethodName)
+			AND (b.v60_filter_type = :v60FilterType OR "" = :v60FilterType)
+			AND (c.name = :coffeeName OR "" = :coffeeName)
+			AND (r.name = :coffeeRoaster COLLATE NOCASE OR "" = :coffeeRoaster)
+			AND (g.name = :grinderName OR "" = :grinderName)
+			AND (b.user_id = :userID OR 0 = :userID)
+			AND (substr(b.date, 1, 10) >= :fromDate OR "" = :fromDate)
+			AND (substr(b.date, 1, 10) <= :toDate OR "" = :toDate)
+			AND (b.water_grams / NULLIF(b.coffee_grams, 0) >= :minRatio OR 0 = :minRatio)
+			AND (b.water_grams / NULLIF(b.coffee_grams, 0) < :maxRatio OR 0 = :maxRatio)
+			GROUP BY period_start, m.name
+			HAVING period_start IS NOT NULL
+			ORDER BY period_start, m.name
+		`
+		query = strings.Replace(query, "?", periodStart, 1)
+		rows, err := tx.QueryContext(ctx, query,
+			sql.Named("brewingMethodName", brewingFilter.brewingMethodName),
+			sql.Named("v60FilterType", brewingFilter.v60FilterType),
+			sql.Named("coffeeName", brewingFilter.coffeeName),
+			sql.Named("coffeeRoaster", brewingFilter.coffeeRoaster),
+			sql.Named("grinderName", brewingFilter.grinderName),
+			sql.Named("fromDate", fromDate),
+			sql.Named("toDate", toDate),
+			sql.Named("minRatio", band.min),
+			sql.Named("maxRatio", band.max),
+			userArg(ctx),
+		)
+		if err != nil {
+			return fmt.Errorf("buna: sqlite_db_statistics: failed to retrieve brewing trend rows: %w", err)
 		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var trend brewingTrend
+			var averageRating, averageRatio interface{}
+			if err := rows.Scan(
+				&trend.periodStart,
+				&trend.brewingMethodName,
+				&trend.brewingCount,
+				&trend.ratedCount,
+				&averageRating,
+				&averageRatio,
+			); err != nil {
+				return fmt.Errorf("buna: sqlite_db_statistics: failed to scan brewing trend row: %w", err)
+			}
 
-		if v := reflect.ValueOf(averageBrewingRating); v.Kind() == reflect.Float64 {
-			averageBrewingRatingFloat = averageBrewingRating.(float64)
-		} else {
-			// no brewings exist
-			return nil
+			// Averages are NULL if no brewing in the period has a rating or weights
+			trend.averageRating = nullableFloatOr(averageRating, 0)
+			trend.averageRatio = nullableFloatOr(averageRatio, 0)
+
+			trends = append(trends, trend)
+		}
+		if err := rows.Err(); err != nil {
+			return fmt.Errorf("buna: sqlite_db_statistics: failed to iterate brewing trend rows: %w", err)
 		}
 
 		return nil
 	}); err != nil {
-		return 0, fmt.Errorf("buna: sqlite_db_statistics: getAverageBrewingRating transaction failed: %w", err)
+		return nil, fmt.Errorf("buna: sqlite_db_statistics: getBrewingTrends transaction failed: %w", err)
 	}
 
-	return averageBrewingRatingFloat, nil
+	return trends, nil
+}
+
+// Returns the number of brewings and the average rating of every time of day, ordered by time of day.
+// Times of day without brewings and brewings whose time is unknown are left out.
+// Only brewings in the ratio band are included.
+// The following fields are used from the brewingFilter argument:
+// brewingMethodName, v60FilterType, coffeeName, coffeeRoaster, grinderName
+func (s *SQLiteDB) getTimeOfDayStatistics(ctx context.Context, brewingFilter brewing, band ratioBand) ([]brewingTimeStatistics, error) {
+	statistics, indexes, err := s.getBrewingTimeStatisticsGroupedBy(ctx, brewingFilter, band, timeOfDayIndexSQL())
+	if err != nil {
+		return nil, fmt.Errorf("buna: sqlite_db_statistics: failed to get brewing statistics by time of day: %w", err)
+	}
+
+	for i, index := range indexes {
+		statistics[i].name = timesOfDay[index].name
+	}
+
+	return statistics, nil
+}
+
+// Returns the number of brewings and the average rating of every weekday, ordered from Monday to Sunday.
+// Weekdays without brewings are left out.
+// Only brewings in the ratio band are included.
+// The following fields are used from the brewingFilter argument:
+// brewingMethodName, v60FilterType, coffeeName, coffeeRoaster, grinderName
+func (s *SQLiteDB) getWeekdayStatistics(ctx context.Context, brewingFilter brewing, band ratioBand) ([]brewingTimeStatistics, error) {
+	statistics, indexes, err := s.getBrewingTimeStatisticsGroupedBy(ctx, brewingFilter, band, weekdayIndexSQL())
+	if err != nil {
+		return nil, fmt.Errorf("buna: sqlite_db_statistics: failed to get brewing statistics by weekday: %w", err)
+	}
+
+	for i, index := range indexes {
+		statistics[i].name = weekdayIndexName(index)
+	}
+
+	return statistics, nil
+}
+
+// Returns the statistics of every value of the brewing expression groupExpr that is not NULL and the values, ordered by value.
+// groupExpr is inserted into the query as is and must not contain user input.
+func (s *SQLiteDB) getBrewingTimeStatisticsGroupedBy(ctx context.Context, brewingFilter brewing, band ratioBand, groupExpr string) ([]brewingTimeStatistics, []int, error) {
+	var statistics []brewingTimeStatistics
+	var indexes []int
+	if err := s.TransactContext(ctx, func(ctx context.Context, tx *sql.Tx) error {
+		rows, err := tx.QueryContext(ctx, fmt.Sprintf(`
+			SELECT 	%s AS grp,
+					count(*),
+					count(b.rating),
+					avg(b.rating)
+			FROM brewings AS b
+			INNER JOIN coffees AS c
+				ON c.id = b.coffee_id
+			INNER JOIN roasters AS r
+				ON r.id = c.roaster_id
+			INNER JOIN brewing_methods AS m
+				ON m.id = b.method_id
+			INNER JOIN grinders AS g
+				ON g.id = b.grinder_id
+			WHERE (m.name = :brewingMethodName OR "" = :brewingMethodName)
+			AND (b.v60_filter_type = :v60FilterType OR "" = :v60FilterType)
+			AND (c.name = :coffeeName OR "" = :coffeeName)
+			AND (r.name = :coffeeRoaster COLLATE NOCASE OR "" = :coffeeRoaster)
+			AND (g.name = :grinderName OR "" = :grinderName)
+			AND (b.user_id = :userID OR 0 = :userID)
+			AND (b.water_grams / NULLIF(b.coffee_grams, 0) >= :minRatio OR 0 = :minRatio)
+			AND (b.water_grams / NULLIF(b.coffee_grams, 0) < :maxRatio OR 0 = :maxRatio)
+			GROUP BY grp
+			HAVING grp IS NOT NULL
+			ORDER BY grp
+		`, groupExpr),
+			sql.Named("brewingMethodName", brewingFilter.brewingMethodName),
+			sql.Named("v60FilterType", brewingFilter.v60FilterType),
+			sql.Named("coffeeName", brewingFilter.coffeeName),
+			sql.Named("coffeeRoaster", brewingFilter.coffeeRoaster),
+			sql.Named("grinderName", brewingFilter.grinderName),
+			sql.Named("minRatio", band.min),
+			sql.Named("maxRatio", band.max),
+			userArg(ctx),
+		)
+		if err != nil {
+			return fmt.Errorf("buna: sqlite_db_statistics: failed to retrieve brewing time statistic rows: %w", err)
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var stats brewingTimeStatistics
+			var index int
+			var averageRating interface{}
+			if err := rows.Scan(&index, &stats.brewingCount, &stats.ratedCount, &averageRating); err != nil {
+				return fmt.Errorf("buna: sqlite_db_statistics: failed to scan brewing time statistic row: %w", err)
+			}
+
+			// The average rating is NULL if no brewing in the group has a rating
+			stats.averageRating = nullableFloatOr(averageRating, 0)
+
+			statistics = append(statistics, stats)
+			indexes = append(indexes, index)
+		}
+		if err := rows.Err(); err != nil {
+			return fmt.Errorf("buna: sqlite_db_statistics: failed to iterate brewing time statistic rows: %w", err)
+		}
+
+		return nil
+	}); err != nil {
+		return nil, nil, fmt.Errorf("buna: sqlite_db_statistics: getBrewingTimeStatisticsGroupedBy transaction failed: %w", err)
+	}
+
+	return statistics, indexes, nil
+}
+
+// Returns the number of brewings, the average rating and the extraction verdict counts of every ratio band,
+// ordered from the strongest to the weakest band. Bands without brewings are left out.
+// The following fields are used from the brewingFilter argument:
+// brewingMethodName, v60FilterType, coffeeName, coffeeRoaster, grinderName
+func (s *SQLiteDB) getRatioBandStatistics(ctx context.Context, brewingFilter brewing) ([]ratioBandStatistics, error) {
+	var statistics []ratioBandStatistics
+	if err := s.TransactContext(ctx, func(ctx context.Context, tx *sql.Tx) error {
+		query := `
+			SELECT 	? AS band_index,
+					count(*),
+					count(b.rating),
+					avg(b.rating),
+					avg(b.water_grams / b.coffee_grams),
+					count(CASE b.extraction WHEN "under" THEN 1 END),
+					count(CASE b.extraction WHEN "balanced" THEN 1 END),
+					count(CASE b.extraction WHEN "over" THEN 1 END)
+			FROM brewings AS b
+			INNER JOIN coffees AS c
+				ON c.id = b.coffee_id
+			INNER JOIN roasters AS r
+				ON r.id = c.roaster_id
+			INNER JOIN brewing_methods AS m
+				ON m.id = b.method_id
+			INNER JOIN grinders AS g
+				ON g.id = b.grinder_id
+			WHERE (m.name = :brewingMethodName OR "" = :brewingMethodName)
+			AND (b.v60_filter_type = :v60FilterType OR "" = :v60FilterType)
+			AND (c.name = :coffeeName OR "" = :coffeeName)
+			AND (r.name = :coffeeRoaster COLLATE NOCASE OR "" = :coffeeRoaster)
+			AND (g.name = :grinderName OR "" = :grinderName)
+			AND (b.user_id = :userID OR 0 = :userID)
+			GROUP BY band_index
+			HAVING band_index IS NOT NULL
+			ORDER BY band_index
+		`
+		query = strings.Replace(query, "?", ratioBandIndexSQL("b.water_grams / NULLIF(b.coffee_grams, 0)"), 1)
+		rows, err := tx.QueryContext(ctx, query,
+			sql.Named("brewingMethodName", brewingFilter.brewingMethodName),
+			sql.Named("v60FilterType", brewingFilter.v60FilterType),
+			sql.Named("coffeeName", brewingFilter.coffeeName),
+			sql.Named("coffeeRoaster", brewingFilter.coffeeRoaster),
+			sql.Named("grinderName", brewingFilter.grinderName),
+			userArg(ctx),
+		)
+		if err != nil {
+			return fmt.Errorf("buna: sqlite_db_statistics: failed to retrieve ratio band statistic rows: %w", err)
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var stats ratioBandStatistics
+			var bandIndex, underCount, balancedCount, overCount int
+			var averageRating interface{}
+			if err := rows.Scan(
+				&bandIndex,
+				&stats.brewingCount,
+				&stats.ratedCount,
+				&averageRating,
+				&stats.averageRatio,
+				&underCount,
+				&balancedCount,
+				&overCount,
+			); err != nil {
+				return fmt.Errorf("buna: sqlite_db_statistics: failed to scan ratio band statistic row: %w", err)
+			}
+
+			// The average rating is NULL if no brewing in the band has a rating
+			stats.band = ratioBands[bandIndex]
+			stats.averageRating = nullableFloatOr(averageRating, 0)
+			stats.extractionCounts = map[string]int{
+				underExtracted:    underCount,
+				balancedExtracted: balancedCount,
+				overExtracted:     overCount,
+			}
+
+			statistics = append(statistics, stats)
+		}
+		if err := rows.Err(); err != nil {
+			return fmt.Errorf("buna: sqlite_db_statistics: failed to iterate ratio band statistic rows: %w", err)
+		}
+
+		return nil
+	}); err != nil {
+		return nil, fmt.Errorf("buna: sqlite_db_statistics: getRatioBandStatistics transaction failed: %w", err)
+	}
+
+	return statistics, nil
 }
 
 func (s *SQLiteDB) getTotalCount(ctx context.Context, entity dbEntity) (int, error) {
@@ -96,8 +444,11 @@ func (s *SQLiteDB) getTotalCount(ctx context.Context, entity dbEntity) (int, err
 			SELECT count(*)
 			FROM ?
 		`
+		if userDBEntities[entity] {
+			query += "WHERE user_id = :userID OR 0 = :userID"
+		}
 		query = strings.Replace(query, "?", dbEntityString, 1)
-		if err := tx.QueryRowContext(ctx, query, sql.Named("entity", dbEntityString)).Scan(&count); err != nil {
+		if err := tx.QueryRowContext(ctx, query, userArg(ctx)).Scan(&count); err != nil {
 			return fmt.Errorf("buna: sqlite_db_statistics: failed to retrieve total count from db: %w", err)
 		}
 
@@ -108,3 +459,302 @@ func (s *SQLiteDB) getTotalCount(ctx context.Context, entity dbEntity) (int, err
 
 	return count, nil
 }
+
+// Returns the statistics of every roaster ordered by the number of coffee purchases.
+// The favourite origin of a roaster is the country with the highest average brewing rating.
+func (s *SQLiteDB) getRoasterStatistics(ctx context.Context) ([]roasterStatistics, error) {
+	var statistics []roasterStatistics
+	if err := s.TransactContext(ctx, func(ctx context.Context, tx *sql.Tx) error {
+		rows, err := tx.QueryContext(ctx, `
+			SELECT 	r.name,
+					(
+						SELECT count(*)
+						FROM coffees AS c
+						WHERE c.roaster_id = r.id
+					),
+					(
+						SELECT count(*)
+						FROM purchases AS p
+						INNER JOIN coffees AS c
+							ON c.id = p.coffee_id
+						WHERE c.roaster_id = r.id
+						AND (p.user_id = :userID OR 0 = :userID)
+					),
+					(
+						SELECT avg(b.rating)
+						FROM brewings AS b
+						INNER JOIN coffees AS c
+							ON c.id = b.coffee_id
+						WHERE c.roaster_id = r.id
+						AND (b.user_id = :userID OR 0 = :userID)
+					)
+			FROM roasters AS r
+			ORDER BY 3 DESC, r.name COLLATE NOCASE
+		`,
+			userArg(ctx),
+		)
+		if err != nil {
+			return fmt.Errorf("buna: sqlite_db_statistics: failed to retrieve roaster statistic rows: %w", err)
+		}
+		defer rows.Close()
+
+		indexByName := make(map[string]int)
+		for rows.Next() {
+			var stats roasterStatistics
+			var averageRating interface{}
+			if err := rows.Scan(&stats.roasterName, &stats.coffeeCount, &stats.purchaseCount, &averageRating); err != nil {
+				return fmt.Errorf("buna: sqlite_db_statistics: failed to scan row: %w", err)
+			}
+
+			// Deal with possible NULL values
+			if v := reflect.ValueOf(averageRating); v.Kind() == reflect.Float64 {
+				stats.averageRating = averageRating.(float64)
+			}
+			stats.favouriteOrigin = "Unknown"
+
+			indexByName[stats.roasterName] = len(statistics)
+			statistics = append(statistics, stats)
+		}
+
+		if err := rows.Err(); err != nil {
+			return fmt.Errorf("buna: sqlite_db_statistics: failed to scan last row: %w", err)
+		}
+
+		oRows, err := tx.QueryContext(ctx, `
+			SELECT r.name, c.country_code
+			FROM brewings AS b
+			INNER JOIN coffees AS c
+				ON c.id = b.coffee_id
+			INNER JOIN roasters AS r
+				ON r.id = c.roaster_id
+			WHERE c.country_code IS NOT NULL AND b.rating IS NOT NULL
+			AND (b.user_id = :userID OR 0 = :userID)
+			GROUP BY r.id, c.country_code
+			ORDER BY avg(b.rating) ASC, count(*) ASC
+		`,
+			userArg(ctx),
+		)
+		if err != nil {
+			return fmt.Errorf("buna: sqlite_db_statistics: failed to retrieve roaster origin rows: %w", err)
+		}
+		defer oRows.Close()
+
+		// Rows are ordered from worst to best, so the last origin of each roaster wins
+		for oRows.Next() {
+			var name, countryCode string
+			if err := oRows.Scan(&name, &countryCode); err != nil {
+				return fmt.Errorf("buna: sqlite_db_statistics: failed to scan oRow: %w", err)
+			}
+
+			if i, ok := indexByName[name]; ok {
+				statistics[i].favouriteOrigin = countryName(countryCode)
+			}
+		}
+
+		if err := oRows.Err(); err != nil {
+			return fmt.Errorf("buna: sqlite_db_statistics: failed to scan last oRow: %w", err)
+		}
+
+		return nil
+	}); err != nil {
+		return nil, fmt.Errorf("buna: sqlite_db_statistics: getRoasterStatistics transaction failed: %w", err)
+	}
+
+	return statistics, nil
+}
+
+// Selects the coffee_id and flavor_id of all flavor tags of brewings and cupped coffees of the current user.
+const flavorTagsCTE = `
+	WITH tags AS (
+		SELECT b.coffee_id, bf.flavor_id
+		FROM brewing_flavors AS bf
+		INNER JOIN brewings AS b
+			ON b.id = bf.brewing_id
+		WHERE b.user_id = :userID OR 0 = :userID
+		UNION ALL
+		SELECT ccf.coffee_id, ccf.flavor_id
+		FROM cupped_coffee_flavors AS ccf
+		INNER JOIN cuppings AS cu
+			ON cu.id = ccf.cupping_id
+		WHERE cu.user_id = :userID OR 0 = :userID
+	)
+`
+
+// Returns the flavor tags of the coffee across all brewings and cuppings, most common first.
+func (s *SQLiteDB) getCoffeeFlavorCounts(ctx context.Context, coffeeName string, coffeeRoaster string) ([]flavorCount, error) {
+	var flavorCounts []flavorCount
+	if err := s.TransactContext(ctx, func(ctx context.Context, tx *sql.Tx) error {
+		rows, err := tx.QueryContext(ctx, flavorTagsCTE+`
+			SELECT f.name, count(*)
+			FROM tags AS t
+			INNER JOIN flavors AS f
+				ON f.id = t.flavor_id
+			INNER JOIN coffees AS c
+				ON c.id = t.coffee_id
+			INNER JOIN roasters AS r
+				ON r.id = c.roaster_id
+			WHERE c.name = :coffeeName AND r.name = :coffeeRoaster COLLATE NOCASE
+			GROUP BY f.id
+			ORDER BY count(*) DESC, f.name
+		`,
+			sql.Named("coffeeName", coffeeName),
+			sql.Named("coffeeRoaster", coffeeRoaster),
+			userArg(ctx),
+		)
+		if err != nil {
+			return fmt.Errorf("buna: sqlite_db_statistics: failed to retrieve flavor count rows: %w", err)
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var flavorCount flavorCount
+			if err := rows.Scan(&flavorCount.flavor, &flavorCount.count); err != nil {
+				return fmt.Errorf("buna: sqlite_db_statistics: failed to scan row: %w", err)
+			}
+
+			flavorCounts = append(flavorCounts, flavorCount)
+		}
+
+		if err := rows.Err(); err != nil {
+			return fmt.Errorf("buna: sqlite_db_statistics: failed to scan last row: %w", err)
+		}
+
+		return nil
+	}); err != nil {
+		return nil, fmt.Errorf("buna: sqlite_db_statistics: getCoffeeFlavorCounts transaction failed: %w", err)
+	}
+
+	return flavorCounts, nil
+}
+
+// The group of the returned flavor counts is the country code.
+func (s *SQLiteDB) getFlavorCountsByOrigin(ctx context.Context, limitPerOrigin int) ([]flavorCount, error) {
+	flavorCounts, err := s.getFlavorCountsGroupedBy(ctx, "c.country_code", limitPerOrigin)
+	if err != nil {
+		return nil, fmt.Errorf("buna: sqlite_db_statistics: failed to get flavor counts by origin: %w", err)
+	}
+
+	return flavorCounts, nil
+}
+
+// The group of the returned flavor counts is the process.
+func (s *SQLiteDB) getFlavorCountsByProcess(ctx context.Context, limitPerProcess int) ([]flavorCount, error) {
+	flavorCounts, err := s.getFlavorCountsGroupedBy(ctx, "c.process", limitPerProcess)
+	if err != nil {
+		return nil, fmt.Errorf("buna: sqlite_db_statistics: failed to get flavor counts by process: %w", err)
+	}
+
+	return flavorCounts, nil
+}
+
+// Returns the most common flavors for every value of the coffees column groupColumn, ordered by group.
+// groupColumn is inserted into the query as is and must not contain user input.
+func (s *SQLiteDB) getFlavorCountsGroupedBy(ctx context.Context, groupColumn string, limitPerGroup int) ([]flavorCount, error) {
+	var flavorCounts []flavorCount
+	if err := s.TransactContext(ctx, func(ctx context.Context, tx *sql.Tx) error {
+		rows, err := tx.QueryContext(ctx, flavorTagsCTE+fmt.Sprintf(`
+			SELECT grp, flavor, cnt
+			FROM (
+				SELECT 	%[1]s AS grp,
+						f.name AS flavor,
+						count(*) AS cnt,
+						row_number() OVER (
+							PARTITION BY %[1]s
+							ORDER BY count(*) DESC, f.name
+						) AS pos
+				FROM tags AS t
+				INNER JOIN flavors AS f
+					ON f.id = t.flavor_id
+				INNER JOIN coffees AS c
+					ON c.id = t.coffee_id
+				WHERE %[1]s IS NOT NULL
+				GROUP BY %[1]s, f.id
+			)
+			WHERE pos <= :limit
+			ORDER BY grp, pos
+		`, groupColumn),
+			sql.Named("limit", limitPerGroup),
+			userArg(ctx),
+		)
+		if err != nil {
+			return fmt.Errorf("buna: sqlite_db_statistics: failed to retrieve flavor count rows: %w", err)
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var flavorCount flavorCount
+			if err := rows.Scan(&flavorCount.group, &flavorCount.flavor, &flavorCount.count); err != nil {
+				return fmt.Errorf("buna: sqlite_db_statistics: failed to scan row: %w", err)
+			}
+
+			flavorCounts = append(flavorCounts, flavorCount)
+		}
+
+		if err := rows.Err(); err != nil {
+			return fmt.Errorf("buna: sqlite_db_statistics: failed to scan last row: %w", err)
+		}
+
+		return nil
+	}); err != nil {
+		return nil, fmt.Errorf("buna: sqlite_db_statistics: getFlavorCountsGroupedBy transaction failed: %w", err)
+	}
+
+	return flavorCounts, nil
+}
+
+// Returns the number of rated brewings and the average rating of both users for every coffee
+// that both users have rated, ordered by the difference of the average ratings, largest first.
+func (s *SQLiteDB) getUserRatingComparisons(ctx context.Context, userID int, otherUserID int) ([]userRatingComparison, error) {
+	var comparisons []userRatingComparison
+	if err := s.TransactContext(ctx, func(ctx context.Context, tx *sql.Tx) error {
+		rows, err := tx.QueryContext(ctx, `
+			SELECT 	c.name,
+					r.name,
+					count(CASE WHEN b.user_id = :userID THEN 1 END) AS user_count,
+					avg(CASE WHEN b.user_id = :userID THEN b.rating END) AS user_avg,
+					count(CASE WHEN b.user_id = :otherUserID THEN 1 END) AS other_count,
+					avg(CASE WHEN b.user_id = :otherUserID THEN b.rating END) AS other_avg
+			FROM brewings AS b
+			INNER JOIN coffees AS c
+				ON c.id = b.coffee_id
+			INNER JOIN roasters AS r
+				ON r.id = c.roaster_id
+			WHERE b.rating IS NOT NULL AND b.user_id IN (:userID, :otherUserID)
+			GROUP BY c.id
+			HAVING user_count > 0 AND other_count > 0
+			ORDER BY abs(user_avg - other_avg) DESC, c.name, r.name COLLATE NOCASE
+		`,
+			sql.Named("userID", userID),
+			sql.Named("otherUserID", otherUserID),
+		)
+		if err != nil {
+			return fmt.Errorf("buna: sqlite_db_statistics: failed to retrieve user rating comparison rows: %w", err)
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var comparison userRatingComparison
+			if err := rows.Scan(
+				&comparison.coffeeName,
+				&comparison.coffeeRoaster,
+				&comparison.brewingCounts[0],
+				&comparison.averageRatings[0],
+				&comparison.brewingCounts[1],
+				&comparison.averageRatings[1],
+			); err != nil {
+				return fmt.Errorf("buna: sqlite_db_statistics: failed to scan user rating comparison row: %w", err)
+			}
+
+			comparisons = append(comparisons, comparison)
+		}
+		if err := rows.Err(); err != nil {
+			return fmt.Errorf("buna: sqlite_db_statistics: failed to iterate user rating comparison rows: %w", err)
+		}
+
+		return nil
+	}); err != nil {
+		return nil, fmt.Errorf("buna: sqlite_db_statistics: getUserRatingComparisons transaction failed: %w", err)
+	}
+
+	return comparisons, nil
+}
@@ -0,0 +1,357 @@
+package buna
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+// Merges the roaster fromName into the roaster intoName.
+// Coffees of both roasters with the same name are merged into a single coffee.
+func (s *SQLiteDB) mergeRoasters(ctx context.Context, fromName string, intoName string) error {
+	if err := s.TransactContext(ctx, func(ctx context.Context, tx *sql.Tx) error {
+		var fromID, intoID int
+		if err := tx.QueryRowContext(ctx, `
+			SELECT id
+			FROM roasters
+			WHERE name = :name COLLATE NOCASE
+		`,
+			sql.Named("name", fromName),
+		).Scan(&fromID); err != nil {
+			return fmt.Errorf("buna: sqlite_db_update: failed to retrieve roaster id to merge from: %w", err)
+		}
+
+		if err := tx.QueryRowContext(ctx, `
+			SELECT id
+			FROM roasters
+			WHERE name = :name COLLATE NOCASE
+		`,
+			sql.Named("name", intoName),
+		).Scan(&intoID); err != nil {
+			return fmt.Errorf("buna: sqlite_db_update: failed to retrieve roaster id to merge into: %w", err)
+		}
+
+		if fromID == intoID {
+			return errors.New("buna: sqlite_db_update: unable to merge a roaster into itself")
+		}
+
+		rows, err := tx.QueryContext(ctx, `
+			SELECT f.id, i.id
+			FROM coffees AS f
+			INNER JOIN coffees AS i
+				ON i.name = f.name
+			WHERE f.roaster_id = :fromID AND i.roaster_id = :intoID
+		`,
+			sql.Named("fromID", fromID),
+			sql.Named("intoID", intoID),
+		)
+		if err != nil {
+			return fmt.Errorf("buna: sqlite_db_update: failed to retrieve coffees to merge: %w", err)
+		}
+		defer rows.Close()
+
+		duplicates := make(map[int]int)
+		for rows.Next() {
+			var fromCoffeeID, intoCoffeeID int
+			if err := rows.Scan(&fromCoffeeID, &intoCoffeeID); err != nil {
+				return fmt.Errorf("buna: sqlite_db_update: failed to scan row: %w", err)
+			}
+
+			duplicates[fromCoffeeID] = intoCoffeeID
+		}
+
+		if err := rows.Err(); err != nil {
+			return fmt.Errorf("buna: sqlite_db_update: failed to scan last row: %w", err)
+		}
+
+		for fromCoffeeID, intoCoffeeID := range duplicates {
+			if err := mergeCoffeeDetails(ctx, tx, fromCoffeeID, intoCoffeeID); err != nil {
+				return fmt.Errorf("buna: sqlite_db_update: failed to merge coffee details: %w", err)
+			}
+
+			if err := mergeCoffees(ctx, tx, fromCoffeeID, intoCoffeeID); err != nil {
+				return fmt.Errorf("buna: sqlite_db_update: failed to merge coffees: %w", err)
+			}
+		}
+
+		if _, err := tx.ExecContext(ctx, `
+			UPDATE coffees
+			SET roaster_id = :intoID
+			WHERE roaster_id = :fromID
+		`,
+			sql.Named("fromID", fromID),
+			sql.Named("intoID", intoID),
+		); err != nil {
+			return fmt.Errorf("buna: sqlite_db_update: failed to move coffees to merged roaster: %w", err)
+		}
+
+		if _, err := tx.ExecContext(ctx, `
+			DELETE FROM roaster_merge_dismissals
+			WHERE roaster_id = :fromID OR other_roaster_id = :fromID
+		`,
+			sql.Named("fromID", fromID),
+		); err != nil {
+			return fmt.Errorf("buna: sqlite_db_update: failed to delete merge dismissals: %w", err)
+		}
+
+		if _, err := tx.ExecContext(ctx, `
+			DELETE FROM roasters
+			WHERE id = :fromID
+		`,
+			sql.Named("fromID", fromID),
+		); err != nil {
+			return fmt.Errorf("buna: sqlite_db_update: failed to delete merged roaster: %w", err)
+		}
+
+		return nil
+	}); err != nil {
+		return fmt.Errorf("buna: sqlite_db_update: mergeRoasters transaction failed: %w", err)
+	}
+	return nil
+}
+
+// Records that the two roasters are different even though their names are similar.
+func (s *SQLiteDB) dismissRoasterMerge(ctx context.Context, name string, otherName string) error {
+	if err := s.TransactContext(ctx, func(ctx context.Context, tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx, `
+			INSERT OR IGNORE INTO roaster_merge_dismissals(roaster_id, other_roaster_id)
+			SELECT r.id, o.id
+			FROM roasters AS r, roasters AS o
+			WHERE r.name = :name COLLATE NOCASE AND o.name = :otherName COLLATE NOCASE
+		`,
+			sql.Named("name", name),
+			sql.Named("otherName", otherName),
+		); err != nil {
+			return fmt.Errorf("buna: sqlite_db_update: failed to insert roaster merge dismissal into db: %w", err)
+		}
+
+		return nil
+	}); err != nil {
+		return fmt.Errorf("buna: sqlite_db_update: dismissRoasterMerge transaction failed: %w", err)
+	}
+	return nil
+}
+
+// Moves the varieties and cupped coffee flavors of the coffee with fromID to the coffee with intoID.
+// Must be called before mergeCoffees.
+// mergeCoffees can't do this itself, as it is also used by migrateRoasters, which runs before these tables exist.
+func mergeCoffeeDetails(ctx context.Context, tx *sql.Tx, fromID int, intoID int) error {
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE OR IGNORE coffee_varieties
+		SET coffee_id = :intoID
+		WHERE coffee_id = :fromID
+	`,
+		sql.Named("fromID", fromID),
+		sql.Named("intoID", intoID),
+	); err != nil {
+		return fmt.Errorf("buna: sqlite_db_update: failed to merge coffee varieties: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		DELETE FROM coffee_varieties
+		WHERE coffee_id = :fromID
+	`,
+		sql.Named("fromID", fromID),
+	); err != nil {
+		return fmt.Errorf("buna: sqlite_db_update: failed to delete remaining coffee varieties: %w", err)
+	}
+
+	// Flavors of cupped coffees that are dropped by mergeCoffees are deleted as well
+	if _, err := tx.ExecContext(ctx, `
+		DELETE FROM cupped_coffee_flavors
+		WHERE coffee_id = :fromID AND cupping_id IN (
+			SELECT cupping_id
+			FROM cupped_coffees
+			WHERE coffee_id = :intoID
+		)
+	`,
+		sql.Named("fromID", fromID),
+		sql.Named("intoID", intoID),
+	); err != nil {
+		return fmt.Errorf("buna: sqlite_db_update: failed to delete flavors of duplicate cupped coffees: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE cupped_coffee_flavors
+		SET coffee_id = :intoID
+		WHERE coffee_id = :fromID
+	`,
+		sql.Named("fromID", fromID),
+		sql.Named("intoID", intoID),
+	); err != nil {
+		return fmt.Errorf("buna: sqlite_db_update: failed to merge cupped coffee flavors: %w", err)
+	}
+
+	return nil
+}
+
+// Points all brewings, purchases and cuppings of the coffee with fromID to the coffee with intoID
+// and deletes the coffee with fromID.
+// If both coffees were part of the same cupping, only the cupped coffee with intoID is kept.
+func mergeCoffees(ctx context.Context, tx *sql.Tx, fromID int, intoID int) error {
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE brewings
+		SET coffee_id = :intoID
+		WHERE coffee_id = :fromID
+	`,
+		sql.Named("fromID", fromID),
+		sql.Named("intoID", intoID),
+	); err != nil {
+		return fmt.Errorf("buna: sqlite_db_update: failed to merge coffee brewings: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE purchases
+		SET coffee_id = :intoID
+		WHERE coffee_id = :fromID
+	`,
+		sql.Named("fromID", fromID),
+		sql.Named("intoID", intoID),
+	); err != nil {
+		return fmt.Errorf("buna: sqlite_db_update: failed to merge coffee purchases: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE OR IGNORE cupped_coffees
+		SET coffee_id = :intoID
+		WHERE coffee_id = :fromID
+	`,
+		sql.Named("fromID", fromID),
+		sql.Named("intoID", intoID),
+	); err != nil {
+		return fmt.Errorf("buna: sqlite_db_update: failed to merge cupped coffees: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		DELETE FROM cupped_coffees
+		WHERE coffee_id = :fromID
+	`,
+		sql.Named("fromID", fromID),
+	); err != nil {
+		return fmt.Errorf("buna: sqlite_db_update: failed to delete remaining cupped coffees: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		DELETE FROM coffees
+		WHERE id = :fromID
+	`,
+		sql.Named("fromID", fromID),
+	); err != nil {
+		return fmt.Errorf("buna: sqlite_db_update: failed to delete merged coffee: %w", err)
+	}
+
+	return nil
+}
+
+// Stores the value of the preference, replacing any previous value.
+func (s *SQLiteDB) setPreference(ctx context.Context, name string, value string) error {
+	if err := s.TransactContext(ctx, func(ctx context.Context, tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO preferences(name, value)
+			VALUES (:name, :value)
+			ON CONFLICT (name) DO UPDATE SET value = excluded.value
+		`,
+			sql.Named("name", name),
+			sql.Named("value", value),
+		); err != nil {
+			return fmt.Errorf("buna: sqlite_db_update: failed to set preference: %w", err)
+		}
+
+		return nil
+	}); err != nil {
+		return fmt.Errorf("buna: sqlite_db_update: setPreference transaction failed: %w", err)
+	}
+
+	return nil
+}
+
+// Stores the draft, inserting it if its id is 0.
+// Returns the id of the draft.
+func (s *SQLiteDB) saveDraft(ctx context.Context, d draft) (int, error) {
+	id := d.id
+	if err := s.TransactContext(ctx, func(ctx context.Context, tx *sql.Tx) error {
+		if id != 0 {
+			if _, err := tx.ExecContext(ctx, `
+				UPDATE drafts
+				SET answers = :answers, updated_at = :updated_at
+				WHERE id = :id
+			`,
+				sql.Named("answers", d.answers),
+				sql.Named("updated_at", d.updatedAt),
+				sql.Named("id", id),
+			); err != nil {
+				return fmt.Errorf("buna: sqlite_db_update: failed to update draft: %w", err)
+			}
+
+			return nil
+		}
+
+		result, err := tx.ExecContext(ctx, `
+			INSERT INTO drafts(flow, answers, created_at, updated_at, user_id)
+			VALUES (:flow, :answers, :created_at, :updated_at, NULLIF(:userID, 0))
+		`,
+			sql.Named("flow", d.flow),
+			sql.Named("answers", d.answers),
+			sql.Named("created_at", d.createdAt),
+			sql.Named("updated_at", d.updatedAt),
+			userArg(ctx),
+		)
+		if err != nil {
+			return fmt.Errorf("buna: sqlite_db_update: failed to insert draft: %w", err)
+		}
+
+		insertedID, err := result.LastInsertId()
+		if err != nil {
+			return fmt.Errorf("buna: sqlite_db_update: failed to get id of inserted draft: %w", err)
+		}
+		id = int(insertedID)
+
+		return nil
+	}); err != nil {
+		return 0, fmt.Errorf("buna: sqlite_db_update: saveDraft transaction failed: %w", err)
+	}
+
+	return id, nil
+}
+
+func (s *SQLiteDB) deleteDraft(ctx context.Context, id int) error {
+	if err := s.TransactContext(ctx, func(ctx context.Context, tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx, `
+			DELETE FROM drafts
+			WHERE id = :id
+		`,
+			sql.Named("id", id),
+		); err != nil {
+			return fmt.Errorf("buna: sqlite_db_update: failed to delete draft: %w", err)
+		}
+
+		return nil
+	}); err != nil {
+		return fmt.Errorf("buna: sqlite_db_update: deleteDraft transaction failed: %w", err)
+	}
+
+	return nil
+}
+
+// Attributes all brewings, coffee purchases, cuppings and drafts without a user to the user.
+func (s *SQLiteDB) assignUnattributedEntries(ctx context.Context, userID int) error {
+	if err := s.TransactContext(ctx, func(ctx context.Context, tx *sql.Tx) error {
+		for _, table := range []string{"brewings", "purchases", "cuppings", "drafts"} {
+			if _, err := tx.ExecContext(ctx, fmt.Sprintf(`
+				UPDATE %s
+				SET user_id = :userID
+				WHERE user_id IS NULL
+			`, table),
+				sql.Named("userID", userID),
+			); err != nil {
+				return fmt.Errorf("buna: sqlite_db_update: failed to assign %s to user: %w", table, err)
+			}
+		}
+
+		return nil
+	}); err != nil {
+		return fmt.Errorf("buna: sqlite_db_update: assignUnattributedEntries transaction failed: %w", err)
+	}
+	return nil
+}
@@ -4,98 +4,165 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"os"
+
+	"github.com/jedib0t/go-pretty/table"
+	"golang.org/x/crypto/ssh/terminal"
 )
 
 func getAverageBrewingRating(ctx context.Context, db DB) error {
-	fmt.Println("Getting average brewing rating (Enter # to quit):")
+	session := sessionFromContext(ctx)
+	fmt.Println("Getting average brewing rating (Enter " + session.quit + " to quit):")
 
-	fmt.Print("Add filters (true or false): ")
-	showOptionalOptions, quit := validateBoolInput(quitStr, true)
-	if quit {
+	brewingFilter, band, quit, err := getBrewingFilterInput(ctx, db, true)
+	if err != nil {
+		return fmt.Errorf("buna: statistics: failed to get brewing filter: %w", err)
+	}
+	if quit != answered {
 		fmt.Println(quitMsg)
 		return nil
 	}
 
-	var (
-		brewingMethodName, v60FilterType, coffeeName, coffeeRoaster, grinderName string
-		err                                                                      error
-	)
-	if showOptionalOptions {
-		brewingMethodName, quit, err = getBrewingMethodNameWithSuggestions(ctx, db, quitStr, true)
-		if err != nil {
-			return fmt.Errorf("buna: statistics: failed to get brewing method name: %w", err)
-		}
-		if quit {
-			fmt.Println(quitMsg)
-			return nil
-		}
+	averages, err := db.getAverageBrewingScores(ctx, brewingFilter, band)
+	if err != nil {
+		return fmt.Errorf("buna: statistics: failed to get the average brewing scores: %w", err)
+	}
+	if averages.brewingCount == 0 {
+		fmt.Println("No brewings exist")
+		return nil
+	}
 
-		if brewingMethodName == "v60" || brewingMethodName == "V60" {
-			v60FilterType, quit = getV60FilterTypeWithSuggestions(quitStr)
-			if quit {
-				fmt.Println(quitMsg)
-				return nil
-			}
-		}
+	fmt.Printf("The average brewing rating is %.1f/10\n", averages.rating)
 
-		coffeeName, quit, err = getCoffeeNameWithSuggestions(ctx, db, quitStr, true)
-		if err != nil {
-			return fmt.Errorf("buna: statistics: failed to get coffee name: %w", err)
-		}
-		if quit {
-			fmt.Println(quitMsg)
-			return nil
-		}
+	if err := displayBrewingScoreAverages(ctx, averages); err != nil {
+		return fmt.Errorf("buna: statistics: failed to display brewing score averages: %w", err)
+	}
 
-		if coffeeName != "" {
-			coffeeRoaster, quit, err = getCoffeeRoasterWithSuggestions(ctx, db, quitStr, coffeeName)
-			if err != nil {
-				return fmt.Errorf("buna: statistics: failed to get coffee roaster: %w", err)
-			}
-			if quit {
-				fmt.Println(quitMsg)
-				return nil
-			}
-		}
+	return nil
+}
 
-		grinderName, quit, err = getCoffeeGrinderNameWithSuggestions(ctx, db, quitStr, true)
-		if err != nil {
-			return fmt.Errorf("buna: statistics: failed to get coffee grinder name: %w", err)
+// Displays the average of every sub-score and how often each extraction verdict was given.
+func displayBrewingScoreAverages(ctx context.Context, averages brewingScoreAverages) error {
+	t := table.NewWriter()
+
+	t.AppendHeader(table.Row{"Score", "Value"})
+	for _, score := range []struct {
+		name    string
+		average float64
+	}{
+		{"Rating", averages.rating},
+		{"Sweetness", averages.sweetness},
+		{"Acidity", averages.acidity},
+		{"Bitterness", averages.bitterness},
+		{"Body", averages.body},
+		{"Clarity", averages.clarity},
+		{"Astringency", averages.astringency},
+	} {
+		average := "-"
+		if score.average != 0 {
+			average = fmt.Sprintf("%.1f/10", score.average)
 		}
-		if quit {
-			fmt.Println(quitMsg)
-			return nil
+		t.AppendRow(table.Row{score.name, average})
+	}
+
+	t.AppendSeparator()
+	for _, extraction := range []struct {
+		name    string
+		verdict string
+	}{
+		{"Under-extracted", underExtracted},
+		{"Balanced", balancedExtracted},
+		{"Over-extracted", overExtracted},
+	} {
+		t.AppendRow(table.Row{
+			extraction.name,
+			fmt.Sprintf("%d of %d brewings", averages.extractionCounts[extraction.verdict], averages.brewingCount),
+		})
+	}
+
+	terminalWidth, _, err := terminal.GetSize(int(os.Stdin.Fd()))
+	if err != nil {
+		return fmt.Errorf("buna: statistics: failed to get terminal width: %w", err)
+	}
+	t.SetAllowedRowLength(terminalWidth)
+
+	t.SetOutputMirror(os.Stdout)
+	renderTable(ctx, t)
+
+	return nil
+}
+
+func getRatioBandStatistics(ctx context.Context, db DB) error {
+	session := sessionFromContext(ctx)
+	fmt.Println("Getting ratings by brew ratio band (Enter " + session.quit + " to quit):")
+
+	brewingFilter, _, quit, err := getBrewingFilterInput(ctx, db, false)
+	if err != nil {
+		return fmt.Errorf("buna: statistics: failed to get brewing filter: %w", err)
+	}
+	if quit != answered {
+		fmt.Println(quitMsg)
+		return nil
+	}
+
+	statistics, err := db.getRatioBandStatistics(ctx, brewingFilter)
+	if err != nil {
+		return fmt.Errorf("buna: statistics: failed to get ratio band statistics: %w", err)
+	}
+
+	if len(statistics) == 0 {
+		fmt.Println("No brewings with weights exist")
+		return nil
+	}
+
+	t := table.NewWriter()
+
+	t.AppendHeader(table.Row{"Ratio band", "Brewings", "Avg ratio", "Avg rating", "Under-extracted", "Balanced", "Over-extracted"})
+	for _, stats := range statistics {
+		averageRating := "-"
+		if stats.ratedCount > 0 {
+			averageRating = fmt.Sprintf("%.1f/10 (%d rated)", stats.averageRating, stats.ratedCount)
 		}
+
+		t.AppendRow(table.Row{
+			stats.band.name,
+			stats.brewingCount,
+			fmt.Sprintf("1:%.1f", stats.averageRatio),
+			averageRating,
+			stats.extractionCounts[underExtracted],
+			stats.extractionCounts[balancedExtracted],
+			stats.extractionCounts[overExtracted],
+		})
 	}
 
-	brewingFilter := brewing{
-		date:                                   "",
-		coffeeName:                             coffeeName,
-		coffeeRoaster:                          coffeeRoaster,
-		brewingMethodName:                      brewingMethodName,
-		roastDate:                              "",
-		grinderName:                            grinderName,
-		grindSetting:                           0,
-		totalBrewingTimeSec:                    0,
-		coffeeGrams:                            0,
-		waterGrams:                             0,
-		v60FilterType:                          v60FilterType,
-		rating:                                 0,
-		recommendedGrindSettingAdjustment:      "",
-		recommendedCoffeeWeightAdjustmentGrams: 0,
-		notes:                                  "",
-	}
-
-	averageRating, err := db.getAverageBrewingRating(ctx, brewingFilter)
+	terminalWidth, _, err := terminal.GetSize(int(os.Stdin.Fd()))
 	if err != nil {
-		return fmt.Errorf("buna: statistics: failed to get the average brewing rating: %w", err)
+		return fmt.Errorf("buna: statistics: failed to get terminal width: %w", err)
 	}
-	if averageRating == 0 {
-		fmt.Println("No brewings exist")
+	t.SetAllowedRowLength(terminalWidth)
+
+	t.SetOutputMirror(os.Stdout)
+	renderTable(ctx, t)
+
+	return nil
+}
+
+func getRoasterStatistics(ctx context.Context, db DB) error {
+	fmt.Println("Getting roaster statistics:")
+
+	statistics, err := db.getRoasterStatistics(ctx)
+	if err != nil {
+		return fmt.Errorf("buna: statistics: failed to get roaster statistics: %w", err)
+	}
+
+	if len(statistics) == 0 {
+		fmt.Println("No roasters exist")
 		return nil
 	}
 
-	fmt.Printf("The average brewing rating is %.1f/10\n", averageRating)
+	if err := displayRoasterStatistics(ctx, statistics); err != nil {
+		return fmt.Errorf("buna: statistics: failed to display roaster statistics: %w", err)
+	}
 
 	return nil
 }
@@ -108,18 +175,21 @@ func getTotalCountInDB(ctx context.Context, db DB) error {
 		3: "Total coffee purchases count",
 		4: "Total brewing methods count",
 		5: "Total coffee grinders count",
+		6: "Total roasters count",
+		7: "Total users count",
 	}
 
-	fmt.Println("Getting total count (Enter # to quit):")
-	if err := displayIntOptions(options); err != nil {
+	session := sessionFromContext(ctx)
+	fmt.Println("Getting total count (Enter " + session.quit + " to quit):")
+	if err := displayIntOptions(ctx, options); err != nil {
 		return fmt.Errorf("buna: statistics: failed to display int options: %w", err)
 	}
 
-	selection, quit, err := getIntSelection(options, quitStr)
+	selection, quit, err := getIntSelection(ctx, options, quitExits(ctx))
 	if err != nil {
 		return fmt.Errorf("buna: statistics: failed to get int selection: %w", err)
 	}
-	if quit {
+	if quit != answered {
 		fmt.Println(quitMsg)
 		return nil
 	}
@@ -154,8 +224,86 @@ func getDBEntityFromSelection(ctx context.Context, selection int) (dbEntity, err
 		entity = brewingMethods
 	case 5:
 		entity = grinders
+	case 6:
+		entity = roasters
+	case 7:
+		entity = users
 	default:
 		return 0, errors.New("buna: statistics: invalid dbEntity selection")
 	}
 	return entity, nil
 }
+
+// Prompts user for optional brewing method, v60 filter type, coffee and grinder filters
+// and, if withRatioBand is true, an optional ratio band.
+// Returns brewingFilter, ratioBand, promptResult, error
+// Only the brewingMethodName, v60FilterType, coffeeName, coffeeRoaster and grinderName fields of brewingFilter are set.
+func getBrewingFilterInput(ctx context.Context, db DB, withRatioBand bool) (brewing, ratioBand, promptResult, error) {
+	fmt.Print("Add filters (true or false): ")
+	showOptionalOptions, quit := validateBoolInput(quitExits(ctx), true)
+	if quit != answered || !showOptionalOptions {
+		return brewing{}, ratioBand{}, quit, nil
+	}
+
+	brewingMethodName, quit, err := getBrewingMethodNameWithSuggestions(ctx, db, quitExits(ctx), true)
+	if err != nil {
+		return brewing{}, ratioBand{}, answered, fmt.Errorf("buna: statistics: failed to get brewing method name: %w", err)
+	}
+	if quit != answered {
+		return brewing{}, ratioBand{}, quit, nil
+	}
+
+	var v60FilterType string
+	if brewingMethodName == "v60" || brewingMethodName == "V60" {
+		v60FilterType, quit, err = getV60FilterTypeWithSuggestions(quitExits(ctx))
+		if err != nil {
+			return brewing{}, ratioBand{}, answered, fmt.Errorf("buna: statistics: failed to get v60 filter type: %w", err)
+		}
+		if quit != answered {
+			return brewing{}, ratioBand{}, quit, nil
+		}
+	}
+
+	coffeeName, quit, err := getCoffeeNameWithSuggestions(ctx, db, quitExits(ctx), true)
+	if err != nil {
+		return brewing{}, ratioBand{}, answered, fmt.Errorf("buna: statistics: failed to get coffee name: %w", err)
+	}
+	if quit != answered {
+		return brewing{}, ratioBand{}, quit, nil
+	}
+
+	var coffeeRoaster string
+	if coffeeName != "" {
+		coffeeRoaster, quit, err = getCoffeeRoasterWithSuggestions(ctx, db, quitExits(ctx), coffeeName)
+		if err != nil {
+			return brewing{}, ratioBand{}, answered, fmt.Errorf("buna: statistics: failed to get coffee roaster: %w", err)
+		}
+		if quit != answered {
+			return brewing{}, ratioBand{}, quit, nil
+		}
+	}
+
+	grinderName, quit, err := getCoffeeGrinderNameWithSuggestions(ctx, db, quitExits(ctx), true)
+	if err != nil {
+		return brewing{}, ratioBand{}, answered, fmt.Errorf("buna: statistics: failed to get coffee grinder name: %w", err)
+	}
+	if quit != answered {
+		return brewing{}, ratioBand{}, quit, nil
+	}
+
+	var band ratioBand
+	if withRatioBand {
+		band, quit = getRatioBandInput(quitExits(ctx))
+		if quit != answered {
+			return brewing{}, ratioBand{}, quit, nil
+		}
+	}
+
+	return brewing{
+		coffeeName:        coffeeName,
+		coffeeRoaster:     coffeeRoaster,
+		brewingMethodName: brewingMethodName,
+		grinderName:       grinderName,
+		v60FilterType:     v60FilterType,
+	}, band, answered, nil
+}
@@ -0,0 +1,308 @@
+package buna
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"time"
+
+	"golang.org/x/crypto/ssh/terminal"
+)
+
+type trendPeriod int
+
+const (
+	weekly trendPeriod = iota
+	monthly
+)
+
+var trendPeriodToName = map[trendPeriod]string{
+	weekly:  "week",
+	monthly: "month",
+}
+
+// Brewing statistics of one brewing method in one period.
+// Averages are 0 if no brewing in the period has a rating or weights.
+type brewingTrend struct {
+	// First day of the period, YYYY-MM-DD
+	periodStart       string
+	brewingMethodName string
+	brewingCount      int
+	ratedCount        int
+	averageRating     float64
+	averageRatio      float64
+}
+
+// Brewing statistics of all brewing methods in one period.
+// Averages are NaN if no brewing in the period has a rating or weights.
+type periodTrend struct {
+	start         time.Time
+	brewingCount  int
+	averageRating float64
+	// Average brew ratio (1:x) by brewing method name
+	averageRatios map[string]float64
+}
+
+// Returns the first day of the period after the one starting at start.
+func nextPeriodStart(period trendPeriod, start time.Time) time.Time {
+	if period == monthly {
+		return start.AddDate(0, 1, 0)
+	}
+	return start.AddDate(0, 0, 7)
+}
+
+func formatPeriodStart(period trendPeriod, start time.Time) string {
+	if period == monthly {
+		return start.Format("2006-01")
+	}
+	return start.Format("2006-01-02")
+}
+
+// Combines the per method trends into one trend per period, from the first to the last period with brewings.
+// Periods without brewings are included so that gaps show up in the charts.
+// trends must be ordered by period.
+// Returns the period trends and the sorted names of all brewing methods in trends.
+func combineBrewingTrends(trends []brewingTrend, period trendPeriod) ([]periodTrend, []string, error) {
+	const layout = "2006-01-02"
+
+	if len(trends) == 0 {
+		return nil, nil, nil
+	}
+
+	first, err := time.Parse(layout, trends[0].periodStart)
+	if err != nil {
+		return nil, nil, fmt.Errorf("buna: trend: failed to parse period start: %w", err)
+	}
+	last, err := time.Parse(layout, trends[len(trends)-1].periodStart)
+	if err != nil {
+		return nil, nil, fmt.Errorf("buna: trend: failed to parse period start: %w", err)
+	}
+
+	var periodTrends []periodTrend
+	indexByStart := make(map[string]int)
+	for start := first; !start.After(last); start = nextPeriodStart(period, start) {
+		indexByStart[start.Format(layout)] = len(periodTrends)
+		periodTrends = append(periodTrends, periodTrend{
+			start:         start,
+			averageRating: math.NaN(),
+			averageRatios: make(map[string]float64),
+		})
+	}
+
+	ratingSums := make([]float64, len(periodTrends))
+	ratedCounts := make([]int, len(periodTrends))
+	methodNames := make(map[string]bool)
+	for _, trend := range trends {
+		i, ok := indexByStart[trend.periodStart]
+		if !ok {
+			return nil, nil, fmt.Errorf("buna: trend: period start %v is not the start of a %v", trend.periodStart, trendPeriodToName[period])
+		}
+
+		periodTrends[i].brewingCount += trend.brewingCount
+		ratingSums[i] += trend.averageRating * float64(trend.ratedCount)
+		ratedCounts[i] += trend.ratedCount
+		if trend.averageRatio != 0 {
+			periodTrends[i].averageRatios[trend.brewingMethodName] = trend.averageRatio
+		}
+		methodNames[trend.brewingMethodName] = true
+	}
+
+	for i := range periodTrends {
+		if ratedCounts[i] > 0 {
+			periodTrends[i].averageRating = ratingSums[i] / float64(ratedCounts[i])
+		}
+	}
+
+	sortedMethodNames := make([]string, 0, len(methodNames))
+	for name := range methodNames {
+		sortedMethodNames = append(sortedMethodNames, name)
+	}
+	sort.Strings(sortedMethodNames)
+
+	return periodTrends, sortedMethodNames, nil
+}
+
+// Prompts user for a period, a brewing filter and a date range,
+// and charts the average rating, the number of brewings and the average brew ratio per brewing method over time.
+func displayBrewingTrends(ctx context.Context, db DB) error {
+	options := map[int]string{
+		0: "Weekly",
+		1: "Monthly",
+	}
+
+	session := sessionFromContext(ctx)
+	fmt.Println("Getting brewing trends (Enter " + session.quit + " to quit):")
+	if err := displayIntOptions(ctx, options); err != nil {
+		return fmt.Errorf("buna: trend: failed to display int options: %w", err)
+	}
+
+	selection, quit, err := getIntSelection(ctx, options, quitExits(ctx))
+	if err != nil {
+		return fmt.Errorf("buna: trend: failed to get int selection: %w", err)
+	}
+	if quit != answered {
+		fmt.Println(quitMsg)
+		return nil
+	}
+
+	period := weekly
+	if selection == 1 {
+		period = monthly
+	}
+
+	brewingFilter, band, quit, err := getBrewingFilterInput(ctx, db, true)
+	if err != nil {
+		return fmt.Errorf("buna: trend: failed to get brewing filter: %w", err)
+	}
+	if quit != answered {
+		fmt.Println(quitMsg)
+		return nil
+	}
+
+	fromDate, quit := getDateInput(quitExits(ctx), true, "Enter first brewing date (Leave empty for no lower bound): ", nil)
+	if quit != answered {
+		fmt.Println(quitMsg)
+		return nil
+	}
+
+	toDate, quit := getDateInput(quitExits(ctx), true, "Enter last brewing date (Leave empty for no upper bound): ", nil)
+	if quit != answered {
+		fmt.Println(quitMsg)
+		return nil
+	}
+
+	var fromDateStr, toDateStr string
+	if fromDate.year != 0 {
+		fromDateStr = createDateString(fromDate)
+	}
+	if toDate.year != 0 {
+		toDateStr = createDateString(toDate)
+	}
+
+	trends, err := db.getBrewingTrends(ctx, brewingFilter, band, fromDateStr, toDateStr, period)
+	if err != nil {
+		return fmt.Errorf("buna: trend: failed to get brewing trends: %w", err)
+	}
+
+	periodTrends, methodNames, err := combineBrewingTrends(trends, period)
+	if err != nil {
+		return fmt.Errorf("buna: trend: failed to combine brewing trends: %w", err)
+	}
+	if len(periodTrends) == 0 {
+		fmt.Println("No brewings exist")
+		return nil
+	}
+
+	terminalWidth, _, err := terminal.GetSize(int(os.Stdin.Fd()))
+	if err != nil {
+		return fmt.Errorf("buna: trend: failed to get terminal width: %w", err)
+	}
+
+	displayPeriodTrends(periodTrends, methodNames, period, terminalWidth)
+
+	return nil
+}
+
+// Charts the period trends in charts that fit into width characters.
+func displayPeriodTrends(periodTrends []periodTrend, methodNames []string, period trendPeriod, width int) {
+	// Bar charts get one row per period, so only the most recent periods are shown
+	const maxBarChartPeriods = 52
+	// Room for the labels around a chart
+	const chartMargin = 40
+
+	periodName := trendPeriodToName[period]
+	chartWidth := width - chartMargin
+	if chartWidth < 10 {
+		chartWidth = 10
+	}
+
+	barTrends := periodTrends
+	if len(barTrends) > maxBarChartPeriods {
+		barTrends = barTrends[len(barTrends)-maxBarChartPeriods:]
+	}
+	labels := make([]string, len(barTrends))
+	for i, trend := range barTrends {
+		labels[i] = formatPeriodStart(period, trend.start)
+	}
+
+	ratings := make([]float64, len(periodTrends))
+	for i, trend := range periodTrends {
+		ratings[i] = trend.averageRating
+	}
+
+	fmt.Printf("\nAverage rating per %v\n", periodName)
+	fmt.Println(renderSparkline(ratings, chartWidth) + " " + describeSparklineRange(ratings, chartWidth))
+	fmt.Println()
+	barRatings := ratings[len(ratings)-len(barTrends):]
+	ratingLabels := make([]string, len(barTrends))
+	for i, rating := range barRatings {
+		ratingLabels[i] = "-"
+		if !math.IsNaN(rating) {
+			ratingLabels[i] = fmt.Sprintf("%.1f", rating)
+		}
+	}
+	fmt.Print(renderBarChart(labels, barRatings, ratingLabels, 10, chartWidth))
+
+	counts := make([]float64, len(barTrends))
+	countLabels := make([]string, len(barTrends))
+	var maxCount float64
+	for i, trend := range barTrends {
+		counts[i] = float64(trend.brewingCount)
+		countLabels[i] = strconv.Itoa(trend.brewingCount)
+		maxCount = math.Max(maxCount, counts[i])
+	}
+
+	fmt.Printf("\nBrewings per %v\n", periodName)
+	fmt.Print(renderBarChart(labels, counts, countLabels, maxCount, chartWidth))
+
+	if len(periodTrends) > len(barTrends) {
+		fmt.Printf("(Showing the last %d of %d %vs)\n", len(barTrends), len(periodTrends), periodName)
+	}
+
+	fmt.Printf("\nAverage brew ratio (1:x) per %v\n", periodName)
+	nameWidth := 0
+	for _, name := range methodNames {
+		if len(name) > nameWidth {
+			nameWidth = len(name)
+		}
+	}
+	for _, name := range methodNames {
+		ratios := make([]float64, len(periodTrends))
+		for i, trend := range periodTrends {
+			ratio, ok := trend.averageRatios[name]
+			if !ok {
+				ratio = math.NaN()
+			}
+			ratios[i] = ratio
+		}
+
+		fmt.Printf("%-*s │%v %v\n", nameWidth, name, renderSparkline(ratios, chartWidth-nameWidth), describeSparklineRange(ratios, chartWidth-nameWidth))
+	}
+
+	fmt.Printf("\n%v to %v\n", formatPeriodStart(period, periodTrends[0].start), formatPeriodStart(period, periodTrends[len(periodTrends)-1].start))
+}
+
+// Describes the range and the latest value of the values drawn by renderSparkline(vals, width).
+func describeSparklineRange(vals []float64, width int) string {
+	if len(vals) > width {
+		vals = vals[len(vals)-width:]
+	}
+
+	min, max, ok := floatRangeIgnoringNaN(vals)
+	if !ok {
+		return "-"
+	}
+
+	var latest float64
+	for i := len(vals) - 1; i >= 0; i-- {
+		if !math.IsNaN(vals[i]) {
+			latest = vals[i]
+			break
+		}
+	}
+
+	return fmt.Sprintf("min %.1f, max %.1f, latest %.1f", min, max, latest)
+}
@@ -0,0 +1,910 @@
+package buna
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/crypto/ssh/terminal"
+)
+
+// Views of the full-screen terminal UI, in the order of their tabs
+type tuiView int
+
+const (
+	brewingsView tuiView = iota
+	coffeesView
+	statisticsView
+	addBrewingView
+)
+
+var tuiViewNames = []string{
+	brewingsView:   "Brewings",
+	coffeesView:    "Coffees",
+	statisticsView: "Statistics",
+	addBrewingView: "Add brewing",
+}
+
+// ANSI escape codes used by the full-screen terminal UI
+const (
+	ansiAltScreen  = "\x1b[?1049h"
+	ansiMainScreen = "\x1b[?1049l"
+	ansiHideCursor = "\x1b[?25l"
+	ansiShowCursor = "\x1b[?25h"
+	ansiHome       = "\x1b[H"
+	ansiClearLine  = "\x1b[K"
+	ansiClearToEnd = "\x1b[J"
+	ansiReverse    = "\x1b[7m"
+	ansiRed        = "\x1b[31m"
+	ansiDim        = "\x1b[2m"
+	ansiReset      = "\x1b[0m"
+)
+
+const (
+	tuiListLimit      = 500 // Maximum number of brewings and coffees listed
+	tuiStatsInterval  = 5 * time.Second
+	tuiMinTermWidth   = 40
+	tuiMinTermHeight  = 10
+	tuiHeaderHeight   = 2
+	tuiFooterHeight   = 2
+	tuiFormLabelWidth = 42
+)
+
+type keyCode int
+
+const (
+	keyRune keyCode = iota
+	keyUp
+	keyDown
+	keyLeft
+	keyRight
+	keyPageUp
+	keyPageDown
+	keyHome
+	keyEnd
+	keyEnter
+	keyTab
+	keyBacktab
+	keyBackspace
+	keyEscape
+	keyCtrlC
+	keyCtrlS
+	keyUnknown
+)
+
+type key struct {
+	code keyCode
+	r    rune // Set for keyRune
+}
+
+// Escape sequences of the keys that send one
+var keySequences = map[string]keyCode{
+	"\x1b[A":  keyUp,
+	"\x1b[B":  keyDown,
+	"\x1b[C":  keyRight,
+	"\x1b[D":  keyLeft,
+	"\x1bOA":  keyUp,
+	"\x1bOB":  keyDown,
+	"\x1bOC":  keyRight,
+	"\x1bOD":  keyLeft,
+	"\x1b[5~": keyPageUp,
+	"\x1b[6~": keyPageDown,
+	"\x1b[H":  keyHome,
+	"\x1b[F":  keyEnd,
+	"\x1b[1~": keyHome,
+	"\x1b[4~": keyEnd,
+	"\x1b[Z":  keyBacktab,
+}
+
+// Parses the keys in input, which was read from a terminal in raw mode.
+func parseKeys(input []byte) []key {
+	var keys []key
+	for len(input) > 0 {
+		if input[0] == 0x1b {
+			if len(input) == 1 {
+				keys = append(keys, key{code: keyEscape})
+				break
+			}
+
+			matched := false
+			for seq, code := range keySequences {
+				if strings.HasPrefix(string(input), seq) {
+					keys = append(keys, key{code: code})
+					input = input[len(seq):]
+					matched = true
+					break
+				}
+			}
+			if !matched {
+				// Unknown sequences are dropped as a whole, as they can not be split into keys reliably
+				keys = append(keys, key{code: keyUnknown})
+				break
+			}
+			continue
+		}
+
+		switch input[0] {
+		case '\r', '\n':
+			keys = append(keys, key{code: keyEnter})
+		case '\t':
+			keys = append(keys, key{code: keyTab})
+		case 0x7f, 0x08:
+			keys = append(keys, key{code: keyBackspace})
+		case 0x03:
+			keys = append(keys, key{code: keyCtrlC})
+		case 0x13:
+			keys = append(keys, key{code: keyCtrlS})
+		default:
+			r, size := utf8.DecodeRune(input)
+			if unicode.IsPrint(r) {
+				keys = append(keys, key{code: keyRune, r: r})
+			}
+			input = input[size:]
+			continue
+		}
+		input = input[1:]
+	}
+
+	return keys
+}
+
+// A scrollable list of rows with a selected row
+type tuiList struct {
+	index  int // Index of the selected row
+	offset int // Index of the first displayed row
+}
+
+// Moves the selection by delta rows, keeping it within the count rows and on the height displayed rows.
+func (l *tuiList) move(delta int, count int, height int) {
+	l.index += delta
+	if l.index >= count {
+		l.index = count - 1
+	}
+	if l.index < 0 {
+		l.index = 0
+	}
+
+	if l.index < l.offset {
+		l.offset = l.index
+	}
+	if height > 0 && l.index >= l.offset+height {
+		l.offset = l.index - height + 1
+	}
+}
+
+// Statistics shown in the statistics view, which are refreshed periodically
+type tuiStatistics struct {
+	counts       []string
+	scores       brewingScoreAverages
+	timesOfDay   []brewingTimeStatistics
+	roasters     []roasterStatistics
+	refreshedAt  time.Time
+	refreshError error
+}
+
+// An add brewing form with an input for every field in brewingFields
+type tuiForm struct {
+	inputs      []string
+	errors      []string // Validation error of every input, "" if it is valid
+	focus       int      // Index of the focused input, len(brewingFields) being the save button
+	suggestions []string
+}
+
+func newTUIForm(ctx context.Context) tuiForm {
+	form := tuiForm{
+		inputs: make([]string, len(brewingFields)),
+		errors: make([]string, len(brewingFields)),
+	}
+	for i, f := range brewingFields {
+		form.inputs[i] = f.defaultValue(ctx)
+	}
+
+	return form
+}
+
+type tui struct {
+	ctx context.Context
+	db  DB
+
+	width  int
+	height int
+	view   tuiView
+	status string // Message shown in the footer until the next key
+
+	brewings       []brewing
+	brewingList    tuiList
+	showingDetails bool
+
+	coffees    []coffee
+	coffeeList tuiList
+
+	stats tuiStatistics
+	form  tuiForm
+}
+
+// RunTUI runs the full-screen terminal UI, an alternative to the numbered menus of Run.
+// It shows navigable lists of brewings and coffees, live-updating statistics and a form for adding brewings.
+func RunTUI(ctx context.Context, db DB, cfg Config) error {
+	ctx, err := startSession(ctx, db, cfg)
+	if err != nil {
+		return fmt.Errorf("buna: tui: failed to start session: %w", err)
+	}
+
+	ctx, err = withDefaultUser(ctx, db)
+	if err != nil {
+		return fmt.Errorf("buna: tui: failed to select default user: %w", err)
+	}
+
+	fd := int(os.Stdin.Fd())
+	if !terminal.IsTerminal(fd) {
+		return errors.New("buna: tui: the full-screen UI needs a terminal")
+	}
+
+	oldState, err := terminal.MakeRaw(fd)
+	if err != nil {
+		return fmt.Errorf("buna: tui: failed to put terminal into raw mode: %w", err)
+	}
+	defer terminal.Restore(fd, oldState) // nolint:errcheck
+
+	fmt.Print(ansiAltScreen + ansiHideCursor)
+	defer fmt.Print(ansiShowCursor + ansiMainScreen)
+
+	t := &tui{ctx: ctx, db: db, form: newTUIForm(ctx)}
+	if err := t.reload(); err != nil {
+		return fmt.Errorf("buna: tui: failed to load brewings and coffees: %w", err)
+	}
+	t.refreshStatistics()
+
+	keys := make(chan []key)
+	readErrs := make(chan error, 1)
+	go func() {
+		buf := make([]byte, 64)
+		for {
+			n, err := os.Stdin.Read(buf)
+			if err != nil {
+				readErrs <- err
+				return
+			}
+			keys <- parseKeys(buf[:n])
+		}
+	}()
+
+	ticker := time.NewTicker(tuiStatsInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := t.render(); err != nil {
+			return fmt.Errorf("buna: tui: failed to render: %w", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case err := <-readErrs:
+			return fmt.Errorf("buna: tui: failed to read keys: %w", err)
+		case <-ticker.C:
+			t.refreshStatistics()
+		case ks := <-keys:
+			for _, k := range ks {
+				quit, err := t.handleKey(k)
+				if err != nil {
+					return fmt.Errorf("buna: tui: failed to handle key: %w", err)
+				}
+				if quit {
+					return nil
+				}
+			}
+		}
+	}
+}
+
+// Loads the brewings and coffees that are listed.
+func (t *tui) reload() error {
+	brewings, err := t.db.getBrewingsOrderByDesc(t.ctx, nil, tuiListLimit, "date")
+	if err != nil {
+		return fmt.Errorf("buna: tui: failed to get brewings by date: %w", err)
+	}
+	t.brewings = brewings
+	t.brewingList.move(0, len(t.brewings), t.bodyHeight())
+
+	coffees, err := t.db.getCoffeesAlphabetically(t.ctx, nil, tuiListLimit)
+	if err != nil {
+		return fmt.Errorf("buna: tui: failed to get coffees alphabetically: %w", err)
+	}
+	t.coffees = coffees
+	t.coffeeList.move(0, len(t.coffees), t.bodyHeight())
+
+	return nil
+}
+
+// Refreshes the statistics, which can change while the UI runs, e.g. when brewings are added from the command line.
+// Errors are shown in the statistics view instead of ending the UI.
+func (t *tui) refreshStatistics() {
+	stats := tuiStatistics{refreshedAt: time.Now()}
+	defer func() { t.stats = stats }()
+
+	for _, entity := range []dbEntity{brewings, coffees, cuppings, roasters, grinders, brewingMethods} {
+		count, err := t.db.getTotalCount(t.ctx, entity)
+		if err != nil {
+			stats.refreshError = fmt.Errorf("buna: tui: failed to get total count: %w", err)
+			return
+		}
+		stats.counts = append(stats.counts, fmt.Sprintf("%v: %d", strings.Title(strings.ReplaceAll(dbEntityToStringMap[entity], "_", " ")), count))
+	}
+
+	var err error
+	stats.scores, err = t.db.getAverageBrewingScores(t.ctx, brewing{}, ratioBand{})
+	if err != nil {
+		stats.refreshError = fmt.Errorf("buna: tui: failed to get average brewing scores: %w", err)
+		return
+	}
+
+	stats.timesOfDay, err = t.db.getTimeOfDayStatistics(t.ctx, brewing{}, ratioBand{})
+	if err != nil {
+		stats.refreshError = fmt.Errorf("buna: tui: failed to get time of day statistics: %w", err)
+		return
+	}
+
+	stats.roasters, err = t.db.getRoasterStatistics(t.ctx)
+	if err != nil {
+		stats.refreshError = fmt.Errorf("buna: tui: failed to get roaster statistics: %w", err)
+		return
+	}
+}
+
+// Returns the number of lines between the header and the footer.
+func (t *tui) bodyHeight() int {
+	return t.height - tuiHeaderHeight - tuiFooterHeight
+}
+
+// Returns didQuit, error
+func (t *tui) handleKey(k key) (bool, error) {
+	t.status = ""
+
+	if k.code == keyCtrlC {
+		return true, nil
+	}
+
+	if t.view == addBrewingView {
+		return false, t.handleFormKey(k)
+	}
+
+	switch {
+	case k.code == keyRune && k.r == 'q':
+		return true, nil
+	case k.code == keyRune && k.r >= '1' && int(k.r-'1') < len(tuiViewNames):
+		t.switchView(tuiView(k.r - '1'))
+	case k.code == keyRight || k.code == keyTab:
+		t.switchView((t.view + 1) % tuiView(len(tuiViewNames)))
+	case k.code == keyLeft || k.code == keyBacktab:
+		t.switchView((t.view + tuiView(len(tuiViewNames)) - 1) % tuiView(len(tuiViewNames)))
+	case k.code == keyRune && k.r == 'a':
+		t.switchView(addBrewingView)
+	case k.code == keyRune && k.r == 'r':
+		if err := t.reload(); err != nil {
+			return false, fmt.Errorf("buna: tui: failed to reload: %w", err)
+		}
+		t.refreshStatistics()
+		t.status = "Refreshed"
+	default:
+		t.handleListKey(k)
+	}
+
+	return false, nil
+}
+
+func (t *tui) switchView(view tuiView) {
+	t.view = view
+	t.showingDetails = false
+	if view == addBrewingView {
+		t.updateSuggestions()
+	}
+}
+
+// Moves the selection of the list of the current view.
+func (t *tui) handleListKey(k key) {
+	var list *tuiList
+	var count int
+	switch t.view {
+	case brewingsView:
+		list, count = &t.brewingList, len(t.brewings)
+	case coffeesView:
+		list, count = &t.coffeeList, len(t.coffees)
+	default:
+		return
+	}
+
+	// The list header takes a line of the body
+	height := t.bodyHeight() - 1
+	switch {
+	case k.code == keyUp || k.code == keyRune && k.r == 'k':
+		list.move(-1, count, height)
+	case k.code == keyDown || k.code == keyRune && k.r == 'j':
+		list.move(1, count, height)
+	case k.code == keyPageUp:
+		list.move(-height, count, height)
+	case k.code == keyPageDown:
+		list.move(height, count, height)
+	case k.code == keyHome || k.code == keyRune && k.r == 'g':
+		list.move(-count, count, height)
+	case k.code == keyEnd || k.code == keyRune && k.r == 'G':
+		list.move(count, count, height)
+	case k.code == keyEnter && t.view == brewingsView && count > 0:
+		t.showingDetails = !t.showingDetails
+	case k.code == keyEscape:
+		t.showingDetails = false
+	}
+}
+
+func (t *tui) handleFormKey(k key) error {
+	f := &t.form
+	switch k.code {
+	case keyEscape:
+		t.switchView(brewingsView)
+	case keyTab, keyDown:
+		f.focus = (f.focus + 1) % (len(brewingFields) + 1)
+		t.updateSuggestions()
+	case keyBacktab, keyUp:
+		f.focus = (f.focus + len(brewingFields)) % (len(brewingFields) + 1)
+		t.updateSuggestions()
+	case keyEnter:
+		if f.focus < len(brewingFields) {
+			f.focus++
+			t.updateSuggestions()
+			return nil
+		}
+		return t.saveForm()
+	case keyCtrlS:
+		return t.saveForm()
+	case keyBackspace:
+		if f.focus < len(brewingFields) {
+			input := []rune(f.inputs[f.focus])
+			if len(input) > 0 {
+				f.inputs[f.focus] = string(input[:len(input)-1])
+			}
+			t.validateInput(f.focus)
+		}
+	case keyRune:
+		if f.focus < len(brewingFields) {
+			f.inputs[f.focus] += string(k.r)
+			t.validateInput(f.focus)
+		}
+	}
+
+	return nil
+}
+
+// Validates the input as it is typed. Missing required values are only reported when saving.
+func (t *tui) validateInput(i int) {
+	t.form.errors[i] = ""
+	if strings.TrimSpace(t.form.inputs[i]) == "" {
+		return
+	}
+
+	if _, err := brewingFields[i].parse(t.ctx, t.form.inputs[i]); err != nil {
+		t.form.errors[i] = fieldErrorMsg(err)
+	}
+}
+
+// Returns the values of the valid inputs of the form, which suggestions depend on.
+func (t *tui) formValues() fieldValues {
+	values := fieldValues{}
+	for i, f := range brewingFields {
+		if value, err := f.parse(t.ctx, t.form.inputs[i]); err == nil {
+			values[f.name] = value
+		}
+	}
+
+	return values
+}
+
+// Looks up the suggestions of the focused field.
+func (t *tui) updateSuggestions() {
+	t.form.suggestions = nil
+	if t.form.focus >= len(brewingFields) {
+		return
+	}
+
+	f := brewingFields[t.form.focus]
+	if f.suggest == nil {
+		t.form.suggestions = f.options
+		return
+	}
+
+	suggestions, err := f.suggest(t.ctx, t.db, t.formValues())
+	if err != nil {
+		t.status = "Failed to get suggestions"
+		return
+	}
+
+	switch s := suggestions.(type) {
+	case []string:
+		t.form.suggestions = s
+	case []int:
+		for _, i := range s {
+			t.form.suggestions = append(t.form.suggestions, fmt.Sprint(i))
+		}
+	case []float64:
+		units := sessionFromContext(t.ctx).unitSystem
+		for _, q := range s {
+			t.form.suggestions = append(t.form.suggestions, units.formatQuantityWithUnit(q, f.quantity))
+		}
+	case []date:
+		for _, d := range s {
+			t.form.suggestions = append(t.form.suggestions, createDateString(d))
+		}
+	}
+}
+
+// Validates all inputs and adds the brewing if they are valid.
+func (t *tui) saveForm() error {
+	values := fieldValues{}
+	valid := true
+	for i, f := range brewingFields {
+		value, err := f.parse(t.ctx, t.form.inputs[i])
+		if err != nil {
+			t.form.errors[i] = fieldErrorMsg(err)
+			valid = false
+			continue
+		}
+		values[f.name] = value
+	}
+	if !valid {
+		t.status = "Please correct the highlighted fields"
+		return nil
+	}
+
+	b := brewingFromFieldValues(values)
+	if b.date == "" {
+		b.date = time.Now().Format(time.RFC3339)
+	}
+
+	if err := checkBrewingReferences(t.ctx, t.db, b); err != nil {
+		t.status = fieldErrorMsg(err)
+		return nil
+	}
+
+	if err := t.db.insertBrewing(t.ctx, b); err != nil {
+		return fmt.Errorf("buna: tui: failed to insert brewing: %w", err)
+	}
+
+	if err := t.reload(); err != nil {
+		return fmt.Errorf("buna: tui: failed to reload: %w", err)
+	}
+	t.refreshStatistics()
+
+	t.form = newTUIForm(t.ctx)
+	t.switchView(brewingsView)
+	t.status = "Added coffee brewing successfully"
+
+	return nil
+}
+
+// Returns the message of a validation error without the prefixes of the wrapping functions.
+func fieldErrorMsg(err error) string {
+	msg := err.Error()
+	if i := strings.LastIndex(msg, "buna: "); i >= 0 {
+		msg = msg[i+len("buna: "):]
+		if j := strings.Index(msg, ": "); j >= 0 {
+			msg = msg[j+2:]
+		}
+	}
+
+	return msg
+}
+
+func (t *tui) render() error {
+	width, height, err := terminal.GetSize(int(os.Stdin.Fd()))
+	if err != nil {
+		return fmt.Errorf("buna: tui: failed to get terminal size: %w", err)
+	}
+	t.width, t.height = width, height
+
+	var sb strings.Builder
+	sb.WriteString(ansiHome)
+
+	if width < tuiMinTermWidth || height < tuiMinTermHeight {
+		sb.WriteString(fitLine("Terminal too small", width) + ansiClearToEnd)
+		fmt.Print(sb.String())
+		return nil
+	}
+
+	var tabs strings.Builder
+	for i, name := range tuiViewNames {
+		tab := fmt.Sprintf(" %d %v ", i+1, name)
+		if tuiView(i) == t.view {
+			tab = ansiReverse + tab + ansiReset
+		}
+		tabs.WriteString(tab + " ")
+	}
+	lines := []string{tabs.String(), strings.Repeat("─", width)}
+
+	var body []string
+	switch t.view {
+	case brewingsView:
+		if t.showingDetails {
+			body = t.brewingDetailLines()
+		} else {
+			body = t.brewingListLines()
+		}
+	case coffeesView:
+		body = t.coffeeListLines()
+	case statisticsView:
+		body = t.statisticsLines()
+	case addBrewingView:
+		body = t.formLines()
+	}
+	for i := 0; i < t.bodyHeight(); i++ {
+		line := ""
+		if i < len(body) {
+			line = body[i]
+		}
+		lines = append(lines, line)
+	}
+
+	lines = append(lines, strings.Repeat("─", width), t.footer())
+
+	for i, line := range lines {
+		// The tabs and styled lines are already short enough, as the escape codes do not take up columns
+		if !strings.Contains(line, "\x1b[") {
+			line = fitLine(line, width)
+		}
+		sb.WriteString(line + ansiClearLine)
+		if i < len(lines)-1 {
+			sb.WriteString("\r\n")
+		}
+	}
+	sb.WriteString(ansiClearToEnd)
+
+	fmt.Print(sb.String())
+	return nil
+}
+
+func (t *tui) footer() string {
+	if t.status != "" {
+		return t.status
+	}
+
+	switch {
+	case t.view == addBrewingView:
+		return "Tab/↓ next  Shift-Tab/↑ previous  Enter next/save  Ctrl-S save  Esc back  Ctrl-C quit"
+	case t.view == brewingsView && t.showingDetails:
+		return "Enter/Esc back to list  ←/→ switch view  q quit"
+	case t.view == statisticsView:
+		return fmt.Sprintf("Refreshed %v, every %v  r refresh  ←/→ or 1-4 switch view  q quit", t.stats.refreshedAt.Format("15:04:05"), tuiStatsInterval)
+	default:
+		return "↑/↓ move  PgUp/PgDn page  Enter details  a add brewing  r refresh  ←/→ or 1-4 switch view  q quit"
+	}
+}
+
+// Returns the rows of the list that fit into the body below its header, marking the selected row.
+func listLines(header string, rows []string, list tuiList, height int) []string {
+	lines := []string{ansiDim + header + ansiReset}
+	if len(rows) == 0 {
+		return append(lines, "Nothing to display yet")
+	}
+
+	for i := list.offset; i < len(rows) && i < list.offset+height-1; i++ {
+		if i == list.index {
+			lines = append(lines, ansiReverse+rows[i]+ansiReset)
+			continue
+		}
+		lines = append(lines, rows[i])
+	}
+
+	return lines
+}
+
+func (t *tui) brewingListLines() []string {
+	const format = "%-16v  %-34v  %-14v  %-8v  %-7v"
+
+	rows := make([]string, len(t.brewings))
+	for i, b := range t.brewings {
+		rows[i] = fitLine(fmt.Sprintf(format,
+			formatTimestamp(b.date, b.timeKnown),
+			truncate(b.coffeeName+" ("+b.coffeeRoaster+")", 34),
+			truncate(b.brewingMethodName, 14),
+			formatBrewRatio(b),
+			formatRating(b.rating),
+		), t.width)
+	}
+
+	header := fitLine(fmt.Sprintf(format, "Date", "Coffee", "Method", "Ratio", "Rating"), t.width)
+	return listLines(header, rows, t.brewingList, t.bodyHeight())
+}
+
+func (t *tui) brewingDetailLines() []string {
+	units := sessionFromContext(t.ctx).unitSystem
+	b := t.brewings[t.brewingList.index]
+
+	restDaysField := "Unknown"
+	if days, ok := restDays(b); ok {
+		restDaysField = fmt.Sprintf("%.0f", days)
+	}
+
+	fields := [][2]string{
+		{"ID", fmt.Sprint(b.id)},
+		{"Date", formatTimestamp(b.date, b.timeKnown)},
+		{"Coffee", b.coffeeName + " (" + b.coffeeRoaster + ")"},
+		{"Brewing method", b.brewingMethodName},
+		{"V60 filter type", b.v60FilterType},
+		{"Grinder", b.grinderName},
+		{"Grind setting", fmt.Sprint(b.grindSetting)},
+		{"Total brewing time (s)", fmt.Sprint(b.totalBrewingTimeSec)},
+		{"Coffee weight", units.formatQuantityWithUnit(b.coffeeGrams, coffeeWeight)},
+		{"Water weight", units.formatQuantityWithUnit(b.waterGrams, waterWeight)},
+		{"Brew ratio", formatBrewRatio(b)},
+		{"Roast date", b.roastDate},
+		{"Rest days", restDaysField},
+		{"Rating", formatRating(b.rating)},
+		{"Scores", formatBrewingScores(b.scores)},
+		{"Extraction", b.extraction},
+		{"Recommended grind adjustment", b.recommendedGrindSettingAdjustment},
+		{"Recommended coffee adjustment", units.formatQuantityChange(b.recommendedCoffeeWeightAdjustmentGrams, coffeeWeight)},
+		{"Flavors", strings.Join(b.flavors, ", ")},
+	}
+
+	var lines []string
+	for _, field := range fields {
+		lines = append(lines, fmt.Sprintf("%-30v %v", field[0], field[1]))
+	}
+
+	notes := strings.Split(splitTextIntoField(b.notes, t.width-31), "\n")
+	for i, line := range notes {
+		label := ""
+		if i == 0 {
+			label = "Notes"
+		}
+		lines = append(lines, fmt.Sprintf("%-30v %v", label, line))
+	}
+
+	return lines
+}
+
+func (t *tui) coffeeListLines() []string {
+	const format = "%-24v  %-20v  %-24v  %-16v  %-8v  %-10v"
+
+	rows := make([]string, len(t.coffees))
+	for i, c := range t.coffees {
+		rows[i] = fitLine(fmt.Sprintf(format,
+			truncate(c.name, 24),
+			truncate(c.roaster, 20),
+			truncate(formatOrigin(c.region, c.countryCode), 24),
+			truncate(formatProcess(c.process, c.processOther), 16),
+			c.brewingCount,
+			formatAverageRating(c.averageRating),
+		), t.width)
+	}
+
+	header := fitLine(fmt.Sprintf(format, "Name", "Roaster", "Origin", "Process", "Brewings", "Avg rating"), t.width)
+	return listLines(header, rows, t.coffeeList, t.bodyHeight())
+}
+
+func (t *tui) statisticsLines() []string {
+	const maxRoasters = 5
+
+	if t.stats.refreshError != nil {
+		return []string{ansiRed + fitLine(t.stats.refreshError.Error(), t.width) + ansiReset}
+	}
+
+	lines := []string{"Totals", "  " + strings.Join(t.stats.counts, "  "), ""}
+
+	s := t.stats.scores
+	lines = append(lines, "Average brewing scores")
+	if s.brewingCount == 0 {
+		lines = append(lines, "  No rated brewings yet")
+	} else {
+		var parts []string
+		for _, score := range []struct {
+			name    string
+			average float64
+		}{
+			{"Rating", s.rating},
+			{"Sweetness", s.sweetness},
+			{"Acidity", s.acidity},
+			{"Bitterness", s.bitterness},
+			{"Body", s.body},
+			{"Clarity", s.clarity},
+			{"Astringency", s.astringency},
+		} {
+			average := "-"
+			if score.average != 0 {
+				average = fmt.Sprintf("%.1f", score.average)
+			}
+			parts = append(parts, score.name+" "+average)
+		}
+		lines = append(lines, "  "+strings.Join(parts, "  "))
+	}
+	lines = append(lines, "")
+
+	lines = append(lines, "Ratings by time of day")
+	for _, stats := range t.stats.timesOfDay {
+		averageRating := "-"
+		if stats.ratedCount > 0 {
+			averageRating = fmt.Sprintf("%.1f/10", stats.averageRating)
+		}
+		lines = append(lines, fmt.Sprintf("  %-22v %4d brewings  %v", stats.name, stats.brewingCount, averageRating))
+	}
+	lines = append(lines, "")
+
+	lines = append(lines, "Roasters")
+	for i, stats := range t.stats.roasters {
+		if i == maxRoasters {
+			break
+		}
+		lines = append(lines, fmt.Sprintf("  %-24v %3d coffees  %3d purchases  %v", truncate(stats.roasterName, 24), stats.coffeeCount, stats.purchaseCount, formatAverageRating(stats.averageRating)))
+	}
+
+	return lines
+}
+
+func (t *tui) formLines() []string {
+	lines := []string{"New coffee brewing", ""}
+
+	for i, f := range brewingFields {
+		label := f.key
+		if !f.optional {
+			label += " *"
+		}
+
+		input := t.form.inputs[i]
+		if i == t.form.focus {
+			input += "_"
+		}
+
+		line := fmt.Sprintf("%-*v %v", tuiFormLabelWidth, truncate(label, tuiFormLabelWidth), input)
+		line = fitLine(line, t.width)
+		if i == t.form.focus {
+			line = ansiReverse + line + ansiReset
+		}
+		if t.form.errors[i] != "" {
+			line = ansiRed + fitLine(fmt.Sprintf("%-*v %v  %v", tuiFormLabelWidth, truncate(label, tuiFormLabelWidth), input, t.form.errors[i]), t.width) + ansiReset
+		}
+		lines = append(lines, line)
+	}
+
+	save := "[ Save ]"
+	if t.form.focus == len(brewingFields) {
+		save = ansiReverse + save + ansiReset
+	}
+	lines = append(lines, "", save, "")
+
+	if t.form.focus < len(brewingFields) {
+		f := brewingFields[t.form.focus]
+		hint := f.help
+		if bounds := f.bounds(t.ctx); bounds != "" {
+			hint += " (" + bounds + ")"
+		}
+		lines = append(lines, ansiDim+fitLine(hint, t.width)+ansiReset)
+		if len(t.form.suggestions) > 0 {
+			lines = append(lines, fitLine("Suggestions: "+strings.Join(t.form.suggestions, ", "), t.width))
+		}
+	}
+
+	return lines
+}
+
+// Returns s cut or padded with spaces to width columns.
+func fitLine(s string, width int) string {
+	runes := []rune(s)
+	if len(runes) > width {
+		return string(runes[:width])
+	}
+
+	return s + strings.Repeat(" ", width-len(runes))
+}
+
+// Returns s cut to at most width columns, ending with "…" if it was cut.
+func truncate(s string, width int) string {
+	runes := []rune(s)
+	if len(runes) <= width {
+		return s
+	}
+
+	return string(runes[:width-1]) + "…"
+}
@@ -29,10 +29,7 @@ const (
 	control
 )
 
-const (
-	quitStr = "#"
-	quitMsg = "Quit"
-)
+const quitMsg = "Quit"
 
 var (
 	categoryRefs = map[category]string{
@@ -50,6 +47,8 @@ var (
 			4: "New coffee",
 			5: "New brewing method",
 			6: "New grinder",
+			7: "New roaster",
+			8: "New user",
 		},
 		retrieve: map[int]string{
 			0: "Retrive brewing",
@@ -58,15 +57,27 @@ var (
 			3: "Retrieve coffee",
 			4: "Retrieve brewing method",
 			5: "Retrieve grinder",
+			6: "Retrieve roaster",
+			7: "Search notes",
+			8: "Drafts",
 		},
 		statistics: map[int]string{
 			0: "Total count",
 			1: "Average brewing rating",
+			2: "Roaster statistics",
+			3: "Flavor statistics",
+			4: "Rating correlations",
+			5: "Brewing trends",
+			6: "Ratings by brew ratio band",
+			7: "Ratings by time of day and weekday",
+			8: "Compare users' ratings",
 		},
 		control: map[int]string{
 			0: "Quit",
 			1: "Clear screen",
 			2: "Display options",
+			3: "Set unit system",
+			4: "Switch profile",
 		},
 	}
 )
@@ -94,15 +105,33 @@ func init() {
 	}
 }
 
-func Run(ctx context.Context, db DB) error {
-	if err := displayOptions(); err != nil {
+func Run(ctx context.Context, db DB, cfg Config) error {
+	ctx, err := startSession(ctx, db, cfg)
+	if err != nil {
+		return fmt.Errorf("buna: ui: failed to start session: %w", err)
+	}
+
+	if err := promptRoasterMerges(ctx, db); err != nil {
+		return fmt.Errorf("buna: ui: failed to prompt for roaster merges: %w", err)
+	}
+
+	ctx, err = withDefaultUser(ctx, db)
+	if err != nil {
+		return fmt.Errorf("buna: ui: failed to select default user: %w", err)
+	}
+
+	ctx, err = selectUser(ctx, db)
+	if err != nil {
+		return fmt.Errorf("buna: ui: failed to select user: %w", err)
+	}
+
+	if err := displayOptions(ctx); err != nil {
 		return fmt.Errorf("buna: ui: failed to display main options: %w", err)
 	}
 
 	var selection selection
-	var err error
 	for {
-		selection, err = getSelection()
+		selection, err = getSelection(ctx)
 		if err != nil {
 			return fmt.Errorf("buna: ui: failed to get main selection: %w", err)
 		}
@@ -112,16 +141,29 @@ func Run(ctx context.Context, db DB) error {
 			break
 		}
 
+		// Switching the profile changes the context of all following selections
+		if selection.category == control && selection.index == 4 {
+			ctx, err = switchProfile(ctx, db)
+			if err != nil {
+				return fmt.Errorf("buna: ui: failed to switch profile: %w", err)
+			}
+			sessionFromContext(ctx).input.queued = nil
+			continue
+		}
+
 		if err := runSelection(ctx, selection, db); err != nil {
 			return fmt.Errorf("buna: ui: failed to run the selection: %w", err)
 		}
+
+		// Answers that were not asked for must not leak into the main prompt
+		sessionFromContext(ctx).input.queued = nil
 	}
 
 	fmt.Println("Bye, keep enjoying your coffee!")
 	return nil
 }
 
-func displayOptions() error {
+func displayOptions(ctx context.Context) error {
 	t := table.NewWriter()
 
 	var header table.Row
@@ -153,61 +195,62 @@ func displayOptions() error {
 	t.SetAllowedRowLength(terminalWidth)
 
 	t.SetOutputMirror(os.Stdout)
-	t.Render()
+	renderTable(ctx, t)
 
 	return nil
 }
 
-func getSelection() (selection, error) {
-	retry := func() error {
-		fmt.Println("Invalid option. The following options are available:")
-		if err := displayOptions(); err != nil {
-			return fmt.Errorf("buna: ui: failed to display main options: %w", err)
-		}
-
-		return nil
+// Renders the table in the output format and colors of the config of the session.
+func renderTable(ctx context.Context, t table.Writer) {
+	cfg := sessionFromContext(ctx).config
+	switch cfg.text("display.colors") {
+	case "dark":
+		t.SetStyle(table.StyleColoredDark)
+	case "bright":
+		t.SetStyle(table.StyleColoredBright)
 	}
 
-	for {
-		fmt.Print("Enter main option: ")
-		var input string
-		fmt.Scanln(&input)
-		input = strings.ToUpper(input)
+	switch cfg.text("display.output") {
+	case "markdown":
+		t.RenderMarkdown()
+	case "csv":
+		t.RenderCSV()
+	default:
+		t.Render()
+	}
+}
 
-		if len(input) != 2 {
-			if err := retry(); err != nil {
-				return selection{}, fmt.Errorf("buna: ui: failed to display main options (retry): %w", err)
-			}
-			continue
-		}
+// Reads the main option, given either as its code like "A0", an alias from the config or words of its description
+// like "new brew". Anything following the option, e.g. the 1 in "B0 1", is queued as answers to its prompts.
+func getSelection(ctx context.Context) (selection, error) {
+	session := sessionFromContext(ctx)
+	completions := paletteCompletions(session.config)
 
-		cat, err := getCategoryByString(input[:1])
+	for {
+		input, quit, err := readPaletteInput(session.input, "Enter main option: ", completions)
 		if err != nil {
-			if err := retry(); err != nil {
-				return selection{}, fmt.Errorf("buna: ui: failed to display main options (retry): %w", err)
-			}
-			continue
+			return selection{}, fmt.Errorf("buna: ui: failed to read main option: %w", err)
 		}
-
-		idx, err := strconv.Atoi(input[1:])
-		if err != nil {
-			if err := retry(); err != nil {
-				return selection{}, fmt.Errorf("buna: ui: failed to display main options (retry): %w", err)
-			}
+		if quit {
+			return selection{category: control, index: 0}, nil
+		}
+		if strings.TrimSpace(input) == "" {
 			continue
 		}
 
-		if _, ok := options[cat][idx]; !ok {
-			if err := retry(); err != nil {
-				return selection{}, fmt.Errorf("buna: ui: failed to display main options (retry): %w", err)
+		commands, answers := resolvePaletteInput(session.config, input)
+		switch len(commands) {
+		case 0:
+			fmt.Printf("No option matches %q. Enter E2 to display all options.\n", input)
+		case 1:
+			session.input.queued = answers
+			return commands[0].selection, nil
+		default:
+			fmt.Println("Did you mean:")
+			for _, command := range commands {
+				fmt.Printf("  %s  %s\n", command.code, command.name)
 			}
-			continue
 		}
-
-		return selection{
-			category: cat,
-			index:    idx,
-		}, nil
 	}
 }
 
@@ -232,17 +275,25 @@ func runSelection(ctx context.Context, selection selection, db DB) error {
 				return fmt.Errorf("buna: ui: failed to create new coffee purchase: %w", err)
 			}
 		case 4:
-			if _, err := addCoffee(ctx, db); err != nil {
+			if _, _, err := addCoffee(ctx, db, "", ""); err != nil {
 				return fmt.Errorf("buna: ui: failed to create new coffee: %w", err)
 			}
 		case 5:
-			if err := addBrewingMethod(ctx, db); err != nil {
+			if _, err := addBrewingMethod(ctx, db, ""); err != nil {
 				return fmt.Errorf("buna: ui: failed to create new coffee brewing method: %w", err)
 			}
 		case 6:
-			if err := addGrinder(ctx, db); err != nil {
+			if _, err := addGrinder(ctx, db, ""); err != nil {
 				return fmt.Errorf("buna: ui: failed to create new coffee grinder: %w", err)
 			}
+		case 7:
+			if _, err := addRoaster(ctx, db, ""); err != nil {
+				return fmt.Errorf("buna: ui: failed to create new roaster: %w", err)
+			}
+		case 8:
+			if err := addUser(ctx, db); err != nil {
+				return fmt.Errorf("buna: ui: failed to create new user: %w", err)
+			}
 		default:
 			return errors.New("buna: ui: invalid create index")
 		}
@@ -272,6 +323,18 @@ func runSelection(ctx context.Context, selection selection, db DB) error {
 			if err := retrieveGrinder(ctx, db); err != nil {
 				return fmt.Errorf("buna: ui: failed to retrieve grinder: %w", err)
 			}
+		case 6:
+			if err := retrieveRoaster(ctx, db); err != nil {
+				return fmt.Errorf("buna: ui: failed to retrieve roaster: %w", err)
+			}
+		case 7:
+			if err := searchNotes(ctx, db); err != nil {
+				return fmt.Errorf("buna: ui: failed to search notes: %w", err)
+			}
+		case 8:
+			if err := displayDrafts(ctx, db); err != nil {
+				return fmt.Errorf("buna: ui: failed to display drafts: %w", err)
+			}
 		default:
 			return errors.New("buna: ui: invalid retrieve index")
 		}
@@ -285,22 +348,54 @@ func runSelection(ctx context.Context, selection selection, db DB) error {
 			if err := getAverageBrewingRating(ctx, db); err != nil {
 				return fmt.Errorf("buna: ui: failed to get average brewing rating: %w", err)
 			}
+		case 2:
+			if err := getRoasterStatistics(ctx, db); err != nil {
+				return fmt.Errorf("buna: ui: failed to get roaster statistics: %w", err)
+			}
+		case 3:
+			if err := retrieveFlavorStatistics(ctx, db); err != nil {
+				return fmt.Errorf("buna: ui: failed to retrieve flavor statistics: %w", err)
+			}
+		case 4:
+			if err := displayRatingCorrelations(ctx, db); err != nil {
+				return fmt.Errorf("buna: ui: failed to display rating correlations: %w", err)
+			}
+		case 5:
+			if err := displayBrewingTrends(ctx, db); err != nil {
+				return fmt.Errorf("buna: ui: failed to display brewing trends: %w", err)
+			}
+		case 6:
+			if err := getRatioBandStatistics(ctx, db); err != nil {
+				return fmt.Errorf("buna: ui: failed to get ratio band statistics: %w", err)
+			}
+		case 7:
+			if err := getBrewingTimeStatistics(ctx, db); err != nil {
+				return fmt.Errorf("buna: ui: failed to get brewing time statistics: %w", err)
+			}
+		case 8:
+			if err := compareUserRatings(ctx, db); err != nil {
+				return fmt.Errorf("buna: ui: failed to compare user ratings: %w", err)
+			}
 		default:
 			return errors.New("buna: ui: invalid statistics index")
 		}
 	case control:
 		switch selection.index {
-		case 0:
-			// Special case
+		case 0, 4:
+			// Special cases
 			// Already handled in Run()
 		case 1:
 			if err := clearTerminalScreen(); err != nil {
 				return fmt.Errorf("buna: ui: failed to clear terminal screen: %w", err)
 			}
 		case 2:
-			if err := displayOptions(); err != nil {
+			if err := displayOptions(ctx); err != nil {
 				return fmt.Errorf("buna: ui: failed to display main options: %w", err)
 			}
+		case 3:
+			if err := setUnitSystem(ctx, db); err != nil {
+				return fmt.Errorf("buna: ui: failed to set unit system: %w", err)
+			}
 		default:
 			return errors.New("buna: ui: control index")
 		}
@@ -334,12 +429,3 @@ func getLongestCategoryLength() int {
 
 	return longest
 }
-
-func getCategoryByString(str string) (category, error) {
-	for cat, val := range categoryRefs {
-		if val == str {
-			return cat, nil
-		}
-	}
-	return 0, errors.New("buna: ui: string does not correspond to category")
-}
@@ -0,0 +1,209 @@
+package buna
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Unit system used for input and display.
+// Values are always stored in SI units (grams) and only converted at the input and display boundaries.
+type unitSystem int
+
+const (
+	metricUnits unitSystem = iota
+	imperialUnits
+)
+
+var unitSystemToName = map[unitSystem]string{
+	metricUnits:   "metric",
+	imperialUnits: "imperial",
+}
+
+// Name of the preference that stores the unit system
+const unitSystemPreference = "unit_system"
+
+// A kind of value that is entered and displayed in the unit of the preferred unit system.
+type quantity int
+
+const (
+	coffeeWeight quantity = iota
+	waterWeight
+)
+
+// A unit that is converted to its SI unit by siValue = value*factor.
+type unit struct {
+	symbol string
+	// Lower case suffixes that select the unit in inputs
+	suffixes []string
+	factor   float64
+	// Number of decimals displayed
+	decimals int
+}
+
+var (
+	grams       = unit{"g", []string{"g", "gram", "grams"}, 1, 1}
+	kilograms   = unit{"kg", []string{"kg"}, 1000, 3}
+	ounces      = unit{"oz", []string{"oz", "ounce", "ounces"}, 28.349523125, 2}
+	pounds      = unit{"lb", []string{"lb", "lbs"}, 453.59237, 3}
+	millilitres = unit{"ml", []string{"ml"}, 1, 0}
+	litres      = unit{"l", []string{"l"}, 1000, 3}
+	fluidOunces = unit{"fl oz", []string{"fl oz", "floz"}, 29.5735295625, 2}
+)
+
+var (
+	// Units accepted as input suffixes for each quantity.
+	// Water can also be measured by volume, as a millilitre of water weighs a gram.
+	quantityUnits = map[quantity][]unit{
+		coffeeWeight: {grams, kilograms, ounces, pounds},
+		waterWeight:  {grams, kilograms, ounces, pounds, millilitres, litres, fluidOunces},
+	}
+
+	// Units every quantity is entered and displayed in by default
+	unitSystemUnits = map[unitSystem]map[quantity]unit{
+		metricUnits: {
+			coffeeWeight: grams,
+			waterWeight:  grams,
+		},
+		imperialUnits: {
+			coffeeWeight: ounces,
+			waterWeight:  ounces,
+		},
+	}
+)
+
+// Returns the unit the quantity is entered and displayed in.
+func (s unitSystem) unit(q quantity) unit {
+	return unitSystemUnits[s][q]
+}
+
+// Returns the unit system with the name.
+func parseUnitSystem(name string) (unitSystem, bool) {
+	for system, systemName := range unitSystemToName {
+		if strings.EqualFold(strings.TrimSpace(name), systemName) {
+			return system, true
+		}
+	}
+	return 0, false
+}
+
+// Returns the unit system of the stored preference. The unit system of cfg is used if there is none.
+func getUnitSystemPreference(ctx context.Context, db DB, cfg Config) (unitSystem, error) {
+	name, err := db.getPreference(ctx, unitSystemPreference)
+	if errors.Is(err, sql.ErrNoRows) {
+		name, err = cfg.text("defaults.units"), nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("buna: units: failed to get unit system preference: %w", err)
+	}
+
+	system, ok := parseUnitSystem(name)
+	if !ok {
+		return 0, fmt.Errorf("buna: units: unknown unit system %q", name)
+	}
+
+	return system, nil
+}
+
+// Prompts user for the unit system and stores it as the preference.
+func setUnitSystem(ctx context.Context, db DB) error {
+	session := sessionFromContext(ctx)
+	fmt.Printf("Setting unit system, currently %v (Enter "+session.quit+" to quit):\n", unitSystemToName[session.unitSystem])
+
+	fmt.Print("Enter the unit system: ")
+	name, quit := validateStrInput(quitExits(ctx), false, []string{unitSystemToName[metricUnits], unitSystemToName[imperialUnits]}, nil)
+	if quit != answered {
+		fmt.Println(quitMsg)
+		return nil
+	}
+
+	system, _ := parseUnitSystem(name)
+	if err := db.setPreference(ctx, unitSystemPreference, unitSystemToName[system]); err != nil {
+		return fmt.Errorf("buna: units: failed to set unit system preference: %w", err)
+	}
+	session.unitSystem = system
+
+	fmt.Printf("Weights are now entered and displayed in %v\n", system.unit(coffeeWeight).symbol)
+	return nil
+}
+
+// Parses a value of the quantity with an optional unit suffix, e.g. "0.5oz" or "200 ml".
+// Values without a suffix are in the unit of the unit system.
+// Returns the value in the SI unit of the quantity and false if the input is not a valid value.
+func (s unitSystem) parseQuantity(input string, q quantity) (float64, bool) {
+	input = strings.ToLower(strings.TrimSpace(input))
+
+	u := s.unit(q)
+	suffixLen := 0
+	for _, candidate := range quantityUnits[q] {
+		for _, suffix := range candidate.suffixes {
+			// The longest matching suffix wins, so "fl oz" is not read as "oz"
+			if strings.HasSuffix(input, suffix) && len(suffix) > suffixLen {
+				u = candidate
+				suffixLen = len(suffix)
+			}
+		}
+	}
+
+	value, err := strconv.ParseFloat(strings.TrimSpace(input[:len(input)-suffixLen]), 64)
+	if err != nil || math.IsNaN(value) || math.IsInf(value, 0) {
+		return 0, false
+	}
+
+	return value * u.factor, true
+}
+
+// Returns the SI value of the quantity in the unit of the unit system, rounded to the displayed decimals.
+func (s unitSystem) fromSI(siValue float64, q quantity) float64 {
+	u := s.unit(q)
+	scale := math.Pow(10, float64(u.decimals))
+
+	return math.Round(siValue/u.factor*scale) / scale
+}
+
+// Returns the SI value of the quantity for display in the unit of the unit system, e.g. "15" or "0.53".
+func (s unitSystem) formatQuantity(siValue float64, q quantity) string {
+	return strconv.FormatFloat(s.fromSI(siValue, q), 'f', -1, 64)
+}
+
+// Returns the SI value of the quantity for display in the unit of the unit system including the unit, e.g. "15 g" or "0.53 oz".
+func (s unitSystem) formatQuantityWithUnit(siValue float64, q quantity) string {
+	return s.formatQuantity(siValue, q) + " " + s.unit(q).symbol
+}
+
+// Returns a change of the quantity in SI units for display in the unit of the unit system, e.g. "+1 g" or "-0.04 oz".
+func (s unitSystem) formatQuantityChange(siChange float64, q quantity) string {
+	change := s.fromSI(siChange, q)
+
+	sign := ""
+	if change > 0 {
+		sign = "+"
+	}
+	return sign + strconv.FormatFloat(change, 'f', -1, 64) + " " + s.unit(q).symbol
+}
+
+// Returns the table header with the unit of the quantity on a new line, e.g. "Coffee\nWeight\n(g)".
+func (s unitSystem) quantityHeader(header string, q quantity) string {
+	return header + "\n(" + s.unit(q).symbol + ")"
+}
+
+// Second return value is how the prompt was left.
+// Optional values default to 0.
+// Inputs are in the unit of units unless a unit suffix is given.
+// The bounds and suggestions are in the SI unit of the quantity, as is the returned value.
+func validateQuantityInput(exits promptExits, units unitSystem, isOptional bool, q quantity, min float64, max float64, suggestions []float64) (float64, promptResult) {
+	parse := func(input string) (float64, bool) {
+		value, ok := units.parseQuantity(input, q)
+		return value, ok && value >= min && value <= max
+	}
+	format := func(value float64) string {
+		return units.formatQuantityWithUnit(value, q)
+	}
+	invalidMsg := fmt.Sprintf("Input invalid (%v <= x <= %v). Please try again: ", units.formatQuantityWithUnit(min, q), units.formatQuantityWithUnit(max, q))
+
+	return validateFloatInput(exits, isOptional, parse, invalidMsg, format, suggestions)
+}
@@ -0,0 +1,49 @@
+package buna
+
+import (
+	"math"
+	"testing"
+)
+
+func TestParseQuantity(t *testing.T) {
+	tests := []struct {
+		name   string
+		system unitSystem
+		input  string
+		q      quantity
+		want   float64
+		wantOK bool
+	}{
+		{"no suffix metric", metricUnits, "200", waterWeight, 200, true},
+		{"no suffix imperial", imperialUnits, "8", waterWeight, 8 * 28.349523125, true},
+		{"grams", metricUnits, "15g", coffeeWeight, 15, true},
+		{"grams word", metricUnits, "15 grams", coffeeWeight, 15, true},
+		{"kilograms over grams", metricUnits, "0.25 kg", coffeeWeight, 250, true},
+		{"ounces", metricUnits, "0.5oz", coffeeWeight, 0.5 * 28.349523125, true},
+		{"pounds", metricUnits, "1 lbs", coffeeWeight, 453.59237, true},
+		{"millilitres over litres", metricUnits, "250ml", waterWeight, 250, true},
+		{"litres", metricUnits, "0.3 l", waterWeight, 300, true},
+		{"fluid ounces over ounces", metricUnits, "8 fl oz", waterWeight, 8 * 29.5735295625, true},
+		{"fluid ounces without space", metricUnits, "8floz", waterWeight, 8 * 29.5735295625, true},
+		{"ounces of water", metricUnits, "8 oz", waterWeight, 8 * 28.349523125, true},
+		{"case and surrounding spaces", metricUnits, "  250 ML ", waterWeight, 250, true},
+		{"unit of another quantity", metricUnits, "15 ml", coffeeWeight, 0, false},
+		{"only a suffix", metricUnits, "ml", waterWeight, 0, false},
+		{"empty", metricUnits, "", waterWeight, 0, false},
+		{"not a number", metricUnits, "abc", waterWeight, 0, false},
+		{"NaN", metricUnits, "NaN", waterWeight, 0, false},
+		{"infinity", metricUnits, "inf", waterWeight, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := tt.system.parseQuantity(tt.input, tt.q)
+			if ok != tt.wantOK {
+				t.Fatalf("parseQuantity(%q) ok = %v, want %v", tt.input, ok, tt.wantOK)
+			}
+			if math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("parseQuantity(%q) = %v, want %v", tt.input, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,325 @@
+package buna
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/jedib0t/go-pretty/table"
+	"golang.org/x/crypto/ssh/terminal"
+)
+
+// A person sharing the database, who brews, buys and cups coffees under their profile.
+type user struct {
+	id   int
+	name string
+}
+
+// The average ratings of a coffee by two users, each user's at the same index.
+type userRatingComparison struct {
+	coffeeName     string
+	coffeeRoaster  string
+	brewingCounts  [2]int
+	averageRatings [2]float64
+}
+
+type userContextKey struct{}
+
+// Returns a context whose new brewings, purchases and cuppings are attributed to the user
+// and whose retrievals and statistics only include the user's entries.
+// A userID of 0 includes the entries of everyone.
+func withUser(ctx context.Context, userID int) context.Context {
+	return context.WithValue(ctx, userContextKey{}, userID)
+}
+
+// Returns the ID of the current user and whether a user is selected.
+func userFromContext(ctx context.Context) (int, bool) {
+	userID, ok := ctx.Value(userContextKey{}).(int)
+	return userID, ok && userID != 0
+}
+
+// Returns the :userID query argument, 0 if the entries of everyone are included.
+func userArg(ctx context.Context) sql.NamedArg {
+	userID, _ := userFromContext(ctx)
+	return sql.Named("userID", userID)
+}
+
+// WithUser returns a context with the profile of the user with the name as current profile.
+func WithUser(ctx context.Context, db DB, name string) (context.Context, error) {
+	u, err := db.getUserByName(ctx, name)
+	if errors.Is(err, sql.ErrNoRows) {
+		users, err := db.getUsers(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("buna: users: failed to get users: %w", err)
+		}
+
+		var names []string
+		for _, u := range users {
+			names = append(names, u.name)
+		}
+
+		return nil, unknownReferenceError("user", name, closestNames(name, names, 2))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("buna: users: failed to get user: %w", err)
+	}
+
+	return withUser(ctx, u.id), nil
+}
+
+// Selects the profile of defaults.user unless the context already has a profile.
+func withDefaultUser(ctx context.Context, db DB) (context.Context, error) {
+	name := sessionFromContext(ctx).config.text("defaults.user")
+	if _, ok := ctx.Value(userContextKey{}).(int); ok || name == "" {
+		return ctx, nil
+	}
+
+	ctx, err := WithUser(ctx, db, name)
+	if err != nil {
+		return nil, fmt.Errorf("buna: users: failed to select default user: %w", err)
+	}
+
+	return ctx, nil
+}
+
+// Prompts for the profile to use and returns a context with it.
+// The context is returned unchanged if it already has a profile or no users exist.
+func selectUser(ctx context.Context, db DB) (context.Context, error) {
+	if _, ok := ctx.Value(userContextKey{}).(int); ok {
+		return ctx, nil
+	}
+
+	users, err := db.getUsers(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("buna: users: failed to get users: %w", err)
+	}
+	if len(users) == 0 {
+		return ctx, nil
+	}
+
+	return switchUser(ctx, users)
+}
+
+// Prompts for the profile to switch to and returns a context with it.
+func switchProfile(ctx context.Context, db DB) (context.Context, error) {
+	users, err := db.getUsers(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("buna: users: failed to get users: %w", err)
+	}
+	if len(users) == 0 {
+		fmt.Println("No users exist. Add one with the New user option first.")
+		return ctx, nil
+	}
+
+	return switchUser(ctx, users)
+}
+
+func switchUser(ctx context.Context, users []user) (context.Context, error) {
+	options := map[int]string{0: "Everyone"}
+	for i, u := range users {
+		options[i+1] = u.name
+	}
+
+	session := sessionFromContext(ctx)
+	fmt.Println("Selecting profile (Enter " + session.quit + " to keep the current one):")
+	if err := displayIntOptions(ctx, options); err != nil {
+		return nil, fmt.Errorf("buna: users: failed to display int options: %w", err)
+	}
+
+	selection, quit, err := getIntSelection(ctx, options, quitExits(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("buna: users: failed to get int selection: %w", err)
+	}
+	if quit != answered {
+		fmt.Println(quitMsg)
+		return ctx, nil
+	}
+
+	if selection == 0 {
+		fmt.Println("Showing the entries of everyone")
+		return withUser(ctx, 0), nil
+	}
+
+	fmt.Println("Using the profile of " + users[selection-1].name)
+	return withUser(ctx, users[selection-1].id), nil
+}
+
+func addUser(ctx context.Context, db DB) error {
+	session := sessionFromContext(ctx)
+	fmt.Println("Adding new user (Enter " + session.quit + " to quit):")
+
+	fmt.Print("Enter user name: ")
+	name, quit := validateStrInput(quitExits(ctx), false, nil, nil)
+	if quit != answered {
+		fmt.Println(quitMsg)
+		return nil
+	}
+	name = strings.TrimSpace(name)
+
+	if _, err := db.getUserByName(ctx, name); err == nil {
+		fmt.Println("A user named " + name + " already exists")
+		return nil
+	} else if !errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("buna: users: failed to check for existing user: %w", err)
+	}
+
+	users, err := db.getUsers(ctx)
+	if err != nil {
+		return fmt.Errorf("buna: users: failed to get users: %w", err)
+	}
+
+	if err := db.insertUser(ctx, user{name: name}); err != nil {
+		return fmt.Errorf("buna: users: failed to insert user: %w", err)
+	}
+	fmt.Println("Added user successfully")
+
+	// Entries added before the first user have no user and are only shown to everyone
+	if len(users) == 0 {
+		if err := promptAssignUnattributedEntries(ctx, db, name); err != nil {
+			return fmt.Errorf("buna: users: failed to prompt for assigning existing entries: %w", err)
+		}
+	}
+
+	fmt.Println("Use the Switch profile option to brew as " + name)
+
+	return nil
+}
+
+func promptAssignUnattributedEntries(ctx context.Context, db DB, name string) error {
+	count, err := db.getTotalCount(withUser(ctx, 0), brewings)
+	if err != nil {
+		return fmt.Errorf("buna: users: failed to get brewings count: %w", err)
+	}
+	if count == 0 {
+		return nil
+	}
+
+	options := map[int]string{
+		0: "Keep them unattributed",
+		1: "Attribute them to " + name,
+	}
+
+	fmt.Println("Existing brewings, purchases and cuppings belong to no user:")
+	if err := displayIntOptions(ctx, options); err != nil {
+		return fmt.Errorf("buna: users: failed to display int options: %w", err)
+	}
+
+	selection, quit, err := getIntSelection(ctx, options, quitExits(ctx))
+	if err != nil {
+		return fmt.Errorf("buna: users: failed to get int selection: %w", err)
+	}
+	if quit != answered || selection == 0 {
+		return nil
+	}
+
+	u, err := db.getUserByName(ctx, name)
+	if err != nil {
+		return fmt.Errorf("buna: users: failed to get user: %w", err)
+	}
+
+	if err := db.assignUnattributedEntries(ctx, u.id); err != nil {
+		return fmt.Errorf("buna: users: failed to assign existing entries: %w", err)
+	}
+	fmt.Println("Attributed existing entries to " + name)
+
+	return nil
+}
+
+func compareUserRatings(ctx context.Context, db DB) error {
+	users, err := db.getUsers(ctx)
+	if err != nil {
+		return fmt.Errorf("buna: users: failed to get users: %w", err)
+	}
+	if len(users) < 2 {
+		fmt.Println("At least two users are needed to compare ratings")
+		return nil
+	}
+
+	options := make(map[int]string)
+	for i, u := range users {
+		options[i] = u.name
+	}
+
+	session := sessionFromContext(ctx)
+	fmt.Println("Comparing users' ratings (Enter " + session.quit + " to quit):")
+	if err := displayIntOptions(ctx, options); err != nil {
+		return fmt.Errorf("buna: users: failed to display int options: %w", err)
+	}
+
+	var selected [2]user
+	for i := range selected {
+		if i == 0 {
+			fmt.Println("Select the first user")
+		} else {
+			fmt.Println("Select the second user")
+		}
+
+		selection, quit, err := getIntSelection(ctx, options, quitExits(ctx))
+		if err != nil {
+			return fmt.Errorf("buna: users: failed to get int selection: %w", err)
+		}
+		if quit != answered {
+			fmt.Println(quitMsg)
+			return nil
+		}
+		selected[i] = users[selection]
+	}
+
+	if selected[0].id == selected[1].id {
+		fmt.Println("Select two different users to compare")
+		return nil
+	}
+
+	comparisons, err := db.getUserRatingComparisons(ctx, selected[0].id, selected[1].id)
+	if err != nil {
+		return fmt.Errorf("buna: users: failed to get user rating comparisons: %w", err)
+	}
+	if len(comparisons) == 0 {
+		fmt.Println(selected[0].name + " and " + selected[1].name + " have not rated any coffee in common")
+		return nil
+	}
+
+	if err := displayUserRatingComparisons(ctx, selected, comparisons); err != nil {
+		return fmt.Errorf("buna: users: failed to display user rating comparisons: %w", err)
+	}
+
+	return nil
+}
+
+func displayUserRatingComparisons(ctx context.Context, users [2]user, comparisons []userRatingComparison) error {
+	t := table.NewWriter()
+
+	t.AppendHeader(table.Row{
+		"Coffee",
+		"Roaster",
+		users[0].name + "\nAverage Rating",
+		users[1].name + "\nAverage Rating",
+		"Difference",
+	})
+
+	for _, comparison := range comparisons {
+		t.AppendRow(table.Row{
+			comparison.coffeeName,
+			comparison.coffeeRoaster,
+			fmt.Sprintf("%.1f (%d)", comparison.averageRatings[0], comparison.brewingCounts[0]),
+			fmt.Sprintf("%.1f (%d)", comparison.averageRatings[1], comparison.brewingCounts[1]),
+			fmt.Sprintf("%+.1f", comparison.averageRatings[0]-comparison.averageRatings[1]),
+		})
+		t.AppendSeparator()
+	}
+
+	terminalWidth, _, err := terminal.GetSize(int(os.Stdin.Fd()))
+	if err != nil {
+		return fmt.Errorf("buna: users: failed to get terminal width: %w", err)
+	}
+	t.SetAllowedRowLength(terminalWidth)
+
+	t.SetOutputMirror(os.Stdout)
+	renderTable(ctx, t)
+	fmt.Println("The number of rated brewings is in parentheses")
+
+	return nil
+}